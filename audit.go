@@ -0,0 +1,212 @@
+package pgraph
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+
+	"github.com/ritamzico/pgraph/internal/graph"
+)
+
+// ErrAuditLogDisabled is returned by Undo and SaveAuditLog when
+// EnableAuditLog has not been called.
+var ErrAuditLogDisabled = errors.New("audit log is not enabled")
+
+// MutationKind names the graph operation a MutationEvent records.
+type MutationKind string
+
+const (
+	MutationAddNode              MutationKind = "add_node"
+	MutationRemoveNode           MutationKind = "remove_node"
+	MutationAddEdge              MutationKind = "add_edge"
+	MutationAddBidirectionalEdge MutationKind = "add_bidirectional_edge"
+	MutationRemoveEdge           MutationKind = "remove_edge"
+	MutationUpdateEdge           MutationKind = "update_edge"
+	MutationUpdateEdgeInterval   MutationKind = "update_edge_interval"
+	MutationAddGroup             MutationKind = "add_group"
+	MutationSetNodeGroup         MutationKind = "set_node_group"
+	MutationCommitTx             MutationKind = "commit_tx"
+)
+
+// MutationArgs holds the arguments of a mutation. Only the fields used by
+// the event's kind are set: Node for node events, Edge for edge events,
+// From, To, Probability and Props for added edges, Probability for
+// update_edge, ProbLow and ProbHigh for update_edge_interval, Group and
+// Props for add_group, Node and Group for set_node_group, and Events, the
+// transaction's mutations in order, for commit_tx.
+type MutationArgs struct {
+	Node        NodeID
+	Edge        EdgeID
+	Group       string
+	From, To    NodeID
+	Probability float64
+	ProbLow     float64
+	ProbHigh    float64
+	Props       map[string]Value
+	Events      []MutationEvent
+}
+
+// MutationEvent is one successful mutation of the graph.
+type MutationEvent struct {
+	Kind MutationKind
+	Args MutationArgs
+}
+
+type auditLog struct {
+	base   graph.ProbabilisticGraphModel
+	events []MutationEvent
+}
+
+// EnableAuditLog starts recording every successful mutation, whether made
+// through Query or a method such as AddNode, so that it can be undone. The
+// log starts from the current graph; replaying it against a copy of that
+// graph, or against an empty graph if the log was enabled on one, rebuilds
+// the graph. A committed transaction is recorded as one event, so Undo(1)
+// reverts all of it. Calling EnableAuditLog again restarts the log.
+func (p *PGraph) EnableAuditLog() {
+	p.observe()
+	p.hooks.audit = &auditLog{base: p.parser.SessionGraph.Clone()}
+}
+
+// AuditLog returns a copy of the recorded events, oldest first.
+func (p *PGraph) AuditLog() []MutationEvent {
+	if p.hooks.audit == nil {
+		return nil
+	}
+	return append([]MutationEvent(nil), p.hooks.audit.events...)
+}
+
+// Undo reverts the n most recent mutations by replaying the rest of the
+// log from the state it started from. The undone events are dropped from
+// the log.
+func (p *PGraph) Undo(n int) error {
+	log := p.hooks.audit
+	if log == nil {
+		return ErrAuditLogDisabled
+	}
+	if n < 0 || n > len(log.events) {
+		return fmt.Errorf("cannot undo %d of %d recorded mutations", n, len(log.events))
+	}
+
+	keep := log.events[:len(log.events)-n]
+	g := log.base.Clone()
+	for i, ev := range keep {
+		if err := applyMutation(g, ev); err != nil {
+			return fmt.Errorf("replaying mutation %d (%s): %w", i, ev.Kind, err)
+		}
+	}
+	p.parser.ReplaceSessionGraph(hookedGraph{ProbabilisticGraphModel: g, h: &p.hooks})
+	log.events = keep
+	return nil
+}
+
+// recordCommit records a transaction's mutations as one commit_tx event.
+// Commit has checked that the graph is unchanged since BeginTx, so the log
+// ends at the state the transaction started from.
+func (p *PGraph) recordCommit(events []MutationEvent) {
+	log := p.hooks.audit
+	if log == nil {
+		return
+	}
+	log.events = append(log.events, MutationEvent{
+		Kind: MutationCommitTx,
+		Args: MutationArgs{Events: events},
+	})
+}
+
+type jsonMutationEvent struct {
+	Kind MutationKind     `json:"kind"`
+	Args jsonMutationArgs `json:"args"`
+}
+
+type jsonMutationArgs struct {
+	Node        string              `json:"node,omitempty"`
+	Edge        string              `json:"edge,omitempty"`
+	Group       string              `json:"group,omitempty"`
+	From        string              `json:"from,omitempty"`
+	To          string              `json:"to,omitempty"`
+	Probability float64             `json:"probability,omitempty"`
+	ProbLow     float64             `json:"prob_low,omitempty"`
+	ProbHigh    float64             `json:"prob_high,omitempty"`
+	Props       map[string]any      `json:"props,omitempty"`
+	Events      []jsonMutationEvent `json:"events,omitempty"`
+}
+
+// SaveAuditLog writes the recorded events to w as a JSON array of
+// {"kind": ..., "args": {...}} objects, oldest first. A commit_tx event
+// holds its transaction's events in the same form under "events".
+func (p *PGraph) SaveAuditLog(w io.Writer) error {
+	if p.hooks.audit == nil {
+		return ErrAuditLogDisabled
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(jsonMutationEvents(p.hooks.audit.events))
+}
+
+func jsonMutationEvents(events []MutationEvent) []jsonMutationEvent {
+	out := make([]jsonMutationEvent, len(events))
+	for i, ev := range events {
+		a := ev.Args
+		out[i] = jsonMutationEvent{
+			Kind: ev.Kind,
+			Args: jsonMutationArgs{
+				Node:        string(a.Node),
+				Edge:        string(a.Edge),
+				Group:       a.Group,
+				From:        string(a.From),
+				To:          string(a.To),
+				Probability: a.Probability,
+				ProbLow:     a.ProbLow,
+				ProbHigh:    a.ProbHigh,
+				Props:       jsonProps(a.Props),
+				Events:      jsonMutationEvents(a.Events),
+			},
+		}
+	}
+	return out
+}
+
+// record appends ev to the audit log, if it is enabled.
+func (h *hooks) record(kind MutationKind, args MutationArgs) {
+	if h.audit == nil {
+		return
+	}
+	args.Props = maps.Clone(args.Props)
+	h.audit.events = append(h.audit.events, MutationEvent{Kind: kind, Args: args})
+}
+
+func applyMutation(g graph.ProbabilisticGraphModel, ev MutationEvent) error {
+	a := ev.Args
+	switch ev.Kind {
+	case MutationAddNode:
+		return g.AddNode(a.Node, a.Props)
+	case MutationRemoveNode:
+		return g.RemoveNode(a.Node)
+	case MutationAddEdge:
+		return g.AddEdge(a.Edge, a.From, a.To, a.Probability, a.Props)
+	case MutationAddBidirectionalEdge:
+		return g.AddBidirectionalEdge(a.Edge, a.From, a.To, a.Probability, a.Props)
+	case MutationRemoveEdge:
+		return g.RemoveEdgeByID(a.Edge)
+	case MutationUpdateEdge:
+		return g.UpdateEdge(a.Edge, a.Probability)
+	case MutationUpdateEdgeInterval:
+		return g.UpdateEdgeInterval(a.Edge, a.ProbLow, a.ProbHigh)
+	case MutationAddGroup:
+		return g.AddGroup(a.Group, a.Props)
+	case MutationSetNodeGroup:
+		return g.SetNodeGroup(a.Node, a.Group)
+	case MutationCommitTx:
+		for _, staged := range a.Events {
+			if err := applyMutation(g, staged); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown mutation kind %q", ev.Kind)
+	}
+}
@@ -0,0 +1,242 @@
+package pgraph
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestAuditLog_RecordsMutations(t *testing.T) {
+	pg := newTestPGraph(t)
+	pg.EnableAuditLog()
+
+	if _, err := pg.Query("CREATE NODE C"); err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if err := pg.AddEdge("eBC", "B", "C", 0.5, nil); err != nil {
+		t.Fatalf("AddEdge: %v", err)
+	}
+	if err := pg.UpdateEdge("eAB", 0.4); err != nil {
+		t.Fatalf("UpdateEdge: %v", err)
+	}
+	// Failed mutations are not recorded.
+	if err := pg.AddNode("C", nil); err == nil {
+		t.Fatal("expected duplicate node error")
+	}
+	if err := pg.RemoveNode("A"); err != nil {
+		t.Fatalf("RemoveNode: %v", err)
+	}
+
+	var kinds []MutationKind
+	for _, ev := range pg.AuditLog() {
+		kinds = append(kinds, ev.Kind)
+	}
+	want := []MutationKind{MutationAddNode, MutationAddEdge, MutationUpdateEdge, MutationRemoveNode}
+	if len(kinds) != len(want) {
+		t.Fatalf("recorded %v, want %v", kinds, want)
+	}
+	for i := range want {
+		if kinds[i] != want[i] {
+			t.Errorf("event %d: kind %s, want %s", i, kinds[i], want[i])
+		}
+	}
+}
+
+func TestAuditLog_Undo(t *testing.T) {
+	pg := newTestPGraph(t)
+	pg.EnableAuditLog()
+
+	if err := pg.AddNode("C", nil); err != nil {
+		t.Fatalf("AddNode: %v", err)
+	}
+	if err := pg.AddEdge("eBC", "B", "C", 0.5, nil); err != nil {
+		t.Fatalf("AddEdge: %v", err)
+	}
+	if err := pg.UpdateEdge("eAB", 0.4); err != nil {
+		t.Fatalf("UpdateEdge: %v", err)
+	}
+
+	if err := pg.Undo(1); err != nil {
+		t.Fatalf("Undo(1): %v", err)
+	}
+	res, err := pg.Query("REACHABILITY FROM A TO C EXACT")
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if p := res.(ProbabilityResult).Probability; math.Abs(p-0.45) > 1e-12 {
+		t.Errorf("expected eAB back at 0.9 giving 0.45, got %f", p)
+	}
+	if n := len(pg.AuditLog()); n != 2 {
+		t.Errorf("expected 2 events after undo, got %d", n)
+	}
+
+	if err := pg.Undo(2); err != nil {
+		t.Fatalf("Undo(2): %v", err)
+	}
+	if s := pg.Stats(); s.NodeCount != 2 || s.EdgeCount != 1 {
+		t.Errorf("expected the original 2 nodes and 1 edge, got %d and %d", s.NodeCount, s.EdgeCount)
+	}
+
+	// The restored graph is still observed.
+	if err := pg.AddNode("D", nil); err != nil {
+		t.Fatalf("AddNode: %v", err)
+	}
+	if n := len(pg.AuditLog()); n != 1 {
+		t.Errorf("expected mutations after undo to be recorded, got %d events", n)
+	}
+
+	if err := pg.Undo(2); err == nil {
+		t.Error("expected error undoing more events than recorded")
+	}
+	if err := pg.Undo(-1); err == nil {
+		t.Error("expected error for negative count")
+	}
+}
+
+func TestAuditLog_UndoGroups(t *testing.T) {
+	pg := newTestPGraph(t)
+	pg.EnableAuditLog()
+
+	for _, q := range []string{`CREATE GROUP g { tier: 1 }`, `CREATE NODE C GROUP g`, `CREATE NODE D`} {
+		if _, err := pg.Query(q); err != nil {
+			t.Fatalf("%q: %v", q, err)
+		}
+	}
+	if err := pg.Undo(1); err != nil {
+		t.Fatalf("Undo(1): %v", err)
+	}
+	res, err := pg.Query("FIND NODES WHERE tier = 1")
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if nodes := res.(NodeListResult).Nodes; len(nodes) != 1 || nodes[0].ID != "C" {
+		t.Errorf("expected replay to keep C in group g, got %v", nodes)
+	}
+}
+
+func TestAuditLog_Disabled(t *testing.T) {
+	pg := newTestPGraph(t)
+	if err := pg.Undo(1); !errors.Is(err, ErrAuditLogDisabled) {
+		t.Errorf("Undo: expected ErrAuditLogDisabled, got %v", err)
+	}
+	if err := pg.SaveAuditLog(&bytes.Buffer{}); !errors.Is(err, ErrAuditLogDisabled) {
+		t.Errorf("SaveAuditLog: expected ErrAuditLogDisabled, got %v", err)
+	}
+}
+
+func TestAuditLog_TxCommitIsOneEvent(t *testing.T) {
+	pg := newTestPGraph(t)
+	pg.EnableAuditLog()
+	if err := pg.AddNode("C", nil); err != nil {
+		t.Fatalf("AddNode: %v", err)
+	}
+
+	tx := pg.BeginTx()
+	if _, err := tx.Query("CREATE NODE D, E"); err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+
+	log := pg.AuditLog()
+	if len(log) != 2 || log[0].Args.Node != "C" || log[1].Kind != MutationCommitTx {
+		t.Fatalf("expected add_node C then commit_tx, got %+v", log)
+	}
+	if staged := log[1].Args.Events; len(staged) != 2 || staged[0].Args.Node != "D" || staged[1].Args.Node != "E" {
+		t.Errorf("expected the commit to hold add_node D and E, got %+v", staged)
+	}
+
+	if err := pg.AddNode("F", nil); err != nil {
+		t.Fatalf("AddNode: %v", err)
+	}
+	if err := pg.Undo(1); err != nil {
+		t.Fatalf("Undo: %v", err)
+	}
+	if s := pg.Stats(); s.NodeCount != 5 {
+		t.Errorf("expected A to E after undoing F, got %d nodes", s.NodeCount)
+	}
+	if err := pg.Undo(1); err != nil {
+		t.Fatalf("Undo: %v", err)
+	}
+	if s := pg.Stats(); s.NodeCount != 3 {
+		t.Errorf("expected A, B and C after undoing the commit, got %d nodes", s.NodeCount)
+	}
+}
+
+func TestAuditLog_UndoAfterBeginTxConflicts(t *testing.T) {
+	pg := newTestPGraph(t)
+	pg.EnableAuditLog()
+	if err := pg.AddNode("C", nil); err != nil {
+		t.Fatalf("AddNode: %v", err)
+	}
+
+	tx := pg.BeginTx()
+	if err := tx.AddNode("D", nil); err != nil {
+		t.Fatalf("AddNode: %v", err)
+	}
+	if err := pg.Undo(1); err != nil {
+		t.Fatalf("Undo: %v", err)
+	}
+	if err := tx.Commit(); !errors.Is(err, ErrTxConflict) {
+		t.Fatalf("expected ErrTxConflict, got %v", err)
+	}
+	if n := len(pg.AuditLog()); n != 0 {
+		t.Errorf("expected the undone log to be left empty, got %d events", n)
+	}
+	if s := pg.Stats(); s.NodeCount != 2 {
+		t.Errorf("expected A and B after the failed commit, got %d nodes", s.NodeCount)
+	}
+}
+
+func TestAuditLog_SaveJSON(t *testing.T) {
+	pg := New()
+	pg.EnableAuditLog()
+	if _, err := pg.Query(`CREATE NODE A { label: "x" }`); err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if err := pg.AddNode("B", nil); err != nil {
+		t.Fatalf("AddNode: %v", err)
+	}
+	if err := pg.AddEdge("eAB", "A", "B", 0.9, nil); err != nil {
+		t.Fatalf("AddEdge: %v", err)
+	}
+	tx := pg.BeginTx()
+	if err := tx.AddNode("C", nil); err != nil {
+		t.Fatalf("AddNode: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := pg.SaveAuditLog(&buf); err != nil {
+		t.Fatalf("SaveAuditLog: %v", err)
+	}
+	var events []struct {
+		Kind string         `json:"kind"`
+		Args map[string]any `json:"args"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &events); err != nil {
+		t.Fatalf("invalid JSON %s: %v", buf.String(), err)
+	}
+	if len(events) != 4 {
+		t.Fatalf("expected 4 events, got %d", len(events))
+	}
+	if events[0].Kind != "add_node" || events[0].Args["node"] != "A" {
+		t.Errorf("unexpected first event %+v", events[0])
+	}
+	if props, _ := events[0].Args["props"].(map[string]any); props["label"] != "x" {
+		t.Errorf("expected props to be saved, got %+v", events[0].Args)
+	}
+	e := events[2]
+	if e.Kind != "add_edge" || e.Args["edge"] != "eAB" || e.Args["from"] != "A" || e.Args["to"] != "B" || e.Args["probability"] != 0.9 {
+		t.Errorf("unexpected edge event %+v", e)
+	}
+	staged, _ := events[3].Args["events"].([]any)
+	if events[3].Kind != "commit_tx" || len(staged) != 1 {
+		t.Errorf("unexpected commit event %+v", events[3])
+	}
+}
@@ -0,0 +1,62 @@
+package pgraph
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ritamzico/pgraph/internal/dsl"
+	"github.com/ritamzico/pgraph/internal/query"
+)
+
+// ReachabilityRun is one inference run of BenchmarkReachability.
+type ReachabilityRun struct {
+	// Samples is the Monte Carlo sample count, or zero for the exact run.
+	Samples int
+	// Result is the exact run's ProbabilityResult (a
+	// ProbabilityIntervalResult on a graph with interval probabilities) or
+	// a Monte Carlo run's SampleResult.
+	Result   Result
+	Duration time.Duration
+}
+
+// BenchmarkReachability computes the reachability probability from start to
+// end exactly, then by Monte Carlo with each of the given sample counts,
+// timing every run. The exact run comes first in the result. It stops at
+// the first error, including ctx's.
+func (p *PGraph) BenchmarkReachability(ctx context.Context, start, end NodeID, samples ...int) ([]ReachabilityRun, error) {
+	return benchmarkReachability(ctx, p.parser, start, end, samples)
+}
+
+// BenchmarkReachability is PGraph.BenchmarkReachability on the
+// transaction's graph.
+func (tx *PGraphTx) BenchmarkReachability(ctx context.Context, start, end NodeID, samples ...int) ([]ReachabilityRun, error) {
+	if tx.done {
+		return nil, ErrTxDone
+	}
+	return benchmarkReachability(ctx, tx.parser, start, end, samples)
+}
+
+func benchmarkReachability(ctx context.Context, parser dsl.Parser, start, end NodeID, samples []int) ([]ReachabilityRun, error) {
+	queries := []query.ReachabilityProbabilityQuery{{Start: start, End: end, Mode: query.Exact}}
+	for _, n := range samples {
+		if n <= 0 {
+			return nil, query.QueryError{
+				Kind:    "InvalidParameter",
+				Message: fmt.Sprintf("sample count must be positive, got %d", n),
+			}
+		}
+		queries = append(queries, query.ReachabilityProbabilityQuery{Start: start, End: end, Mode: query.MonteCarlo, Samples: n})
+	}
+
+	runs := make([]ReachabilityRun, 0, len(queries))
+	for _, q := range queries {
+		began := time.Now()
+		res, err := parser.ExecuteQuery(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, ReachabilityRun{Samples: q.Samples, Result: res, Duration: time.Since(began)})
+	}
+	return runs, nil
+}
@@ -0,0 +1,66 @@
+package pgraph
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestBenchmarkReachability(t *testing.T) {
+	pg := newTestPGraph(t)
+	runs, err := pg.BenchmarkReachability(context.Background(), "A", "B", 1000, 20000)
+	if err != nil {
+		t.Fatalf("BenchmarkReachability: %v", err)
+	}
+	if len(runs) != 3 {
+		t.Fatalf("expected 3 runs, got %d", len(runs))
+	}
+
+	exact, ok := runs[0].Result.(ProbabilityResult)
+	if !ok || runs[0].Samples != 0 || exact.Probability != 0.9 {
+		t.Errorf("expected the exact run first with probability 0.9, got %+v", runs[0])
+	}
+	for i, want := range []int{1000, 20000} {
+		run := runs[i+1]
+		sample, ok := run.Result.(SampleResult)
+		if !ok || run.Samples != want || sample.NumSamples != want {
+			t.Errorf("run %d: expected a SampleResult of %d samples, got %+v", i+1, want, run)
+			continue
+		}
+		if math.Abs(sample.Estimate-0.9) > 0.05 {
+			t.Errorf("run %d: estimate %f too far from 0.9", i+1, sample.Estimate)
+		}
+	}
+}
+
+func TestBenchmarkReachability_Errors(t *testing.T) {
+	pg := newTestPGraph(t)
+	if _, err := pg.BenchmarkReachability(context.Background(), "A", "Z", 1000); err == nil {
+		t.Error("expected error for missing node")
+	}
+	if _, err := pg.BenchmarkReachability(context.Background(), "A", "B", 0); err == nil {
+		t.Error("expected error for zero samples")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := pg.BenchmarkReachability(ctx, "A", "B", 1000); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+
+	// A transaction benchmarks its own graph.
+	tx := pg.BeginTx()
+	if err := tx.AddNode("C", nil); err != nil {
+		t.Fatalf("AddNode: %v", err)
+	}
+	if _, err := tx.BenchmarkReachability(context.Background(), "A", "C", 1000); err != nil {
+		t.Errorf("expected the transaction's node C to be found, got %v", err)
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatalf("Rollback: %v", err)
+	}
+	if _, err := tx.BenchmarkReachability(context.Background(), "A", "B", 1000); !errors.Is(err, ErrTxDone) {
+		t.Errorf("expected ErrTxDone, got %v", err)
+	}
+}
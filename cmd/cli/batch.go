@@ -13,8 +13,9 @@ import (
 )
 
 type batchOpts struct {
-	jsonOutput      bool
-	continueOnError bool
+	jsonOutput       bool
+	continueOnError  bool
+	noVerifyChecksum bool
 }
 
 // runBatch executes a .pgraph script file line by line against a fresh session.
@@ -30,6 +31,7 @@ func runBatch(filename string, opts batchOpts, stdout, stderr io.Writer) int {
 	defer f.Close()
 
 	s := newSession() // scanner is nil → batch mode (auto-confirms saves)
+	s.skipChecksum = opts.noVerifyChecksum
 	scanner := bufio.NewScanner(f)
 	lineNum := 0
 	hasErrors := false
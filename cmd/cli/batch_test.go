@@ -86,6 +86,27 @@ REACHABILITY FROM A TO B EXACT
 	}
 }
 
+func TestRunBatch_FailedAssertionStopsScript(t *testing.T) {
+	script := writeScript(t, `new g
+CREATE NODE A, B
+CREATE EDGE e1 FROM A TO B PROB 0.7
+ASSERT REACHABILITY FROM A TO B EXACT >= 0.5
+ASSERT REACHABILITY FROM A TO B EXACT >= 0.8
+CREATE NODE C
+`)
+	var stdout, stderr strings.Builder
+	code := runBatch(script, batchOpts{}, &stdout, &stderr)
+	if code != 1 {
+		t.Errorf("expected exit code 1, got %d", code)
+	}
+	if !strings.Contains(stderr.String(), "line 5") || !strings.Contains(stderr.String(), "assertion failed: expected >= 0.8, got 0.7") {
+		t.Errorf("expected the failed assertion on line 5 in stderr, got:\n%s", stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "Result: true") {
+		t.Errorf("expected the passing assertion's result in output, got:\n%s", stdout.String())
+	}
+}
+
 func TestRunBatch_ContinueOnError_ReportsAllErrors(t *testing.T) {
 	script := writeScript(t, `new g
 INVALID ONE
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	pgraph "github.com/ritamzico/pgraph"
+)
+
+// benchmarkSamples are the Monte Carlo sample counts compared with exact
+// inference by the benchmark command.
+var benchmarkSamples = []int{1000, 10000, 100000}
+
+// benchmark handles "benchmark REACHABILITY FROM <a> TO <b>".
+func (s *sessionState) benchmark(args []string) (pgraph.Result, string, error) {
+	if len(args) != 5 || !strings.EqualFold(args[0], "REACHABILITY") ||
+		!strings.EqualFold(args[1], "FROM") || !strings.EqualFold(args[3], "TO") {
+		return nil, "", fmt.Errorf("usage: benchmark REACHABILITY FROM <a> TO <b>")
+	}
+	if s.active == "" {
+		return nil, "", fmt.Errorf("no active graph — use 'load', 'use', or 'new' first")
+	}
+
+	start, end := pgraph.NodeID(args[2]), pgraph.NodeID(args[4])
+	entry := s.graphs[s.active]
+	var runs []pgraph.ReachabilityRun
+	var err error
+	if entry.tx != nil {
+		runs, err = entry.tx.BenchmarkReachability(context.Background(), start, end, benchmarkSamples...)
+	} else {
+		runs, err = entry.pg.BenchmarkReachability(context.Background(), start, end, benchmarkSamples...)
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("query error: %w", err)
+	}
+	return nil, formatBenchmark(runs), nil
+}
+
+// formatBenchmark tabulates runs, whose first entry is the exact run. Each
+// Monte Carlo estimate's error is its distance from the exact probability,
+// or from the nearest end of the exact interval.
+func formatBenchmark(runs []pgraph.ReachabilityRun) string {
+	var sb strings.Builder
+	w := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "MODE\tSAMPLES\tESTIMATE\t95% CI\tERROR\tTIME")
+
+	low, high := 0.0, 0.0
+	for _, run := range runs {
+		elapsed := run.Duration.Round(time.Microsecond)
+		switch r := run.Result.(type) {
+		case pgraph.ProbabilityResult:
+			low, high = r.Probability, r.Probability
+			fmt.Fprintf(w, "EXACT\t-\t%.6f\t-\t-\t%s\n", r.Probability, elapsed)
+		case pgraph.ProbabilityIntervalResult:
+			low, high = r.Low, r.High
+			fmt.Fprintf(w, "EXACT\t-\t[%.6f, %.6f]\t-\t-\t%s\n", r.Low, r.High, elapsed)
+		case pgraph.SampleResult:
+			deviation := math.Max(0, math.Max(low-r.Estimate, r.Estimate-high))
+			fmt.Fprintf(w, "MONTECARLO\t%d\t%.6f\t[%.6f, %.6f]\t%.6f\t%s\n",
+				run.Samples, r.Estimate, r.CI95Low, r.CI95High, deviation, elapsed)
+		}
+	}
+	w.Flush()
+	return strings.TrimRight(sb.String(), "\n")
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+
+	pgraph "github.com/ritamzico/pgraph"
+)
+
+// Example graphs are built into the binary so new users can experiment
+// without a graph file of their own.
+//
+//go:embed examples/*.json
+var exampleFS embed.FS
+
+// exampleNames returns the embedded example names, without the .json
+// extension, in sorted order.
+func exampleNames() []string {
+	entries, _ := fs.ReadDir(exampleFS, "examples")
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		names = append(names, strings.TrimSuffix(e.Name(), ".json"))
+	}
+	return names
+}
+
+func loadExample(name string) (*pgraph.PGraph, error) {
+	f, err := exampleFS.Open(path.Join("examples", name+".json"))
+	if err != nil {
+		return nil, fmt.Errorf("no example named %q (available: %s)", name, strings.Join(exampleNames(), ", "))
+	}
+	defer f.Close()
+	return pgraph.Load(f)
+}
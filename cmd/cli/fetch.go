@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	pgraph "github.com/ritamzico/pgraph"
+)
+
+// fetchTimeout bounds the whole HTTP request, including reading the body,
+// when a graph is loaded from or saved to a URL.
+const fetchTimeout = 30 * time.Second
+
+// maxFetchSize bounds the response body read when a graph is loaded from a
+// URL. It is a variable so tests can lower it.
+var maxFetchSize int64 = 64 << 20
+
+// isURL reports whether a load argument names an HTTP(S) URL rather than a
+// file.
+func isURL(path string) bool {
+	return strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://")
+}
+
+// readGraphSource returns the contents of path, fetching it with a GET
+// request if it is an HTTP(S) URL and reading it from disk otherwise. A
+// fetched graph is also written to its cache file; see cachePath.
+func readGraphSource(path string) ([]byte, error) {
+	if !isURL(path) {
+		return os.ReadFile(path)
+	}
+
+	client := http.Client{Timeout: fetchTimeout}
+	resp, err := client.Get(path)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("server returned %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxFetchSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxFetchSize {
+		return nil, fmt.Errorf("response is larger than the %d byte limit", maxFetchSize)
+	}
+	if _, err := writeCache(path, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// saveToURL writes pg to url's cache file and then uploads it with a PUT
+// request, for servers that accept writes. If the upload fails, the graph is
+// still in the cache file, whose path is returned with the error.
+func saveToURL(pg *pgraph.PGraph, url string) (string, error) {
+	var buf bytes.Buffer
+	if err := pg.Save(&buf); err != nil {
+		return "", err
+	}
+	cached, err := writeCache(url, buf.Bytes())
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		return cached, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	client := http.Client{Timeout: fetchTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return cached, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return cached, fmt.Errorf("server returned %s", resp.Status)
+	}
+	return cached, nil
+}
+
+// cachePath returns the local file holding the last copy of the graph
+// fetched from or saved to url, under the user's cache directory.
+func cachePath(url string) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(dir, "pgraph", "fetched", hex.EncodeToString(sum[:12])+".json"), nil
+}
+
+// writeCache stores data as url's cache file and returns its path.
+func writeCache(url string, data []byte) (string, error) {
+	path, err := cachePath(url)
+	if err != nil {
+		return "", fmt.Errorf("caching %s: %w", url, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", fmt.Errorf("caching %s: %w", url, err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return "", fmt.Errorf("caching %s: %w", url, err)
+	}
+	return path, nil
+}
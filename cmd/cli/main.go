@@ -2,21 +2,44 @@ package main
 
 import (
 	"bufio"
+	"context"
 	"errors"
 	"fmt"
 	"os"
+	"os/signal"
 	"strings"
+	"time"
 )
 
 const helpText = `pgraph interactive REPL
 
 Commands:
   new <name>           Create a new empty graph
-  load <name> <file>   Load a graph from a JSON file
+  new <name> TRANSPOSE <existing>
+                       Create a graph with every edge of <existing> reversed
+  clone AS <name>      Copy the active graph into a new graph <name>
+  load <name> <file>   Load a graph from a JSON file or http(s):// URL
+  load --example <example> [name]
+                       Load a built-in example graph ("load --example list" lists them)
+  generate <name> ERDOS_RENYI NODES <n> EDGE_PROB <p> [SEED <s>]
+  generate <name> SCALE_FREE NODES <n> [SEED <s>]
+                       Create a random graph (edge probabilities in [0.5, 1.0])
   save <name> [file]   Save a graph to a JSON file
   unload <name>        Remove a loaded graph
   list                 List all loaded graphs
   use <name>           Set the active graph for queries
+  print [graph] [name] Show the active (or named) graph as an adjacency list
+  visualize <file> [<refresh>]
+                       Write the active graph as an HTML+SVG page (reloading every <refresh>, e.g. 5s)
+  jaccard <a> <b>      Compare the edge sets of two graphs (shared / all edges)
+  begin                Start a transaction on the active graph
+  commit               Apply the open transaction's changes
+  rollback             Discard the open transaction's changes
+  profile <n> <query>  Run <query> n times and report min/max/mean/p95 latency
+  benchmark REACHABILITY FROM <a> TO <b>
+                       Compare exact reachability with Monte Carlo at 1k/10k/100k samples
+  watch <interval> <query>
+                       Re-run <query> every <interval> (e.g. 5s) until Ctrl-C
   help                 Show this help message
   exit / quit          Exit the REPL
 
@@ -31,11 +54,18 @@ DSL examples:
   CREATE EDGE e1 FROM nodeA TO nodeB PROB 0.8
 
 Batch mode:
-  pgraph-cli run <script.pgraph> [--json] [--continue]
+  pgraph-cli run <script.pgraph> [--json] [--continue] [--no-verify-checksum]
+
+Pass --no-verify-checksum, in either mode, to load graph files without
+checking their checksum.
+
+Interactive flags:
+  --query-timeout <duration>
+                       Cancel a DSL query that runs longer than <duration> (e.g. 30s)
 `
 
 func main() {
-	// Batch mode: pgraph-cli run <file> [--json] [--continue]
+	// Batch mode: pgraph-cli run <file> [--json] [--continue] [--no-verify-checksum]
 	if len(os.Args) >= 3 && strings.ToLower(os.Args[1]) == "run" {
 		filename := os.Args[2]
 		var opts batchOpts
@@ -45,13 +75,19 @@ func main() {
 				opts.jsonOutput = true
 			case "--continue":
 				opts.continueOnError = true
+			case "--no-verify-checksum":
+				opts.noVerifyChecksum = true
 			}
 		}
 		os.Exit(runBatch(filename, opts, os.Stdout, os.Stderr))
 	}
 
-	// Interactive REPL
+	// Interactive REPL: pgraph-cli [--no-verify-checksum] [--query-timeout <duration>]
 	s := newSession()
+	if err := s.parseFlags(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
 	scanner := bufio.NewScanner(os.Stdin)
 	s.scanner = scanner
 
@@ -75,12 +111,22 @@ func main() {
 			continue
 		}
 
+		if strings.EqualFold(strings.Fields(line)[0], "watch") {
+			if err := runWatch(s, line); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+			}
+			continue
+		}
+
 		res, msg, err := s.processLine(line)
 		if err != nil {
 			if errors.Is(err, errExit) {
 				return
 			}
 			fmt.Fprintln(os.Stderr, err)
+			if marker := syntaxErrorMarker(line, err); marker != "" {
+				fmt.Fprintln(os.Stderr, marker)
+			}
 			continue
 		}
 
@@ -91,3 +137,36 @@ func main() {
 		}
 	}
 }
+
+// parseFlags applies the REPL's command-line flags to s.
+func (s *sessionState) parseFlags(args []string) error {
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--no-verify-checksum":
+			s.skipChecksum = true
+		case "--query-timeout":
+			if i+1 == len(args) {
+				return fmt.Errorf("usage: --query-timeout <duration>")
+			}
+			i++
+			d, err := time.ParseDuration(args[i])
+			if err != nil || d <= 0 {
+				return fmt.Errorf("query timeout must be a positive duration, got %q", args[i])
+			}
+			s.queryTimeout = d
+		}
+	}
+	return nil
+}
+
+// runWatch runs a watch command until Ctrl-C, which ends the watch but not
+// the REPL.
+func runWatch(s *sessionState, line string) error {
+	interval, query, err := parseWatch(line)
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	return s.watch(ctx, interval, query, os.Stdout, isTerminal(os.Stdout))
+}
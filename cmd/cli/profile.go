@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"time"
+)
+
+type profileStats struct {
+	runs                int
+	min, max, mean, p95 time.Duration
+}
+
+// profile calls run n times and summarizes the wall-clock durations. It
+// stops at the first error.
+func profile(n int, run func() error) (profileStats, error) {
+	durations := make([]time.Duration, n)
+	var total time.Duration
+	for i := range n {
+		start := time.Now()
+		if err := run(); err != nil {
+			return profileStats{}, err
+		}
+		durations[i] = time.Since(start)
+		total += durations[i]
+	}
+
+	slices.Sort(durations)
+	return profileStats{
+		runs: n,
+		min:  durations[0],
+		max:  durations[n-1],
+		mean: total / time.Duration(n),
+		p95:  durations[percentileIndex(n, 0.95)],
+	}, nil
+}
+
+// percentileIndex returns the nearest-rank index of percentile p in a sorted
+// sample of size n.
+func percentileIndex(n int, p float64) int {
+	rank := int(math.Ceil(float64(n) * p))
+	return max(min(rank, n), 1) - 1
+}
+
+func (s profileStats) String() string {
+	return fmt.Sprintf("%d runs: min %s  max %s  mean %s  p95 %s", s.runs, s.min, s.max, s.mean, s.p95)
+}
@@ -2,9 +2,16 @@ package main
 
 import (
 	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"math/rand/v2"
+	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	pgraph "github.com/ritamzico/pgraph"
 )
@@ -14,13 +21,17 @@ var errExit = errors.New("exit")
 
 type graphEntry struct {
 	pg         *pgraph.PGraph
-	sourcePath string // empty if created via "new"
+	sourcePath string           // empty if created via "new"
+	tx         *pgraph.PGraphTx // non-nil between "begin" and "commit"/"rollback"
 }
 
 type sessionState struct {
 	graphs  map[string]*graphEntry
 	active  string
 	scanner *bufio.Scanner // non-nil in interactive mode; nil in batch (auto-confirms saves)
+
+	skipChecksum bool          // set by --no-verify-checksum
+	queryTimeout time.Duration // set by --query-timeout; zero means none
 }
 
 func newSession() *sessionState {
@@ -59,16 +70,85 @@ func (s *sessionState) processLine(line string) (pgraph.Result, string, error) {
 		return nil, strings.TrimRight(sb.String(), "\n"), nil
 
 	case "new":
-		if len(parts) < 2 {
-			return nil, "", fmt.Errorf("usage: new <name>")
+		if len(parts) != 2 && (len(parts) != 4 || strings.ToUpper(parts[2]) != "TRANSPOSE") {
+			return nil, "", fmt.Errorf("usage: new <name> [TRANSPOSE <existing>]")
 		}
 		name := parts[1]
+		if len(parts) == 4 {
+			src, ok := s.graphs[parts[3]]
+			if !ok {
+				return nil, "", fmt.Errorf("no graph named %q", parts[3])
+			}
+			s.graphs[name] = &graphEntry{pg: src.pg.Transpose()}
+			if s.active == "" {
+				s.active = name
+			}
+			return nil, fmt.Sprintf("created %q as the transpose of %q", name, parts[3]), nil
+		}
 		s.graphs[name] = &graphEntry{pg: pgraph.New()}
 		if s.active == "" {
 			s.active = name
 		}
 		return nil, fmt.Sprintf("created empty graph %q", name), nil
 
+	case "clone":
+		if len(parts) != 3 || strings.ToUpper(parts[1]) != "AS" {
+			return nil, "", fmt.Errorf("usage: clone AS <name>")
+		}
+		if s.active == "" {
+			return nil, "", fmt.Errorf("no active graph — use 'load', 'use', or 'new' first")
+		}
+		name := parts[2]
+		s.graphs[name] = &graphEntry{pg: s.graphs[s.active].pg.Clone()}
+		return nil, fmt.Sprintf("cloned %q as %q", s.active, name), nil
+
+	case "generate":
+		return s.generate(parts[1:])
+
+	case "jaccard":
+		if len(parts) != 3 {
+			return nil, "", fmt.Errorf("usage: jaccard <graphA> <graphB>")
+		}
+		a, ok := s.graphs[parts[1]]
+		if !ok {
+			return nil, "", fmt.Errorf("no graph named %q", parts[1])
+		}
+		b, ok := s.graphs[parts[2]]
+		if !ok {
+			return nil, "", fmt.Errorf("no graph named %q", parts[2])
+		}
+		return nil, fmt.Sprintf("Jaccard edge similarity: %.6f", pgraph.JaccardEdgeSimilarity(a.pg, b.pg)), nil
+
+	case "print":
+		args := parts[1:]
+		if len(args) > 0 && strings.EqualFold(args[0], "GRAPH") {
+			args = args[1:]
+		}
+		if len(args) > 1 {
+			return nil, "", fmt.Errorf("usage: print [graph] [name]")
+		}
+		name := s.active
+		if len(args) == 1 {
+			name = args[0]
+		} else if name == "" {
+			return nil, "", fmt.Errorf("no active graph — use 'load', 'use', or 'new' first")
+		}
+		entry, ok := s.graphs[name]
+		if !ok {
+			return nil, "", fmt.Errorf("no graph named %q", name)
+		}
+		var sb strings.Builder
+		if err := entry.pg.WriteAdjacencyList(&sb, maxPrintedNodes); err != nil {
+			return nil, "", err
+		}
+		if sb.Len() == 0 {
+			return nil, fmt.Sprintf("(graph %q is empty)", name), nil
+		}
+		return nil, strings.TrimRight(sb.String(), "\n"), nil
+
+	case "visualize":
+		return s.visualize(parts[1:])
+
 	case "use":
 		if len(parts) < 2 {
 			return nil, "", fmt.Errorf("usage: use <name>")
@@ -81,11 +161,18 @@ func (s *sessionState) processLine(line string) (pgraph.Result, string, error) {
 		return nil, fmt.Sprintf("active graph set to %q", name), nil
 
 	case "load":
+		if len(parts) >= 2 && parts[1] == "--example" {
+			return s.loadExample(parts[2:])
+		}
 		if len(parts) < 3 {
 			return nil, "", fmt.Errorf("usage: load <name> <file>")
 		}
 		name, path := parts[1], parts[2]
-		pg, err := pgraph.LoadFile(path)
+		data, err := readGraphSource(path)
+		if err != nil {
+			return nil, "", fmt.Errorf("error loading %q: %w", path, err)
+		}
+		pg, err := pgraph.LoadWithOptions(bytes.NewReader(data), pgraph.LoadOptions{SkipChecksum: s.skipChecksum})
 		if err != nil {
 			return nil, "", fmt.Errorf("error loading %q: %w", path, err)
 		}
@@ -93,7 +180,11 @@ func (s *sessionState) processLine(line string) (pgraph.Result, string, error) {
 		if s.active == "" {
 			s.active = name
 		}
-		return nil, fmt.Sprintf("loaded %q (%d nodes)", name, len(pg.Graph.GetNodes())), nil
+		msg := fmt.Sprintf("loaded %q (%d nodes)", name, len(pg.Graph.GetNodes()))
+		if warning := unreachableWarning(pg, data); warning != "" {
+			msg += "\n" + warning
+		}
+		return nil, msg, nil
 
 	case "save":
 		if len(parts) < 2 {
@@ -126,12 +217,52 @@ func (s *sessionState) processLine(line string) (pgraph.Result, string, error) {
 			return nil, "", fmt.Errorf("graph was created in-memory — specify a file path: save <name> <file>")
 		}
 
-		if err := entry.pg.SaveFile(savePath); err != nil {
+		if isURL(savePath) {
+			cached, err := saveToURL(entry.pg, savePath)
+			if err != nil {
+				if cached != "" {
+					return nil, "", fmt.Errorf("error saving %q: %w (a copy was written to %s)", savePath, err, cached)
+				}
+				return nil, "", fmt.Errorf("error saving %q: %w", savePath, err)
+			}
+		} else if err := entry.pg.SaveFile(savePath); err != nil {
 			return nil, "", fmt.Errorf("error saving %q: %w", savePath, err)
 		}
 		entry.sourcePath = savePath
 		return nil, fmt.Sprintf("saved %q to %s", name, savePath), nil
 
+	case "begin", "commit", "rollback":
+		if s.active == "" {
+			return nil, "", fmt.Errorf("no active graph — use 'load', 'use', or 'new' first")
+		}
+		entry := s.graphs[s.active]
+
+		if cmd == "begin" {
+			if entry.tx != nil {
+				return nil, "", fmt.Errorf("a transaction is already open on %q", s.active)
+			}
+			entry.tx = entry.pg.BeginTx()
+			return nil, fmt.Sprintf("transaction started on %q", s.active), nil
+		}
+
+		if entry.tx == nil {
+			return nil, "", fmt.Errorf("no open transaction on %q", s.active)
+		}
+		if cmd == "commit" {
+			if err := entry.tx.Commit(); err != nil {
+				return nil, "", fmt.Errorf("commit failed: %w", err)
+			}
+			entry.tx = nil
+			return nil, fmt.Sprintf("committed transaction on %q", s.active), nil
+		}
+		entry.tx.Rollback()
+		entry.tx = nil
+		return nil, fmt.Sprintf("rolled back transaction on %q", s.active), nil
+
+	case "watch":
+		// The interactive loop intercepts watch before processLine.
+		return nil, "", fmt.Errorf("watch is only available in the interactive REPL")
+
 	case "unload":
 		if len(parts) < 2 {
 			return nil, "", fmt.Errorf("usage: unload <name>")
@@ -146,15 +277,235 @@ func (s *sessionState) processLine(line string) (pgraph.Result, string, error) {
 		}
 		return nil, fmt.Sprintf("unloaded %q", name), nil
 
+	case "benchmark":
+		return s.benchmark(parts[1:])
+
+	case "profile":
+		if len(parts) < 3 {
+			return nil, "", fmt.Errorf("usage: profile <n> <query>")
+		}
+		n, err := strconv.Atoi(parts[1])
+		if err != nil || n <= 0 {
+			return nil, "", fmt.Errorf("profile run count must be a positive integer, got %q", parts[1])
+		}
+		query, err := s.activeQuery()
+		if err != nil {
+			return nil, "", err
+		}
+		stats, err := profile(n, func() error {
+			_, err := query(context.Background(), strings.Join(parts[2:], " "))
+			return err
+		})
+		if err != nil {
+			return nil, "", fmt.Errorf("query error: %w", err)
+		}
+		return nil, stats.String(), nil
+
 	default:
 		// Treat as a DSL query against the active graph
-		if s.active == "" {
-			return nil, "", fmt.Errorf("no active graph — use 'load', 'use', or 'new' first")
+		query, err := s.activeQuery()
+		if err != nil {
+			return nil, "", err
+		}
+		ctx := context.Background()
+		if s.queryTimeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, s.queryTimeout)
+			defer cancel()
+		}
+		res, err := query(ctx, line)
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, "", fmt.Errorf("query timed out after %s", s.queryTimeout)
 		}
-		res, err := s.graphs[s.active].pg.Query(line)
 		if err != nil {
 			return nil, "", fmt.Errorf("query error: %w", err)
 		}
 		return res, "", nil
 	}
 }
+
+// activeQuery returns the QueryContext method of the active graph, or of
+// its open transaction if there is one.
+func (s *sessionState) activeQuery() (func(context.Context, string) (pgraph.Result, error), error) {
+	if s.active == "" {
+		return nil, fmt.Errorf("no active graph — use 'load', 'use', or 'new' first")
+	}
+	entry := s.graphs[s.active]
+	if entry.tx != nil {
+		return entry.tx.QueryContext, nil
+	}
+	return entry.pg.QueryContext, nil
+}
+
+// loadExample handles "load --example list" and
+// "load --example <example> [name]". The graph is named after the example
+// unless a name is given.
+func (s *sessionState) loadExample(args []string) (pgraph.Result, string, error) {
+	if len(args) == 0 || len(args) > 2 {
+		return nil, "", fmt.Errorf("usage: load --example <example> [name] | load --example list")
+	}
+	if strings.EqualFold(args[0], "list") {
+		return nil, "examples: " + strings.Join(exampleNames(), ", "), nil
+	}
+
+	example, name := args[0], args[0]
+	if len(args) == 2 {
+		name = args[1]
+	}
+	pg, err := loadExample(example)
+	if err != nil {
+		return nil, "", err
+	}
+	s.graphs[name] = &graphEntry{pg: pg}
+	if s.active == "" {
+		s.active = name
+	}
+	return nil, fmt.Sprintf("loaded example %q as %q (%d nodes)", example, name, len(pg.Graph.GetNodes())), nil
+}
+
+const visualizeUsage = "usage: visualize <file> [<refresh interval>]"
+
+// visualize handles "visualize <file> [<refresh interval>]", writing the
+// active graph to file as an HTML page that reloads itself every refresh
+// interval if one is given.
+func (s *sessionState) visualize(args []string) (pgraph.Result, string, error) {
+	if len(args) < 1 || len(args) > 2 {
+		return nil, "", errors.New(visualizeUsage)
+	}
+	if s.active == "" {
+		return nil, "", fmt.Errorf("no active graph — use 'load', 'use', or 'new' first")
+	}
+	var refresh time.Duration
+	if len(args) == 2 {
+		var err error
+		if refresh, err = time.ParseDuration(args[1]); err != nil || refresh <= 0 {
+			return nil, "", fmt.Errorf("refresh interval must be a positive duration such as 5s, got %q", args[1])
+		}
+	}
+
+	f, err := os.Create(args[0])
+	if err != nil {
+		return nil, "", err
+	}
+	if err := s.graphs[s.active].pg.WriteHTML(f, refresh); err != nil {
+		f.Close()
+		return nil, "", err
+	}
+	if err := f.Close(); err != nil {
+		return nil, "", err
+	}
+	return nil, fmt.Sprintf("wrote %q to %s", s.active, args[0]), nil
+}
+
+// maxPrintedNodes caps how many nodes the print command lists.
+const maxPrintedNodes = 20
+
+// scaleFreeEdgesPerNode is the number of earlier nodes each new node links
+// to in a generated SCALE_FREE graph.
+const scaleFreeEdgesPerNode = 2
+
+const generateUsage = "usage: generate <name> ERDOS_RENYI NODES <n> EDGE_PROB <p> [SEED <s>] | generate <name> SCALE_FREE NODES <n> [SEED <s>]"
+
+// generate handles "generate <name> <model> NODES <n> ...", adding a random
+// graph named name. Without SEED, a random seed is used.
+func (s *sessionState) generate(args []string) (pgraph.Result, string, error) {
+	if len(args) < 4 || !strings.EqualFold(args[2], "NODES") {
+		return nil, "", errors.New(generateUsage)
+	}
+	name, model := args[0], strings.ToUpper(args[1])
+	n, err := strconv.Atoi(args[3])
+	if err != nil {
+		return nil, "", fmt.Errorf("node count must be an integer, got %q", args[3])
+	}
+	rest := args[4:]
+
+	var edgeProb float64
+	if model == "ERDOS_RENYI" {
+		if len(rest) < 2 || !strings.EqualFold(rest[0], "EDGE_PROB") {
+			return nil, "", errors.New(generateUsage)
+		}
+		if edgeProb, err = strconv.ParseFloat(rest[1], 64); err != nil {
+			return nil, "", fmt.Errorf("edge probability must be a number, got %q", rest[1])
+		}
+		rest = rest[2:]
+	}
+
+	seed := rand.Uint64()
+	switch {
+	case len(rest) == 2 && strings.EqualFold(rest[0], "SEED"):
+		if seed, err = strconv.ParseUint(rest[1], 10, 64); err != nil {
+			return nil, "", fmt.Errorf("seed must be a non-negative integer, got %q", rest[1])
+		}
+	case len(rest) != 0:
+		return nil, "", errors.New(generateUsage)
+	}
+
+	var pg *pgraph.PGraph
+	switch model {
+	case "ERDOS_RENYI":
+		pg, err = pgraph.GenerateErdosRenyi(n, edgeProb, seed)
+	case "SCALE_FREE":
+		pg, err = pgraph.GenerateScaleFree(n, scaleFreeEdgesPerNode, seed)
+	default:
+		return nil, "", fmt.Errorf("unknown graph model %q (available: ERDOS_RENYI, SCALE_FREE)", args[1])
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	s.graphs[name] = &graphEntry{pg: pg}
+	if s.active == "" {
+		s.active = name
+	}
+	stats := pg.Stats()
+	return nil, fmt.Sprintf("generated %q (%d nodes, %d edges, seed %d)", name, stats.NodeCount, stats.EdgeCount, seed), nil
+}
+
+// maxListedUnreachable caps how many node IDs unreachableWarning names.
+const maxListedUnreachable = 5
+
+// unreachableWarning checks that every node of a freshly loaded graph is
+// reachable from the first node listed in its JSON file data, and describes
+// any that are not. It returns "" when there is nothing to report.
+func unreachableWarning(pg *pgraph.PGraph, data []byte) string {
+	var file struct {
+		Nodes []struct {
+			ID string `json:"id"`
+		} `json:"nodes"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil || len(file.Nodes) == 0 {
+		return ""
+	}
+	root := file.Nodes[0].ID
+	unreachable, err := pg.UnreachableNodes(pgraph.NodeID(root))
+	if err != nil || len(unreachable) == 0 {
+		return ""
+	}
+
+	names := make([]string, 0, maxListedUnreachable)
+	for _, id := range unreachable[:min(len(unreachable), maxListedUnreachable)] {
+		names = append(names, string(id))
+	}
+	list := strings.Join(names, ", ")
+	if extra := len(unreachable) - len(names); extra > 0 {
+		list += fmt.Sprintf(" and %d more", extra)
+	}
+	return fmt.Sprintf("warning: %d node(s) unreachable from %q: %s", len(unreachable), root, list)
+}
+
+// syntaxErrorMarker echoes line with carets under the token that err, a
+// DSL syntax error, points at. It returns "" if err is not a syntax error
+// or its position does not fall on line, as for a query typed after
+// another command.
+func syntaxErrorMarker(line string, err error) string {
+	var se pgraph.SyntaxError
+	if !errors.As(err, &se) || se.Line != 1 || se.Column < 1 {
+		return ""
+	}
+	runes := []rune(line)
+	col := se.Column - 1
+	if col > len(runes) || !strings.HasPrefix(string(runes[col:]), se.OffendingToken) {
+		return ""
+	}
+	width := max(1, len([]rune(se.OffendingToken)))
+	return "  " + line + "\n  " + strings.Repeat(" ", col) + strings.Repeat("^", width)
+}
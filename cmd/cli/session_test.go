@@ -1,13 +1,21 @@
 package main
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"math"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
+
+	pgraph "github.com/ritamzico/pgraph"
 )
 
 // probabilistic matches any result that exposes a probability value,
@@ -93,6 +101,95 @@ func TestProcessLine_New_MissingName(t *testing.T) {
 	}
 }
 
+func TestProcessLine_New_Transpose(t *testing.T) {
+	s := newSession()
+	s.processLine("new g")
+	s.processLine("CREATE NODE A, B")
+	s.processLine("CREATE EDGE e1 FROM A TO B PROB 0.75")
+
+	if _, _, err := s.processLine("new rev TRANSPOSE g"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s.processLine("use rev")
+
+	res, _, err := s.processLine("REACHABILITY FROM B TO A EXACT")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pr := res.(probabilistic); math.Abs(pr.ProbabilityValue()-0.75) > 0.0001 {
+		t.Errorf("expected probability 0.75, got %f", pr.ProbabilityValue())
+	}
+
+	// The source graph must be unchanged.
+	s.processLine("use g")
+	res, _, err = s.processLine("REACHABILITY FROM B TO A EXACT")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pr := res.(probabilistic); pr.ProbabilityValue() != 0 {
+		t.Errorf("expected source graph to be unchanged, got %f", pr.ProbabilityValue())
+	}
+}
+
+func TestProcessLine_New_TransposeUnknownSource(t *testing.T) {
+	s := newSession()
+	_, _, err := s.processLine("new rev TRANSPOSE missing")
+	if err == nil {
+		t.Error("expected error for unknown source graph")
+	}
+}
+
+// --- clone ---
+
+func TestProcessLine_Clone_Independent(t *testing.T) {
+	s := newSession()
+	s.processLine("new g")
+	s.processLine("CREATE NODE A, B")
+	s.processLine("CREATE EDGE e1 FROM A TO B PROB 0.75")
+
+	if _, _, err := s.processLine("clone AS whatif"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.active != "g" {
+		t.Errorf("expected active graph to stay %q, got %q", "g", s.active)
+	}
+
+	s.processLine("use whatif")
+	if _, _, err := s.processLine("DELETE EDGE e1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	res, _, err := s.processLine("REACHABILITY FROM A TO B EXACT")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pr := res.(probabilistic); pr.ProbabilityValue() != 0 {
+		t.Errorf("expected clone without e1 to give 0, got %f", pr.ProbabilityValue())
+	}
+
+	// The source graph must be unchanged.
+	s.processLine("use g")
+	res, _, err = s.processLine("REACHABILITY FROM A TO B EXACT")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pr := res.(probabilistic); math.Abs(pr.ProbabilityValue()-0.75) > 0.0001 {
+		t.Errorf("expected source graph to be unchanged, got %f", pr.ProbabilityValue())
+	}
+}
+
+func TestProcessLine_Clone_Errors(t *testing.T) {
+	s := newSession()
+	if _, _, err := s.processLine("clone AS copy"); err == nil {
+		t.Error("expected error with no active graph")
+	}
+	s.processLine("new g")
+	for _, line := range []string{"clone", "clone copy", "clone TO copy", "clone AS"} {
+		if _, _, err := s.processLine(line); err == nil {
+			t.Errorf("%q: expected usage error", line)
+		}
+	}
+}
+
 // --- use ---
 
 func TestProcessLine_Use_SwitchesActive(t *testing.T) {
@@ -226,6 +323,159 @@ func TestProcessLine_Load_FromFile(t *testing.T) {
 	}
 }
 
+func TestProcessLine_Load_ChecksumMismatch(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "graph.json")
+	graphJSON := `{"checksum":"sha256:00","nodes":[{"id":"X"}],"edges":[]}`
+	if err := os.WriteFile(tmpFile, []byte(graphJSON), 0644); err != nil {
+		t.Fatalf("failed to write graph file: %v", err)
+	}
+
+	s := newSession()
+	_, _, err := s.processLine("load g " + tmpFile)
+	var ce pgraph.ChecksumMismatchError
+	if !errors.As(err, &ce) {
+		t.Fatalf("expected ChecksumMismatchError, got %v", err)
+	}
+
+	// --no-verify-checksum
+	s.skipChecksum = true
+	if _, _, err := s.processLine("load g " + tmpFile); err != nil {
+		t.Errorf("expected load without verification to succeed, got %v", err)
+	}
+}
+
+func TestProcessLine_Load_FromURL(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	graphJSON := `{"nodes":[{"id":"X"},{"id":"Y"}],"edges":[{"id":"e1","from":"X","to":"Y","probability":0.9}]}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/graph.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(graphJSON))
+	}))
+	defer srv.Close()
+
+	s := newSession()
+	url := srv.URL + "/graph.json"
+	_, msg, err := s.processLine("load g " + url)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(msg, "(2 nodes)") {
+		t.Errorf("expected node count in message, got %q", msg)
+	}
+	if s.graphs["g"].sourcePath != url {
+		t.Errorf("expected the URL as the save path, got %q", s.graphs["g"].sourcePath)
+	}
+	cached, err := cachePath(url)
+	if err != nil {
+		t.Fatalf("cachePath: %v", err)
+	}
+	if data, err := os.ReadFile(cached); err != nil || string(data) != graphJSON {
+		t.Errorf("expected the fetched graph in %s, got %q (%v)", cached, data, err)
+	}
+
+	_, _, err = s.processLine("load h " + srv.URL + "/missing.json")
+	if err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("expected a 404 error, got %v", err)
+	}
+}
+
+func TestProcessLine_Load_FromURLTooLarge(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	old := maxFetchSize
+	maxFetchSize = 16
+	t.Cleanup(func() { maxFetchSize = old })
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"nodes":[{"id":"X"}],"edges":[]}`))
+	}))
+	defer srv.Close()
+
+	s := newSession()
+	_, _, err := s.processLine("load g " + srv.URL)
+	if err == nil || !strings.Contains(err.Error(), "16 byte limit") {
+		t.Errorf("expected a size limit error, got %v", err)
+	}
+}
+
+func TestProcessLine_Save_ToURL(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	stored := []byte(`{"nodes":[{"id":"X"}],"edges":[]}`)
+	writable := true
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			w.Write(stored)
+		case http.MethodPut:
+			if !writable {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			stored, _ = io.ReadAll(r.Body)
+		}
+	}))
+	defer srv.Close()
+
+	s := newSession()
+	if _, _, err := s.processLine("load g " + srv.URL); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if _, _, err := s.processLine("CREATE NODE Y"); err != nil {
+		t.Fatalf("CREATE NODE: %v", err)
+	}
+	if _, _, err := s.processLine("save g"); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if !bytes.Contains(stored, []byte(`"Y"`)) {
+		t.Errorf("expected the server to receive node Y, got %s", stored)
+	}
+
+	writable = false
+	_, _, err := s.processLine("save g")
+	cached, _ := cachePath(srv.URL)
+	if err == nil || !strings.Contains(err.Error(), "405") || !strings.Contains(err.Error(), cached) {
+		t.Errorf("expected a 405 error naming the cached copy, got %v", err)
+	}
+	if data, err := os.ReadFile(cached); err != nil || !bytes.Contains(data, []byte(`"Y"`)) {
+		t.Errorf("expected the unsaved graph in %s, got %q (%v)", cached, data, err)
+	}
+}
+
+func TestProcessLine_Load_WarnsAboutUnreachableNodes(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "graph.json")
+	graphJSON := `{"nodes":[{"id":"X"},{"id":"Y"},{"id":"Z"}],"edges":[{"id":"e1","from":"X","to":"Y","probability":0.9},{"id":"e2","from":"Z","to":"X","probability":0.5}]}`
+	if err := os.WriteFile(tmpFile, []byte(graphJSON), 0644); err != nil {
+		t.Fatalf("failed to write graph file: %v", err)
+	}
+
+	s := newSession()
+	_, msg, err := s.processLine("load g " + tmpFile)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(msg, `warning: 1 node(s) unreachable from "X": Z`) {
+		t.Errorf("expected unreachable warning, got %q", msg)
+	}
+}
+
+func TestProcessLine_Load_NoWarningWhenConnected(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "graph.json")
+	graphJSON := `{"nodes":[{"id":"X"},{"id":"Y"}],"edges":[{"id":"e1","from":"X","to":"Y","probability":0.9}]}`
+	if err := os.WriteFile(tmpFile, []byte(graphJSON), 0644); err != nil {
+		t.Fatalf("failed to write graph file: %v", err)
+	}
+
+	s := newSession()
+	_, msg, err := s.processLine("load g " + tmpFile)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(msg, "warning") {
+		t.Errorf("unexpected warning in %q", msg)
+	}
+}
+
 func TestProcessLine_Load_SetsFirstGraphActive(t *testing.T) {
 	tmpFile := filepath.Join(t.TempDir(), "graph.json")
 	os.WriteFile(tmpFile, []byte(`{"nodes":[],"edges":[]}`), 0644)
@@ -253,8 +503,214 @@ func TestProcessLine_Load_FileNotFound(t *testing.T) {
 	}
 }
 
+func TestProcessLine_Load_Example(t *testing.T) {
+	s := newSession()
+	_, msg, err := s.processLine("load --example list")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	names := exampleNames()
+	if len(names) == 0 {
+		t.Fatal("expected embedded examples")
+	}
+	for _, name := range names {
+		if !strings.Contains(msg, name) {
+			t.Errorf("list output %q missing %q", msg, name)
+		}
+		if _, _, err := s.processLine("load --example " + name); err != nil {
+			t.Errorf("loading example %q: %v", name, err)
+		}
+		if _, ok := s.graphs[name]; !ok {
+			t.Errorf("example %q not registered under its own name", name)
+		}
+	}
+	if s.active != names[0] {
+		t.Errorf("expected first example %q to be active, got %q", names[0], s.active)
+	}
+}
+
+func TestProcessLine_Load_ExampleWithName(t *testing.T) {
+	s := newSession()
+	if _, _, err := s.processLine("load --example supply_chain sc"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	res, _, err := s.processLine("REACHABILITY FROM supplier TO retailer")
+	if err != nil {
+		t.Fatalf("query on example failed: %v", err)
+	}
+	if _, ok := res.(probabilistic); !ok {
+		t.Errorf("expected a probability result, got %T", res)
+	}
+	if s.graphs["sc"].sourcePath != "" {
+		t.Error("example should have no source path, so save needs an explicit file")
+	}
+}
+
+func TestProcessLine_Load_ExampleErrors(t *testing.T) {
+	s := newSession()
+	for _, line := range []string{"load --example", "load --example nope", "load --example a b c"} {
+		if _, _, err := s.processLine(line); err == nil {
+			t.Errorf("%q: expected error", line)
+		}
+	}
+}
+
+// --- generate ---
+
+func TestProcessLine_Generate(t *testing.T) {
+	s := newSession()
+	_, msg, err := s.processLine("generate er erdos_renyi NODES 12 EDGE_PROB 0.3 SEED 5")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(msg, `"er" (12 nodes`) || !strings.Contains(msg, "seed 5") {
+		t.Errorf("unexpected message %q", msg)
+	}
+	if s.active != "er" {
+		t.Errorf("expected generated graph to become active, got %q", s.active)
+	}
+	if _, _, err := s.processLine("REACHABILITY FROM n0 TO n11 EXACT"); err != nil {
+		t.Errorf("query on generated graph failed: %v", err)
+	}
+
+	// The same seed regenerates the same graph.
+	_, again, err := s.processLine("generate er2 ERDOS_RENYI NODES 12 EDGE_PROB 0.3 SEED 5")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Replace(again, "er2", "er", 1) != msg {
+		t.Errorf("same seed gave %q and %q", msg, again)
+	}
+
+	_, msg, err = s.processLine("generate sf SCALE_FREE NODES 20")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := fmt.Sprintf(`"sf" (20 nodes, %d edges`, (20-scaleFreeEdgesPerNode)*scaleFreeEdgesPerNode)
+	if !strings.Contains(msg, want) {
+		t.Errorf("expected message containing %q, got %q", want, msg)
+	}
+}
+
+func TestProcessLine_Generate_Errors(t *testing.T) {
+	s := newSession()
+	for _, line := range []string{
+		"generate",
+		"generate g ERDOS_RENYI NODES 10",
+		"generate g ERDOS_RENYI NODES ten EDGE_PROB 0.5",
+		"generate g ERDOS_RENYI NODES 10 EDGE_PROB high",
+		"generate g ERDOS_RENYI NODES 10 EDGE_PROB 1.5",
+		"generate g SCALE_FREE NODES 10 SEED -1",
+		"generate g SCALE_FREE NODES 10 EXTRA",
+		"generate g SMALL_WORLD NODES 10",
+	} {
+		if _, _, err := s.processLine(line); err == nil {
+			t.Errorf("%q: expected error", line)
+		}
+	}
+	if len(s.graphs) != 0 {
+		t.Errorf("expected no graphs after failed generates, got %d", len(s.graphs))
+	}
+}
+
+// --- print ---
+
+func TestProcessLine_Print(t *testing.T) {
+	s := newSession()
+	s.processLine("new g")
+	for _, line := range []string{
+		"CREATE NODE b, a, c",
+		"CREATE EDGE e1 FROM a TO c PROB 0.5",
+		"CREATE EDGE e2 FROM a TO b PROB 0.9",
+	} {
+		if _, _, err := s.processLine(line); err != nil {
+			t.Fatalf("%q: %v", line, err)
+		}
+	}
+	s.processLine("new empty")
+
+	want := "a -> [b(0.9), c(0.5)]\nb -> []\nc -> []"
+	for _, line := range []string{"print", "PRINT GRAPH", "print g", "print graph g"} {
+		_, msg, err := s.processLine(line)
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", line, err)
+		}
+		if msg != want {
+			t.Errorf("%q: got %q, want %q", line, msg, want)
+		}
+	}
+
+	if _, msg, _ := s.processLine("print empty"); !strings.Contains(msg, "empty") {
+		t.Errorf("expected an empty-graph message, got %q", msg)
+	}
+	for _, line := range []string{"print nope", "print graph g extra"} {
+		if _, _, err := s.processLine(line); err == nil {
+			t.Errorf("%q: expected error", line)
+		}
+	}
+	if _, _, err := newSession().processLine("print"); err == nil {
+		t.Error("expected error with no active graph")
+	}
+}
+
+// --- jaccard ---
+
+func TestProcessLine_Jaccard(t *testing.T) {
+	s := newSession()
+	s.processLine("new g")
+	s.processLine("CREATE NODE A, B, C")
+	s.processLine("CREATE EDGE e1 FROM A TO B PROB 0.5")
+	s.processLine("CREATE EDGE e2 FROM B TO C PROB 0.5")
+	s.processLine("clone AS h")
+	s.processLine("use h")
+	s.processLine("DELETE EDGE e2")
+
+	_, msg, err := s.processLine("jaccard g h")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if msg != "Jaccard edge similarity: 0.500000" {
+		t.Errorf("unexpected message %q", msg)
+	}
+
+	for _, line := range []string{"jaccard", "jaccard g", "jaccard g nope", "jaccard nope g", "jaccard g h x"} {
+		if _, _, err := s.processLine(line); err == nil {
+			t.Errorf("%q: expected error", line)
+		}
+	}
+}
+
 // --- save ---
 
+func TestProcessLine_Visualize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "g.html")
+	s := newSession()
+	if _, _, err := s.processLine("visualize " + path); err == nil {
+		t.Error("expected error without an active graph")
+	}
+
+	s.processLine("new g")
+	s.processLine("CREATE NODE a, b")
+	s.processLine("CREATE EDGE e1 FROM a TO b PROB 0.75")
+	if _, _, err := s.processLine("visualize " + path + " 10s"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	page := string(data)
+	if !strings.Contains(page, "<svg") || !strings.Contains(page, ">0.75</text>") || !strings.Contains(page, `content="10"`) {
+		t.Errorf("unexpected page:\n%s", page)
+	}
+
+	for _, line := range []string{"visualize", "visualize " + path + " soon", "visualize " + path + " 0s"} {
+		if _, _, err := s.processLine(line); err == nil {
+			t.Errorf("%q: expected error", line)
+		}
+	}
+}
+
 func TestProcessLine_Save_InMemoryNoPath(t *testing.T) {
 	s := newSession()
 	s.processLine("new g")
@@ -371,3 +827,297 @@ func TestProcessLine_DSL_StateAccumulatesAcrossCalls(t *testing.T) {
 		t.Errorf("expected probability 0.72, got %f", pr.ProbabilityValue())
 	}
 }
+
+// --- transactions ---
+
+func TestProcessLine_Tx_CommitAndRollback(t *testing.T) {
+	s := newSession()
+	s.processLine("new g")
+	s.processLine("CREATE NODE A")
+
+	if _, _, err := s.processLine("begin"); err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	s.processLine("CREATE NODE B")
+	s.processLine("CREATE EDGE e1 FROM A TO B PROB 0.5")
+	if _, _, err := s.processLine("rollback"); err != nil {
+		t.Fatalf("rollback: %v", err)
+	}
+	if _, _, err := s.processLine("MAXPATH FROM A TO B"); err == nil {
+		t.Error("expected rolled-back node B to be gone")
+	}
+
+	s.processLine("BEGIN")
+	s.processLine("CREATE NODE B")
+	s.processLine("CREATE EDGE e1 FROM A TO B PROB 0.5")
+	if _, _, err := s.processLine("COMMIT"); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	res, _, err := s.processLine("REACHABILITY FROM A TO B EXACT")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pr := res.(probabilistic); math.Abs(pr.ProbabilityValue()-0.5) > 0.0001 {
+		t.Errorf("expected committed probability 0.5, got %f", pr.ProbabilityValue())
+	}
+}
+
+func TestProcessLine_Tx_Errors(t *testing.T) {
+	s := newSession()
+	if _, _, err := s.processLine("begin"); err == nil {
+		t.Error("expected error for begin with no active graph")
+	}
+
+	s.processLine("new g")
+	if _, _, err := s.processLine("commit"); err == nil {
+		t.Error("expected error for commit with no open transaction")
+	}
+	if _, _, err := s.processLine("rollback"); err == nil {
+		t.Error("expected error for rollback with no open transaction")
+	}
+
+	s.processLine("begin")
+	if _, _, err := s.processLine("begin"); err == nil {
+		t.Error("expected error for nested begin")
+	}
+}
+
+// --- watch ---
+
+func TestParseWatch(t *testing.T) {
+	interval, query, err := parseWatch("WATCH 5s REACHABILITY FROM A TO D EXACT")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if interval != 5*time.Second || query != "REACHABILITY FROM A TO D EXACT" {
+		t.Errorf("got (%v, %q)", interval, query)
+	}
+
+	for _, line := range []string{"watch", "watch 5s", "watch soon MAXPATH FROM A TO B", "watch -1s MAXPATH FROM A TO B"} {
+		if _, _, err := parseWatch(line); err == nil {
+			t.Errorf("%q: expected error", line)
+		}
+	}
+}
+
+func TestWatch_RerunsUntilCancelled(t *testing.T) {
+	s := newSession()
+	s.processLine("new g")
+	s.processLine("CREATE NODE A, B")
+	s.processLine("CREATE EDGE e1 FROM A TO B PROB 0.5")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 55*time.Millisecond)
+	defer cancel()
+	var out strings.Builder
+	if err := s.watch(ctx, 10*time.Millisecond, "REACHABILITY FROM A TO B EXACT", &out, true); err != nil {
+		t.Fatalf("watch: %v", err)
+	}
+
+	runs := strings.Count(out.String(), "every 10ms")
+	if runs < 2 {
+		t.Errorf("expected at least 2 runs, got %d:\n%s", runs, out.String())
+	}
+	if !strings.Contains(out.String(), "\033[") {
+		t.Error("expected ANSI escapes to overwrite previous output")
+	}
+}
+
+func TestWatch_FirstRunErrorStops(t *testing.T) {
+	s := newSession()
+	s.processLine("new g")
+	var out strings.Builder
+	err := s.watch(context.Background(), time.Millisecond, "NOT A QUERY", &out, false)
+	if err == nil {
+		t.Fatal("expected error from invalid query")
+	}
+	if out.Len() != 0 {
+		t.Errorf("expected no output, got %q", out.String())
+	}
+}
+
+func TestProcessLine_WatchRejectedOutsideREPL(t *testing.T) {
+	s := newSession()
+	if _, _, err := s.processLine("watch 1s list"); err == nil {
+		t.Error("expected error")
+	}
+}
+
+// --- query timeout ---
+
+func TestProcessLine_QueryTimeout(t *testing.T) {
+	s := newSession()
+	s.processLine("new g")
+	s.processLine("CREATE NODE A, B")
+	s.processLine("CREATE EDGE e1 FROM A TO B PROB 0.5")
+	s.queryTimeout = 20 * time.Millisecond
+
+	// This width needs far more samples than the timeout allows.
+	_, _, err := s.processLine("CONFIDENCE FROM A TO B WIDTH 0.0001")
+	if err == nil || err.Error() != "query timed out after 20ms" {
+		t.Fatalf("expected timeout error, got %v", err)
+	}
+
+	// The session stays usable, and fast queries finish within the timeout.
+	res, _, err := s.processLine("REACHABILITY FROM A TO B EXACT")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p := res.(probabilistic).ProbabilityValue(); p != 0.5 {
+		t.Errorf("expected probability 0.5, got %f", p)
+	}
+}
+
+func TestParseFlags(t *testing.T) {
+	s := newSession()
+	if err := s.parseFlags([]string{"--query-timeout", "1m30s", "--no-verify-checksum"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.queryTimeout != 90*time.Second || !s.skipChecksum {
+		t.Errorf("expected a 1m30s timeout and skipped checksums, got %v and %v", s.queryTimeout, s.skipChecksum)
+	}
+
+	for _, args := range [][]string{{"--query-timeout"}, {"--query-timeout", "soon"}, {"--query-timeout", "0s"}} {
+		if err := newSession().parseFlags(args); err == nil {
+			t.Errorf("%v: expected error", args)
+		}
+	}
+}
+
+// --- benchmark ---
+
+func TestProcessLine_Benchmark(t *testing.T) {
+	s := newSession()
+	s.processLine("new g")
+	s.processLine("CREATE NODE A, B")
+	s.processLine("CREATE EDGE e1 FROM A TO B PROB 0.5")
+
+	res, msg, err := s.processLine("benchmark REACHABILITY FROM A TO B")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res != nil {
+		t.Errorf("expected a table, not a result, got %v", res)
+	}
+	lines := strings.Split(msg, "\n")
+	if len(lines) != 5 {
+		t.Fatalf("expected a header and 4 rows, got:\n%s", msg)
+	}
+	for i, want := range []string{"MODE", "EXACT", "MONTECARLO  1000 ", "MONTECARLO  10000", "MONTECARLO  100000"} {
+		if !strings.HasPrefix(lines[i], want) {
+			t.Errorf("line %d: expected prefix %q, got %q", i, want, lines[i])
+		}
+	}
+	if !strings.Contains(lines[1], "0.500000") {
+		t.Errorf("expected the exact probability in %q", lines[1])
+	}
+}
+
+func TestProcessLine_BenchmarkErrors(t *testing.T) {
+	s := newSession()
+	if _, _, err := s.processLine("benchmark REACHABILITY FROM A TO B"); err == nil {
+		t.Error("expected error with no active graph")
+	}
+	s.processLine("new g")
+	for _, line := range []string{"benchmark", "benchmark MAXPATH FROM A TO B", "benchmark REACHABILITY FROM A", "benchmark REACHABILITY FROM A TO B"} {
+		if _, _, err := s.processLine(line); err == nil {
+			t.Errorf("%q: expected error", line)
+		}
+	}
+}
+
+// --- profile ---
+
+func TestProcessLine_Profile(t *testing.T) {
+	s := newSession()
+	s.processLine("new g")
+	s.processLine("CREATE NODE A, B")
+	s.processLine("CREATE EDGE e1 FROM A TO B PROB 0.5")
+
+	res, msg, err := s.processLine("profile 5 REACHABILITY FROM A TO B EXACT")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res != nil {
+		t.Errorf("expected results to be suppressed, got %v", res)
+	}
+	for _, want := range []string{"5 runs", "min", "max", "mean", "p95"} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("expected %q in summary %q", want, msg)
+		}
+	}
+}
+
+func TestProcessLine_ProfileErrors(t *testing.T) {
+	s := newSession()
+	if _, _, err := s.processLine("profile 3 MAXPATH FROM A TO B"); err == nil {
+		t.Error("expected error with no active graph")
+	}
+	s.processLine("new g")
+	for _, line := range []string{"profile", "profile 3", "profile 0 MAXPATH FROM A TO B", "profile x MAXPATH FROM A TO B", "profile 3 MAXPATH FROM A TO B"} {
+		if _, _, err := s.processLine(line); err == nil {
+			t.Errorf("%q: expected error", line)
+		}
+	}
+}
+
+func TestProfile_Stats(t *testing.T) {
+	calls := 0
+	stats, err := profile(20, func() error {
+		calls++
+		time.Sleep(time.Duration(calls) * 100 * time.Microsecond)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 20 || stats.runs != 20 {
+		t.Errorf("expected 20 runs, got calls=%d runs=%d", calls, stats.runs)
+	}
+	if stats.mean < stats.min || stats.mean > stats.max || stats.p95 < stats.min || stats.p95 > stats.max {
+		t.Errorf("expected mean and p95 within [min, max], got %+v", stats)
+	}
+
+	if got := percentileIndex(20, 0.95); got != 18 {
+		t.Errorf("percentileIndex(20, 0.95) = %d, want 18", got)
+	}
+	if got := percentileIndex(1, 0.95); got != 0 {
+		t.Errorf("percentileIndex(1, 0.95) = %d, want 0", got)
+	}
+
+	calls = 0
+	if _, err := profile(5, func() error { calls++; return errors.New("boom") }); err == nil || calls != 1 {
+		t.Errorf("expected to stop at first error, calls=%d err=%v", calls, err)
+	}
+}
+
+// --- syntax error marker ---
+
+func TestSyntaxErrorMarker(t *testing.T) {
+	s := newSession()
+	s.processLine("new g")
+	s.processLine("CREATE NODE A")
+
+	line := "MAXPATH FROM A TOO B"
+	_, _, err := s.processLine(line)
+	if err == nil {
+		t.Fatal("expected a syntax error")
+	}
+	want := "  MAXPATH FROM A TOO B\n                 ^^^"
+	if got := syntaxErrorMarker(line, err); got != want {
+		t.Errorf("expected marker\n%s\ngot\n%s", want, got)
+	}
+
+	// A query typed after a CLI command is not underlined.
+	line = "profile 2 MAXPATH FROM A TOO B"
+	_, _, err = s.processLine(line)
+	if err == nil {
+		t.Fatal("expected a syntax error")
+	}
+	if got := syntaxErrorMarker(line, err); got != "" {
+		t.Errorf("expected no marker, got\n%s", got)
+	}
+
+	if got := syntaxErrorMarker("x", errors.New("other")); got != "" {
+		t.Errorf("expected no marker for a non-syntax error, got %q", got)
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// parseWatch splits "watch <interval> <line>" into its interval and line.
+func parseWatch(line string) (time.Duration, string, error) {
+	parts := strings.Fields(line)
+	if len(parts) < 3 {
+		return 0, "", fmt.Errorf("usage: watch <interval> <query>")
+	}
+	interval, err := time.ParseDuration(parts[1])
+	if err != nil || interval <= 0 {
+		return 0, "", fmt.Errorf("invalid watch interval %q (examples: 500ms, 5s, 1m)", parts[1])
+	}
+	return interval, strings.Join(parts[2:], " "), nil
+}
+
+// watch runs line immediately and then every interval until ctx is done,
+// writing each outcome to out. With ansi set, each outcome overwrites the
+// previous one. It returns early only if the first run fails, so a mistyped
+// query is reported rather than repeated; later failures are printed and
+// the loop continues.
+func (s *sessionState) watch(ctx context.Context, interval time.Duration, line string, out io.Writer, ansi bool) error {
+	text, err := s.watchOnce(line)
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	prevLines := 0
+	for {
+		if ansi && prevLines > 0 {
+			// Move the cursor to the start of the previous output and clear
+			// everything below it.
+			fmt.Fprintf(out, "\033[%dA\033[J", prevLines)
+		}
+		header := fmt.Sprintf("every %s: %s  (%s)", interval, line, time.Now().Format(time.TimeOnly))
+		fmt.Fprintf(out, "%s\n%s\n", header, text)
+		prevLines = 2 + strings.Count(text, "\n")
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		text, err = s.watchOnce(line)
+		if err != nil {
+			text = err.Error()
+		}
+	}
+}
+
+func (s *sessionState) watchOnce(line string) (string, error) {
+	res, msg, err := s.processLine(line)
+	if err != nil {
+		return "", err
+	}
+	if res != nil {
+		return res.String(), nil
+	}
+	return msg, nil
+}
+
+// isTerminal reports whether f is attached to a character device.
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
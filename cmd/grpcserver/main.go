@@ -0,0 +1,34 @@
+// Command grpcserver serves PGraphService, defined in proto/pgraph.proto,
+// over gRPC. Graphs are held in memory until unloaded or the server exits.
+//
+// Usage:
+//
+//	grpcserver [-listen :50051]
+package main
+
+import (
+	"flag"
+	"log"
+	"net"
+
+	pgraphgrpc "github.com/ritamzico/pgraph/grpc"
+	"github.com/ritamzico/pgraph/grpc/pgraphpb"
+	"google.golang.org/grpc"
+)
+
+func main() {
+	listen := flag.String("listen", ":50051", "address to listen on")
+	flag.Parse()
+
+	lis, err := net.Listen("tcp", *listen)
+	if err != nil {
+		log.Fatal(err)
+	}
+	srv := grpc.NewServer()
+	pgraphpb.RegisterPGraphServiceServer(srv, pgraphgrpc.NewServer())
+
+	log.Printf("pgraph gRPC server listening on %s", lis.Addr())
+	if err := srv.Serve(lis); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,81 @@
+//go:build js && wasm
+
+// Command wasm exposes pgraph to JavaScript when built with
+// GOOS=js GOARCH=wasm. It registers three global functions:
+//
+//	pgraphNew()               returns a handle to a new empty graph
+//	pgraphLoad(jsonStr)       returns a handle to a graph loaded from JSON
+//	pgraphQuery(handle, dsl)  runs a DSL query and returns the result as JSON
+//
+// Handles are plain numbers. Query results use the {"kind", "data"} shape
+// of pgraph.MarshalResultJSON. Failures are returned, not thrown, as a
+// JavaScript Error.
+package main
+
+import (
+	"fmt"
+	"strings"
+	"syscall/js"
+
+	"github.com/ritamzico/pgraph"
+)
+
+// graphs holds every graph created from JavaScript, keyed by handle. The
+// js/wasm runtime is single threaded, so it needs no lock.
+var (
+	graphs     = make(map[int]*pgraph.PGraph)
+	nextHandle = 1
+)
+
+func main() {
+	js.Global().Set("pgraphNew", js.FuncOf(pgraphNew))
+	js.Global().Set("pgraphLoad", js.FuncOf(pgraphLoad))
+	js.Global().Set("pgraphQuery", js.FuncOf(pgraphQuery))
+	// Keep the exported functions callable for the life of the page.
+	select {}
+}
+
+func pgraphNew(this js.Value, args []js.Value) any {
+	return register(pgraph.New())
+}
+
+func pgraphLoad(this js.Value, args []js.Value) any {
+	if len(args) != 1 || args[0].Type() != js.TypeString {
+		return jsError(fmt.Errorf("pgraphLoad expects one JSON string argument"))
+	}
+	g, err := pgraph.Load(strings.NewReader(args[0].String()))
+	if err != nil {
+		return jsError(err)
+	}
+	return register(g)
+}
+
+func pgraphQuery(this js.Value, args []js.Value) any {
+	if len(args) != 2 || args[0].Type() != js.TypeNumber || args[1].Type() != js.TypeString {
+		return jsError(fmt.Errorf("pgraphQuery expects a graph handle and a DSL string"))
+	}
+	g, ok := graphs[args[0].Int()]
+	if !ok {
+		return jsError(fmt.Errorf("unknown graph handle %d", args[0].Int()))
+	}
+	res, err := g.Query(args[1].String())
+	if err != nil {
+		return jsError(err)
+	}
+	raw, err := pgraph.MarshalResultJSON(res)
+	if err != nil {
+		return jsError(err)
+	}
+	return string(raw)
+}
+
+func register(g *pgraph.PGraph) int {
+	h := nextHandle
+	nextHandle++
+	graphs[h] = g
+	return h
+}
+
+func jsError(err error) js.Value {
+	return js.Global().Get("Error").New(err.Error())
+}
@@ -0,0 +1,35 @@
+package pgraph
+
+import (
+	"math/rand/v2"
+
+	"github.com/ritamzico/pgraph/internal/generator"
+)
+
+// GenerateErdosRenyi returns a random directed G(n, p) graph with nodes
+// n0..n<n-1>, in which each ordered pair of distinct nodes is joined with
+// probability p. Edge probabilities are uniform in [0.5, 1.0]. The same
+// seed always yields the same graph.
+func GenerateErdosRenyi(n int, p float64, seed uint64) (*PGraph, error) {
+	g, err := generator.ErdosRenyi(n, p, seededRand(seed))
+	if err != nil {
+		return nil, err
+	}
+	return newLoaded(g, nil)
+}
+
+// GenerateScaleFree returns a random Barabási–Albert graph of n nodes in
+// which each node after the first m links to m earlier nodes, preferring
+// those with many links already. Edge probabilities are uniform in
+// [0.5, 1.0]. The same seed always yields the same graph.
+func GenerateScaleFree(n, m int, seed uint64) (*PGraph, error) {
+	g, err := generator.ScaleFree(n, m, seededRand(seed))
+	if err != nil {
+		return nil, err
+	}
+	return newLoaded(g, nil)
+}
+
+func seededRand(seed uint64) *rand.Rand {
+	return rand.New(rand.NewPCG(seed, seed^0xda942042e4dd58b5))
+}
@@ -0,0 +1,255 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: proto/pgraph.proto
+
+package pgraphpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	PGraphService_LoadGraph_FullMethodName   = "/pgraph.v1.PGraphService/LoadGraph"
+	PGraphService_UnloadGraph_FullMethodName = "/pgraph.v1.PGraphService/UnloadGraph"
+	PGraphService_Query_FullMethodName       = "/pgraph.v1.PGraphService/Query"
+	PGraphService_BatchQuery_FullMethodName  = "/pgraph.v1.PGraphService/BatchQuery"
+)
+
+// PGraphServiceClient is the client API for PGraphService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// PGraphService serves DSL queries against named, server-held graphs.
+type PGraphServiceClient interface {
+	// LoadGraph parses a graph in the JSON format written by PGraph.Save and
+	// stores it under name, replacing any graph already loaded with that name.
+	LoadGraph(ctx context.Context, in *LoadGraphRequest, opts ...grpc.CallOption) (*LoadGraphResponse, error)
+	// UnloadGraph discards the named graph.
+	UnloadGraph(ctx context.Context, in *UnloadGraphRequest, opts ...grpc.CallOption) (*UnloadGraphResponse, error)
+	// Query runs one DSL line. A MULTI query streams one Result per
+	// sub-query, in order; every other query streams exactly one.
+	Query(ctx context.Context, in *QueryRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Result], error)
+	// BatchQuery runs each line in order and stops at the first error.
+	BatchQuery(ctx context.Context, in *BatchQueryRequest, opts ...grpc.CallOption) (*BatchQueryResponse, error)
+}
+
+type pGraphServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPGraphServiceClient(cc grpc.ClientConnInterface) PGraphServiceClient {
+	return &pGraphServiceClient{cc}
+}
+
+func (c *pGraphServiceClient) LoadGraph(ctx context.Context, in *LoadGraphRequest, opts ...grpc.CallOption) (*LoadGraphResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LoadGraphResponse)
+	err := c.cc.Invoke(ctx, PGraphService_LoadGraph_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pGraphServiceClient) UnloadGraph(ctx context.Context, in *UnloadGraphRequest, opts ...grpc.CallOption) (*UnloadGraphResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UnloadGraphResponse)
+	err := c.cc.Invoke(ctx, PGraphService_UnloadGraph_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pGraphServiceClient) Query(ctx context.Context, in *QueryRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Result], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &PGraphService_ServiceDesc.Streams[0], PGraphService_Query_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[QueryRequest, Result]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PGraphService_QueryClient = grpc.ServerStreamingClient[Result]
+
+func (c *pGraphServiceClient) BatchQuery(ctx context.Context, in *BatchQueryRequest, opts ...grpc.CallOption) (*BatchQueryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchQueryResponse)
+	err := c.cc.Invoke(ctx, PGraphService_BatchQuery_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PGraphServiceServer is the server API for PGraphService service.
+// All implementations must embed UnimplementedPGraphServiceServer
+// for forward compatibility.
+//
+// PGraphService serves DSL queries against named, server-held graphs.
+type PGraphServiceServer interface {
+	// LoadGraph parses a graph in the JSON format written by PGraph.Save and
+	// stores it under name, replacing any graph already loaded with that name.
+	LoadGraph(context.Context, *LoadGraphRequest) (*LoadGraphResponse, error)
+	// UnloadGraph discards the named graph.
+	UnloadGraph(context.Context, *UnloadGraphRequest) (*UnloadGraphResponse, error)
+	// Query runs one DSL line. A MULTI query streams one Result per
+	// sub-query, in order; every other query streams exactly one.
+	Query(*QueryRequest, grpc.ServerStreamingServer[Result]) error
+	// BatchQuery runs each line in order and stops at the first error.
+	BatchQuery(context.Context, *BatchQueryRequest) (*BatchQueryResponse, error)
+	mustEmbedUnimplementedPGraphServiceServer()
+}
+
+// UnimplementedPGraphServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedPGraphServiceServer struct{}
+
+func (UnimplementedPGraphServiceServer) LoadGraph(context.Context, *LoadGraphRequest) (*LoadGraphResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LoadGraph not implemented")
+}
+func (UnimplementedPGraphServiceServer) UnloadGraph(context.Context, *UnloadGraphRequest) (*UnloadGraphResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UnloadGraph not implemented")
+}
+func (UnimplementedPGraphServiceServer) Query(*QueryRequest, grpc.ServerStreamingServer[Result]) error {
+	return status.Errorf(codes.Unimplemented, "method Query not implemented")
+}
+func (UnimplementedPGraphServiceServer) BatchQuery(context.Context, *BatchQueryRequest) (*BatchQueryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchQuery not implemented")
+}
+func (UnimplementedPGraphServiceServer) mustEmbedUnimplementedPGraphServiceServer() {}
+func (UnimplementedPGraphServiceServer) testEmbeddedByValue()                       {}
+
+// UnsafePGraphServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PGraphServiceServer will
+// result in compilation errors.
+type UnsafePGraphServiceServer interface {
+	mustEmbedUnimplementedPGraphServiceServer()
+}
+
+func RegisterPGraphServiceServer(s grpc.ServiceRegistrar, srv PGraphServiceServer) {
+	// If the following call pancis, it indicates UnimplementedPGraphServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&PGraphService_ServiceDesc, srv)
+}
+
+func _PGraphService_LoadGraph_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LoadGraphRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PGraphServiceServer).LoadGraph(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PGraphService_LoadGraph_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PGraphServiceServer).LoadGraph(ctx, req.(*LoadGraphRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PGraphService_UnloadGraph_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnloadGraphRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PGraphServiceServer).UnloadGraph(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PGraphService_UnloadGraph_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PGraphServiceServer).UnloadGraph(ctx, req.(*UnloadGraphRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PGraphService_Query_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(QueryRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(PGraphServiceServer).Query(m, &grpc.GenericServerStream[QueryRequest, Result]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PGraphService_QueryServer = grpc.ServerStreamingServer[Result]
+
+func _PGraphService_BatchQuery_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchQueryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PGraphServiceServer).BatchQuery(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PGraphService_BatchQuery_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PGraphServiceServer).BatchQuery(ctx, req.(*BatchQueryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// PGraphService_ServiceDesc is the grpc.ServiceDesc for PGraphService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PGraphService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "pgraph.v1.PGraphService",
+	HandlerType: (*PGraphServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "LoadGraph",
+			Handler:    _PGraphService_LoadGraph_Handler,
+		},
+		{
+			MethodName: "UnloadGraph",
+			Handler:    _PGraphService_UnloadGraph_Handler,
+		},
+		{
+			MethodName: "BatchQuery",
+			Handler:    _PGraphService_BatchQuery_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Query",
+			Handler:       _PGraphService_Query_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "proto/pgraph.proto",
+}
@@ -0,0 +1,166 @@
+// Package grpc implements PGraphService, defined in proto/pgraph.proto,
+// over named graphs held in memory by the server.
+package grpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+
+	"github.com/ritamzico/pgraph"
+	"github.com/ritamzico/pgraph/grpc/pgraphpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Server implements pgraphpb.PGraphServiceServer. Calls on different graphs
+// run in parallel; calls on the same graph run one at a time, since a
+// PGraph is not safe for concurrent use.
+type Server struct {
+	pgraphpb.UnimplementedPGraphServiceServer
+
+	mu     sync.Mutex
+	graphs map[string]*entry
+}
+
+// entry is a loaded graph and the lock serializing calls on it.
+type entry struct {
+	mu sync.Mutex
+	pg *pgraph.PGraph
+}
+
+// NewServer returns a Server with no graphs loaded.
+func NewServer() *Server {
+	return &Server{graphs: make(map[string]*entry)}
+}
+
+// LoadGraph parses graph_json and stores the graph under name, replacing
+// any graph already loaded with that name.
+func (s *Server) LoadGraph(ctx context.Context, req *pgraphpb.LoadGraphRequest) (*pgraphpb.LoadGraphResponse, error) {
+	if req.GetName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "graph name is required")
+	}
+	pg, err := pgraph.Load(bytes.NewReader(req.GetGraphJson()))
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "loading graph %q: %v", req.GetName(), err)
+	}
+	stats := pg.Stats()
+
+	s.mu.Lock()
+	s.graphs[req.GetName()] = &entry{pg: pg}
+	s.mu.Unlock()
+
+	return &pgraphpb.LoadGraphResponse{
+		NodeCount: int32(stats.NodeCount),
+		EdgeCount: int32(stats.EdgeCount),
+	}, nil
+}
+
+// UnloadGraph discards the named graph. Calls already running on it finish
+// against the discarded graph.
+func (s *Server) UnloadGraph(ctx context.Context, req *pgraphpb.UnloadGraphRequest) (*pgraphpb.UnloadGraphResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.graphs[req.GetName()]; !ok {
+		return nil, status.Errorf(codes.NotFound, "no graph named %q", req.GetName())
+	}
+	delete(s.graphs, req.GetName())
+	return &pgraphpb.UnloadGraphResponse{}, nil
+}
+
+// Query runs one DSL line. A MULTI query streams one Result per sub-query,
+// in order; every other query streams exactly one.
+func (s *Server) Query(req *pgraphpb.QueryRequest, stream pgraphpb.PGraphService_QueryServer) error {
+	e, err := s.lookup(req.GetGraph())
+	if err != nil {
+		return err
+	}
+	e.mu.Lock()
+	res, err := e.pg.QueryContext(stream.Context(), req.GetQuery())
+	e.mu.Unlock()
+	if err != nil {
+		return queryError(err)
+	}
+
+	results := []pgraph.Result{res}
+	if multi, ok := res.(pgraph.MultiResult); ok {
+		results = multi.Results
+	}
+	for _, r := range results {
+		msg, err := toProto(r)
+		if err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+		if err := stream.Send(msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BatchQuery runs each line in order and stops at the first error, which
+// it returns instead of the results so far.
+func (s *Server) BatchQuery(ctx context.Context, req *pgraphpb.BatchQueryRequest) (*pgraphpb.BatchQueryResponse, error) {
+	e, err := s.lookup(req.GetGraph())
+	if err != nil {
+		return nil, err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	resp := &pgraphpb.BatchQueryResponse{}
+	for i, line := range req.GetQueries() {
+		res, err := e.pg.QueryContext(ctx, line)
+		if err != nil {
+			st := status.Convert(queryError(err))
+			return nil, status.Errorf(st.Code(), "query %d: %s", i+1, st.Message())
+		}
+		msg, err := toProto(res)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		resp.Results = append(resp.Results, msg)
+	}
+	return resp, nil
+}
+
+func (s *Server) lookup(name string) (*entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.graphs[name]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "no graph named %q", name)
+	}
+	return e, nil
+}
+
+// queryError maps an error from PGraph.QueryContext to a status: the RPC's
+// own cancellation or deadline keeps its code, and anything else is a bad
+// query.
+func queryError(err error) error {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return status.FromContextError(err).Err()
+	}
+	return status.Error(codes.InvalidArgument, err.Error())
+}
+
+// toProto wraps r in the pgraph.MarshalResultJSON envelope. A nil result,
+// from a statement, becomes an empty Result.
+func toProto(r pgraph.Result) (*pgraphpb.Result, error) {
+	if r == nil {
+		return &pgraphpb.Result{}, nil
+	}
+	raw, err := pgraph.MarshalResultJSON(r)
+	if err != nil {
+		return nil, err
+	}
+	var envelope struct {
+		Kind string `json:"kind"`
+	}
+	if err := json.Unmarshal(raw, &envelope); err != nil {
+		return nil, err
+	}
+	return &pgraphpb.Result{Kind: envelope.Kind, ResultJson: raw}, nil
+}
@@ -0,0 +1,220 @@
+package grpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/ritamzico/pgraph"
+	"github.com/ritamzico/pgraph/grpc/pgraphpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newTestClient serves a new Server over an in-memory connection and
+// returns a client for it with graph "g" loaded: A -> B (0.9), B -> C (0.8).
+func newTestClient(t *testing.T) pgraphpb.PGraphServiceClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	pgraphpb.RegisterPGraphServiceServer(srv, NewServer())
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	client := pgraphpb.NewPGraphServiceClient(conn)
+
+	pg := pgraph.New()
+	for _, line := range []string{
+		"CREATE NODE A, B, C",
+		"CREATE EDGE eAB FROM A TO B PROB 0.9",
+		"CREATE EDGE eBC FROM B TO C PROB 0.8",
+	} {
+		if _, err := pg.Query(line); err != nil {
+			t.Fatalf("Query(%q): %v", line, err)
+		}
+	}
+	var buf bytes.Buffer
+	if err := pg.Save(&buf); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	resp, err := client.LoadGraph(context.Background(), &pgraphpb.LoadGraphRequest{Name: "g", GraphJson: buf.Bytes()})
+	if err != nil {
+		t.Fatalf("LoadGraph: %v", err)
+	}
+	if resp.GetNodeCount() != 3 || resp.GetEdgeCount() != 2 {
+		t.Fatalf("expected 3 nodes and 2 edges, got %d and %d", resp.GetNodeCount(), resp.GetEdgeCount())
+	}
+	return client
+}
+
+func receiveAll(t *testing.T, stream grpc.ServerStreamingClient[pgraphpb.Result]) ([]*pgraphpb.Result, error) {
+	t.Helper()
+	var results []*pgraphpb.Result
+	for {
+		r, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return results, nil
+		}
+		if err != nil {
+			return results, err
+		}
+		results = append(results, r)
+	}
+}
+
+func probability(t *testing.T, r *pgraphpb.Result) float64 {
+	t.Helper()
+	res, err := pgraph.UnmarshalResultJSON(r.GetResultJson())
+	if err != nil {
+		t.Fatalf("UnmarshalResultJSON(%s): %v", r.GetResultJson(), err)
+	}
+	pr, ok := res.(pgraph.ProbabilityResult)
+	if !ok {
+		t.Fatalf("expected ProbabilityResult, got %T", res)
+	}
+	return pr.Probability
+}
+
+func TestQuery_StreamsMultiResults(t *testing.T) {
+	client := newTestClient(t)
+
+	stream, err := client.Query(context.Background(), &pgraphpb.QueryRequest{
+		Graph: "g",
+		Query: "MULTI ( REACHABILITY FROM A TO B EXACT, REACHABILITY FROM A TO C EXACT, MAXPATH FROM A TO C )",
+	})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	results, err := receiveAll(t, stream)
+	if err != nil {
+		t.Fatalf("Recv: %v", err)
+	}
+
+	if len(results) != 3 {
+		t.Fatalf("expected one message per sub-query, got %d", len(results))
+	}
+	for i, want := range []string{"probability", "probability", "path"} {
+		if results[i].GetKind() != want {
+			t.Errorf("result %d: expected kind %q, got %q", i, want, results[i].GetKind())
+		}
+	}
+	if p := probability(t, results[1]); p < 0.7199 || p > 0.7201 {
+		t.Errorf("expected A to C reachability 0.72, got %f", p)
+	}
+}
+
+func TestQuery_SingleResultAndStatement(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	stream, err := client.Query(ctx, &pgraphpb.QueryRequest{Graph: "g", Query: "CREATE NODE D"})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	results, err := receiveAll(t, stream)
+	if err != nil {
+		t.Fatalf("Recv: %v", err)
+	}
+	if len(results) != 1 || results[0].GetKind() != "" || len(results[0].GetResultJson()) != 0 {
+		t.Fatalf("expected one empty result for a statement, got %v", results)
+	}
+
+	stream, err = client.Query(ctx, &pgraphpb.QueryRequest{Graph: "g", Query: "REACHABILITY FROM A TO B EXACT"})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	results, err = receiveAll(t, stream)
+	if err != nil {
+		t.Fatalf("Recv: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("expected one result, got %d", len(results))
+	}
+	var envelope struct {
+		Kind string          `json:"kind"`
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(results[0].GetResultJson(), &envelope); err != nil || envelope.Kind != "probability" {
+		t.Errorf("expected a probability envelope, got %s (%v)", results[0].GetResultJson(), err)
+	}
+}
+
+func TestQuery_Errors(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	for _, tc := range []struct {
+		graph, query string
+		code         codes.Code
+	}{
+		{"missing", "REACHABILITY FROM A TO B EXACT", codes.NotFound},
+		{"g", "REACHABILITY FROM", codes.InvalidArgument},
+		{"g", "MAXPATH FROM A TO Z", codes.InvalidArgument},
+	} {
+		stream, err := client.Query(ctx, &pgraphpb.QueryRequest{Graph: tc.graph, Query: tc.query})
+		if err == nil {
+			_, err = receiveAll(t, stream)
+		}
+		if status.Code(err) != tc.code {
+			t.Errorf("%s on %q: expected %s, got %v", tc.query, tc.graph, tc.code, err)
+		}
+	}
+}
+
+func TestBatchQuery_StopsAtFirstError(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	resp, err := client.BatchQuery(ctx, &pgraphpb.BatchQueryRequest{
+		Graph:   "g",
+		Queries: []string{"CREATE NODE D", "REACHABILITY FROM A TO C EXACT"},
+	})
+	if err != nil {
+		t.Fatalf("BatchQuery: %v", err)
+	}
+	if len(resp.GetResults()) != 2 || resp.GetResults()[1].GetKind() != "probability" {
+		t.Errorf("expected an empty result then a probability, got %v", resp.GetResults())
+	}
+
+	_, err = client.BatchQuery(ctx, &pgraphpb.BatchQueryRequest{
+		Graph:   "g",
+		Queries: []string{"CREATE NODE E", "CREATE NODE E", "CREATE NODE F"},
+	})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected InvalidArgument, got %v", err)
+	}
+	// F comes after the failing line, so it was never created.
+	if _, err := client.BatchQuery(ctx, &pgraphpb.BatchQueryRequest{Graph: "g", Queries: []string{"CREATE NODE F"}}); err != nil {
+		t.Errorf("expected F not to exist after the failed batch: %v", err)
+	}
+}
+
+func TestUnloadGraph(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	if _, err := client.UnloadGraph(ctx, &pgraphpb.UnloadGraphRequest{Name: "g"}); err != nil {
+		t.Fatalf("UnloadGraph: %v", err)
+	}
+	if _, err := client.UnloadGraph(ctx, &pgraphpb.UnloadGraphRequest{Name: "g"}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound for an unloaded graph, got %v", err)
+	}
+	if _, err := client.BatchQuery(ctx, &pgraphpb.BatchQueryRequest{Graph: "g"}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound querying an unloaded graph, got %v", err)
+	}
+}
@@ -0,0 +1,215 @@
+package pgraph
+
+import "github.com/ritamzico/pgraph/internal/graph"
+
+type hooks struct {
+	nodeAdded   []func(NodeID)
+	edgeAdded   []func(EdgeID)
+	nodeRemoved []func(NodeID)
+	edgeRemoved []func(EdgeID)
+
+	audit *auditLog
+}
+
+// hookedGraph wraps the session graph and fires the owning PGraph's hooks,
+// and records to its audit log, after each successful mutation. Clone
+// returns an unhooked copy.
+type hookedGraph struct {
+	graph.ProbabilisticGraphModel
+	h *hooks
+}
+
+func (g hookedGraph) AddNode(id NodeID, props map[string]Value) error {
+	if err := g.ProbabilisticGraphModel.AddNode(id, props); err != nil {
+		return err
+	}
+	g.h.record(MutationAddNode, MutationArgs{Node: id, Props: props})
+	fireNode(g.h.nodeAdded, id)
+	return nil
+}
+
+// RemoveNode also fires OnEdgeRemoved for every edge removed with the node.
+// A bidirectional edge fires once, with its own ID, as in RemoveEdgeByID.
+func (g hookedGraph) RemoveNode(id NodeID) error {
+	var incident []*graph.Edge
+	if out, err := g.OutgoingEdges(id); err == nil {
+		incident = append(incident, out...)
+	}
+	if in, err := g.IncomingEdges(id); err == nil {
+		for _, e := range in {
+			if e.From != id {
+				incident = append(incident, e)
+			}
+		}
+	}
+	if err := g.ProbabilisticGraphModel.RemoveNode(id); err != nil {
+		return err
+	}
+	g.h.record(MutationRemoveNode, MutationArgs{Node: id})
+	fireNode(g.h.nodeRemoved, id)
+	for _, e := range incident {
+		if e.IsReverseHalf() {
+			continue
+		}
+		fireEdge(g.h.edgeRemoved, e.ID)
+	}
+	return nil
+}
+
+func (g hookedGraph) AddEdge(id EdgeID, from, to NodeID, prob float64, props map[string]Value) error {
+	if err := g.ProbabilisticGraphModel.AddEdge(id, from, to, prob, props); err != nil {
+		return err
+	}
+	g.h.record(MutationAddEdge, MutationArgs{Edge: id, From: from, To: to, Probability: prob, Props: props})
+	fireEdge(g.h.edgeAdded, id)
+	return nil
+}
+
+// AddBidirectionalEdge fires OnEdgeAdded once, with the forward edge's ID.
+func (g hookedGraph) AddBidirectionalEdge(id EdgeID, a, b NodeID, prob float64, props map[string]Value) error {
+	if err := g.ProbabilisticGraphModel.AddBidirectionalEdge(id, a, b, prob, props); err != nil {
+		return err
+	}
+	g.h.record(MutationAddBidirectionalEdge, MutationArgs{Edge: id, From: a, To: b, Probability: prob, Props: props})
+	fireEdge(g.h.edgeAdded, id)
+	return nil
+}
+
+func (g hookedGraph) RemoveEdge(from, to NodeID) error {
+	e, err := g.GetEdge(from, to)
+	if err != nil {
+		return err
+	}
+	return g.RemoveEdgeByID(e.ID)
+}
+
+func (g hookedGraph) RemoveEdgeByID(id EdgeID) error {
+	if err := g.ProbabilisticGraphModel.RemoveEdgeByID(id); err != nil {
+		return err
+	}
+	g.h.record(MutationRemoveEdge, MutationArgs{Edge: id})
+	fireEdge(g.h.edgeRemoved, id)
+	return nil
+}
+
+func (g hookedGraph) UpdateEdge(id EdgeID, prob float64) error {
+	if err := g.ProbabilisticGraphModel.UpdateEdge(id, prob); err != nil {
+		return err
+	}
+	g.h.record(MutationUpdateEdge, MutationArgs{Edge: id, Probability: prob})
+	return nil
+}
+
+func (g hookedGraph) AddGroup(name string, defaults map[string]Value) error {
+	if err := g.ProbabilisticGraphModel.AddGroup(name, defaults); err != nil {
+		return err
+	}
+	g.h.record(MutationAddGroup, MutationArgs{Group: name, Props: defaults})
+	return nil
+}
+
+func (g hookedGraph) SetNodeGroup(id NodeID, group string) error {
+	if err := g.ProbabilisticGraphModel.SetNodeGroup(id, group); err != nil {
+		return err
+	}
+	g.h.record(MutationSetNodeGroup, MutationArgs{Node: id, Group: group})
+	return nil
+}
+
+func (g hookedGraph) UpdateEdgeInterval(id EdgeID, low, high float64) error {
+	if err := g.ProbabilisticGraphModel.UpdateEdgeInterval(id, low, high); err != nil {
+		return err
+	}
+	g.h.record(MutationUpdateEdgeInterval, MutationArgs{Edge: id, ProbLow: low, ProbHigh: high})
+	return nil
+}
+
+// NodesWithProperty passes index lookups through to the wrapped graph,
+// which embedding the ProbabilisticGraphModel interface would hide.
+func (g hookedGraph) NodesWithProperty(key string, value Value) ([]*graph.Node, bool) {
+	if pi, ok := g.ProbabilisticGraphModel.(graph.PropertyIndexer); ok {
+		return pi.NodesWithProperty(key, value)
+	}
+	return nil, false
+}
+
+func fireNode(fns []func(NodeID), id NodeID) {
+	for _, fn := range fns {
+		fn(id)
+	}
+}
+
+func fireEdge(fns []func(EdgeID), id EdgeID) {
+	for _, fn := range fns {
+		fn(id)
+	}
+}
+
+// observe routes session graph mutations through the receiver's hooks.
+func (p *PGraph) observe() {
+	if _, ok := p.parser.SessionGraph.(hookedGraph); ok {
+		return
+	}
+	p.parser.ReplaceSessionGraph(hookedGraph{ProbabilisticGraphModel: p.parser.SessionGraph, h: &p.hooks})
+}
+
+// OnNodeAdded registers fn to be called with the ID of each node added to
+// the graph, whether through Query or AddNode. A transaction's changes fire
+// hooks when it commits.
+func (p *PGraph) OnNodeAdded(fn func(id NodeID)) {
+	p.hooks.nodeAdded = append(p.hooks.nodeAdded, fn)
+	p.observe()
+}
+
+// OnEdgeAdded registers fn to be called with the ID of each edge added to
+// the graph.
+func (p *PGraph) OnEdgeAdded(fn func(id EdgeID)) {
+	p.hooks.edgeAdded = append(p.hooks.edgeAdded, fn)
+	p.observe()
+}
+
+// OnNodeRemoved registers fn to be called with the ID of each node removed
+// from the graph.
+func (p *PGraph) OnNodeRemoved(fn func(id NodeID)) {
+	p.hooks.nodeRemoved = append(p.hooks.nodeRemoved, fn)
+	p.observe()
+}
+
+// OnEdgeRemoved registers fn to be called with the ID of each edge removed
+// from the graph, including edges removed along with one of their nodes.
+func (p *PGraph) OnEdgeRemoved(fn func(id EdgeID)) {
+	p.hooks.edgeRemoved = append(p.hooks.edgeRemoved, fn)
+	p.observe()
+}
+
+func (p *PGraph) AddNode(id NodeID, props map[string]Value) error {
+	return p.parser.SessionGraph.AddNode(id, props)
+}
+
+func (p *PGraph) RemoveNode(id NodeID) error {
+	return p.parser.SessionGraph.RemoveNode(id)
+}
+
+func (p *PGraph) AddEdge(id EdgeID, from, to NodeID, prob float64, props map[string]Value) error {
+	return p.parser.SessionGraph.AddEdge(id, from, to, prob, props)
+}
+
+func (p *PGraph) RemoveEdge(from, to NodeID) error {
+	return p.parser.SessionGraph.RemoveEdge(from, to)
+}
+
+// UpdateEdge sets the probability of edge id. Only that edge changes, not
+// the other half of a bidirectional pair; the DSL's UPDATE EDGE sets both.
+func (p *PGraph) UpdateEdge(id EdgeID, prob float64) error {
+	return p.parser.UpdateEdge(id, prob)
+}
+
+func (p *PGraph) AddGroup(name string, defaults map[string]Value) error {
+	return p.parser.SessionGraph.AddGroup(name, defaults)
+}
+
+// SetNodeGroup places node id in group name, or takes it out of its group
+// when name is empty.
+func (p *PGraph) SetNodeGroup(id NodeID, name string) error {
+	return p.parser.SessionGraph.SetNodeGroup(id, name)
+}
@@ -0,0 +1,118 @@
+package pgraph
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHooks_OnNodeAddedViaDSL(t *testing.T) {
+	pg := New()
+	var added []NodeID
+	pg.OnNodeAdded(func(id NodeID) { added = append(added, id) })
+
+	if _, err := pg.Query("CREATE NODE A"); err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if !reflect.DeepEqual(added, []NodeID{"A"}) {
+		t.Errorf("OnNodeAdded got %v, want [A]", added)
+	}
+
+	// Failed mutations must not fire hooks.
+	if _, err := pg.Query("CREATE NODE A"); err == nil {
+		t.Fatal("expected duplicate node error")
+	}
+	if len(added) != 1 {
+		t.Errorf("hook fired on failed mutation: %v", added)
+	}
+}
+
+func TestHooks_AllMutations(t *testing.T) {
+	pg := newTestPGraph(t)
+	var nodesAdded, nodesRemoved []NodeID
+	var edgesAdded, edgesRemoved []EdgeID
+	pg.OnNodeAdded(func(id NodeID) { nodesAdded = append(nodesAdded, id) })
+	pg.OnNodeRemoved(func(id NodeID) { nodesRemoved = append(nodesRemoved, id) })
+	pg.OnEdgeAdded(func(id EdgeID) { edgesAdded = append(edgesAdded, id) })
+	pg.OnEdgeRemoved(func(id EdgeID) { edgesRemoved = append(edgesRemoved, id) })
+
+	if err := pg.AddNode("C", nil); err != nil {
+		t.Fatalf("AddNode: %v", err)
+	}
+	if err := pg.AddEdge("eBC", "B", "C", 0.5, nil); err != nil {
+		t.Fatalf("AddEdge: %v", err)
+	}
+	if err := pg.RemoveEdge("A", "B"); err != nil {
+		t.Fatalf("RemoveEdge: %v", err)
+	}
+	if _, err := pg.Query("DELETE NODE C"); err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+
+	if !reflect.DeepEqual(nodesAdded, []NodeID{"C"}) {
+		t.Errorf("nodesAdded = %v, want [C]", nodesAdded)
+	}
+	if !reflect.DeepEqual(edgesAdded, []EdgeID{"eBC"}) {
+		t.Errorf("edgesAdded = %v, want [eBC]", edgesAdded)
+	}
+	if !reflect.DeepEqual(nodesRemoved, []NodeID{"C"}) {
+		t.Errorf("nodesRemoved = %v, want [C]", nodesRemoved)
+	}
+	if !reflect.DeepEqual(edgesRemoved, []EdgeID{"eAB", "eBC"}) {
+		t.Errorf("edgesRemoved = %v, want [eAB eBC]", edgesRemoved)
+	}
+}
+
+func TestHooks_RemoveNodeFiresBidirectionalEdgeOnce(t *testing.T) {
+	pg := newTestPGraph(t)
+	if err := pg.AddNode("C", nil); err != nil {
+		t.Fatalf("AddNode: %v", err)
+	}
+	if _, err := pg.Query("CREATE EDGE road FROM B TO C PROB 0.6 BIDIRECTIONAL"); err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	var edgesRemoved []EdgeID
+	pg.OnEdgeRemoved(func(id EdgeID) { edgesRemoved = append(edgesRemoved, id) })
+
+	if _, err := pg.Query("DELETE NODE C"); err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+
+	if !reflect.DeepEqual(edgesRemoved, []EdgeID{"road"}) {
+		t.Errorf("edgesRemoved = %v, want [road]", edgesRemoved)
+	}
+}
+
+func TestHooks_FireOnCommit(t *testing.T) {
+	pg := New()
+	var added []NodeID
+	pg.OnNodeAdded(func(id NodeID) { added = append(added, id) })
+
+	rolledBack := pg.BeginTx()
+	if err := rolledBack.AddNode("X", nil); err != nil {
+		t.Fatalf("AddNode: %v", err)
+	}
+	if err := rolledBack.Rollback(); err != nil {
+		t.Fatalf("Rollback: %v", err)
+	}
+
+	tx := pg.BeginTx()
+	if err := tx.AddNode("A", nil); err != nil {
+		t.Fatalf("AddNode: %v", err)
+	}
+	if len(added) != 0 {
+		t.Errorf("staged changes should not fire hooks before Commit, got %v", added)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+	if !reflect.DeepEqual(added, []NodeID{"A"}) {
+		t.Errorf("added = %v after Commit, want [A]", added)
+	}
+
+	if _, err := pg.Query("CREATE NODE B"); err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if !reflect.DeepEqual(added, []NodeID{"A", "B"}) {
+		t.Errorf("added = %v, want [A B]", added)
+	}
+}
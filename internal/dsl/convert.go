@@ -1,12 +1,23 @@
 package dsl
 
 import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"math"
+	"math/rand/v2"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/alecthomas/participle/v2/lexer"
 	"github.com/ritamzico/pgraph/internal/graph"
+	"github.com/ritamzico/pgraph/internal/inference"
 	"github.com/ritamzico/pgraph/internal/query"
+	"github.com/ritamzico/pgraph/internal/scripting"
+	"github.com/ritamzico/pgraph/internal/serialization"
 )
 
 var validIdentifier = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
@@ -35,9 +46,51 @@ func convertStatement(ast *StatementAST) (Statement, error) {
 	if ast.Create != nil {
 		return convertCreate(ast.Create)
 	}
+	if ast.Update != nil {
+		return convertUpdateEdge(ast.Update)
+	}
+	if ast.Transpose {
+		return &TransposeStatement{}, nil
+	}
+	if ast.ImportJSON != nil {
+		return convertImportJSON(*ast.ImportJSON)
+	}
 	return convertDelete(ast.Delete)
 }
 
+// convertImportJSON decodes the string literal of IMPORT JSON. It holds
+// either the graph JSON itself, with quotes escaped, or its base64 encoding.
+func convertImportJSON(literal string) (Statement, error) {
+	text, err := strconv.Unquote(literal)
+	if err != nil {
+		return nil, SyntaxError{
+			Kind:    "InvalidImport",
+			Message: fmt.Sprintf("invalid string literal %s: %v", literal, err),
+		}
+	}
+
+	data := []byte(text)
+	if !json.Valid(data) {
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(text))
+		if err != nil || !json.Valid(decoded) {
+			return nil, SyntaxError{
+				Kind:    "InvalidImport",
+				Message: "IMPORT JSON expects a JSON graph or its base64 encoding",
+			}
+		}
+		data = decoded
+	}
+
+	g, err := serialization.ReadJSON(bytes.NewReader(data))
+	if err != nil {
+		return nil, SyntaxError{
+			Kind:    "InvalidImport",
+			Message: fmt.Sprintf("invalid graph JSON: %v", err),
+		}
+	}
+	return &ImportJSONStatement{Graph: g}, nil
+}
+
 func convertCreate(ast *CreateAST) (Statement, error) {
 	if ast.Node != nil {
 		ids := make([]graph.NodeID, len(ast.Node.IDs))
@@ -47,53 +100,233 @@ func convertCreate(ast *CreateAST) (Statement, error) {
 			}
 			ids[i] = graph.NodeID(id)
 		}
+		props, err := convertProps(ast.Node.Props)
+		if err != nil {
+			return nil, err
+		}
+		var group string
+		if ast.Node.Group != nil {
+			group = *ast.Node.Group
+		}
 		return &CreateNodeStatement{
 			NodeIDs: ids,
-			Props:   convertProps(ast.Node.Props),
+			Props:   props,
+			Group:   group,
 		}, nil
 	}
 
+	if ast.Group != nil {
+		if err := validateIdentifier(ast.Group.Name, "group"); err != nil {
+			return nil, err
+		}
+		props, err := convertProps(ast.Group.Props)
+		if err != nil {
+			return nil, err
+		}
+		return &CreateGroupStatement{Name: ast.Group.Name, Props: props}, nil
+	}
+
 	e := ast.Edge
 	if err := validateIdentifier(e.EdgeID, "edge"); err != nil {
 		return nil, err
 	}
+	props, err := convertProps(e.Props)
+	if err != nil {
+		return nil, err
+	}
+	if e.ProbInterval != nil {
+		low, high := e.ProbInterval.Low, e.ProbInterval.High
+		if low < 0 || high > 1 || low > high {
+			return nil, SyntaxError{
+				Kind:    "InvalidProbability",
+				Message: fmt.Sprintf("probability interval must satisfy 0 <= lo <= hi <= 1, got [%g, %g]", low, high),
+			}
+		}
+		return &CreateEdgeStatement{
+			EdgeID:        graph.EdgeID(e.EdgeID),
+			From:          graph.NodeID(e.From),
+			To:            graph.NodeID(e.To),
+			ProbLow:       low,
+			ProbHigh:      high,
+			Props:         props,
+			Bidirectional: e.Bidirectional,
+		}, nil
+	}
+	prob, err := convertEdgeProb(e)
+	if err != nil {
+		return nil, err
+	}
 	return &CreateEdgeStatement{
-		EdgeID: graph.EdgeID(e.EdgeID),
-		From:   graph.NodeID(e.From),
-		To:     graph.NodeID(e.To),
-		Prob:   e.Prob,
-		Props:  convertProps(e.Props),
+		EdgeID:        graph.EdgeID(e.EdgeID),
+		From:          graph.NodeID(e.From),
+		To:            graph.NodeID(e.To),
+		Prob:          prob,
+		Props:         props,
+		Bidirectional: e.Bidirectional,
 	}, nil
 }
 
-func convertProps(props []*PropAST) map[string]graph.Value {
+// convertNodeSet converts a GROUP reference or a braced node list.
+func convertNodeSet(ast *NodeSetAST) query.NodeSet {
+	if ast.Group != nil {
+		return query.NodeSet{Group: *ast.Group}
+	}
+	nodes := make([]graph.NodeID, len(ast.Nodes))
+	for i, id := range ast.Nodes {
+		nodes[i] = graph.NodeID(id)
+	}
+	return query.NodeSet{Nodes: nodes}
+}
+
+// convertTimeout parses an optional TIMEOUT clause; nil gives zero, meaning
+// no timeout.
+func convertTimeout(d *string) (time.Duration, error) {
+	if d == nil {
+		return 0, nil
+	}
+	timeout, err := time.ParseDuration(*d)
+	if err != nil || timeout <= 0 {
+		return 0, SyntaxError{
+			Kind:    "InvalidTimeout",
+			Message: fmt.Sprintf("TIMEOUT must be a positive duration such as 500ms, 2s or 1m, got %q", *d),
+		}
+	}
+	return timeout, nil
+}
+
+func convertAllPathsStat(ast *AllPathsAST) (query.AllPathsStatQuery, error) {
+	if ast.MaxPaths != 0 || ast.MaxLength != 0 {
+		return query.AllPathsStatQuery{}, SyntaxError{
+			Kind:    "InvalidSyntax",
+			Message: "STAT covers every path and cannot be combined with MAX or MAXLEN",
+		}
+	}
+
+	q := query.AllPathsStatQuery{
+		Start: graph.NodeID(ast.From),
+		End:   graph.NodeID(ast.To),
+	}
+
+	switch strings.ToUpper(ast.Stat.Stat) {
+	case "MEAN":
+		q.Stat = inference.PathStatMean
+	case "MAX":
+		q.Stat = inference.PathStatMax
+	case "MIN":
+		q.Stat = inference.PathStatMin
+	case "COUNT":
+		q.Stat = inference.PathStatCount
+	case "VARIANCE":
+		q.Stat = inference.PathStatVariance
+	default:
+		return query.AllPathsStatQuery{}, SyntaxError{
+			Kind:    "InvalidStatistic",
+			Message: fmt.Sprintf("unknown path statistic %q — expected MEAN, MAX, MIN, COUNT or VARIANCE", ast.Stat.Stat),
+		}
+	}
+
+	switch strings.ToUpper(ast.Stat.Measure) {
+	case "", "PROB":
+		q.Measure = query.MeasureProbability
+	case "HOPS":
+		q.Measure = query.MeasureHops
+	default:
+		return query.AllPathsStatQuery{}, SyntaxError{
+			Kind:    "InvalidMeasure",
+			Message: fmt.Sprintf("unknown path measure %q — expected PROB or HOPS", ast.Stat.Measure),
+		}
+	}
+	return q, nil
+}
+
+// convertEdgeProb returns the edge probability given directly by PROB, or
+// implied by LOGPROB as exp(logprob). A PROB outside [0, 1] is rejected here,
+// rather than by AddEdge, so that it is reported as a syntax error.
+func convertEdgeProb(e *CreateEdgeAST) (float64, error) {
+	if e.Prob != nil {
+		prob := *e.Prob
+		if e.ProbNegative {
+			prob = -prob
+		}
+		if prob < 0 || prob > 1 {
+			return 0, SyntaxError{
+				Kind:    "InvalidProbability",
+				Message: fmt.Sprintf("probability must be in [0,1], got %g", prob),
+			}
+		}
+		return prob, nil
+	}
+
+	logProb := e.LogProb.Magnitude
+	if e.LogProb.Negative {
+		logProb = -logProb
+	}
+	if logProb > 0 {
+		return 0, SyntaxError{
+			Kind:    "InvalidLogProb",
+			Message: fmt.Sprintf("log-probability must be <= 0, got %g", logProb),
+		}
+	}
+	return math.Exp(logProb), nil
+}
+
+func convertProps(props []*PropAST) (map[string]graph.Value, error) {
 	if len(props) == 0 {
-		return nil
+		return nil, nil
 	}
 
 	propMap := make(map[string]graph.Value, len(props))
 
 	for _, p := range props {
-		var value graph.Value
-
-		switch {
-		case p.Value.Str != nil:
-			value = graph.Value{Kind: graph.StringVal, S: strings.Trim(*p.Value.Str, "\"")}
-		case p.Value.Float != nil:
-			value = graph.Value{Kind: graph.FloatVal, F: *p.Value.Float}
-		case p.Value.Int != nil:
-			value = graph.Value{Kind: graph.IntVal, I: *p.Value.Int}
-		case p.Value.True:
-			value = graph.Value{Kind: graph.BoolVal, B: true}
-		case p.Value.False:
-			value = graph.Value{Kind: graph.BoolVal, B: false}
-		default:
+		value, err := convertPropValue(p.Value)
+		if err != nil {
+			return nil, fmt.Errorf("property %q: %w", p.Key, err)
 		}
-
 		propMap[p.Key] = value
 	}
 
-	return propMap
+	return propMap, nil
+}
+
+func convertPropValue(ast *PropValueAST) (graph.Value, error) {
+	switch {
+	case ast.Str != nil:
+		return graph.Value{Kind: graph.StringVal, S: strings.Trim(*ast.Str, "\"")}, nil
+	case ast.Float != nil:
+		return graph.Value{Kind: graph.FloatVal, F: *ast.Float}, nil
+	case ast.Int != nil:
+		return graph.Value{Kind: graph.IntVal, I: *ast.Int}, nil
+	case ast.True:
+		return graph.Value{Kind: graph.BoolVal, B: true}, nil
+	case ast.False:
+		return graph.Value{Kind: graph.BoolVal, B: false}, nil
+	case ast.Null:
+		return graph.Value{Kind: graph.NullVal}, nil
+	case ast.List != nil:
+		return convertArrayValue(ast.List)
+	default:
+		return graph.Value{}, nil
+	}
+}
+
+// convertArrayValue converts a list literal, rejecting lists whose elements
+// are not all of the same kind.
+func convertArrayValue(ast *ListValueAST) (graph.Value, error) {
+	elems := make([]graph.Value, len(ast.Elems))
+	for i, e := range ast.Elems {
+		v, err := convertPropValue(e)
+		if err != nil {
+			return graph.Value{}, err
+		}
+		if i > 0 && v.Kind != elems[0].Kind {
+			return graph.Value{}, SyntaxError{
+				Kind:    "HeterogeneousArray",
+				Message: fmt.Sprintf("array elements must all have the same type: element %d is %s, expected %s", i, v.Kind, elems[0].Kind),
+			}
+		}
+		elems[i] = v
+	}
+	return graph.Value{Kind: graph.ArrayVal, A: elems}, nil
 }
 
 func convertDelete(ast *DeleteAST) (Statement, error) {
@@ -117,6 +350,16 @@ func convertDelete(ast *DeleteAST) (Statement, error) {
 	}, nil
 }
 
+func convertUpdateEdge(ast *UpdateEdgeAST) (Statement, error) {
+	if ast.Prob < 0 || ast.Prob > 1 {
+		return nil, SyntaxError{
+			Kind:    "InvalidProbability",
+			Message: fmt.Sprintf("probability must be in [0,1], got %g", ast.Prob),
+		}
+	}
+	return &UpdateEdgeStatement{EdgeID: graph.EdgeID(ast.EdgeID), Prob: ast.Prob}, nil
+}
+
 func convertQuery(ast *QueryAST, g graph.ProbabilisticGraphModel) (query.Query, error) {
 	switch {
 	case ast.Conditional != nil:
@@ -125,36 +368,196 @@ func convertQuery(ast *QueryAST, g graph.ProbabilisticGraphModel) (query.Query,
 	case ast.Threshold != nil:
 		return convertThreshold(ast.Threshold, g)
 
+	case ast.Assert != nil:
+		return convertAssert(ast.Assert, g)
+
 	case ast.Aggregate != nil:
 		return convertAggregate(ast.Aggregate, g)
 
 	case ast.MaxPath != nil:
+		if ast.MaxPath.WeightKw != "" && !strings.EqualFold(ast.MaxPath.WeightKw, "WEIGHT") {
+			return nil, SyntaxError{
+				Kind:    "InvalidSyntax",
+				Message: fmt.Sprintf("expected WEIGHT <property> after the target node, got %q", ast.MaxPath.WeightKw),
+			}
+		}
 		return query.MaxProbabilityPathQuery{
-			Start: graph.NodeID(ast.MaxPath.From),
-			End:   graph.NodeID(ast.MaxPath.To),
+			Start:          graph.NodeID(ast.MaxPath.From),
+			End:            graph.NodeID(ast.MaxPath.To),
+			WeightProperty: ast.MaxPath.Weight,
+			Bottleneck:     ast.MaxPath.Bottleneck,
+		}, nil
+
+	case ast.Connected != nil:
+		return query.ConnectedQuery{
+			Start: graph.NodeID(ast.Connected.From),
+			End:   graph.NodeID(ast.Connected.To),
+		}, nil
+
+	case ast.PathProb != nil:
+		nodes := make([]graph.NodeID, len(ast.PathProb.Nodes))
+		for i, id := range ast.PathProb.Nodes {
+			nodes[i] = graph.NodeID(id)
+		}
+		return query.PathProbabilityQuery{Nodes: nodes}, nil
+
+	case ast.Unreachable != nil:
+		return query.UnreachableQuery{Root: graph.NodeID(ast.Unreachable.Root)}, nil
+
+	case ast.AllPaths != nil && ast.AllPaths.Stat != nil:
+		return convertAllPathsStat(ast.AllPaths)
+
+	case ast.AllPaths != nil:
+		return query.AllPathsQuery{
+			Start:     graph.NodeID(ast.AllPaths.From),
+			End:       graph.NodeID(ast.AllPaths.To),
+			MaxPaths:  ast.AllPaths.MaxPaths,
+			MaxLength: ast.AllPaths.MaxLength,
+		}, nil
+
+	case ast.ExpectedHops != nil:
+		return query.ExpectedHopsQuery{
+			Start: graph.NodeID(ast.ExpectedHops.From),
+			End:   graph.NodeID(ast.ExpectedHops.To),
+		}, nil
+
+	case ast.CountPaths != nil:
+		return query.CountPathsQuery{
+			Start:     graph.NodeID(ast.CountPaths.From),
+			End:       graph.NodeID(ast.CountPaths.To),
+			MaxLength: ast.CountPaths.MaxLength,
+		}, nil
+
+	case ast.TopKProbs != nil:
+		return query.TopKProbabilityValuesQuery{
+			Start: graph.NodeID(ast.TopKProbs.From),
+			End:   graph.NodeID(ast.TopKProbs.To),
+			K:     ast.TopKProbs.K,
 		}, nil
 
 	case ast.TopK != nil:
-		return query.TopKProbabilityPathsQuery{
+		q := query.TopKProbabilityPathsQuery{
 			Start: graph.NodeID(ast.TopK.From),
 			End:   graph.NodeID(ast.TopK.To),
 			K:     ast.TopK.K,
-		}, nil
+		}
+		if p := ast.TopK.MinProb; p != nil {
+			if *p > 1 {
+				return nil, SyntaxError{
+					Kind:    "InvalidMinProb",
+					Message: fmt.Sprintf("MIN_PROB must be between 0 and 1, got %v", *p),
+				}
+			}
+			q.MinProb = *p
+		}
+		if ast.TopK.HopsKw != "" {
+			if !strings.EqualFold(ast.TopK.HopsKw, "MAXHOPS") {
+				return nil, SyntaxError{
+					Kind:    "InvalidSyntax",
+					Message: fmt.Sprintf("expected MAXHOPS <n> after K, got %q", ast.TopK.HopsKw),
+				}
+			}
+			if ast.TopK.MaxHops <= 0 {
+				return nil, SyntaxError{
+					Kind:    "InvalidMaxHops",
+					Message: fmt.Sprintf("MAXHOPS must be positive, got %d", ast.TopK.MaxHops),
+				}
+			}
+			q.MaxHops = ast.TopK.MaxHops
+		}
+		timeout, err := convertTimeout(ast.TopK.Timeout)
+		if err != nil {
+			return nil, err
+		}
+		q.Timeout = timeout
+		return q, nil
 
 	case ast.Reachability != nil:
 		r := ast.Reachability
 		mode := query.Exact
 		if strings.EqualFold(r.Mode, "MONTECARLO") {
 			mode = query.MonteCarlo
+		} else if strings.EqualFold(r.Mode, "BOTH") {
+			mode = query.Both
 		}
-		return query.ReachabilityProbabilityQuery{
-			Start: graph.NodeID(r.From),
-			End:   graph.NodeID(r.To),
-			Mode:  mode,
+		timeout, err := convertTimeout(r.Timeout)
+		if err != nil {
+			return nil, err
+		}
+		if timeout > 0 && mode == query.MonteCarlo {
+			return nil, SyntaxError{
+				Kind:    "InvalidTimeout",
+				Message: "TIMEOUT limits exact inference and applies only to EXACT and BOTH",
+			}
+		}
+		if len(r.From) == 1 && len(r.To) == 1 {
+			return query.ReachabilityProbabilityQuery{
+				Start:   graph.NodeID(r.From[0]),
+				End:     graph.NodeID(r.To[0]),
+				Mode:    mode,
+				Timeout: timeout,
+			}, nil
+		}
+		sources := make([]graph.NodeID, len(r.From))
+		for i, id := range r.From {
+			sources[i] = graph.NodeID(id)
+		}
+		sinks := make([]graph.NodeID, len(r.To))
+		for i, id := range r.To {
+			sinks[i] = graph.NodeID(id)
+		}
+		return query.MultiSourceSinkReachabilityQuery{
+			Sources: sources,
+			Sinks:   sinks,
+			Mode:    mode,
+			Timeout: timeout,
+		}, nil
+
+	case ast.Matrix != nil:
+		m := ast.Matrix
+		mode := query.Exact
+		if strings.EqualFold(m.Mode, "MONTECARLO") {
+			mode = query.MonteCarlo
+		}
+		return query.MatrixReachabilityQuery{
+			Sources: convertNodeSet(m.From),
+			Targets: convertNodeSet(m.To),
+			Mode:    mode,
+		}, nil
+
+	case ast.Confidence != nil:
+		c := ast.Confidence
+		seed := rand.Uint64()
+		if c.Seed != nil {
+			seed = *c.Seed
+		}
+		return query.ConfidenceQuery{
+			Start: graph.NodeID(c.From),
+			End:   graph.NodeID(c.To),
+			Width: c.Width,
+			Seed:  seed,
 		}, nil
 
 	case ast.Sensitivity != nil:
 		s := ast.Sensitivity
+		if s.MapKw != "" {
+			if !strings.EqualFold(s.MapKw, "MAP") {
+				return nil, SyntaxError{
+					Kind:    "InvalidSyntax",
+					Message: fmt.Sprintf("expected MAP or FROM after SENSITIVITY, got %q", s.MapKw),
+				}
+			}
+			if strings.EqualFold(s.Mode, "MONTECARLO") {
+				return nil, SyntaxError{
+					Kind:    "InvalidSyntax",
+					Message: "SENSITIVITY MAP only supports EXACT inference",
+				}
+			}
+			return query.SensitivityMapQuery{
+				Start: graph.NodeID(s.From),
+				End:   graph.NodeID(s.To),
+			}, nil
+		}
 		mode := query.Exact
 		if strings.EqualFold(s.Mode, "MONTECARLO") {
 			mode = query.MonteCarlo
@@ -165,6 +568,95 @@ func convertQuery(ast *QueryAST, g graph.ProbabilisticGraphModel) (query.Query,
 			Mode:  mode,
 		}, nil
 
+	case ast.Reliability != nil:
+		return query.ReliabilityPolynomialQuery{
+			Start: graph.NodeID(ast.Reliability.From),
+			End:   graph.NodeID(ast.Reliability.To),
+		}, nil
+
+	case ast.RandomWalk != nil:
+		w := ast.RandomWalk
+		seed := rand.Uint64()
+		if w.Seed != nil {
+			seed = *w.Seed
+		}
+		return query.RandomWalkQuery{
+			Start: graph.NodeID(w.From),
+			Steps: w.Steps,
+			Seed:  seed,
+		}, nil
+
+	case ast.Sample != nil:
+		seed := rand.Uint64()
+		if ast.Sample.Seed != nil {
+			seed = *ast.Sample.Seed
+		}
+		return query.SampleWorldQuery{Seed: seed}, nil
+
+	case ast.Find != nil:
+		return convertFind(ast.Find)
+
+	case ast.Histogram:
+		return query.EdgeHistogramQuery{}, nil
+
+	case ast.ExportJSON:
+		return query.ExportJSONQuery{}, nil
+
+	case ast.Centrality:
+		return query.BetweennessCentralityQuery{}, nil
+
+	case ast.SourceNodes:
+		return query.SourceNodesQuery{}, nil
+
+	case ast.SinkNodes:
+		return query.SinkNodesQuery{}, nil
+
+	case ast.RowSum != nil:
+		return query.RowSumQuery{Node: graph.NodeID(*ast.RowSum)}, nil
+
+	case ast.Validate != nil:
+		v := ast.Validate
+		if !strings.EqualFold(v.Check, "ROWSUMS") {
+			return nil, SyntaxError{
+				Kind:    "InvalidSyntax",
+				Message: fmt.Sprintf("expected ROWSUMS after VALIDATE, got %q", v.Check),
+			}
+		}
+		q := query.ValidateRowSumsQuery{Epsilon: inference.DefaultRowSumEpsilon}
+		if v.EpsilonKw != "" {
+			if !strings.EqualFold(v.EpsilonKw, "EPSILON") {
+				return nil, SyntaxError{
+					Kind:    "InvalidSyntax",
+					Message: fmt.Sprintf("expected EPSILON <e> after ROWSUMS, got %q", v.EpsilonKw),
+				}
+			}
+			q.Epsilon = *v.Epsilon
+		}
+		return q, nil
+
+	case ast.PageRank != nil:
+		q := query.PageRankQuery{
+			Damping:    inference.DefaultPageRankDamping,
+			Iterations: inference.DefaultPageRankIterations,
+		}
+		if ast.PageRank.Damping != nil {
+			q.Damping = *ast.PageRank.Damping
+		}
+		if ast.PageRank.Iterations != nil {
+			q.Iterations = *ast.PageRank.Iterations
+		}
+		return q, nil
+
+	case ast.Subgraph != nil:
+		if ast.Subgraph.AncestorsOf != nil {
+			return query.SubgraphQuery{AncestorsOf: graph.NodeID(*ast.Subgraph.AncestorsOf)}, nil
+		}
+		nodes := make([]graph.NodeID, len(ast.Subgraph.Nodes))
+		for i, id := range ast.Subgraph.Nodes {
+			nodes[i] = graph.NodeID(id)
+		}
+		return query.SubgraphQuery{Nodes: nodes}, nil
+
 	case ast.Multi != nil:
 		queries, err := convertComposite(ast.Multi, g)
 		if err != nil {
@@ -186,11 +678,184 @@ func convertQuery(ast *QueryAST, g graph.ProbabilisticGraphModel) (query.Query,
 		}
 		return query.OrQuery{Queries: queries}, nil
 
+	case ast.Xor != nil:
+		queries, err := convertComposite(ast.Xor, g)
+		if err != nil {
+			return nil, err
+		}
+		return query.XorQuery{Queries: queries}, nil
+
+	case ast.Not != nil:
+		inner, err := convertQuery(ast.Not.Query, g)
+		if err != nil {
+			return nil, err
+		}
+		return query.NotQuery{Inner: inner}, nil
+
+	case ast.ForEach != nil:
+		return convertForEach(ast.ForEach, g)
+
+	case ast.Concat != nil:
+		first, err := convertQuery(ast.Concat.First, g)
+		if err != nil {
+			return nil, err
+		}
+		second, err := convertQuery(ast.Concat.Second, g)
+		if err != nil {
+			return nil, err
+		}
+		return query.ConcatPathQuery{First: first, Second: second}, nil
+
 	default:
 		return nil, SyntaxError{Kind: "InvalidQuery", Message: fmt.Sprintf("unknown query AST: %+v", ast)}
 	}
 }
 
+func convertFind(ast *FindAST) (query.Query, error) {
+	f := ast.Nodes
+	if f == nil {
+		f = ast.Edges
+	}
+
+	op, err := convertFilterOp(f.Op)
+	if err != nil {
+		return nil, err
+	}
+	value, err := convertPropValue(f.Value)
+	if err != nil {
+		return nil, err
+	}
+	key, path, err := convertPropertyPath(f)
+	if err != nil {
+		return nil, err
+	}
+	re, err := convertPattern(op, f.Op, value)
+	if err != nil {
+		return nil, err
+	}
+
+	limit, offset, err := convertPage(ast.Page)
+	if err != nil {
+		return nil, err
+	}
+
+	if ast.Edges != nil {
+		return query.FindEdgesQuery{Key: key, Path: path, Op: op, Value: value, Pattern: re, Limit: limit, Offset: offset}, nil
+	}
+	return query.FindNodesQuery{Key: key, Path: path, Op: op, Value: value, Pattern: re, Limit: limit, Offset: offset}, nil
+}
+
+// convertPattern checks that CONTAINS and MATCHES are given a string, and
+// compiles the regular expression of MATCHES so that an invalid one fails
+// here rather than when the query runs.
+func convertPattern(op query.FilterOp, name string, value graph.Value) (query.Regexp, error) {
+	if op != query.OpContains && op != query.OpMatches {
+		return query.Regexp{}, nil
+	}
+	if value.Kind != graph.StringVal {
+		return query.Regexp{}, SyntaxError{
+			Kind:    "InvalidSyntax",
+			Message: fmt.Sprintf("%s needs a string, got %s", strings.ToUpper(name), value),
+		}
+	}
+	if op == query.OpContains {
+		return query.Regexp{}, nil
+	}
+	re, err := query.CompilePattern(value.S)
+	if err != nil {
+		return query.Regexp{}, SyntaxError{
+			Kind:    "InvalidPattern",
+			Message: fmt.Sprintf("invalid MATCHES pattern %q: %v", value.S, err),
+		}
+	}
+	return re, nil
+}
+
+// convertPage reads FIND's LIMIT and OFFSET clauses, each allowed once in
+// either order. A missing clause is returned as 0.
+func convertPage(clauses []*PageClauseAST) (limit, offset int, err error) {
+	seen := make(map[string]bool)
+	for _, c := range clauses {
+		kw := strings.ToUpper(c.Keyword)
+		if kw != "LIMIT" && kw != "OFFSET" {
+			return 0, 0, SyntaxError{
+				Kind:    "InvalidSyntax",
+				Message: fmt.Sprintf("expected LIMIT or OFFSET after the filter, got %q", c.Keyword),
+			}
+		}
+		if seen[kw] {
+			return 0, 0, SyntaxError{
+				Kind:    "InvalidSyntax",
+				Message: fmt.Sprintf("%s may only be given once", kw),
+			}
+		}
+		seen[kw] = true
+
+		if kw == "OFFSET" {
+			offset = c.N
+			continue
+		}
+		if c.N <= 0 {
+			return 0, 0, SyntaxError{
+				Kind:    "InvalidLimit",
+				Message: fmt.Sprintf("LIMIT must be positive, got %d", c.N),
+			}
+		}
+		limit = c.N
+	}
+	return limit, offset, nil
+}
+
+// convertPropertyPath splits a filter's left-hand side into the property key
+// and the segments below it. props["k"] is an alias for k, so a filter can
+// name properties that are not valid identifiers.
+func convertPropertyPath(f *FilterExprAST) (string, []string, error) {
+	segs := make([]string, 0, len(f.Path)+1)
+	if f.Key != "props" || len(f.Path) == 0 || f.Path[0].Key == nil {
+		segs = append(segs, f.Key)
+	}
+	for _, p := range f.Path {
+		if p.Key != nil {
+			segs = append(segs, strings.Trim(*p.Key, "\""))
+		} else {
+			segs = append(segs, strconv.Itoa(*p.Index))
+		}
+	}
+
+	if len(segs) > query.MaxPropertyPathDepth {
+		return "", nil, SyntaxError{
+			Kind:    "PathTooDeep",
+			Message: fmt.Sprintf("property path %s has %d levels; at most %d are supported", strings.Join(segs, "."), len(segs), query.MaxPropertyPathDepth),
+		}
+	}
+	return segs[0], segs[1:], nil
+}
+
+func convertFilterOp(op string) (query.FilterOp, error) {
+	switch op {
+	case "=":
+		return query.OpEq, nil
+	case "!=":
+		return query.OpNeq, nil
+	case ">":
+		return query.OpGt, nil
+	case "<":
+		return query.OpLt, nil
+	case ">=":
+		return query.OpGte, nil
+	case "<=":
+		return query.OpLte, nil
+	}
+	switch strings.ToUpper(op) {
+	case "CONTAINS":
+		return query.OpContains, nil
+	case "MATCHES":
+		return query.OpMatches, nil
+	default:
+		return 0, SyntaxError{Kind: "InvalidOperator", Message: fmt.Sprintf("unknown filter operator %q", op)}
+	}
+}
+
 func convertComposite(ast *CompositeAST, g graph.ProbabilisticGraphModel) ([]query.Query, error) {
 	queries := make([]query.Query, len(ast.Queries))
 	for i, q := range ast.Queries {
@@ -225,6 +890,7 @@ func convertCondition(items []*ConditionItemAST, g graph.ProbabilisticGraphModel
 	var forcedInaActiveEdges []*graph.Edge
 	var forcedActiveNodes []graph.NodeID
 	var forcedInactiveNodes []graph.NodeID
+	var edgeProbabilities map[graph.EdgeID]float64
 
 	for _, item := range items {
 		switch {
@@ -236,14 +902,20 @@ func convertCondition(items []*ConditionItemAST, g graph.ProbabilisticGraphModel
 				return graph.Condition{}, err
 			}
 
-			if item.Edge.State == "ACTIVE" {
+			switch {
+			case item.Edge.Prob != nil:
+				if edgeProbabilities == nil {
+					edgeProbabilities = make(map[graph.EdgeID]float64)
+				}
+				edgeProbabilities[edgeID] = *item.Edge.Prob
+			case strings.EqualFold(item.Edge.State, "ACTIVE"):
 				forcedActiveEdges = append(forcedActiveEdges, edge)
-			} else {
+			default:
 				forcedInaActiveEdges = append(forcedInaActiveEdges, edge)
 			}
 		case item.Node != nil:
 			nodeID := graph.NodeID(item.Node.NodeID)
-			if item.Node.State == "ACTIVE" {
+			if strings.EqualFold(item.Node.State, "ACTIVE") {
 				forcedActiveNodes = append(forcedActiveNodes, nodeID)
 			} else {
 				forcedInactiveNodes = append(forcedInactiveNodes, nodeID)
@@ -261,6 +933,7 @@ func convertCondition(items []*ConditionItemAST, g graph.ProbabilisticGraphModel
 		ForcedInactiveEdges: forcedInaActiveEdges,
 		ForcedActiveNodes:   forcedActiveNodes,
 		ForcedInactiveNodes: forcedInactiveNodes,
+		EdgeProbabilities:   edgeProbabilities,
 	}, nil
 }
 
@@ -276,6 +949,23 @@ func convertThreshold(ast *ThresholdAST, g graph.ProbabilisticGraphModel) (query
 	}, nil
 }
 
+func convertAssert(ast *AssertAST, g graph.ProbabilisticGraphModel) (query.Query, error) {
+	inner, err := convertQuery(ast.Query, g)
+	if err != nil {
+		return nil, err
+	}
+	op, err := convertFilterOp(ast.Op)
+	if err != nil {
+		return nil, err
+	}
+
+	return query.AssertQuery{
+		Inner: inner,
+		Op:    op,
+		Value: ast.Value,
+	}, nil
+}
+
 func convertAggregate(ast *AggregateAST, g graph.ProbabilisticGraphModel) (query.Query, error) {
 	queries := make([]query.Query, len(ast.Queries))
 	for i, q := range ast.Queries {
@@ -292,8 +982,9 @@ func convertAggregate(ast *AggregateAST, g graph.ProbabilisticGraphModel) (query
 	}
 
 	return query.AggregateQuery{
-		Queries: queries,
-		Reducer: reducer,
+		Queries:      queries,
+		Reducer:      reducer,
+		ShortCircuit: ast.ShortCircuit,
 	}, nil
 }
 
@@ -301,6 +992,10 @@ func convertReducer(ast *ReducerAST) (query.Reducer, error) {
 	switch {
 	case ast.Mean:
 		return query.MeanProbabilityReducer{}, nil
+	case ast.GeoMean:
+		return query.GeometricMeanProbabilityReducer{}, nil
+	case ast.Product:
+		return query.ProductProbabilityReducer{}, nil
 	case ast.Max:
 		return query.MaxProbabilityReducer{}, nil
 	case ast.Min:
@@ -309,7 +1004,80 @@ func convertReducer(ast *ReducerAST) (query.Reducer, error) {
 		return query.BestPathReducer{}, nil
 	case ast.CountAbove != nil:
 		return query.CountAboveThresholdReducer{Threshold: *ast.CountAbove}, nil
+	case ast.Script != nil:
+		script, err := strconv.Unquote(*ast.Script)
+		if err != nil {
+			return nil, SyntaxError{
+				Kind:    "InvalidScript",
+				Message: fmt.Sprintf("invalid string literal %s: %v", *ast.Script, err),
+			}
+		}
+		r := scripting.LuaReducer{Script: script}
+		if err := r.Check(); err != nil {
+			return nil, SyntaxError{Kind: "InvalidScript", Message: err.Error()}
+		}
+		return r, nil
 	default:
 		return nil, SyntaxError{Kind: "InvalidReducer", Message: "unknown reducer"}
 	}
 }
+
+func convertForEach(ast *ForEachAST, g graph.ProbabilisticGraphModel) (query.Query, error) {
+	// Bind the variable to the source node once up front, so that a body
+	// that cannot be converted fails now rather than once per neighbour.
+	if _, err := bindForEachBody(ast.Body, ast.Variable, ast.Source, g); err != nil {
+		return nil, err
+	}
+
+	return query.ForEachQuery{
+		SourceNode: graph.NodeID(ast.Source),
+		Variable:   ast.Variable,
+		Body: func(n graph.NodeID) (query.Query, error) {
+			return bindForEachBody(ast.Body, ast.Variable, string(n), g)
+		},
+	}, nil
+}
+
+// bindForEachBody re-parses body with every identifier equal to variable
+// replaced by value. The substitution is on tokens rather than text, so
+// value need not itself be a valid identifier.
+func bindForEachBody(body *ForEachBodyAST, variable, value string, g graph.ProbabilisticGraphModel) (query.Query, error) {
+	symbols := dslLexer.Symbols()
+	ident := symbols["Ident"]
+
+	tokens := make([]lexer.Token, 0, len(body.Tokens)+1)
+	for _, t := range body.Tokens {
+		if t.Type == ident && t.Value == variable {
+			t.Value = value
+		}
+		tokens = append(tokens, t)
+	}
+	var end lexer.Position
+	if n := len(tokens); n > 0 {
+		end = tokens[n-1].Pos
+	}
+	tokens = append(tokens, lexer.EOFToken(end))
+
+	lex, err := lexer.Upgrade(&tokenLexer{tokens: tokens}, symbols["Whitespace"])
+	if err != nil {
+		return nil, err
+	}
+	ast, err := dslQueryParser.ParseFromLexer(lex)
+	if err != nil {
+		return nil, withPosition(SyntaxError{Kind: "InvalidSyntax", Message: cleanParticipeError(err.Error())}, err)
+	}
+	return convertQuery(ast, g)
+}
+
+// tokenLexer replays a fixed token slice ending in EOF.
+type tokenLexer struct {
+	tokens []lexer.Token
+}
+
+func (l *tokenLexer) Next() (lexer.Token, error) {
+	t := l.tokens[0]
+	if !t.EOF() {
+		l.tokens = l.tokens[1:]
+	}
+	return t, nil
+}
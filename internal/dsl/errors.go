@@ -1,14 +1,25 @@
 package dsl
 
 import (
+	"errors"
 	"fmt"
 	"strings"
+
+	"github.com/alecthomas/participle/v2"
 )
 
 // SyntaxError is returned when the DSL input cannot be parsed.
 type SyntaxError struct {
 	Kind    string
 	Message string
+
+	// Line and Column locate the error in the input, both 1-based, with
+	// Column counted in characters. They are zero when the error has no
+	// position, e.g. a value out of range. OffendingToken is the text of
+	// the unexpected token, or empty at the end of the input.
+	Line           int
+	Column         int
+	OffendingToken string
 }
 
 func (e SyntaxError) Error() string {
@@ -22,11 +33,15 @@ type commandSyntax struct {
 
 var commandHelp = map[string]commandSyntax{
 	"create node": {
-		usage:   "CREATE NODE <id> [, <id>]* [{ key: value, ... }]",
-		example: "CREATE NODE nodeA  OR  CREATE NODE a, b, c",
+		usage:   "CREATE NODE <id> [, <id>]* [GROUP <group>] [{ key: value, ... }]",
+		example: "CREATE NODE nodeA  OR  CREATE NODE a, b, c GROUP us",
+	},
+	"create group": {
+		usage:   "CREATE GROUP <name> [{ key: value, ... }]",
+		example: `CREATE GROUP us { currency: "USD" }`,
 	},
 	"create edge": {
-		usage:   "CREATE EDGE <id> FROM <from> TO <to> PROB <probability>",
+		usage:   "CREATE EDGE <id> FROM <from> TO <to> [PROB <probability> | LOGPROB <log-probability>] [BIDIRECTIONAL]",
 		example: "CREATE EDGE e1 FROM nodeA TO nodeB PROB 0.9",
 	},
 	"delete node": {
@@ -37,18 +52,118 @@ var commandHelp = map[string]commandSyntax{
 		usage:   "DELETE EDGE <id>  OR  DELETE EDGE FROM <from> TO <to>",
 		example: "DELETE EDGE e1   OR   DELETE EDGE FROM nodeA TO nodeB",
 	},
+	"update edge": {
+		usage:   "UPDATE EDGE <id> PROB <probability>",
+		example: "UPDATE EDGE e1 PROB 0.75",
+	},
 	"maxpath": {
-		usage:   "MAXPATH FROM <from> TO <to>",
+		usage:   "MAXPATH FROM <from> TO <to> [WEIGHT <property> [BOTTLENECK]]",
 		example: "MAXPATH FROM nodeA TO nodeB",
 	},
+	"connected": {
+		usage:   "CONNECTED FROM <from> TO <to>",
+		example: "CONNECTED FROM nodeA TO nodeB",
+	},
+	"pathprob": {
+		usage:   "PATHPROB <id> -> <id> [-> <id>]*",
+		example: "PATHPROB nodeA -> nodeB -> nodeC",
+	},
+	"unreachable": {
+		usage:   "UNREACHABLE FROM <root>",
+		example: "UNREACHABLE FROM supplier",
+	},
+	"allpaths": {
+		usage:   "ALLPATHS FROM <from> TO <to> [MAX <n>] [MAXLEN <n>] | ALLPATHS FROM <from> TO <to> STAT <MEAN|MAX|MIN|COUNT|VARIANCE> [PROB|HOPS]",
+		example: "ALLPATHS FROM nodeA TO nodeB MAX 100 MAXLEN 6",
+	},
+	"countpaths": {
+		usage:   "COUNTPATHS FROM <from> TO <to> [MAXLEN <n>]",
+		example: "COUNTPATHS FROM nodeA TO nodeB MAXLEN 5",
+	},
+	"expectedhops": {
+		usage:   "EXPECTEDHOPS FROM <from> TO <to>",
+		example: "EXPECTEDHOPS FROM nodeA TO nodeB",
+	},
 	"topk": {
-		usage:   "TOPK FROM <from> TO <to> K <n>",
+		usage:   "TOPK FROM <from> TO <to> K <n> [MIN_PROB <p>] [MAXHOPS <n>] [TIMEOUT <duration>]",
 		example: "TOPK FROM nodeA TO nodeB K 3",
 	},
+	"topk_probs": {
+		usage:   "TOPK_PROBS FROM <from> TO <to> K <n>",
+		example: "TOPK_PROBS FROM nodeA TO nodeB K 5",
+	},
 	"reachability": {
-		usage:   "REACHABILITY FROM <from> TO <to> [EXACT | MONTECARLO]",
+		usage:   "REACHABILITY FROM <from> TO <to> [EXACT | MONTECARLO | BOTH] [TIMEOUT <duration>]; <from> and <to> may be node sets {<a>, <b>, ...}",
 		example: "REACHABILITY FROM nodeA TO nodeB EXACT",
 	},
+	"matrix": {
+		usage:   "MATRIX REACHABILITY FROM <GROUP g | {a, b, ...}> TO <GROUP g | {a, b, ...}> [EXACT|MONTECARLO]",
+		example: "MATRIX REACHABILITY FROM GROUP suppliers TO {store1, store2} EXACT",
+	},
+	"confidence": {
+		usage:   "CONFIDENCE FROM <from> TO <to> WIDTH <float> [SEED <s>]",
+		example: "CONFIDENCE FROM nodeA TO nodeB WIDTH 0.02",
+	},
+	"reliability": {
+		usage:   "RELIABILITY POLYNOMIAL FROM <from> TO <to>",
+		example: "RELIABILITY POLYNOMIAL FROM nodeA TO nodeB",
+	},
+	"randomwalk": {
+		usage:   "RANDOMWALK FROM <from> STEPS <n> [SEED <s>]",
+		example: "RANDOMWALK FROM nodeA STEPS 10 SEED 42",
+	},
+	"find nodes": {
+		usage:   "FIND NODES WHERE <key> [= | != | > | < | >= | <= | CONTAINS | MATCHES] <value> [LIMIT <n>] [OFFSET <m>]",
+		example: `FIND NODES WHERE region = "US"`,
+	},
+	"find edges": {
+		usage:   "FIND EDGES WHERE <key> [= | != | > | < | >= | <= | CONTAINS | MATCHES] <value> [LIMIT <n>] [OFFSET <m>]",
+		example: `FIND EDGES WHERE mode = "rail"`,
+	},
+	"subgraph": {
+		usage:   "SUBGRAPH INDUCED BY <id> [, <id>]*  OR  SUBGRAPH INDUCED BY ANCESTORS OF <id>",
+		example: "SUBGRAPH INDUCED BY ANCESTORS OF nodeD",
+	},
+	"histogram": {
+		usage:   "HISTOGRAM EDGES",
+		example: "HISTOGRAM EDGES",
+	},
+	"import": {
+		usage:   "IMPORT JSON \"<graph JSON with escaped quotes, or its base64 encoding>\"",
+		example: `IMPORT JSON "{\"nodes\": [{\"id\": \"a\"}], \"edges\": []}"`,
+	},
+	"export": {
+		usage:   "EXPORT JSON",
+		example: "EXPORT JSON",
+	},
+	"centrality": {
+		usage:   "CENTRALITY BETWEENNESS",
+		example: "CENTRALITY BETWEENNESS",
+	},
+	"source nodes": {
+		usage:   "SOURCE NODES",
+		example: "SOURCE NODES",
+	},
+	"sink nodes": {
+		usage:   "SINK NODES",
+		example: "SINK NODES",
+	},
+	"rowsum": {
+		usage:   "ROWSUM <node>",
+		example: "ROWSUM a",
+	},
+	"validate": {
+		usage:   "VALIDATE ROWSUMS [EPSILON <e>]",
+		example: "VALIDATE ROWSUMS EPSILON 0.001",
+	},
+	"pagerank": {
+		usage:   "PAGERANK [DAMPING <float>] [ITERATIONS <n>]",
+		example: "PAGERANK DAMPING 0.85 ITERATIONS 50",
+	},
+	"sample": {
+		usage:   "SAMPLE [SEED <n>]",
+		example: "SAMPLE SEED 42",
+	},
 	"multi": {
 		usage:   "MULTI ( <query>, <query>, ... )",
 		example: "MULTI ( MAXPATH FROM a TO b, REACHABILITY FROM c TO d EXACT )",
@@ -61,16 +176,36 @@ var commandHelp = map[string]commandSyntax{
 		usage:   "OR ( <query>, <query>, ... )",
 		example: "OR ( REACHABILITY FROM a TO b EXACT, REACHABILITY FROM c TO d EXACT )",
 	},
+	"xor": {
+		usage:   "XOR ( <query>, <query>, ... )",
+		example: "XOR ( REACHABILITY FROM a TO b EXACT, REACHABILITY FROM c TO d EXACT )",
+	},
+	"not": {
+		usage:   "NOT ( <query> )",
+		example: "NOT ( REACHABILITY FROM a TO b EXACT )",
+	},
+	"concat": {
+		usage:   "CONCAT ( <path query>, <path query> )",
+		example: "CONCAT ( MAXPATH FROM a TO b, MAXPATH FROM b TO c )",
+	},
 	"conditional": {
-		usage:   "CONDITIONAL GIVEN [EDGE|NODE] <id> [ACTIVE|INACTIVE] [, ...]* ( <query> )",
+		usage:   "CONDITIONAL GIVEN [EDGE|NODE] <id> [ACTIVE|INACTIVE|PROB <p>] [, ...]* ( <query> )",
 		example: "CONDITIONAL GIVEN EDGE e1 INACTIVE ( REACHABILITY FROM a TO b EXACT )",
 	},
 	"threshold": {
 		usage:   "THRESHOLD <probability> ( <query> )",
 		example: "THRESHOLD 0.9 ( REACHABILITY FROM a TO b EXACT )",
 	},
+	"assert": {
+		usage:   "ASSERT <query> <op> <value>",
+		example: "ASSERT REACHABILITY FROM a TO b EXACT >= 0.8",
+	},
+	"foreach": {
+		usage:   "FOREACH <var> IN NEIGHBORS OF <node> DO <query>",
+		example: "FOREACH n IN NEIGHBORS OF a DO REACHABILITY FROM n TO d EXACT",
+	},
 	"aggregate": {
-		usage:   "AGGREGATE [MEAN|MAX|MIN|BESTPATH|COUNTABOVE <float>] ( <query>, ... )",
+		usage:   "AGGREGATE [MEAN|GEOMEAN|PRODUCT|MAX|MIN|BESTPATH|COUNTABOVE <float>|SCRIPT \"<lua>\"] [SHORTCIRCUIT] ( <query>, ... )",
 		example: "AGGREGATE MEAN ( REACHABILITY FROM a TO b EXACT, REACHABILITY FROM c TO d EXACT )",
 	},
 }
@@ -121,7 +256,22 @@ func enrichSyntaxError(input string, parseErr error) SyntaxError {
 		fmt.Fprintf(&b, "\n  Usage:   %s", help.usage)
 		fmt.Fprintf(&b, "\n  Example: %s", help.example)
 	}
-	return SyntaxError{Kind: "InvalidSyntax", Message: b.String()}
+	return withPosition(SyntaxError{Kind: "InvalidSyntax", Message: b.String()}, parseErr)
+}
+
+// withPosition copies the position and unexpected token of a participle or
+// lexer error into se.
+func withPosition(se SyntaxError, parseErr error) SyntaxError {
+	var perr participle.Error
+	if errors.As(parseErr, &perr) {
+		pos := perr.Position()
+		se.Line, se.Column = pos.Line, pos.Column
+	}
+	var ute *participle.UnexpectedTokenError
+	if errors.As(parseErr, &ute) && !ute.Unexpected.EOF() {
+		se.OffendingToken = ute.Unexpected.Value
+	}
+	return se
 }
 
 // internalTypeNames maps participle's internal AST struct names and token type names
@@ -131,17 +281,42 @@ var internalTypeNames = []struct{ from, to string }{
 	{"CreateNodeAST", `node ID (e.g. "myNode")`},
 	{"DeleteEdgeAST", `edge ID or "FROM <from> TO <to>"`},
 	{"DeleteNodeAST", `node ID`},
+	{"UpdateEdgeAST", `edge ID (e.g. "myEdge")`},
 	{"QueryAST", `query keyword (MAXPATH, TOPK, REACHABILITY, ...)`},
-	{"StatementAST", `"CREATE" or "DELETE"`},
-	{"CreateAST", `"NODE" or "EDGE"`},
+	{"StatementAST", `"CREATE", "DELETE", "UPDATE" or "TRANSPOSE"`},
+	{"CreateGroupAST", `group name (e.g. "us")`},
+	{"CreateAST", `"NODE", "EDGE" or "GROUP"`},
 	{"DeleteAST", `"NODE" or "EDGE"`},
-	{"MaxPathAST", `FROM <from> TO <to>`},
-	{"TopKAST", `FROM <from> TO <to> K <n>`},
-	{"ReachabilityAST", `FROM <from> TO <to> [EXACT | MONTECARLO]`},
+	{"MaxPathAST", `FROM <from> TO <to> [WEIGHT <property> [BOTTLENECK]]`},
+	{"ConnectedAST", `FROM <from> TO <to>`},
+	{"UnreachableAST", `FROM <root>`},
+	{"AllPathsAST", `FROM <from> TO <to> [MAX <n>] [MAXLEN <n>] or FROM <from> TO <to> STAT <stat> [PROB|HOPS]`},
+	{"PathStatAST", `MEAN, MAX, MIN, COUNT or VARIANCE`},
+	{"CountPathsAST", `FROM <from> TO <to> [MAXLEN <n>]`},
+	{"ExpectedHopsAST", `FROM <from> TO <to>`},
+	{"PathProbAST", `<id> -> <id> [-> <id>]*`},
+	{"TopKAST", `FROM <from> TO <to> K <n> [MIN_PROB <p>] [MAXHOPS <n>] [TIMEOUT <duration>]`},
+	{"TopKProbsAST", `FROM <from> TO <to> K <n>`},
+	{"ConfidenceAST", `FROM <from> TO <to> WIDTH <float> [SEED <s>]`},
+	{"MatrixAST", `REACHABILITY FROM <set> TO <set> [EXACT | MONTECARLO]`},
+	{"NodeSetAST", `GROUP <name> or "{" <id> [, <id>]* "}"`},
+	{"ReachabilityAST", `FROM <from> TO <to> [EXACT | MONTECARLO | BOTH] [TIMEOUT <duration>]`},
 	{"CompositeAST", `"(" <query> [, <query>]* ")"`},
+	{"ConcatAST", `"(" <path query> , <path query> ")"`},
 	{"ConditionalAST", `GIVEN ... ( <query> )`},
 	{"ThresholdAST", `<probability> ( <query> )`},
-	{"AggregateAST", `<reducer> ( <query>, ... )`},
+	{"AssertAST", `<query> <op> <value>`},
+	{"NotAST", `( <query> )`},
+	{"AggregateAST", `<reducer> [SHORTCIRCUIT] ( <query>, ... )`},
+	{"ForEachBodyAST", `<query>`},
+	{"ForEachAST", `<var> IN NEIGHBORS OF <node> DO <query>`},
+	{"ReliabilityAST", `FROM <from> TO <to>`},
+	{"RandomWalkAST", `FROM <from> STEPS <n> [SEED <s>]`},
+	{"FindAST", `NODES|EDGES WHERE <key> <op> <value> [LIMIT <n>] [OFFSET <m>]`},
+	{"PageClauseAST", `LIMIT <n> or OFFSET <m>`},
+	{"FilterExprAST", `<key> <op> <value>`},
+	{"SubgraphAST", `INDUCED BY <id>, ... or INDUCED BY ANCESTORS OF <id>`},
+	{"ValidateAST", `ROWSUMS [EPSILON <e>]`},
 	{"Grammar", `a valid DSL statement or query`},
 	{"<ident>", "identifier"},
 }
@@ -177,14 +352,27 @@ func cleanParticipeError(msg string) string {
 // dslKeywords is the set of all reserved DSL keywords (uppercased).
 var dslKeywords = map[string]bool{
 	"CREATE": true, "DELETE": true, "NODE": true, "EDGE": true,
-	"FROM": true, "TO": true, "PROB": true,
+	"FROM": true, "TO": true, "PROB": true, "LOGPROB": true,
 	"MAXPATH": true, "TOPK": true, "REACHABILITY": true,
 	"EXACT": true, "MONTECARLO": true,
-	"MULTI": true, "AND": true, "OR": true,
+	"MULTI": true, "AND": true, "OR": true, "XOR": true, "NOT": true,
 	"CONDITIONAL": true, "GIVEN": true, "ACTIVE": true, "INACTIVE": true,
-	"THRESHOLD": true, "AGGREGATE": true,
-	"MEAN": true, "MAX": true, "MIN": true, "BESTPATH": true, "COUNTABOVE": true,
-	"K": true, "TRUE": true, "FALSE": true,
+	"THRESHOLD": true, "AGGREGATE": true, "ASSERT": true,
+	"MEAN": true, "GEOMEAN": true, "PRODUCT": true, "MAX": true, "MIN": true, "BESTPATH": true, "COUNTABOVE": true,
+	"FIND": true, "NODES": true, "EDGES": true, "WHERE": true,
+	"K": true, "TRUE": true, "FALSE": true, "NULL": true,
+	"BIDIRECTIONAL": true, "TRANSPOSE": true,
+	"SUBGRAPH": true, "INDUCED": true, "BY": true, "ANCESTORS": true,
+	"HISTOGRAM": true, "CENTRALITY": true, "BETWEENNESS": true,
+	"PAGERANK": true, "DAMPING": true, "ITERATIONS": true,
+	"RELIABILITY": true, "POLYNOMIAL": true,
+	"RANDOMWALK": true, "BOTH": true,
+	"CONCAT": true, "SAMPLE": true, "MIN_PROB": true, "CONNECTED": true, "PATHPROB": true,
+	"COUNTPATHS": true, "MAXLEN": true,
+	"CONFIDENCE": true, "ALLPATHS": true, "UNREACHABLE": true,
+	"SHORTCIRCUIT": true, "TIMEOUT": true, "FOREACH": true,
+	"EXPECTEDHOPS": true, "TOPK_PROBS": true,
+	"ROWSUM": true, "VALIDATE": true,
 }
 
 // specificDiagnostic returns a targeted human-readable hint for well-known mistake patterns.
@@ -239,15 +427,23 @@ func createEdgeDiagnostic(rest []string) string {
 			}
 		}
 	}
-	// Scan for a PROB clause and validate its value.
+	// Scan for a PROB or LOGPROB clause and validate its value.
 	for i, w := range rest {
-		if w == "PROB" {
+		switch w {
+		case "PROB":
 			if i+1 >= len(rest) {
 				return "probability value is required after PROB (e.g. PROB 0.9)"
 			}
 			if !strings.Contains(rest[i+1], ".") {
 				return fmt.Sprintf("probability must be a decimal number (e.g. 0.9, not %q)", rest[i+1])
 			}
+		case "LOGPROB":
+			if i+1 >= len(rest) {
+				return "log-probability value is required after LOGPROB (e.g. LOGPROB -0.105)"
+			}
+			if !strings.Contains(rest[i+1], ".") {
+				return fmt.Sprintf("log-probability must be a decimal number (e.g. -0.105, not %q)", rest[i+1])
+			}
 		}
 	}
 	return ""
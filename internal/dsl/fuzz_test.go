@@ -0,0 +1,85 @@
+package dsl
+
+import (
+	"testing"
+)
+
+// fuzzSeeds are inputs from the parser tests, covering every statement and
+// query form.
+var fuzzSeeds = []string{
+	"CREATE NODE A",
+	"CREATE NODE A, B, C",
+	`CREATE NODE supplier { region: "US", risk_score: 0.85, priority: 1, is_active: true }`,
+	`CREATE NODE A { features: [0.1, 0.2, 0.3], tags: ["hub", "port"] }`,
+	"CREATE EDGE eAB FROM A TO B PROB 0.9",
+	"CREATE EDGE eAB FROM A TO B PROB [0.7, 0.9]",
+	"CREATE EDGE eAB FROM A TO B LOGPROB -0.1",
+	"CREATE EDGE eAB FROM A TO B PROB 0.9 BIDIRECTIONAL",
+	"DELETE NODE A",
+	"DELETE EDGE FROM A TO B",
+	"DELETE EDGE eAB",
+	"TRANSPOSE",
+	"EXPORT JSON",
+	`IMPORT JSON "{\"nodes\": [{\"id\": \"X\"}], \"edges\": []}"`,
+	"MAXPATH FROM A TO D",
+	"MAXPATH FROM A TO D WEIGHT cost",
+	"TOPK FROM A TO D K 2",
+	"TOPK FROM A TO D K 5 MIN_PROB 0.5 TIMEOUT 100ms",
+	"TOPK_PROBS FROM A TO D K 2",
+	"REACHABILITY FROM A TO D EXACT",
+	"REACHABILITY FROM A TO D MONTECARLO",
+	"REACHABILITY FROM { A, B } TO D BOTH",
+	"MULTI ( REACHABILITY FROM A TO B EXACT, REACHABILITY FROM A TO C EXACT )",
+	"AND ( REACHABILITY FROM A TO B EXACT, NOT ( REACHABILITY FROM A TO C EXACT ) )",
+	"OR ( REACHABILITY FROM A TO B EXACT, REACHABILITY FROM A TO C EXACT )",
+	"XOR ( REACHABILITY FROM A TO B EXACT, REACHABILITY FROM A TO C EXACT )",
+	"THRESHOLD 0.85 ( REACHABILITY FROM A TO B EXACT )",
+	"CONDITIONAL GIVEN EDGE eAB INACTIVE, EDGE eCD PROB 0.25 ( REACHABILITY FROM A TO D EXACT )",
+	"CONDITIONAL GIVEN NODE B INACTIVE ( REACHABILITY FROM A TO D EXACT )",
+	"AGGREGATE MEAN ( REACHABILITY FROM A TO B EXACT, REACHABILITY FROM A TO C EXACT )",
+	"AGGREGATE PRODUCT ( REACHABILITY FROM A TO B EXACT, REACHABILITY FROM A TO C EXACT )",
+	"AGGREGATE BESTPATH ( MAXPATH FROM A TO D, MAXPATH FROM A TO C )",
+	"SUBGRAPH INDUCED BY A, B",
+	"SUBGRAPH INDUCED BY ANCESTORS OF C",
+	"HISTOGRAM EDGES",
+	"CENTRALITY BETWEENNESS",
+	"PAGERANK DAMPING 0.5 ITERATIONS 20",
+	"RELIABILITY POLYNOMIAL FROM A TO D",
+	"RANDOMWALK FROM A STEPS 5 SEED 42",
+	"UNREACHABLE FROM A",
+	"SOURCE NODES",
+	"SINK NODES",
+	"CONNECTED FROM A TO D",
+	"PATHPROB A -> B -> D",
+	"CONFIDENCE FROM A TO D WIDTH 0.1 SEED 1",
+	"ALLPATHS FROM A TO D MAX 5 MAXLEN 2",
+	"COUNTPATHS FROM A TO D MAXLEN 2",
+	"EXPECTEDHOPS FROM A TO D",
+	"SAMPLE SEED 42",
+	"CONCAT ( MAXPATH FROM A TO B, MAXPATH FROM B TO D )",
+	"SENSITIVITY FROM A TO D EXACT",
+	"SENSITIVITY MAP FROM A TO D",
+	`FIND NODES WHERE region = "US" LIMIT 1 OFFSET 1`,
+	`FIND NODES WHERE props["tags"][0] = "port"`,
+	`FIND EDGES WHERE mode MATCHES "^r"`,
+	"FOREACH n IN NEIGHBORS OF A DO REACHABILITY FROM n TO D EXACT",
+}
+
+// FuzzDSLParser checks that parsing and converting any input returns an
+// error rather than panicking. Queries are converted but not executed, since
+// valid ones may legitimately run for a long time.
+func FuzzDSLParser(f *testing.F) {
+	for _, seed := range fuzzSeeds {
+		f.Add(seed)
+	}
+	g := buildTestGraph(f)
+
+	f.Fuzz(func(t *testing.T, input string) {
+		ast, err := dslParser.ParseString("", input)
+		if err != nil {
+			_ = enrichSyntaxError(input, err)
+			return
+		}
+		_, _ = convertGrammar(ast, g.Clone())
+	})
+}
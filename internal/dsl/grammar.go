@@ -5,13 +5,20 @@ import (
 	"github.com/alecthomas/participle/v2/lexer"
 )
 
+// dslLexer lexes reserved words as Keyword tokens. Modifier words that only
+// act as keywords in one place, such as SOURCE in SOURCE NODES, are left to
+// lex as Ident, so that they stay usable as names: the parsers match Ident
+// tokens against grammar literals case-insensitively.
 var dslLexer = lexer.MustSimple([]lexer.SimpleRule{
-	{Name: "Keyword", Pattern: `(?i)\b(CREATE|DELETE|NODE|EDGE|FROM|TO|PROB|MAXPATH|TOPK|REACHABILITY|SENSITIVITY|EXACT|MONTECARLO|MULTI|AND|OR|CONDITIONAL|GIVEN|ACTIVE|INACTIVE|THRESHOLD|AGGREGATE|MEAN|MAX|MIN|BESTPATH|COUNTABOVE|K|TRUE|FALSE)\b`},
+	{Name: "Keyword", Pattern: `(?i)\b(CREATE|DELETE|NODE|EDGE|FROM|TO|PROB|LOGPROB|MAXPATH|TOPK|REACHABILITY|SENSITIVITY|EXACT|MONTECARLO|MULTI|AND|OR|XOR|NOT|CONDITIONAL|GIVEN|ACTIVE|INACTIVE|THRESHOLD|AGGREGATE|MEAN|GEOMEAN|PRODUCT|MAX|MIN|BESTPATH|COUNTABOVE|FIND|NODES|EDGES|WHERE|K|TRUE|FALSE|NULL|BIDIRECTIONAL|TRANSPOSE|SUBGRAPH|INDUCED|BY|ANCESTORS|HISTOGRAM|CENTRALITY|BETWEENNESS|PAGERANK|DAMPING|ITERATIONS|RELIABILITY|POLYNOMIAL|RANDOMWALK|BOTH|CONCAT|SAMPLE|MIN_PROB|CONNECTED|PATHPROB|COUNTPATHS|MAXLEN|CONFIDENCE|ALLPATHS|UNREACHABLE|SHORTCIRCUIT|TIMEOUT|FOREACH|EXPECTEDHOPS|TOPK_PROBS|ASSERT|ROWSUM|VALIDATE)\b`},
+	{Name: "Duration", Pattern: `\d+(ms|s|m)\b`},
 	{Name: "Float", Pattern: `\d+\.\d+`},
 	{Name: "Int", Pattern: `\d+`},
 	{Name: "String", Pattern: `"([^"\\]|\\.)*"`},
 	{Name: "Ident", Pattern: `[a-zA-Z_][a-zA-Z0-9_]*`},
-	{Name: "Punct", Pattern: `[(),{}:]`},
+	{Name: "Arrow", Pattern: `->`},
+	{Name: "Operator", Pattern: `!=|>=|<=|=|>|<`},
+	{Name: "Punct", Pattern: `[(),{}:\[\]-]`},
 	{Name: "Whitespace", Pattern: `\s+`},
 })
 
@@ -21,31 +28,59 @@ type Grammar struct {
 	Query     *QueryAST     `parser:"| @@"`
 }
 
-// StatementAST dispatches on CREATE or DELETE.
+// StatementAST dispatches on CREATE, DELETE, UPDATE, TRANSPOSE or IMPORT.
 type StatementAST struct {
-	Create *CreateAST `parser:"\"CREATE\" @@"`
-	Delete *DeleteAST `parser:"| \"DELETE\" @@"`
+	Create     *CreateAST     `parser:"  \"CREATE\" @@"`
+	Delete     *DeleteAST     `parser:"| \"DELETE\" @@"`
+	Update     *UpdateEdgeAST `parser:"| \"UPDATE\" \"EDGE\" @@"`
+	Transpose  bool           `parser:"| @\"TRANSPOSE\""`
+	ImportJSON *string        `parser:"| \"IMPORT\" \"JSON\" @String"`
 }
 
-// CreateAST dispatches on NODE or EDGE.
+// CreateAST dispatches on NODE, EDGE or GROUP.
 type CreateAST struct {
-	Node *CreateNodeAST `parser:"\"NODE\" @@"`
-	Edge *CreateEdgeAST `parser:"| \"EDGE\" @@"`
+	Node  *CreateNodeAST  `parser:"  \"NODE\" @@"`
+	Edge  *CreateEdgeAST  `parser:"| \"EDGE\" @@"`
+	Group *CreateGroupAST `parser:"| \"GROUP\" @@"`
 }
 
-// CreateNodeAST: comma-separated list of identifiers, with optional properties.
+// CreateNodeAST: comma-separated list of identifiers, with an optional group
+// and optional properties.
 type CreateNodeAST struct {
 	IDs   []string   `parser:"@Ident ( \",\" @Ident )*"`
+	Group *string    `parser:"( \"GROUP\" @Ident )?"`
 	Props []*PropAST `parser:"( \"{\" @@ ( \",\" @@ )* \"}\" )?"`
 }
 
-// CreateEdgeAST: <id> FROM <a> TO <b> PROB <p>, with optional properties.
+// CreateGroupAST: <name> with optional default properties.
+type CreateGroupAST struct {
+	Name  string     `parser:"@Ident"`
+	Props []*PropAST `parser:"( \"{\" @@ ( \",\" @@ )* \"}\" )?"`
+}
+
+// CreateEdgeAST: <id> FROM <a> TO <b> PROB <p>|PROB [<lo>, <hi>]|LOGPROB <log p> [BIDIRECTIONAL], with optional properties.
 type CreateEdgeAST struct {
-	EdgeID string     `parser:"@Ident"`
-	From   string     `parser:"\"FROM\" @Ident"`
-	To     string     `parser:"\"TO\" @Ident"`
-	Prob   float64    `parser:"\"PROB\" @Float"`
-	Props  []*PropAST `parser:"( \"{\" @@ ( \",\" @@ )* \"}\" )?"`
+	EdgeID        string           `parser:"@Ident"`
+	From          string           `parser:"\"FROM\" @Ident"`
+	To            string           `parser:"\"TO\" @Ident"`
+	ProbNegative  bool             `parser:"( \"PROB\" ( @\"-\"?"`
+	Prob          *float64         `parser:"@Float"`
+	ProbInterval  *ProbIntervalAST `parser:"| @@ )"`
+	LogProb       *LogProbAST      `parser:"| \"LOGPROB\" @@ )"`
+	Bidirectional bool             `parser:"@\"BIDIRECTIONAL\"?"`
+	Props         []*PropAST       `parser:"( \"{\" @@ ( \",\" @@ )* \"}\" )?"`
+}
+
+// ProbIntervalAST: [ <lo> , <hi> ]
+type ProbIntervalAST struct {
+	Low  float64 `parser:"\"[\" @Float"`
+	High float64 `parser:"\",\" @Float \"]\""`
+}
+
+// LogProbAST: an optionally negative float, the natural log of a probability.
+type LogProbAST struct {
+	Negative  bool    `parser:"@\"-\"?"`
+	Magnitude float64 `parser:"@Float"`
 }
 
 // PropAST: <key> : <value>
@@ -56,11 +91,18 @@ type PropAST struct {
 
 // PropValueAST: a typed property value.
 type PropValueAST struct {
-	Str   *string  `parser:"  @String"`
-	Float *float64 `parser:"| @Float"`
-	Int   *int64   `parser:"| @Int"`
-	True  bool     `parser:"| @\"TRUE\""`
-	False bool     `parser:"| @\"FALSE\""`
+	Str   *string       `parser:"  @String"`
+	Float *float64      `parser:"| @Float"`
+	Int   *int64        `parser:"| @Int"`
+	True  bool          `parser:"| @\"TRUE\""`
+	False bool          `parser:"| @\"FALSE\""`
+	Null  bool          `parser:"| @\"NULL\""`
+	List  *ListValueAST `parser:"| @@"`
+}
+
+// ListValueAST: [ <value> ( , <value> )* ]
+type ListValueAST struct {
+	Elems []*PropValueAST `parser:"\"[\" ( @@ ( \",\" @@ )* )? \"]\""`
 }
 
 // DeleteAST dispatches on NODE or EDGE.
@@ -91,45 +133,254 @@ type DeleteEdgeByIDAST struct {
 	EdgeID string `parser:"@Ident"`
 }
 
+// UpdateEdgeAST: <id> PROB <p>
+type UpdateEdgeAST struct {
+	EdgeID string  `parser:"@Ident"`
+	Prob   float64 `parser:"\"PROB\" @Float"`
+}
+
 // QueryAST dispatches on the query keyword.
 type QueryAST struct {
 	Conditional  *ConditionalAST  `parser:"\"CONDITIONAL\" @@"`
 	Threshold    *ThresholdAST    `parser:"| \"THRESHOLD\" @@"`
+	Assert       *AssertAST       `parser:"| \"ASSERT\" @@"`
 	Aggregate    *AggregateAST    `parser:"| \"AGGREGATE\" @@"`
 	MaxPath      *MaxPathAST      `parser:"| \"MAXPATH\" @@"`
 	TopK         *TopKAST         `parser:"| \"TOPK\" @@"`
+	TopKProbs    *TopKProbsAST    `parser:"| \"TOPK_PROBS\" @@"`
+	Connected    *ConnectedAST    `parser:"| \"CONNECTED\" @@"`
+	Unreachable  *UnreachableAST  `parser:"| \"UNREACHABLE\" @@"`
+	PathProb     *PathProbAST     `parser:"| \"PATHPROB\" @@"`
+	CountPaths   *CountPathsAST   `parser:"| \"COUNTPATHS\" @@"`
+	ExpectedHops *ExpectedHopsAST `parser:"| \"EXPECTEDHOPS\" @@"`
+	AllPaths     *AllPathsAST     `parser:"| \"ALLPATHS\" @@"`
 	Reachability *ReachabilityAST `parser:"| \"REACHABILITY\" @@"`
+	Matrix       *MatrixAST       `parser:"| \"MATRIX\" @@"`
+	Confidence   *ConfidenceAST   `parser:"| \"CONFIDENCE\" @@"`
 	Sensitivity  *SensitivityAST  `parser:"| \"SENSITIVITY\" @@"`
+	Reliability  *ReliabilityAST  `parser:"| \"RELIABILITY\" \"POLYNOMIAL\" @@"`
+	RandomWalk   *RandomWalkAST   `parser:"| \"RANDOMWALK\" @@"`
+	Find         *FindAST         `parser:"| \"FIND\" @@"`
+	Subgraph     *SubgraphAST     `parser:"| \"SUBGRAPH\" @@"`
+	Histogram    bool             `parser:"| @( \"HISTOGRAM\" \"EDGES\" )"`
+	Centrality   bool             `parser:"| @( \"CENTRALITY\" \"BETWEENNESS\" )"`
+	SourceNodes  bool             `parser:"| @( \"SOURCE\" \"NODES\" )"`
+	SinkNodes    bool             `parser:"| @( \"SINK\" \"NODES\" )"`
+	RowSum       *string          `parser:"| \"ROWSUM\" @Ident"`
+	Validate     *ValidateAST     `parser:"| \"VALIDATE\" @@"`
+	ExportJSON   bool             `parser:"| @( \"EXPORT\" \"JSON\" )"`
+	PageRank     *PageRankAST     `parser:"| @@"`
+	Sample       *SampleAST       `parser:"| @@"`
 	Multi        *CompositeAST    `parser:"| \"MULTI\" @@"`
 	And          *CompositeAST    `parser:"| \"AND\" @@"`
 	Or           *CompositeAST    `parser:"| \"OR\" @@"`
+	Xor          *CompositeAST    `parser:"| \"XOR\" @@"`
+	Not          *NotAST          `parser:"| \"NOT\" @@"`
+	Concat       *ConcatAST       `parser:"| \"CONCAT\" @@"`
+	ForEach      *ForEachAST      `parser:"| \"FOREACH\" @@"`
+}
+
+// ForEachAST: <var> IN NEIGHBORS OF <node> DO <query>
+type ForEachAST struct {
+	Variable string          `parser:"@Ident \"IN\" \"NEIGHBORS\" \"OF\""`
+	Source   string          `parser:"@Ident \"DO\""`
+	Body     *ForEachBodyAST `parser:"@@"`
+}
+
+// ForEachBodyAST keeps the tokens of a FOREACH body so that it can be
+// re-parsed with the loop variable bound; see bindForEachBody.
+type ForEachBodyAST struct {
+	Tokens []lexer.Token
+	Query  *QueryAST `parser:"@@"`
 }
 
-// SensitivityAST: FROM <a> TO <b> [EXACT|MONTECARLO]
+// ConfidenceAST: FROM <a> TO <b> WIDTH <float> [SEED <s>]
+type ConfidenceAST struct {
+	From  string  `parser:"\"FROM\" @Ident"`
+	To    string  `parser:"\"TO\" @Ident"`
+	Width float64 `parser:"\"WIDTH\" @Float"`
+	Seed  *uint64 `parser:"( \"SEED\" @Int )?"`
+}
+
+// SensitivityAST: [MAP] FROM <a> TO <b> [EXACT|MONTECARLO]
+// MAP is matched as an identifier so that it stays usable as a name.
 type SensitivityAST struct {
+	MapKw string `parser:"@Ident?"`
+	From  string `parser:"\"FROM\" @Ident"`
+	To    string `parser:"\"TO\" @Ident"`
+	Mode  string `parser:"@( \"EXACT\" | \"MONTECARLO\" )?"`
+}
+
+// ReliabilityAST: FROM <a> TO <b>
+type ReliabilityAST struct {
 	From string `parser:"\"FROM\" @Ident"`
 	To   string `parser:"\"TO\" @Ident"`
-	Mode string `parser:"@( \"EXACT\" | \"MONTECARLO\" )?"`
 }
 
-// MaxPathAST: FROM <a> TO <b>
+// RandomWalkAST: FROM <a> STEPS <n> [SEED <s>]
+type RandomWalkAST struct {
+	From  string  `parser:"\"FROM\" @Ident"`
+	Steps int     `parser:"\"STEPS\" @Int"`
+	Seed  *uint64 `parser:"( \"SEED\" @Int )?"`
+}
+
+// FindAST: NODES WHERE <filter>  or  EDGES WHERE <filter>, then optionally
+// LIMIT <n> and OFFSET <m>
+type FindAST struct {
+	Nodes *FilterExprAST   `parser:"(  \"NODES\" \"WHERE\" @@"`
+	Edges *FilterExprAST   `parser:" | \"EDGES\" \"WHERE\" @@ )"`
+	Page  []*PageClauseAST `parser:"@@*"`
+}
+
+// PageClauseAST: LIMIT <n>  or  OFFSET <m>
+// LIMIT and OFFSET are matched as identifiers, and checked by convertFind,
+// so that they stay usable as names and property keys.
+type PageClauseAST struct {
+	Keyword string `parser:"@Ident"`
+	N       int    `parser:"@Int"`
+}
+
+// PageRankAST: PAGERANK [DAMPING <float>] [ITERATIONS <n>]
+type PageRankAST struct {
+	Keyword    bool     `parser:"@\"PAGERANK\""`
+	Damping    *float64 `parser:"( \"DAMPING\" @Float )?"`
+	Iterations *int     `parser:"( \"ITERATIONS\" @Int )?"`
+}
+
+// ValidateAST: ROWSUMS [EPSILON <e>]
+// ROWSUMS and EPSILON are matched as identifiers so that they stay usable
+// as names.
+type ValidateAST struct {
+	Check     string   `parser:"@Ident"`
+	EpsilonKw string   `parser:"( @Ident"`
+	Epsilon   *float64 `parser:"  @(Float|Int) )?"`
+}
+
+// SampleAST: SAMPLE [SEED <s>]
+type SampleAST struct {
+	Keyword bool    `parser:"@\"SAMPLE\""`
+	Seed    *uint64 `parser:"( \"SEED\" @Int )?"`
+}
+
+// SubgraphAST: INDUCED BY ANCESTORS OF <id>  or  INDUCED BY <id> ( , <id> )*
+type SubgraphAST struct {
+	AncestorsOf *string  `parser:"\"INDUCED\" \"BY\" ( \"ANCESTORS\" \"OF\" @Ident"`
+	Nodes       []string `parser:"| @Ident ( \",\" @Ident )* )"`
+}
+
+// FilterExprAST: <key> <op> <value>  or  <key>[<seg>]... <op> <value>
+// The CONTAINS and MATCHES operators are matched as identifiers, and
+// checked by convertFilterOp, so that they stay usable as names.
+type FilterExprAST struct {
+	Key   string            `parser:"@Ident"`
+	Path  []*PathSegmentAST `parser:"( \"[\" @@ \"]\" )*"`
+	Op    string            `parser:"( @Operator | @Ident )"`
+	Value *PropValueAST     `parser:"@@"`
+}
+
+// PathSegmentAST: "<key>"  or  <index>
+type PathSegmentAST struct {
+	Key   *string `parser:"  @String"`
+	Index *int    `parser:"| @Int"`
+}
+
+// MaxPathAST: FROM <a> TO <b> [WEIGHT <property> [BOTTLENECK]]
+// WEIGHT is matched as an identifier, and checked by convertQuery, so that
+// "weight" stays usable as a property key.
 type MaxPathAST struct {
+	From       string `parser:"\"FROM\" @Ident"`
+	To         string `parser:"\"TO\" @Ident"`
+	WeightKw   string `parser:"( @Ident"`
+	Weight     string `parser:"  @Ident"`
+	Bottleneck bool   `parser:"  @\"BOTTLENECK\"? )?"`
+}
+
+// UnreachableAST: FROM <root>
+type UnreachableAST struct {
+	Root string `parser:"\"FROM\" @Ident"`
+}
+
+// ConnectedAST: FROM <a> TO <b>
+type ConnectedAST struct {
+	From string `parser:"\"FROM\" @Ident"`
+	To   string `parser:"\"TO\" @Ident"`
+}
+
+// ExpectedHopsAST: FROM <a> TO <b>
+type ExpectedHopsAST struct {
 	From string `parser:"\"FROM\" @Ident"`
 	To   string `parser:"\"TO\" @Ident"`
 }
 
-// TopKAST: FROM <a> TO <b> K <n>
+// PathProbAST: <id> -> <id> ( -> <id> )*
+type PathProbAST struct {
+	Nodes []string `parser:"@Ident ( \"->\" @Ident )+"`
+}
+
+// AllPathsAST: FROM <a> TO <b> [MAX <n>] [MAXLEN <n>]
+// or FROM <a> TO <b> STAT MEAN|MAX|MIN|COUNT|VARIANCE [PROB|HOPS]
+type AllPathsAST struct {
+	From      string       `parser:"\"FROM\" @Ident"`
+	To        string       `parser:"\"TO\" @Ident"`
+	Stat      *PathStatAST `parser:"( \"STAT\" @@ )?"`
+	MaxPaths  int          `parser:"( \"MAX\" @Int )?"`
+	MaxLength int          `parser:"( \"MAXLEN\" @Int )?"`
+}
+
+// PathStatAST: MEAN|MAX|MIN|COUNT|VARIANCE [PROB|HOPS]
+// COUNT, VARIANCE and HOPS are matched as identifiers, and checked by
+// convertAllPathsStat, so that they stay usable as names and property keys.
+type PathStatAST struct {
+	Stat    string `parser:"@( \"MEAN\" | \"MAX\" | \"MIN\" | Ident )"`
+	Measure string `parser:"@( \"PROB\" | Ident )?"`
+}
+
+// CountPathsAST: FROM <a> TO <b> [MAXLEN <n>]
+type CountPathsAST struct {
+	From      string `parser:"\"FROM\" @Ident"`
+	To        string `parser:"\"TO\" @Ident"`
+	MaxLength int    `parser:"( \"MAXLEN\" @Int )?"`
+}
+
+// TopKAST: FROM <a> TO <b> K <n> [MIN_PROB <p>] [MAXHOPS <n>] [TIMEOUT <duration>]
+// MAXHOPS is matched as an identifier so that it stays usable as a name.
 type TopKAST struct {
+	From    string   `parser:"\"FROM\" @Ident"`
+	To      string   `parser:"\"TO\" @Ident"`
+	K       int      `parser:"\"K\" @Int"`
+	MinProb *float64 `parser:"( \"MIN_PROB\" @Float )?"`
+	HopsKw  string   `parser:"( @Ident"`
+	MaxHops int      `parser:"  @Int )?"`
+	Timeout *string  `parser:"( \"TIMEOUT\" @Duration )?"`
+}
+
+// TopKProbsAST: FROM <a> TO <b> K <n>
+type TopKProbsAST struct {
 	From string `parser:"\"FROM\" @Ident"`
 	To   string `parser:"\"TO\" @Ident"`
 	K    int    `parser:"\"K\" @Int"`
 }
 
-// ReachabilityAST: FROM <a> TO <b> [EXACT|MONTECARLO]
+// ReachabilityAST: FROM <a> TO <b> [EXACT|MONTECARLO|BOTH] [TIMEOUT <duration>]
+// Either end may be a node set: FROM { <a>, <b> } TO { <c>, <d> }
 type ReachabilityAST struct {
-	From string `parser:"\"FROM\" @Ident"`
-	To   string `parser:"\"TO\" @Ident"`
-	Mode string `parser:"@( \"EXACT\" | \"MONTECARLO\" )?"`
+	From    []string `parser:"\"FROM\" ( @Ident | \"{\" @Ident ( \",\" @Ident )* \"}\" )"`
+	To      []string `parser:"\"TO\" ( @Ident | \"{\" @Ident ( \",\" @Ident )* \"}\" )"`
+	Mode    string   `parser:"@( \"EXACT\" | \"MONTECARLO\" | \"BOTH\" )?"`
+	Timeout *string  `parser:"( \"TIMEOUT\" @Duration )?"`
+}
+
+// MatrixAST: REACHABILITY FROM <set> TO <set> [EXACT|MONTECARLO]
+type MatrixAST struct {
+	From *NodeSetAST `parser:"\"REACHABILITY\" \"FROM\" @@"`
+	To   *NodeSetAST `parser:"\"TO\" @@"`
+	Mode string      `parser:"@( \"EXACT\" | \"MONTECARLO\" )?"`
+}
+
+// NodeSetAST: GROUP <name>  or  { <id> ( , <id> )* }
+type NodeSetAST struct {
+	Group *string  `parser:"  \"GROUP\" @Ident"`
+	Nodes []string `parser:"| \"{\" @Ident ( \",\" @Ident )* \"}\""`
 }
 
 // CompositeAST: ( <query> ( , <query> )* )
@@ -137,6 +388,12 @@ type CompositeAST struct {
 	Queries []*QueryAST `parser:"\"(\" @@ ( \",\" @@ )* \")\""`
 }
 
+// ConcatAST: ( <query> , <query> )
+type ConcatAST struct {
+	First  *QueryAST `parser:"\"(\" @@"`
+	Second *QueryAST `parser:"\",\" @@ \")\""`
+}
+
 // ConditionalAST: GIVEN <conditions> ( <query> )
 type ConditionalAST struct {
 	Conditions []*ConditionItemAST `parser:"\"GIVEN\" @@ ( \",\" @@ )*"`
@@ -149,31 +406,48 @@ type ThresholdAST struct {
 	Query     *QueryAST `parser:"\"(\" @@ \")\""`
 }
 
-// AggregateAST: <reducer> ( <query> ( , <query> )* )
+// AssertAST: <query> <op> <value>
+type AssertAST struct {
+	Query *QueryAST `parser:"@@"`
+	Op    string    `parser:"@Operator"`
+	Value float64   `parser:"@( Float | Int )"`
+}
+
+// NotAST: ( <query> )
+type NotAST struct {
+	Query *QueryAST `parser:"\"(\" @@ \")\""`
+}
+
+// AggregateAST: <reducer> [SHORTCIRCUIT] ( <query> ( , <query> )* )
 type AggregateAST struct {
-	Reducer *ReducerAST `parser:"@@"`
-	Queries []*QueryAST `parser:"\"(\" @@ ( \",\" @@ )* \")\""`
+	Reducer      *ReducerAST `parser:"@@"`
+	ShortCircuit bool        `parser:"@\"SHORTCIRCUIT\"?"`
+	Queries      []*QueryAST `parser:"\"(\" @@ ( \",\" @@ )* \")\""`
 }
 
-// ReducerAST: MEAN | MAX | MIN | BESTPATH | COUNTABOVE <float>
+// ReducerAST: MEAN | GEOMEAN | PRODUCT | MAX | MIN | BESTPATH | COUNTABOVE <float> | SCRIPT "<lua>"
 type ReducerAST struct {
 	Mean       bool     `parser:"  @\"MEAN\""`
+	GeoMean    bool     `parser:"| @\"GEOMEAN\""`
+	Product    bool     `parser:"| @\"PRODUCT\""`
 	Max        bool     `parser:"| @\"MAX\""`
 	Min        bool     `parser:"| @\"MIN\""`
 	BestPath   bool     `parser:"| @\"BESTPATH\""`
 	CountAbove *float64 `parser:"| \"COUNTABOVE\" @Float"`
+	Script     *string  `parser:"| \"SCRIPT\" @String"`
 }
 
-// ConditionItemAST: EDGE <id> ACTIVE/INACTIVE  or  NODE <id> ACTIVE/INACTIVE
+// ConditionItemAST: EDGE <id> ACTIVE/INACTIVE/PROB <p>  or  NODE <id> ACTIVE/INACTIVE
 type ConditionItemAST struct {
 	Edge *EdgeConditionAST `parser:"  \"EDGE\" @@"`
 	Node *NodeConditionAST `parser:"| \"NODE\" @@"`
 }
 
-// EdgeConditionAST: <edgeID> ACTIVE|INACTIVE
+// EdgeConditionAST: <edgeID> ACTIVE|INACTIVE|PROB <float>
 type EdgeConditionAST struct {
-	EdgeID string `parser:"@Ident"`
-	State  string `parser:"@( \"ACTIVE\" | \"INACTIVE\" )"`
+	EdgeID string   `parser:"@Ident"`
+	State  string   `parser:"(  @( \"ACTIVE\" | \"INACTIVE\" )"`
+	Prob   *float64 `parser:"| \"PROB\" @Float )"`
 }
 
 // NodeConditionAST: <nodeID> ACTIVE|INACTIVE
@@ -185,6 +459,14 @@ type NodeConditionAST struct {
 // Parser singleton built from the grammar.
 var dslParser = participle.MustBuild[Grammar](
 	participle.Lexer(dslLexer),
-	participle.CaseInsensitive("Keyword"),
+	participle.CaseInsensitive("Keyword", "Ident"),
+	participle.Elide("Whitespace"),
+)
+
+// dslQueryParser parses a single query from already-lexed tokens; it is
+// used to re-parse FOREACH bodies.
+var dslQueryParser = participle.MustBuild[QueryAST](
+	participle.Lexer(dslLexer),
+	participle.CaseInsensitive("Keyword", "Ident"),
 	participle.Elide("Whitespace"),
 )
@@ -1,7 +1,9 @@
 package dsl
 
 import (
+	"context"
 	"fmt"
+	"time"
 
 	"github.com/ritamzico/pgraph/internal/engine"
 	"github.com/ritamzico/pgraph/internal/graph"
@@ -12,6 +14,7 @@ import (
 type Parser struct {
 	SessionGraph graph.ProbabilisticGraphModel
 	ie           engine.InferenceEngine
+	cache        *engine.CachedInferenceEngine // nil unless EnableQueryCache
 }
 
 func CreateParser(baseGraph graph.ProbabilisticGraphModel) Parser {
@@ -23,7 +26,76 @@ func CreateParser(baseGraph graph.ProbabilisticGraphModel) Parser {
 	}
 }
 
+// Fork returns a parser on a clone of the session graph with p's settings:
+// the same MinEdgeDensity, and the same caches enabled, but empty.
+func (p Parser) Fork() Parser {
+	g := p.SessionGraph.Clone()
+	fork := Parser{SessionGraph: g, ie: p.ie.WithGraph(g)}
+	if p.cache != nil {
+		fork.EnableQueryCache(p.cache.MaxEntries, p.cache.TTL)
+	}
+	return fork
+}
+
+// ReplaceSessionGraph makes g the session graph for subsequent statements and
+// queries. Unlike CreateParser, g is used as-is rather than cloned.
+func (p *Parser) ReplaceSessionGraph(g graph.ProbabilisticGraphModel) {
+	p.SessionGraph = g
+	p.ie.Graph = g
+	p.syncCache()
+}
+
+// SetMinEdgeDensity configures the engine's MinEdgeDensity check for
+// subsequent queries.
+func (p *Parser) SetMinEdgeDensity(d float64) {
+	p.ie.MinEdgeDensity = d
+	p.syncCache()
+}
+
+// EnableIncrementalCache makes exact reachability queries reuse earlier
+// results across edge probability updates; see
+// engine.InferenceEngine.EnableIncrementalCache.
+func (p *Parser) EnableIncrementalCache() {
+	p.ie.EnableIncrementalCache()
+	p.syncCache()
+}
+
+// UpdateEdge sets the probability of edge id through the engine, so the
+// incremental cache keeps results the edge does not affect.
+func (p *Parser) UpdateEdge(id graph.EdgeID, prob float64) error {
+	return p.ie.UpdateEdge(id, prob)
+}
+
+// EnableQueryCache memoizes query results by query and graph version; see
+// engine.CachedInferenceEngine, whose MaxEntries and TTL are maxEntries and
+// ttl. Calling it again starts a new, empty cache.
+func (p *Parser) EnableQueryCache(maxEntries int, ttl time.Duration) {
+	p.cache = &engine.CachedInferenceEngine{Engine: p.ie, MaxEntries: maxEntries, TTL: ttl}
+}
+
+// QueryCacheStats returns the query cache's hit and miss counts, which are
+// zero if it is not enabled.
+func (p Parser) QueryCacheStats() (hits, misses uint64) {
+	if p.cache == nil {
+		return 0, 0
+	}
+	return p.cache.Hits.Load(), p.cache.Misses.Load()
+}
+
+// syncCache gives the query cache the current engine settings.
+func (p *Parser) syncCache() {
+	if p.cache != nil {
+		p.cache.Engine = p.ie
+	}
+}
+
 func (p Parser) ParseLine(input string) (result.Result, error) {
+	return p.ParseLineContext(context.Background(), input)
+}
+
+// ParseLineContext is like ParseLine but runs queries under ctx, so
+// cancelling it stops inference. Nothing is executed if ctx is already done.
+func (p Parser) ParseLineContext(ctx context.Context, input string) (result.Result, error) {
 	ast, err := dslParser.ParseString("", input)
 	if err != nil {
 		return nil, enrichSyntaxError(input, err)
@@ -33,15 +105,34 @@ func (p Parser) ParseLine(input string) (result.Result, error) {
 	if err != nil {
 		return nil, err
 	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
 	switch n := node.(type) {
+	case *UpdateEdgeStatement:
+		return nil, n.apply(p.SessionGraph, p.ie.UpdateEdge)
+
 	case Statement:
 		return nil, n.Execute(p.SessionGraph)
 
 	case query.Query:
-		return p.ie.Execute(n)
+		return p.execute(ctx, n)
 
 	default:
 		return nil, fmt.Errorf("internal error: unknown AST node %T", n)
 	}
 }
+
+// ExecuteQuery runs an already built query on the session graph, through
+// the same engine as parsed queries.
+func (p Parser) ExecuteQuery(ctx context.Context, q query.Query) (result.Result, error) {
+	return p.execute(ctx, q)
+}
+
+func (p Parser) execute(ctx context.Context, q query.Query) (result.Result, error) {
+	if p.cache != nil {
+		return p.cache.ExecuteWithContext(ctx, q)
+	}
+	return p.ie.ExecuteWithContext(ctx, q)
+}
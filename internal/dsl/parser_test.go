@@ -1,14 +1,22 @@
 package dsl
 
 import (
+	"encoding/base64"
+	"errors"
 	"math"
+	"slices"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/ritamzico/pgraph/internal/graph"
+	"github.com/ritamzico/pgraph/internal/inference"
+	"github.com/ritamzico/pgraph/internal/query"
 	"github.com/ritamzico/pgraph/internal/result"
+	"github.com/ritamzico/pgraph/internal/scripting"
 )
 
-func buildTestGraph(t *testing.T) graph.ProbabilisticGraphModel {
+func buildTestGraph(t testing.TB) graph.ProbabilisticGraphModel {
 	t.Helper()
 	g := graph.CreateProbAdjListGraph()
 
@@ -138,6 +146,85 @@ func TestParser_DeleteEdgeByID(t *testing.T) {
 	}
 }
 
+func TestParser_UpdateEdge(t *testing.T) {
+	g := graph.CreateProbAdjListGraph()
+	g.AddNode("A", nil)
+	g.AddNode("B", nil)
+	if err := g.AddBidirectionalEdge("eAB", "A", "B", 0.9, nil); err != nil {
+		t.Fatalf("AddBidirectionalEdge failed: %v", err)
+	}
+	parser := CreateParser(g)
+
+	if _, err := parser.ParseLine("UPDATE EDGE eAB PROB 0.4"); err != nil {
+		t.Fatalf("ParseLine failed: %v", err)
+	}
+	for _, id := range []graph.EdgeID{"eAB", "eAB" + graph.ReverseEdgeSuffix} {
+		e, err := parser.SessionGraph.GetEdgeByID(id)
+		if err != nil {
+			t.Fatalf("GetEdgeByID(%s) failed: %v", id, err)
+		}
+		if e.Probability != 0.4 {
+			t.Errorf("expected %s to have probability 0.4, got %v", id, e.Probability)
+		}
+	}
+
+	for _, input := range []string{"UPDATE EDGE missing PROB 0.5", "UPDATE EDGE eAB PROB 1.5"} {
+		if _, err := parser.ParseLine(input); err == nil {
+			t.Errorf("%s: expected error", input)
+		}
+	}
+}
+
+// exploringGraph counts OutgoingEdges calls, which every reachability
+// search makes and a cached result does not.
+type exploringGraph struct {
+	graph.ProbabilisticGraphModel
+	calls int
+}
+
+func (g *exploringGraph) OutgoingEdges(id graph.NodeID) ([]*graph.Edge, error) {
+	g.calls++
+	return g.ProbabilisticGraphModel.OutgoingEdges(id)
+}
+
+func TestParser_UpdateEdgeKeepsIncrementalCache(t *testing.T) {
+	g := &exploringGraph{ProbabilisticGraphModel: buildTestGraph(t)}
+	if err := g.AddNode("X", nil); err != nil {
+		t.Fatalf("AddNode failed: %v", err)
+	}
+	if err := g.AddEdge("eXA", "X", "A", 0.5, nil); err != nil {
+		t.Fatalf("AddEdge failed: %v", err)
+	}
+	var parser Parser
+	parser.ReplaceSessionGraph(g)
+	parser.EnableIncrementalCache()
+
+	reach := func() float64 {
+		t.Helper()
+		r, err := parser.ParseLine("REACHABILITY FROM A TO D EXACT")
+		if err != nil {
+			t.Fatalf("ParseLine failed: %v", err)
+		}
+		return r.(result.ProbabilityResult).Probability
+	}
+
+	want := reach()
+	g.calls = 0
+	if _, err := parser.ParseLine("UPDATE EDGE eXA PROB 0.1"); err != nil {
+		t.Fatalf("ParseLine failed: %v", err)
+	}
+	if got := reach(); got != want || g.calls != 0 {
+		t.Errorf("expected %v served from the cache, got %v after %d searches", want, got, g.calls)
+	}
+
+	if _, err := parser.ParseLine("UPDATE EDGE eAB PROB 0.1"); err != nil {
+		t.Fatalf("ParseLine failed: %v", err)
+	}
+	if got := reach(); got == want || g.calls == 0 {
+		t.Errorf("expected updating eAB to recompute, got %v after %d searches", got, g.calls)
+	}
+}
+
 func TestParser_MaxPathQuery(t *testing.T) {
 	baseGraph := buildTestGraph(t)
 	parser := CreateParser(baseGraph)
@@ -159,6 +246,81 @@ func TestParser_MaxPathQuery(t *testing.T) {
 	}
 }
 
+func TestParser_MaxPathWeight(t *testing.T) {
+	baseGraph := graph.CreateProbAdjListGraph()
+	parser := CreateParser(baseGraph)
+
+	setup := []string{
+		"CREATE NODE A", "CREATE NODE B", "CREATE NODE C", "CREATE NODE D",
+		"CREATE EDGE eAB FROM A TO B PROB 0.9 { weight: 0.2 }",
+		"CREATE EDGE eAC FROM A TO C PROB 0.8 { weight: 0.9 }",
+		"CREATE EDGE eBD FROM B TO D PROB 0.7 { weight: 0.9 }",
+		"CREATE EDGE eCD FROM C TO D PROB 0.6 { weight: 1 }",
+	}
+	for _, line := range setup {
+		if _, err := parser.ParseLine(line); err != nil {
+			t.Fatalf("%q failed: %v", line, err)
+		}
+	}
+
+	// "weight" is both the keyword and the property key.
+	res, err := parser.ParseLine("maxpath from A to D weight weight")
+	if err != nil {
+		t.Fatalf("ParseLine failed: %v", err)
+	}
+	path := res.(result.PathResult).Path
+	if !slices.Equal(path.NodeIDs, []graph.NodeID{"A", "C", "D"}) {
+		t.Errorf("expected path A->C->D, got %v", path.NodeIDs)
+	}
+
+	invalid := []string{
+		"MAXPATH FROM A TO D WEIGHT",         // Missing property key
+		"MAXPATH FROM A TO D BY weight",      // Wrong keyword
+		"MAXPATH FROM A TO D WEIGHT missing", // Property not set on edges
+		"MAXPATH FROM A TO D BOTTLENECK",     // BOTTLENECK without WEIGHT
+	}
+	for _, line := range invalid {
+		if _, err := parser.ParseLine(line); err == nil {
+			t.Errorf("%q: expected error", line)
+		}
+	}
+}
+
+func TestParser_MaxPathWeightBottleneck(t *testing.T) {
+	parser := CreateParser(graph.CreateProbAdjListGraph())
+	setup := []string{
+		"CREATE NODE A", "CREATE NODE B", "CREATE NODE C", "CREATE NODE D",
+		"CREATE EDGE eAB FROM A TO B PROB 0.9 { capacity: 10 }",
+		"CREATE EDGE eBD FROM B TO D PROB 0.9 { capacity: 2 }",
+		"CREATE EDGE eAC FROM A TO C PROB 0.5 { capacity: 3 }",
+		"CREATE EDGE eCD FROM C TO D PROB 0.5 { capacity: 3.5 }",
+	}
+	for _, line := range setup {
+		if _, err := parser.ParseLine(line); err != nil {
+			t.Fatalf("%q failed: %v", line, err)
+		}
+	}
+
+	// A product of capacities above 1 is rejected rather than maximised.
+	_, err := parser.ParseLine("MAXPATH FROM A TO D WEIGHT capacity")
+	var ie inference.InferenceError
+	if !errors.As(err, &ie) || ie.Kind != "InvalidWeight" {
+		t.Fatalf("expected InvalidWeight error, got %v", err)
+	}
+
+	res, err := parser.ParseLine("MAXPATH FROM A TO D WEIGHT capacity BOTTLENECK")
+	if err != nil {
+		t.Fatalf("ParseLine failed: %v", err)
+	}
+	path := res.(result.PathResult).Path
+	if !slices.Equal(path.NodeIDs, []graph.NodeID{"A", "C", "D"}) {
+		t.Errorf("expected the widest path A->C->D, got %v", path.NodeIDs)
+	}
+	if math.Abs(path.Probability-0.25) > 1e-9 {
+		t.Errorf("expected probability 0.25, got %f", path.Probability)
+	}
+}
+
 func TestParser_TopKQuery(t *testing.T) {
 	baseGraph := buildTestGraph(t)
 	parser := CreateParser(baseGraph)
@@ -178,6 +340,39 @@ func TestParser_TopKQuery(t *testing.T) {
 	}
 }
 
+func TestParser_TopKProbsQuery(t *testing.T) {
+	parser := CreateParser(buildTestGraph(t))
+
+	res, err := parser.ParseLine("topk_probs from A to D k 5")
+	if err != nil {
+		t.Fatalf("ParseLine failed: %v", err)
+	}
+	valuesRes, ok := res.(result.ValuesResult)
+	if !ok {
+		t.Fatalf("expected ValuesResult, got %T", res)
+	}
+	// Paths A->B->D (0.63) and A->C->D (0.48).
+	want := []float64{0.9 * 0.7, 0.8 * 0.6}
+	if len(valuesRes.Values) != len(want) {
+		t.Fatalf("expected %v, got %v", want, valuesRes.Values)
+	}
+	for i := range want {
+		if math.Abs(valuesRes.Values[i]-want[i]) > 1e-9 {
+			t.Errorf("value %d: expected %f, got %f", i, want[i], valuesRes.Values[i])
+		}
+	}
+
+	for _, line := range []string{
+		"TOPK_PROBS FROM A TO D K 0", // Non-positive K
+		"TOPK_PROBS FROM A TO D",     // Missing K
+		"TOPK_PROBS FROM A TO Z K 2", // Unknown node
+	} {
+		if _, err := parser.ParseLine(line); err == nil {
+			t.Errorf("%q: expected error", line)
+		}
+	}
+}
+
 func TestParser_ReachabilityExact(t *testing.T) {
 	baseGraph := buildTestGraph(t)
 	parser := CreateParser(baseGraph)
@@ -240,6 +435,47 @@ func TestParser_ReachabilityDefaultMode(t *testing.T) {
 	}
 }
 
+func TestParser_ReachabilityNodeSets(t *testing.T) {
+	tests := []struct {
+		line string
+		want float64
+	}{
+		// B->D and C->D are independent: 1 - 0.3*0.4.
+		{"REACHABILITY FROM {B, C} TO D EXACT", 0.88},
+		{"REACHABILITY FROM {B,C} TO {D}", 0.88},
+		// A->B and A->C are independent: 1 - 0.1*0.2.
+		{"REACHABILITY FROM A TO { B, C } EXACT", 0.98},
+		{"REACHABILITY FROM {A} TO {D} EXACT", 1 - (1-0.63)*(1-0.48)},
+	}
+	for _, tt := range tests {
+		parser := CreateParser(buildTestGraph(t))
+		res, err := parser.ParseLine(tt.line)
+		if err != nil {
+			t.Fatalf("%q: ParseLine failed: %v", tt.line, err)
+		}
+		probRes, ok := res.(result.ProbabilityResult)
+		if !ok {
+			t.Fatalf("%q: expected ProbabilityResult, got %T", tt.line, res)
+		}
+		if math.Abs(probRes.Probability-tt.want) > 0.0001 {
+			t.Errorf("%q: expected probability %f, got %f", tt.line, tt.want, probRes.Probability)
+		}
+	}
+}
+
+func TestParser_ReachabilityNodeSetsInvalid(t *testing.T) {
+	for _, line := range []string{
+		"REACHABILITY FROM {} TO D",
+		"REACHABILITY FROM {B, C TO D",
+		"REACHABILITY FROM {B,} TO D",
+	} {
+		parser := CreateParser(buildTestGraph(t))
+		if _, err := parser.ParseLine(line); err == nil {
+			t.Errorf("%q: expected a syntax error", line)
+		}
+	}
+}
+
 func TestParser_MultiQuery(t *testing.T) {
 	baseGraph := buildTestGraph(t)
 	parser := CreateParser(baseGraph)
@@ -301,6 +537,65 @@ func TestParser_OrQuery(t *testing.T) {
 	}
 }
 
+func TestParser_XorQuery(t *testing.T) {
+	baseGraph := buildTestGraph(t)
+	parser := CreateParser(baseGraph)
+
+	res, err := parser.ParseLine("XOR ( REACHABILITY FROM A TO B EXACT, REACHABILITY FROM A TO C EXACT )")
+	if err != nil {
+		t.Fatalf("ParseLine failed: %v", err)
+	}
+
+	probRes, ok := res.(result.ProbabilityResult)
+	if !ok {
+		t.Fatalf("expected ProbabilityResult, got %T", res)
+	}
+
+	// XOR(0.9, 0.8) = 0.9 + 0.8 - 2*0.9*0.8 = 0.26
+	expectedProb := 0.9 + 0.8 - 2*0.9*0.8
+	if math.Abs(probRes.Probability-expectedProb) > 0.0001 {
+		t.Errorf("expected probability %f, got %f", expectedProb, probRes.Probability)
+	}
+}
+
+func TestParser_NotQuery(t *testing.T) {
+	baseGraph := buildTestGraph(t)
+	parser := CreateParser(baseGraph)
+
+	res, err := parser.ParseLine("NOT ( REACHABILITY FROM A TO B EXACT )")
+	if err != nil {
+		t.Fatalf("ParseLine failed: %v", err)
+	}
+
+	probRes, ok := res.(result.ProbabilityResult)
+	if !ok {
+		t.Fatalf("expected ProbabilityResult, got %T", res)
+	}
+
+	// NOT(0.9) = 0.1
+	if math.Abs(probRes.Probability-0.1) > 0.0001 {
+		t.Errorf("expected probability 0.1, got %f", probRes.Probability)
+	}
+
+	// NOT composes with AND: P(A->B and not A->C) = 0.9 * (1 - 0.8)
+	res, err = parser.ParseLine("AND ( REACHABILITY FROM A TO B EXACT, NOT ( REACHABILITY FROM A TO C EXACT ) )")
+	if err != nil {
+		t.Fatalf("ParseLine failed: %v", err)
+	}
+	probRes, ok = res.(result.ProbabilityResult)
+	if !ok {
+		t.Fatalf("expected ProbabilityResult, got %T", res)
+	}
+	expectedProb := 0.9 * (1.0 - 0.8)
+	if math.Abs(probRes.Probability-expectedProb) > 0.0001 {
+		t.Errorf("expected probability %f, got %f", expectedProb, probRes.Probability)
+	}
+
+	if _, err := parser.ParseLine("NOT ( TOPK FROM A TO C K 2 )"); err == nil {
+		t.Error("expected error for a non-probabilistic inner query")
+	}
+}
+
 func TestParser_ThresholdQuery(t *testing.T) {
 	baseGraph := buildTestGraph(t)
 	parser := CreateParser(baseGraph)
@@ -321,6 +616,45 @@ func TestParser_ThresholdQuery(t *testing.T) {
 	}
 }
 
+func TestParser_Assert(t *testing.T) {
+	parser := CreateParser(buildTestGraph(t))
+
+	for _, input := range []string{
+		"ASSERT REACHABILITY FROM A TO B EXACT >= 0.9",
+		"ASSERT REACHABILITY FROM A TO D < 1",
+		"ASSERT COUNTPATHS FROM A TO D = 2",
+		"assert connected from A to D = 1",
+		"MULTI ( ASSERT PATHPROB A -> B > 0.5, ASSERT MAXPATH FROM A TO D != 0.5 )",
+	} {
+		res, err := parser.ParseLine(input)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", input, err)
+			continue
+		}
+		if b, ok := res.(result.BooleanResult); ok && !b.Value {
+			t.Errorf("%q: expected true, got false", input)
+		}
+	}
+
+	_, err := parser.ParseLine("ASSERT REACHABILITY FROM A TO B EXACT > 0.95")
+	var ae query.AssertionError
+	if !errors.As(err, &ae) {
+		t.Errorf("expected AssertionError, got %v", err)
+	}
+
+	for _, input := range []string{
+		"ASSERT REACHABILITY FROM A TO B EXACT",
+		"ASSERT REACHABILITY FROM A TO B EXACT >= high",
+		"ASSERT REACHABILITY FROM A TO B EXACT CONTAINS 0.5",
+		"ASSERT >= 0.5",
+	} {
+		var se SyntaxError
+		if _, err := parser.ParseLine(input); !errors.As(err, &se) {
+			t.Errorf("%q: expected SyntaxError, got %v", input, err)
+		}
+	}
+}
+
 func TestParser_ThresholdQueryFalse(t *testing.T) {
 	baseGraph := buildTestGraph(t)
 	parser := CreateParser(baseGraph)
@@ -362,6 +696,88 @@ func TestParser_ConditionalQueryInactiveEdge(t *testing.T) {
 	}
 }
 
+func TestParser_ConditionalQuerySoftEvidence(t *testing.T) {
+	baseGraph := buildTestGraph(t)
+	parser := CreateParser(baseGraph)
+
+	res, err := parser.ParseLine("CONDITIONAL GIVEN EDGE eAB PROB 0.5 ( REACHABILITY FROM A TO D EXACT )")
+	if err != nil {
+		t.Fatalf("ParseLine failed: %v", err)
+	}
+
+	probRes, ok := res.(result.ProbabilityResult)
+	if !ok {
+		t.Fatalf("expected ProbabilityResult, got %T", res)
+	}
+
+	// With P(A->B) = 0.5: 1 - (1 - 0.5*0.7) * (1 - 0.8*0.6)
+	expectedProb := 1 - (1-0.5*0.7)*(1-0.8*0.6)
+	if math.Abs(probRes.Probability-expectedProb) > 0.0001 {
+		t.Errorf("expected probability %f, got %f", expectedProb, probRes.Probability)
+	}
+
+	// The session graph must be unchanged.
+	edge, err := parser.SessionGraph.GetEdgeByID("eAB")
+	if err != nil {
+		t.Fatalf("GetEdgeByID failed: %v", err)
+	}
+	if edge.Probability != 0.9 {
+		t.Errorf("expected eAB probability to remain 0.9, got %f", edge.Probability)
+	}
+}
+
+func TestParser_ConditionalQuerySoftEvidenceBidirectional(t *testing.T) {
+	parser := CreateParser(graph.CreateProbAdjListGraph())
+	for _, line := range []string{
+		"CREATE NODE A",
+		"CREATE NODE B",
+		"CREATE EDGE eAB FROM A TO B PROB 0.9 BIDIRECTIONAL",
+	} {
+		if _, err := parser.ParseLine(line); err != nil {
+			t.Fatalf("ParseLine(%q) failed: %v", line, err)
+		}
+	}
+
+	res, err := parser.ParseLine("CONDITIONAL GIVEN EDGE eAB PROB 0.5 ( REACHABILITY FROM B TO A EXACT )")
+	if err != nil {
+		t.Fatalf("ParseLine failed: %v", err)
+	}
+	probRes, ok := res.(result.ProbabilityResult)
+	if !ok {
+		t.Fatalf("expected ProbabilityResult, got %T", res)
+	}
+	// The reverse half B->A takes the conditioned probability too.
+	if math.Abs(probRes.Probability-0.5) > 0.0001 {
+		t.Errorf("expected probability 0.5, got %f", probRes.Probability)
+	}
+}
+
+func TestParser_ConditionalQuerySoftEvidenceWithHardEvidence(t *testing.T) {
+	baseGraph := buildTestGraph(t)
+	parser := CreateParser(baseGraph)
+
+	res, err := parser.ParseLine("CONDITIONAL GIVEN EDGE eAB INACTIVE, EDGE eCD PROB 0.25 ( REACHABILITY FROM A TO D EXACT )")
+	if err != nil {
+		t.Fatalf("ParseLine failed: %v", err)
+	}
+
+	// Only A->C->D remains, with P(C->D) = 0.25
+	expectedProb := 0.8 * 0.25
+	if probRes := res.(result.ProbabilityResult); math.Abs(probRes.Probability-expectedProb) > 0.0001 {
+		t.Errorf("expected probability %f, got %f", expectedProb, probRes.Probability)
+	}
+}
+
+func TestParser_ConditionalQuerySoftEvidenceInvalidProbability(t *testing.T) {
+	baseGraph := buildTestGraph(t)
+	parser := CreateParser(baseGraph)
+
+	_, err := parser.ParseLine("CONDITIONAL GIVEN EDGE eAB PROB 1.5 ( REACHABILITY FROM A TO D EXACT )")
+	if err == nil {
+		t.Error("expected error for soft evidence probability outside [0, 1]")
+	}
+}
+
 func TestParser_ConditionalQueryInactiveNode(t *testing.T) {
 	baseGraph := buildTestGraph(t)
 	parser := CreateParser(baseGraph)
@@ -383,6 +799,66 @@ func TestParser_ConditionalQueryInactiveNode(t *testing.T) {
 	}
 }
 
+func TestParser_ConditionalQueryActiveEdge(t *testing.T) {
+	parser := CreateParser(buildTestGraph(t))
+
+	// With A->B certain: 1 - (1 - 0.7) * (1 - 0.8*0.6). Hard evidence
+	// overrides soft evidence on the same edge, and keywords are
+	// case-insensitive.
+	expectedProb := 1 - (1-0.7)*(1-0.8*0.6)
+	for _, line := range []string{
+		"CONDITIONAL GIVEN EDGE eAB ACTIVE ( REACHABILITY FROM A TO D EXACT )",
+		"conditional given edge eAB active ( reachability from A to D exact )",
+		"CONDITIONAL GIVEN EDGE eAB PROB 0.3, EDGE eAB ACTIVE ( REACHABILITY FROM A TO D EXACT )",
+	} {
+		res, err := parser.ParseLine(line)
+		if err != nil {
+			t.Fatalf("%s: ParseLine failed: %v", line, err)
+		}
+		if probRes := res.(result.ProbabilityResult); math.Abs(probRes.Probability-expectedProb) > 0.0001 {
+			t.Errorf("%s: expected probability %f, got %f", line, expectedProb, probRes.Probability)
+		}
+	}
+
+	edge, err := parser.SessionGraph.GetEdgeByID("eAB")
+	if err != nil {
+		t.Fatalf("GetEdgeByID failed: %v", err)
+	}
+	if edge.Probability != 0.9 {
+		t.Errorf("expected eAB probability to remain 0.9, got %f", edge.Probability)
+	}
+}
+
+func TestParser_ConditionalQueryActiveNode(t *testing.T) {
+	parser := CreateParser(buildTestGraph(t))
+
+	res, err := parser.ParseLine("CONDITIONAL GIVEN NODE C ACTIVE ( REACHABILITY FROM A TO D EXACT )")
+	if err != nil {
+		t.Fatalf("ParseLine failed: %v", err)
+	}
+
+	// Node C active makes C->D certain: 1 - (1 - 0.9*0.7) * (1 - 0.8)
+	expectedProb := 1 - (1-0.9*0.7)*(1-0.8)
+	if probRes := res.(result.ProbabilityResult); math.Abs(probRes.Probability-expectedProb) > 0.0001 {
+		t.Errorf("expected probability %f, got %f", expectedProb, probRes.Probability)
+	}
+}
+
+func TestParser_ConditionalQueryContradictoryConditions(t *testing.T) {
+	parser := CreateParser(buildTestGraph(t))
+
+	for _, line := range []string{
+		"CONDITIONAL GIVEN EDGE eAB ACTIVE, EDGE eAB INACTIVE ( REACHABILITY FROM A TO D EXACT )",
+		"CONDITIONAL GIVEN NODE B ACTIVE, NODE B INACTIVE ( REACHABILITY FROM A TO D EXACT )",
+		"CONDITIONAL GIVEN NODE B INACTIVE, EDGE eBD ACTIVE ( REACHABILITY FROM A TO D EXACT )",
+		"CONDITIONAL GIVEN NODE Z ACTIVE ( REACHABILITY FROM A TO D EXACT )",
+	} {
+		if _, err := parser.ParseLine(line); err == nil {
+			t.Errorf("%s: expected error", line)
+		}
+	}
+}
+
 func TestParser_ConditionalQueryMultipleConditions(t *testing.T) {
 	baseGraph := buildTestGraph(t)
 	parser := CreateParser(baseGraph)
@@ -577,11 +1053,11 @@ func TestParser_AggregateMean(t *testing.T) {
 	}
 }
 
-func TestParser_AggregateMax(t *testing.T) {
+func TestParser_AggregateScript(t *testing.T) {
 	baseGraph := buildTestGraph(t)
 	parser := CreateParser(baseGraph)
 
-	res, err := parser.ParseLine("AGGREGATE MAX ( REACHABILITY FROM A TO B EXACT, REACHABILITY FROM A TO C EXACT, REACHABILITY FROM B TO D EXACT )")
+	res, err := parser.ParseLine(`AGGREGATE SCRIPT "function reduce(p) return p[1] * p[2] end" ( REACHABILITY FROM A TO B EXACT, REACHABILITY FROM A TO C EXACT )`)
 	if err != nil {
 		t.Fatalf("ParseLine failed: %v", err)
 	}
@@ -591,17 +1067,31 @@ func TestParser_AggregateMax(t *testing.T) {
 		t.Fatalf("expected ProbabilityResult, got %T", res)
 	}
 
-	// Max of 0.9, 0.8, 0.7 = 0.9
-	if math.Abs(probRes.Probability-0.9) > 0.0001 {
-		t.Errorf("expected 0.9, got %f", probRes.Probability)
+	// 0.9 * 0.8 = 0.72
+	if math.Abs(probRes.Probability-0.72) > 0.0001 {
+		t.Errorf("expected 0.72, got %f", probRes.Probability)
+	}
+
+	// A script that does not compile is rejected before any sub-query runs.
+	_, err = parser.ParseLine(`AGGREGATE SCRIPT "function reduce(p) return" ( REACHABILITY FROM A TO B EXACT )`)
+	var se SyntaxError
+	if !errors.As(err, &se) || se.Kind != "InvalidScript" {
+		t.Errorf("expected InvalidScript error, got %v", err)
+	}
+
+	// The result must be a probability.
+	_, err = parser.ParseLine(`AGGREGATE SCRIPT "function reduce(p) return 5 end" ( REACHABILITY FROM A TO B EXACT )`)
+	var scriptErr scripting.ScriptError
+	if !errors.As(err, &scriptErr) || scriptErr.Kind != "InvalidParameter" {
+		t.Errorf("expected InvalidParameter error, got %v", err)
 	}
 }
 
-func TestParser_AggregateMin(t *testing.T) {
+func TestParser_AggregateGeoMean(t *testing.T) {
 	baseGraph := buildTestGraph(t)
 	parser := CreateParser(baseGraph)
 
-	res, err := parser.ParseLine("AGGREGATE MIN ( REACHABILITY FROM A TO B EXACT, REACHABILITY FROM A TO C EXACT, REACHABILITY FROM B TO D EXACT )")
+	res, err := parser.ParseLine("AGGREGATE GEOMEAN ( REACHABILITY FROM A TO B EXACT, REACHABILITY FROM A TO C EXACT )")
 	if err != nil {
 		t.Fatalf("ParseLine failed: %v", err)
 	}
@@ -611,28 +1101,128 @@ func TestParser_AggregateMin(t *testing.T) {
 		t.Fatalf("expected ProbabilityResult, got %T", res)
 	}
 
-	// Min of 0.9, 0.8, 0.7 = 0.7
-	if math.Abs(probRes.Probability-0.7) > 0.0001 {
-		t.Errorf("expected 0.7, got %f", probRes.Probability)
+	// Geometric mean of 0.9 and 0.8 = sqrt(0.72)
+	if math.Abs(probRes.Probability-math.Sqrt(0.72)) > 0.0001 {
+		t.Errorf("expected %f, got %f", math.Sqrt(0.72), probRes.Probability)
 	}
 }
 
-func TestParser_AggregateBestPath(t *testing.T) {
+func TestParser_AggregateProduct(t *testing.T) {
 	baseGraph := buildTestGraph(t)
 	parser := CreateParser(baseGraph)
 
-	res, err := parser.ParseLine("AGGREGATE BESTPATH ( MAXPATH FROM A TO D, MAXPATH FROM A TO B )")
+	res, err := parser.ParseLine("AGGREGATE PRODUCT ( REACHABILITY FROM A TO B EXACT, REACHABILITY FROM A TO C EXACT )")
 	if err != nil {
 		t.Fatalf("ParseLine failed: %v", err)
 	}
 
-	pathRes, ok := res.(result.PathResult)
+	probRes, ok := res.(result.ProbabilityResult)
 	if !ok {
-		t.Fatalf("expected PathResult, got %T", res)
+		t.Fatalf("expected ProbabilityResult, got %T", res)
 	}
 
-	// A→D best path = A→B→D (0.63), A→B = 0.9; best is 0.9
-	if math.Abs(pathRes.Path.Probability-0.9) > 0.0001 {
+	// Product of 0.9 and 0.8 = 0.72
+	if math.Abs(probRes.Probability-0.72) > 0.0001 {
+		t.Errorf("expected 0.72, got %f", probRes.Probability)
+	}
+}
+
+func TestParser_AggregateMax(t *testing.T) {
+	baseGraph := buildTestGraph(t)
+	parser := CreateParser(baseGraph)
+
+	res, err := parser.ParseLine("AGGREGATE MAX ( REACHABILITY FROM A TO B EXACT, REACHABILITY FROM A TO C EXACT, REACHABILITY FROM B TO D EXACT )")
+	if err != nil {
+		t.Fatalf("ParseLine failed: %v", err)
+	}
+
+	probRes, ok := res.(result.ProbabilityResult)
+	if !ok {
+		t.Fatalf("expected ProbabilityResult, got %T", res)
+	}
+
+	// Max of 0.9, 0.8, 0.7 = 0.9
+	if math.Abs(probRes.Probability-0.9) > 0.0001 {
+		t.Errorf("expected 0.9, got %f", probRes.Probability)
+	}
+}
+
+func TestParser_AggregateMaxOverThresholds(t *testing.T) {
+	baseGraph := buildTestGraph(t)
+	parser := CreateParser(baseGraph)
+
+	res, err := parser.ParseLine("AGGREGATE MAX ( THRESHOLD 0.95 ( REACHABILITY FROM A TO B EXACT ), THRESHOLD 0.7 ( REACHABILITY FROM A TO C EXACT ) )")
+	if err != nil {
+		t.Fatalf("ParseLine failed: %v", err)
+	}
+
+	probRes, ok := res.(result.ProbabilityResult)
+	if !ok {
+		t.Fatalf("expected ProbabilityResult, got %T", res)
+	}
+
+	// 0.9 >= 0.95 is false (0.0), 0.8 >= 0.7 is true (1.0) → max = 1.0
+	if math.Abs(probRes.Probability-1.0) > 0.0001 {
+		t.Errorf("expected 1.0, got %f", probRes.Probability)
+	}
+}
+
+func TestParser_AggregateMeanOverThresholds(t *testing.T) {
+	baseGraph := buildTestGraph(t)
+	parser := CreateParser(baseGraph)
+
+	res, err := parser.ParseLine("AGGREGATE MEAN ( THRESHOLD 0.95 ( REACHABILITY FROM A TO B EXACT ), THRESHOLD 0.7 ( REACHABILITY FROM A TO C EXACT ) )")
+	if err != nil {
+		t.Fatalf("ParseLine failed: %v", err)
+	}
+
+	probRes, ok := res.(result.ProbabilityResult)
+	if !ok {
+		t.Fatalf("expected ProbabilityResult, got %T", res)
+	}
+
+	// 0.9 >= 0.95 is false (0.0), 0.8 >= 0.7 is true (1.0) → mean = 0.5
+	if math.Abs(probRes.Probability-0.5) > 0.0001 {
+		t.Errorf("expected 0.5, got %f", probRes.Probability)
+	}
+}
+
+func TestParser_AggregateMin(t *testing.T) {
+	baseGraph := buildTestGraph(t)
+	parser := CreateParser(baseGraph)
+
+	res, err := parser.ParseLine("AGGREGATE MIN ( REACHABILITY FROM A TO B EXACT, REACHABILITY FROM A TO C EXACT, REACHABILITY FROM B TO D EXACT )")
+	if err != nil {
+		t.Fatalf("ParseLine failed: %v", err)
+	}
+
+	probRes, ok := res.(result.ProbabilityResult)
+	if !ok {
+		t.Fatalf("expected ProbabilityResult, got %T", res)
+	}
+
+	// Min of 0.9, 0.8, 0.7 = 0.7
+	if math.Abs(probRes.Probability-0.7) > 0.0001 {
+		t.Errorf("expected 0.7, got %f", probRes.Probability)
+	}
+}
+
+func TestParser_AggregateBestPath(t *testing.T) {
+	baseGraph := buildTestGraph(t)
+	parser := CreateParser(baseGraph)
+
+	res, err := parser.ParseLine("AGGREGATE BESTPATH ( MAXPATH FROM A TO D, MAXPATH FROM A TO B )")
+	if err != nil {
+		t.Fatalf("ParseLine failed: %v", err)
+	}
+
+	pathRes, ok := res.(result.PathResult)
+	if !ok {
+		t.Fatalf("expected PathResult, got %T", res)
+	}
+
+	// A→D best path = A→B→D (0.63), A→B = 0.9; best is 0.9
+	if math.Abs(pathRes.Path.Probability-0.9) > 0.0001 {
 		t.Errorf("expected 0.9, got %f", pathRes.Path.Probability)
 	}
 }
@@ -808,6 +1398,50 @@ func TestParser_CreateNodeWithProperties(t *testing.T) {
 	}
 }
 
+func TestParser_CreateNodeWithArrayProperty(t *testing.T) {
+	parser := CreateParser(graph.CreateProbAdjListGraph())
+
+	_, err := parser.ParseLine(`CREATE NODE A { features: [0.1, 0.2, 0.3], tags: ["a", "b"], empty: [] }`)
+	if err != nil {
+		t.Fatalf("ParseLine failed: %v", err)
+	}
+
+	nodes := parser.SessionGraph.GetNodes()
+	if len(nodes) != 1 {
+		t.Fatalf("expected 1 node, got %d", len(nodes))
+	}
+	props := nodes[0].Props
+
+	features := props["features"]
+	if features.Kind != graph.ArrayVal || len(features.A) != 3 {
+		t.Fatalf("expected 3-element array for features, got %+v", features)
+	}
+	if features.A[1].Kind != graph.FloatVal || features.A[1].F != 0.2 {
+		t.Errorf("expected features[1] = 0.2, got %+v", features.A[1])
+	}
+
+	tags := props["tags"]
+	if tags.Kind != graph.ArrayVal || len(tags.A) != 2 || tags.A[0].S != "a" {
+		t.Errorf("expected tags [\"a\", \"b\"], got %+v", tags)
+	}
+
+	if empty := props["empty"]; empty.Kind != graph.ArrayVal || len(empty.A) != 0 {
+		t.Errorf("expected empty array, got %+v", empty)
+	}
+}
+
+func TestParser_CreateNodeWithHeterogeneousArrayRejected(t *testing.T) {
+	parser := CreateParser(graph.CreateProbAdjListGraph())
+
+	_, err := parser.ParseLine(`CREATE NODE A { mixed: [1, "two"] }`)
+	if err == nil {
+		t.Fatal("expected error for array with mixed element types")
+	}
+	if parser.SessionGraph.ContainsNode("A") {
+		t.Error("node A should not be created when its properties are invalid")
+	}
+}
+
 func TestParser_CreateNodeWithBoolFalse(t *testing.T) {
 	baseGraph := graph.CreateProbAdjListGraph()
 	parser := CreateParser(baseGraph)
@@ -935,156 +1569,1253 @@ func TestParser_CreateEdgeWithoutProperties(t *testing.T) {
 	}
 }
 
-func TestParser_PropertyKeywordsCaseInsensitive(t *testing.T) {
+func TestParser_CreateEdgeLogProb(t *testing.T) {
 	baseGraph := graph.CreateProbAdjListGraph()
+	baseGraph.AddNode("A", nil)
+	baseGraph.AddNode("B", nil)
+	baseGraph.AddNode("C", nil)
 	parser := CreateParser(baseGraph)
 
-	_, err := parser.ParseLine(`CREATE NODE n { flag: TRUE, other: FALSE }`)
-	if err != nil {
+	if _, err := parser.ParseLine("CREATE EDGE eAB FROM A TO B LOGPROB -0.105"); err != nil {
 		t.Fatalf("ParseLine failed: %v", err)
 	}
+	edge, err := parser.SessionGraph.GetEdge("A", "B")
+	if err != nil {
+		t.Fatalf("GetEdge failed: %v", err)
+	}
+	if want := math.Exp(-0.105); math.Abs(edge.Probability-want) > 1e-12 {
+		t.Errorf("expected probability %f, got %f", want, edge.Probability)
+	}
 
-	nodes := parser.SessionGraph.GetNodes()
-	var node *graph.Node
-	for _, n := range nodes {
-		if n.ID == "n" {
-			node = n
-			break
-		}
+	if _, err := parser.ParseLine("CREATE EDGE eBC FROM B TO C LOGPROB 0.0"); err != nil {
+		t.Fatalf("ParseLine failed: %v", err)
 	}
-	if node == nil {
-		t.Fatal("could not find node n")
+	if edge, _ := parser.SessionGraph.GetEdge("B", "C"); edge.Probability != 1.0 {
+		t.Errorf("expected LOGPROB 0.0 to give probability 1, got %f", edge.Probability)
 	}
 
-	if v := node.Props["flag"]; v.Kind != graph.BoolVal || !v.B {
-		t.Errorf("expected BoolVal true, got %+v", v)
+	_, err = parser.ParseLine("CREATE EDGE eAC FROM A TO C LOGPROB 0.5")
+	if se, ok := err.(SyntaxError); !ok || se.Kind != "InvalidLogProb" {
+		t.Errorf("expected InvalidLogProb error, got %v", err)
 	}
-	if v := node.Props["other"]; v.Kind != graph.BoolVal || v.B {
-		t.Errorf("expected BoolVal false, got %+v", v)
+	if parser.SessionGraph.ContainsEdgeByID("eAC") {
+		t.Error("edge with positive log-probability should not be created")
 	}
 }
 
-// ── Case sensitivity and identifier tests ───────────────────────────────
-
-func TestParser_NodeNamesCaseSensitive(t *testing.T) {
-	baseGraph := graph.CreateProbAdjListGraph()
-	parser := CreateParser(baseGraph)
+func TestParser_CreateEdgeProbabilityInterval(t *testing.T) {
+	parser := CreateParser(buildTestGraph(t))
 
-	// Create two nodes whose names differ only in case
-	_, err := parser.ParseLine("CREATE NODE NodeA")
-	if err != nil {
+	if _, err := parser.ParseLine("CREATE EDGE eAD FROM A TO D PROB [0.5, 0.7] BIDIRECTIONAL"); err != nil {
 		t.Fatalf("ParseLine failed: %v", err)
 	}
-	_, err = parser.ParseLine("CREATE NODE nodea")
+	for _, id := range []graph.EdgeID{"eAD", "eAD" + graph.ReverseEdgeSuffix} {
+		edge, err := parser.SessionGraph.GetEdgeByID(id)
+		if err != nil {
+			t.Fatalf("GetEdgeByID %s failed: %v", id, err)
+		}
+		if low, high := edge.ProbabilityBounds(); low != 0.5 || high != 0.7 {
+			t.Errorf("%s: expected interval [0.5, 0.7], got [%g, %g]", id, low, high)
+		}
+	}
+
+	res, err := parser.ParseLine("REACHABILITY FROM A TO D EXACT")
 	if err != nil {
 		t.Fatalf("ParseLine failed: %v", err)
 	}
+	interval, ok := res.(result.ProbabilityIntervalResult)
+	if !ok {
+		t.Fatalf("expected ProbabilityIntervalResult, got %T", res)
+	}
+	// The diamond reaches D with 1 - (1 - 0.63)(1 - 0.48); the new edge is
+	// a third, independent route.
+	diamond := 1.0 - (1.0-0.9*0.7)*(1.0-0.8*0.6)
+	wantLow := 1.0 - (1.0-diamond)*(1.0-0.5)
+	wantHigh := 1.0 - (1.0-diamond)*(1.0-0.7)
+	if math.Abs(interval.Low-wantLow) > 1e-9 || math.Abs(interval.High-wantHigh) > 1e-9 {
+		t.Errorf("expected [%f, %f], got [%f, %f]", wantLow, wantHigh, interval.Low, interval.High)
+	}
 
-	if !parser.SessionGraph.ContainsNode("NodeA") {
-		t.Error("node NodeA should exist")
+	for _, input := range []string{
+		"CREATE EDGE eX FROM A TO D PROB [0.9, 0.5]",
+		"CREATE EDGE eX FROM A TO D PROB [0.5, 1.5]",
+		"CREATE EDGE eX FROM A TO D PROB [0.5]",
+	} {
+		if _, err := parser.ParseLine(input); err == nil {
+			t.Errorf("%q: expected error", input)
+		}
 	}
-	if !parser.SessionGraph.ContainsNode("nodea") {
-		t.Error("node nodea should exist")
+}
+
+func TestParser_CreateEdgeInvalidProbability(t *testing.T) {
+	baseGraph := graph.CreateProbAdjListGraph()
+	baseGraph.AddNode("A", nil)
+	baseGraph.AddNode("B", nil)
+	parser := CreateParser(baseGraph)
+
+	for line, want := range map[string]string{
+		"CREATE EDGE e1 FROM A TO B PROB -0.5": "probability must be in [0,1], got -0.5",
+		"CREATE EDGE e1 FROM A TO B PROB 1.5":  "probability must be in [0,1], got 1.5",
+	} {
+		_, err := parser.ParseLine(line)
+		se, ok := err.(SyntaxError)
+		if !ok || se.Kind != "InvalidProbability" {
+			t.Errorf("%q: expected InvalidProbability error, got %v", line, err)
+			continue
+		}
+		if se.Message != want {
+			t.Errorf("%q: expected message %q, got %q", line, want, se.Message)
+		}
+	}
+	if parser.SessionGraph.ContainsEdgeByID("e1") {
+		t.Error("edge with invalid probability should not be created")
 	}
 
-	// They must be distinct nodes
-	nodes := parser.SessionGraph.GetNodes()
-	if len(nodes) != 2 {
-		t.Errorf("expected 2 distinct nodes, got %d", len(nodes))
+	// The bounds themselves are valid.
+	if _, err := parser.ParseLine("CREATE EDGE e1 FROM A TO B PROB 1.0"); err != nil {
+		t.Errorf("PROB 1.0: unexpected error: %v", err)
+	}
+	if _, err := parser.ParseLine("CREATE EDGE e2 FROM B TO A PROB 0.0"); err != nil {
+		t.Errorf("PROB 0.0: unexpected error: %v", err)
 	}
 }
 
-func TestParser_EdgeNamesCaseSensitive(t *testing.T) {
+func TestParser_CreateBidirectionalEdge(t *testing.T) {
 	baseGraph := graph.CreateProbAdjListGraph()
 	baseGraph.AddNode("A", nil)
 	baseGraph.AddNode("B", nil)
-	baseGraph.AddNode("C", nil)
 	parser := CreateParser(baseGraph)
 
-	_, err := parser.ParseLine("CREATE EDGE MyEdge FROM A TO B PROB 0.9")
+	_, err := parser.ParseLine(`CREATE EDGE eAB FROM A TO B PROB 0.8 BIDIRECTIONAL { mode: "road" }`)
 	if err != nil {
 		t.Fatalf("ParseLine failed: %v", err)
 	}
-	_, err = parser.ParseLine("CREATE EDGE myedge FROM A TO C PROB 0.8")
+
+	reverse, err := parser.SessionGraph.GetEdge("B", "A")
 	if err != nil {
-		t.Fatalf("ParseLine failed: %v", err)
+		t.Fatalf("GetEdge(B, A) failed: %v", err)
+	}
+	if reverse.ID != "eAB"+graph.ReverseEdgeSuffix || reverse.Probability != 0.8 {
+		t.Errorf("unexpected reverse edge %+v", reverse)
+	}
+	if v := reverse.Props["mode"]; v.Kind != graph.StringVal || v.S != "road" {
+		t.Errorf("expected reverse edge to share props, got %+v", reverse.Props)
 	}
 
-	edgeAB, err := parser.SessionGraph.GetEdge("A", "B")
+	res, err := parser.ParseLine("REACHABILITY FROM B TO A EXACT")
 	if err != nil {
-		t.Fatalf("GetEdge A->B failed: %v", err)
+		t.Fatalf("ParseLine failed: %v", err)
 	}
-	edgeAC, err := parser.SessionGraph.GetEdge("A", "C")
-	if err != nil {
-		t.Fatalf("GetEdge A->C failed: %v", err)
+	pr, ok := res.(result.ProbabilityResult)
+	if !ok {
+		t.Fatalf("expected ProbabilityResult, got %T", res)
+	}
+	if math.Abs(pr.Probability-0.8) > 0.0001 {
+		t.Errorf("expected reverse reachability 0.8, got %f", pr.Probability)
 	}
 
-	if edgeAB.ID != "MyEdge" {
-		t.Errorf("expected edge ID MyEdge, got %s", edgeAB.ID)
+	if _, err := parser.ParseLine("DELETE EDGE eAB"); err != nil {
+		t.Fatalf("ParseLine failed: %v", err)
 	}
-	if edgeAC.ID != "myedge" {
-		t.Errorf("expected edge ID myedge, got %s", edgeAC.ID)
+	if parser.SessionGraph.ContainsEdge("B", "A") {
+		t.Error("deleting the forward half should remove the reverse half")
 	}
 }
 
-func TestParser_KeywordsCaseInsensitiveInStatements(t *testing.T) {
-	testCases := []struct {
-		name  string
-		input string
-	}{
-		{"lowercase create", "create node X"},
-		{"uppercase CREATE", "CREATE NODE X"},
-		{"mixed case CrEaTe", "CrEaTe NoDe X"},
-	}
-
-	for _, tc := range testCases {
-		t.Run(tc.name, func(t *testing.T) {
-			baseGraph := graph.CreateProbAdjListGraph()
-			parser := CreateParser(baseGraph)
+func TestParser_Transpose(t *testing.T) {
+	parser := CreateParser(buildTestGraph(t))
 
-			_, err := parser.ParseLine(tc.input)
-			if err != nil {
-				t.Fatalf("ParseLine failed for %q: %v", tc.input, err)
-			}
+	if _, err := parser.ParseLine("TRANSPOSE"); err != nil {
+		t.Fatalf("ParseLine failed: %v", err)
+	}
 
-			if !parser.SessionGraph.ContainsNode("X") {
-				t.Errorf("node X should exist after %q", tc.input)
-			}
-		})
+	edge, err := parser.SessionGraph.GetEdge("B", "A")
+	if err != nil {
+		t.Fatalf("expected reversed edge B -> A: %v", err)
+	}
+	if edge.ID != "eAB" || edge.Probability != 0.9 {
+		t.Errorf("unexpected reversed edge %+v", edge)
+	}
+	if parser.SessionGraph.ContainsEdge("A", "B") {
+		t.Error("original direction A -> B should be gone")
 	}
 }
 
-// ── Sensitivity query tests ─────────────────────────────────────────────
-
-func TestParser_SensitivityExact(t *testing.T) {
-	baseGraph := buildTestGraph(t)
-	parser := CreateParser(baseGraph)
-
-	res, err := parser.ParseLine("SENSITIVITY FROM A TO D EXACT")
-	if err != nil {
+func TestParser_ExportImportJSON(t *testing.T) {
+	source := CreateParser(buildTestGraph(t))
+	if _, err := source.ParseLine("CREATE EDGE eDA FROM D TO A PROB 0.5 BIDIRECTIONAL { kind: \"loop\" }"); err != nil {
 		t.Fatalf("ParseLine failed: %v", err)
 	}
 
-	sensRes, ok := res.(result.SensitivityResult)
+	res, err := source.ParseLine("EXPORT JSON")
+	if err != nil {
+		t.Fatalf("EXPORT JSON failed: %v", err)
+	}
+	exported, ok := res.(result.StringResult)
 	if !ok {
-		t.Fatalf("expected SensitivityResult, got %T", res)
+		t.Fatalf("expected StringResult, got %T", res)
 	}
 
-	path1 := 0.9 * 0.7
-	path2 := 0.8 * 0.6
-	wantBaseline := 1.0 - (1.0-path1)*(1.0-path2)
-
-	if math.Abs(sensRes.Baseline-wantBaseline) > 1e-9 {
-		t.Errorf("baseline: want %.10f, got %.10f", wantBaseline, sensRes.Baseline)
-	}
-	if len(sensRes.Impacts) != 4 {
-		t.Errorf("expected 4 impacts, got %d", len(sensRes.Impacts))
+	for name, literal := range map[string]string{
+		"escaped": strconv.Quote(exported.Value),
+		"base64":  strconv.Quote(base64.StdEncoding.EncodeToString([]byte(exported.Value))),
+	} {
+		target := CreateParser(graph.CreateProbAdjListGraph())
+		if _, err := target.ParseLine("IMPORT JSON " + literal); err != nil {
+			t.Fatalf("%s: IMPORT JSON failed: %v", name, err)
+		}
+		if !graph.Equal(source.SessionGraph, target.SessionGraph) {
+			t.Errorf("%s: imported graph differs from the exported one", name)
+		}
 	}
 }
 
-func TestParser_SensitivityDefaultModeIsExact(t *testing.T) {
-	baseGraph := buildTestGraph(t)
-	parser := CreateParser(baseGraph)
+func TestParser_ImportJSONMergesIntoSession(t *testing.T) {
+	parser := CreateParser(buildTestGraph(t))
+
+	if _, err := parser.ParseLine(`IMPORT JSON "{\"nodes\": [{\"id\": \"E\"}], \"edges\": [{\"id\": \"eEE\", \"from\": \"E\", \"to\": \"E\", \"probability\": 0.5}]}"`); err != nil {
+		t.Fatalf("IMPORT JSON failed: %v", err)
+	}
+	if !parser.SessionGraph.ContainsNode("E") || !parser.SessionGraph.ContainsNode("A") {
+		t.Error("expected the imported node alongside the existing ones")
+	}
+
+	// A clash leaves the session graph untouched.
+	version := parser.SessionGraph.Version()
+	_, err := parser.ParseLine(`IMPORT JSON "{\"nodes\": [{\"id\": \"F\"}, {\"id\": \"A\"}], \"edges\": []}"`)
+	var ge graph.GraphError
+	if !errors.As(err, &ge) || ge.Kind != "NodeAlreadyExists" {
+		t.Errorf("expected NodeAlreadyExists error, got %v", err)
+	}
+	if parser.SessionGraph.ContainsNode("F") || parser.SessionGraph.Version() != version {
+		t.Error("expected a failed import to add nothing")
+	}
+
+	for _, input := range []string{
+		`IMPORT JSON "not json"`,
+		`IMPORT JSON "{\"nodes\": [{\"id\": 1}]}"`,
+		`IMPORT JSON nodes`,
+	} {
+		if _, err := parser.ParseLine(input); err == nil {
+			t.Errorf("%q: expected error", input)
+		}
+	}
+}
+
+func TestParser_CreateGroup(t *testing.T) {
+	parser := CreateParser(graph.CreateProbAdjListGraph())
+
+	for _, cmd := range []string{
+		`CREATE GROUP us { currency: "USD", region: "US" }`,
+		`CREATE GROUP empty`,
+		`CREATE NODE A, B GROUP us`,
+		`CREATE NODE C GROUP us { currency: "CAD" }`,
+		`CREATE NODE D`,
+	} {
+		if _, err := parser.ParseLine(cmd); err != nil {
+			t.Fatalf("%q failed: %v", cmd, err)
+		}
+	}
+
+	cases := []struct {
+		input string
+		want  []graph.NodeID
+	}{
+		{`FIND NODES WHERE currency = "USD"`, []graph.NodeID{"A", "B"}},
+		{`FIND NODES WHERE currency = "CAD"`, []graph.NodeID{"C"}},
+		{`FIND NODES WHERE region CONTAINS "U"`, []graph.NodeID{"A", "B", "C"}},
+	}
+	for _, tc := range cases {
+		res, err := parser.ParseLine(tc.input)
+		if err != nil {
+			t.Fatalf("%q failed: %v", tc.input, err)
+		}
+		var got []graph.NodeID
+		for _, n := range res.(result.NodeListResult).Nodes {
+			got = append(got, n.ID)
+		}
+		if !slices.Equal(got, tc.want) {
+			t.Errorf("%q: expected %v, got %v", tc.input, tc.want, got)
+		}
+	}
+
+	// An unknown group adds none of the nodes.
+	_, err := parser.ParseLine(`CREATE NODE E, F GROUP eu`)
+	var ge graph.GraphError
+	if !errors.As(err, &ge) || ge.Kind != "GroupDoesNotExist" {
+		t.Errorf("expected GroupDoesNotExist, got %v", err)
+	}
+	if parser.SessionGraph.ContainsNode("E") {
+		t.Error("expected no node to be added for an unknown group")
+	}
+	if _, err := parser.ParseLine(`CREATE GROUP us`); err == nil {
+		t.Error("expected error for duplicate group")
+	}
+	if _, err := parser.ParseLine(`CREATE GROUP`); err == nil {
+		t.Error("expected error for missing group name")
+	}
+}
+
+func TestParser_ImportJSONGroups(t *testing.T) {
+	parser := CreateParser(buildTestGraph(t))
+
+	input := `IMPORT JSON "{\"groups\": [{\"name\": \"eu\", \"props\": {\"currency\": {\"kind\": \"string\", \"value\": \"EUR\"}}}], \"nodes\": [{\"id\": \"E\", \"group\": \"eu\"}], \"edges\": []}"`
+	if _, err := parser.ParseLine(input); err != nil {
+		t.Fatalf("IMPORT JSON failed: %v", err)
+	}
+	res, err := parser.ParseLine(`FIND NODES WHERE currency = "EUR"`)
+	if err != nil {
+		t.Fatalf("FIND failed: %v", err)
+	}
+	if nodes := res.(result.NodeListResult).Nodes; len(nodes) != 1 || nodes[0].ID != "E" {
+		t.Errorf("expected the imported node to keep its group, got %v", nodes)
+	}
+
+	// Importing the same group again clashes before anything is added.
+	_, err = parser.ParseLine(strings.Replace(input, `\"E\"`, `\"F\"`, 2))
+	var ge graph.GraphError
+	if !errors.As(err, &ge) || ge.Kind != "GroupAlreadyExists" {
+		t.Errorf("expected GroupAlreadyExists, got %v", err)
+	}
+	if parser.SessionGraph.ContainsNode("F") {
+		t.Error("expected a failed import to add nothing")
+	}
+}
+
+func TestParser_SubgraphInducedBy(t *testing.T) {
+	parser := CreateParser(buildTestGraph(t))
+
+	tests := []struct {
+		input string
+		nodes int
+		edges int
+	}{
+		{"SUBGRAPH INDUCED BY A, B", 2, 1},
+		{"SUBGRAPH INDUCED BY ANCESTORS OF C", 2, 1},
+		{"subgraph induced by ancestors of D", 4, 4},
+	}
+
+	for _, tt := range tests {
+		res, err := parser.ParseLine(tt.input)
+		if err != nil {
+			t.Fatalf("%s: ParseLine failed: %v", tt.input, err)
+		}
+		gr, ok := res.(result.GraphResult)
+		if !ok {
+			t.Fatalf("%s: expected GraphResult, got %T", tt.input, res)
+		}
+		if len(gr.Graph.GetNodes()) != tt.nodes || len(gr.Graph.GetEdges()) != tt.edges {
+			t.Errorf("%s: expected %d nodes and %d edges, got %d and %d",
+				tt.input, tt.nodes, tt.edges, len(gr.Graph.GetNodes()), len(gr.Graph.GetEdges()))
+		}
+	}
+
+	if _, err := parser.ParseLine("SUBGRAPH INDUCED BY ANCESTORS OF Z"); err == nil {
+		t.Error("expected error for unknown node")
+	}
+}
+
+func TestParser_HistogramEdges(t *testing.T) {
+	parser := CreateParser(buildTestGraph(t))
+
+	res, err := parser.ParseLine("HISTOGRAM EDGES")
+	if err != nil {
+		t.Fatalf("ParseLine failed: %v", err)
+	}
+	hr, ok := res.(result.HistogramResult)
+	if !ok {
+		t.Fatalf("expected HistogramResult, got %T", res)
+	}
+	if len(hr.Buckets) != 10 {
+		t.Fatalf("expected 10 buckets, got %d", len(hr.Buckets))
+	}
+
+	// Edge probabilities are 0.9, 0.8, 0.7 and 0.6.
+	for i, want := range []int{0, 0, 0, 0, 0, 0, 1, 1, 1, 1} {
+		if hr.Buckets[i].Count != want {
+			t.Errorf("bucket %d: expected count %d, got %d", i, want, hr.Buckets[i].Count)
+		}
+	}
+	if math.Abs(hr.Buckets[6].Low-0.6) > 0.0001 || math.Abs(hr.Buckets[6].High-0.7) > 0.0001 {
+		t.Errorf("unexpected bounds for bucket 6: %+v", hr.Buckets[6])
+	}
+}
+
+func TestParser_CentralityBetweenness(t *testing.T) {
+	parser := CreateParser(buildTestGraph(t))
+
+	res, err := parser.ParseLine("CENTRALITY BETWEENNESS")
+	if err != nil {
+		t.Fatalf("ParseLine failed: %v", err)
+	}
+	sr, ok := res.(result.NodeScoresResult)
+	if !ok {
+		t.Fatalf("expected NodeScoresResult, got %T", res)
+	}
+	if len(sr.Scores) != 4 {
+		t.Fatalf("expected 4 scores, got %d", len(sr.Scores))
+	}
+	if sr.Scores["B"] != sr.Scores["C"] || sr.Scores["B"] <= sr.Scores["A"] {
+		t.Errorf("unexpected scores %v", sr.Scores)
+	}
+	if ranked := sr.Ranked(); ranked[0] != "B" || ranked[1] != "C" {
+		t.Errorf("expected B, C to rank first, got %v", ranked)
+	}
+}
+
+func TestParser_PageRank(t *testing.T) {
+	parser := CreateParser(buildTestGraph(t))
+
+	for _, input := range []string{
+		"PAGERANK",
+		"PAGERANK DAMPING 0.5",
+		"PAGERANK ITERATIONS 20",
+		"pagerank damping 0.9 iterations 10",
+	} {
+		res, err := parser.ParseLine(input)
+		if err != nil {
+			t.Fatalf("%s: ParseLine failed: %v", input, err)
+		}
+		sr, ok := res.(result.NodeScoresResult)
+		if !ok {
+			t.Fatalf("%s: expected NodeScoresResult, got %T", input, res)
+		}
+		total := 0.0
+		for _, s := range sr.Scores {
+			total += s
+		}
+		if math.Abs(total-1.0) > 0.0001 {
+			t.Errorf("%s: expected scores to sum to 1, got %f", input, total)
+		}
+	}
+
+	if _, err := parser.ParseLine("PAGERANK DAMPING 1.5"); err == nil {
+		t.Error("expected error for damping above 1")
+	}
+}
+
+func TestParser_ReliabilityPolynomial(t *testing.T) {
+	parser := CreateParser(buildTestGraph(t))
+
+	res, err := parser.ParseLine("RELIABILITY POLYNOMIAL FROM A TO D")
+	if err != nil {
+		t.Fatalf("ParseLine failed: %v", err)
+	}
+	pr, ok := res.(result.PolynomialResult)
+	if !ok {
+		t.Fatalf("expected PolynomialResult, got %T", res)
+	}
+	if len(pr.Points) != 11 {
+		t.Fatalf("expected 11 points, got %d", len(pr.Points))
+	}
+	if pr.Points[0].Reliability != 0 || math.Abs(pr.Points[10].Reliability-1) > 0.0001 {
+		t.Errorf("expected R(0) = 0 and R(1) = 1, got %f and %f", pr.Points[0].Reliability, pr.Points[10].Reliability)
+	}
+	if math.Abs(pr.Points[5].Reliability-0.4375) > 0.0001 {
+		t.Errorf("expected R(0.5) = 0.4375, got %f", pr.Points[5].Reliability)
+	}
+}
+
+func TestParser_RandomWalk(t *testing.T) {
+	parser := CreateParser(buildTestGraph(t))
+
+	res, err := parser.ParseLine("RANDOMWALK FROM A STEPS 5 SEED 42")
+	if err != nil {
+		t.Fatalf("ParseLine failed: %v", err)
+	}
+	pr, ok := res.(result.PathResult)
+	if !ok {
+		t.Fatalf("expected PathResult, got %T", res)
+	}
+	nodes := pr.Path.NodeIDs
+	if len(nodes) != 3 || nodes[0] != "A" || nodes[2] != "D" {
+		t.Errorf("expected walk A -> B|C -> D, got %v", nodes)
+	}
+
+	again, err := parser.ParseLine("RANDOMWALK FROM A STEPS 5 SEED 42")
+	if err != nil {
+		t.Fatalf("ParseLine failed: %v", err)
+	}
+	if again.String() != res.String() {
+		t.Errorf("same seed gave different walks: %s vs %s", res, again)
+	}
+
+	if _, err := parser.ParseLine("RANDOMWALK FROM A STEPS 2"); err != nil {
+		t.Errorf("SEED should be optional: %v", err)
+	}
+}
+
+func TestParser_ReachabilityBoth(t *testing.T) {
+	parser := CreateParser(buildTestGraph(t))
+
+	res, err := parser.ParseLine("REACHABILITY FROM A TO D BOTH")
+	if err != nil {
+		t.Fatalf("ParseLine failed: %v", err)
+	}
+	cr, ok := res.(result.ComparisonResult)
+	if !ok {
+		t.Fatalf("expected ComparisonResult, got %T", res)
+	}
+	if cr.Deviation > 0.1 {
+		t.Errorf("expected exact and Monte Carlo to agree, deviation %f", cr.Deviation)
+	}
+
+	// BOTH yields a ProbabilisticResult, so it composes with THRESHOLD.
+	if _, err := parser.ParseLine("THRESHOLD 0.5 ( REACHABILITY FROM A TO D BOTH )"); err != nil {
+		t.Errorf("ParseLine failed: %v", err)
+	}
+}
+
+func TestParser_Unreachable(t *testing.T) {
+	g := buildTestGraph(t)
+	for _, id := range []graph.NodeID{"F", "E"} {
+		if err := g.AddNode(id, nil); err != nil {
+			t.Fatalf("AddNode: %v", err)
+		}
+	}
+	// E -> A does not make E reachable from A.
+	if err := g.AddEdge("eEA", "E", "A", 0.5, nil); err != nil {
+		t.Fatalf("AddEdge: %v", err)
+	}
+	parser := CreateParser(g)
+
+	tests := []struct {
+		line string
+		want []graph.NodeID
+	}{
+		{"UNREACHABLE FROM A", []graph.NodeID{"E", "F"}},
+		{"unreachable from D", []graph.NodeID{"A", "B", "C", "E", "F"}},
+		{"UNREACHABLE FROM E", []graph.NodeID{"F"}},
+	}
+	for _, tt := range tests {
+		res, err := parser.ParseLine(tt.line)
+		if err != nil {
+			t.Fatalf("%s: ParseLine failed: %v", tt.line, err)
+		}
+		nl, ok := res.(result.NodeListResult)
+		if !ok {
+			t.Fatalf("%s: expected NodeListResult, got %T", tt.line, res)
+		}
+		var got []graph.NodeID
+		for _, n := range nl.Nodes {
+			got = append(got, n.ID)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.line, got, tt.want)
+		}
+	}
+
+	if _, err := parser.ParseLine("UNREACHABLE FROM Z"); err == nil {
+		t.Error("expected error for unknown root")
+	}
+}
+
+func TestParser_SourceAndSinkNodes(t *testing.T) {
+	parser := CreateParser(buildTestGraph(t))
+
+	tests := []struct {
+		line string
+		want graph.NodeID
+	}{
+		{"SOURCE NODES", "A"},
+		{"sink nodes", "D"},
+	}
+	for _, tt := range tests {
+		res, err := parser.ParseLine(tt.line)
+		if err != nil {
+			t.Fatalf("%s: ParseLine failed: %v", tt.line, err)
+		}
+		nl, ok := res.(result.NodeListResult)
+		if !ok {
+			t.Fatalf("%s: expected NodeListResult, got %T", tt.line, res)
+		}
+		if len(nl.Nodes) != 1 || nl.Nodes[0].ID != tt.want {
+			t.Errorf("%s: got %v, want [%s]", tt.line, nl.Nodes, tt.want)
+		}
+	}
+}
+
+func TestParser_RowSums(t *testing.T) {
+	parser := CreateParser(buildTestGraph(t))
+
+	res, err := parser.ParseLine("ROWSUM A")
+	if err != nil {
+		t.Fatalf("ParseLine failed: %v", err)
+	}
+	pr, ok := res.(result.ProbabilityResult)
+	if !ok {
+		t.Fatalf("expected ProbabilityResult, got %T", res)
+	}
+	if math.Abs(pr.Probability-1.7) > 1e-9 {
+		t.Errorf("expected row sum 1.7, got %f", pr.Probability)
+	}
+
+	// Row sums: A 1.7, B 0.7, C 0.6, D 0.
+	tests := []struct {
+		line string
+		want []graph.NodeID
+	}{
+		{"VALIDATE ROWSUMS", []graph.NodeID{"A", "B", "C", "D"}},
+		{"validate rowsums epsilon 0.35", []graph.NodeID{"A", "C", "D"}},
+		{"VALIDATE ROWSUMS EPSILON 1", nil},
+	}
+	for _, tt := range tests {
+		res, err := parser.ParseLine(tt.line)
+		if err != nil {
+			t.Fatalf("%s: ParseLine failed: %v", tt.line, err)
+		}
+		var ids []graph.NodeID
+		for _, n := range res.(result.NodeListResult).Nodes {
+			ids = append(ids, n.ID)
+		}
+		if !slices.Equal(ids, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.line, ids, tt.want)
+		}
+	}
+
+	for _, line := range []string{"VALIDATE EDGES", "VALIDATE ROWSUMS TOLERANCE 0.1"} {
+		var se SyntaxError
+		if _, err := parser.ParseLine(line); !errors.As(err, &se) || se.Kind != "InvalidSyntax" {
+			t.Errorf("%s: expected InvalidSyntax error, got %v", line, err)
+		}
+	}
+	if _, err := parser.ParseLine("ROWSUM Z"); err == nil {
+		t.Error("expected error for missing node")
+	}
+}
+
+func TestParser_MatrixReachability(t *testing.T) {
+	g := buildTestGraph(t)
+	if err := g.AddGroup("ends", nil); err != nil {
+		t.Fatalf("AddGroup: %v", err)
+	}
+	for _, id := range []graph.NodeID{"C", "D"} {
+		if err := g.SetNodeGroup(id, "ends"); err != nil {
+			t.Fatalf("SetNodeGroup: %v", err)
+		}
+	}
+	parser := CreateParser(g)
+
+	for _, line := range []string{
+		"MATRIX REACHABILITY FROM {A, B} TO GROUP ends EXACT",
+		"matrix reachability from {A, B} to {C, D}",
+	} {
+		res, err := parser.ParseLine(line)
+		if err != nil {
+			t.Fatalf("%s: ParseLine failed: %v", line, err)
+		}
+		m, ok := res.(result.MatrixResult)
+		if !ok {
+			t.Fatalf("%s: expected MatrixResult, got %T", line, res)
+		}
+		if len(m.Values) != 2 || math.Abs(m.Values[0][1]-0.8076) > 0.0001 || m.Values[1][0] != 0 {
+			t.Errorf("%s: unexpected matrix %v", line, m.Values)
+		}
+	}
+
+	res, err := parser.ParseLine("MATRIX REACHABILITY FROM {A} TO GROUP ends MONTECARLO")
+	if err != nil {
+		t.Fatalf("ParseLine failed: %v", err)
+	}
+	if m := res.(result.MatrixResult); math.Abs(m.Values[0][0]-0.8) > 0.05 {
+		t.Errorf("expected a Monte Carlo estimate near 0.8, got %f", m.Values[0][0])
+	}
+
+	for _, line := range []string{
+		"MATRIX REACHABILITY FROM A TO D",
+		"MATRIX REACHABILITY FROM {A} TO {D} BOTH",
+	} {
+		var se SyntaxError
+		if _, err := parser.ParseLine(line); !errors.As(err, &se) {
+			t.Errorf("%s: expected SyntaxError, got %v", line, err)
+		}
+	}
+}
+
+func TestParser_Connected(t *testing.T) {
+	g := buildTestGraph(t)
+	if err := g.AddNode("E", nil); err != nil {
+		t.Fatalf("AddNode: %v", err)
+	}
+	if err := g.AddEdge("eDE", "D", "E", 0.0, nil); err != nil {
+		t.Fatalf("AddEdge: %v", err)
+	}
+	parser := CreateParser(g)
+
+	tests := []struct {
+		line string
+		want bool
+	}{
+		{"CONNECTED FROM A TO D", true},
+		{"CONNECTED FROM D TO A", false},
+		{"connected from A to E", true}, // zero-probability edges still count
+	}
+	for _, tt := range tests {
+		res, err := parser.ParseLine(tt.line)
+		if err != nil {
+			t.Fatalf("%s: ParseLine failed: %v", tt.line, err)
+		}
+		br, ok := res.(result.BooleanResult)
+		if !ok {
+			t.Fatalf("%s: expected BooleanResult, got %T", tt.line, res)
+		}
+		if br.Value != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.line, br.Value, tt.want)
+		}
+	}
+
+	if _, err := parser.ParseLine("CONNECTED FROM A TO Z"); err == nil {
+		t.Error("expected error for unknown node")
+	}
+}
+
+func TestParser_PathProb(t *testing.T) {
+	parser := CreateParser(buildTestGraph(t))
+
+	res, err := parser.ParseLine("PATHPROB A -> B -> D")
+	if err != nil {
+		t.Fatalf("ParseLine failed: %v", err)
+	}
+	pr, ok := res.(result.ProbabilityResult)
+	if !ok {
+		t.Fatalf("expected ProbabilityResult, got %T", res)
+	}
+	if math.Abs(pr.Probability-0.63) > 0.0001 {
+		t.Errorf("expected 0.63, got %f", pr.Probability)
+	}
+
+	// No edge B -> C.
+	_, err = parser.ParseLine("PATHPROB A -> B -> C")
+	var ge graph.GraphError
+	if !errors.As(err, &ge) {
+		t.Errorf("expected GraphError for missing edge, got %v", err)
+	}
+	if _, err := parser.ParseLine("PATHPROB A -> Z"); err == nil {
+		t.Error("expected error for unknown node")
+	}
+	if _, err := parser.ParseLine("PATHPROB A"); err == nil {
+		t.Error("expected syntax error for a single node")
+	}
+}
+
+func TestParser_Confidence(t *testing.T) {
+	parser := CreateParser(buildTestGraph(t))
+
+	res, err := parser.ParseLine("confidence from A to D width 0.02 seed 3")
+	if err != nil {
+		t.Fatalf("ParseLine failed: %v", err)
+	}
+	sr, ok := res.(result.SampleResult)
+	if !ok {
+		t.Fatalf("expected SampleResult, got %T", res)
+	}
+	if sr.CI95High-sr.CI95Low > 0.02 {
+		t.Errorf("CI [%f, %f] wider than 0.02", sr.CI95Low, sr.CI95High)
+	}
+
+	if _, err := parser.ParseLine("CONFIDENCE FROM A TO D WIDTH 0.0"); err == nil {
+		t.Error("expected error for zero width")
+	}
+}
+
+func TestParser_AggregateShortCircuit(t *testing.T) {
+	parser := CreateParser(buildTestGraph(t))
+
+	res, err := parser.ParseLine("AGGREGATE MAX SHORTCIRCUIT ( REACHABILITY FROM A TO A EXACT, REACHABILITY FROM A TO D EXACT )")
+	if err != nil {
+		t.Fatalf("ParseLine failed: %v", err)
+	}
+	if p := res.(result.ProbabilityResult).Probability; p != 1 {
+		t.Errorf("expected 1, got %f", p)
+	}
+
+	res, err = parser.ParseLine("aggregate min shortcircuit ( REACHABILITY FROM A TO B EXACT, REACHABILITY FROM A TO C EXACT )")
+	if err != nil {
+		t.Fatalf("ParseLine failed: %v", err)
+	}
+	if p := res.(result.ProbabilityResult).Probability; math.Abs(p-0.8) > 0.0001 {
+		t.Errorf("expected 0.8, got %f", p)
+	}
+}
+
+func TestParser_AllPaths(t *testing.T) {
+	parser := CreateParser(buildTestGraph(t))
+
+	tests := []struct {
+		line string
+		want int
+	}{
+		{"ALLPATHS FROM A TO D", 2},
+		{"allpaths from A to D max 1", 1},
+		{"ALLPATHS FROM A TO D MAXLEN 1", 0},
+		{"ALLPATHS FROM A TO D MAX 5 MAXLEN 2", 2},
+	}
+	for _, tt := range tests {
+		res, err := parser.ParseLine(tt.line)
+		if err != nil {
+			t.Fatalf("%s: ParseLine failed: %v", tt.line, err)
+		}
+		pr, ok := res.(result.PathsResult)
+		if !ok {
+			t.Fatalf("%s: expected PathsResult, got %T", tt.line, res)
+		}
+		if len(pr.Paths) != tt.want {
+			t.Errorf("%s: got %d paths, want %d", tt.line, len(pr.Paths), tt.want)
+		}
+		if len(pr.Paths) == 2 && pr.Paths[0].Probability < pr.Paths[1].Probability {
+			t.Errorf("%s: paths not sorted by probability: %v", tt.line, pr.Paths)
+		}
+	}
+}
+
+func TestParser_CountPaths(t *testing.T) {
+	parser := CreateParser(buildTestGraph(t))
+
+	tests := []struct {
+		line string
+		want int
+	}{
+		{"COUNTPATHS FROM A TO D", 2},
+		{"countpaths from A to D maxlen 1", 0},
+		{"COUNTPATHS FROM A TO D MAXLEN 2", 2},
+		{"COUNTPATHS FROM D TO A", 0},
+	}
+	for _, tt := range tests {
+		res, err := parser.ParseLine(tt.line)
+		if err != nil {
+			t.Fatalf("%s: ParseLine failed: %v", tt.line, err)
+		}
+		nr, ok := res.(result.NumberResult)
+		if !ok {
+			t.Fatalf("%s: expected NumberResult, got %T", tt.line, res)
+		}
+		if nr.Value != tt.want {
+			t.Errorf("%s: got %d, want %d", tt.line, nr.Value, tt.want)
+		}
+	}
+}
+
+func TestParser_AllPathsStat(t *testing.T) {
+	parser := CreateParser(buildTestGraph(t))
+
+	// Paths: A-B-D 0.63, A-C-D 0.48.
+	tests := []struct {
+		line string
+		want float64
+	}{
+		{"ALLPATHS FROM A TO D STAT MEAN PROB", 0.555},
+		{"ALLPATHS FROM A TO D STAT MEAN", 0.555},
+		{"ALLPATHS FROM A TO D STAT MAX PROB", 0.63},
+		{"ALLPATHS FROM A TO D STAT MIN PROB", 0.48},
+		{"allpaths from A to D stat count", 2},
+		{"ALLPATHS FROM A TO D STAT VARIANCE PROB", 0.075 * 0.075},
+		{"ALLPATHS FROM A TO D STAT MEAN HOPS", 2},
+		{"ALLPATHS FROM D TO A STAT COUNT", 0},
+	}
+	for _, tt := range tests {
+		res, err := parser.ParseLine(tt.line)
+		if err != nil {
+			t.Fatalf("%s: ParseLine failed: %v", tt.line, err)
+		}
+		pr, ok := res.(result.ProbabilityResult)
+		if !ok {
+			t.Fatalf("%s: expected ProbabilityResult, got %T", tt.line, res)
+		}
+		if math.Abs(pr.Probability-tt.want) > 1e-9 {
+			t.Errorf("%s: got %g, want %g", tt.line, pr.Probability, tt.want)
+		}
+	}
+}
+
+func TestParser_AllPathsStatInvalid(t *testing.T) {
+	parser := CreateParser(buildTestGraph(t))
+
+	for line, kind := range map[string]string{
+		"ALLPATHS FROM A TO D STAT MEDIAN":      "InvalidStatistic",
+		"ALLPATHS FROM A TO D STAT MEAN WEIGHT": "InvalidMeasure",
+	} {
+		_, err := parser.ParseLine(line)
+		if se, ok := err.(SyntaxError); !ok || se.Kind != kind {
+			t.Errorf("%s: expected %s error, got %v", line, kind, err)
+		}
+	}
+
+	for _, line := range []string{
+		"ALLPATHS FROM A TO D STAT",
+		"ALLPATHS FROM A TO D STAT MEAN MAX 3",
+		"ALLPATHS FROM A TO D MAX 3 STAT MEAN",
+	} {
+		if _, err := parser.ParseLine(line); err == nil {
+			t.Errorf("%s: expected a syntax error", line)
+		}
+	}
+}
+
+func TestParser_ExpectedHops(t *testing.T) {
+	parser := CreateParser(buildTestGraph(t))
+
+	tests := []struct {
+		line string
+		want float64
+	}{
+		{"EXPECTEDHOPS FROM A TO D", 2},
+		{"expectedhops from A to B", 1},
+		{"EXPECTEDHOPS FROM A TO A", 0},
+	}
+	for _, tt := range tests {
+		res, err := parser.ParseLine(tt.line)
+		if err != nil {
+			t.Fatalf("%s: ParseLine failed: %v", tt.line, err)
+		}
+		pr, ok := res.(result.ProbabilityResult)
+		if !ok {
+			t.Fatalf("%s: expected ProbabilityResult, got %T", tt.line, res)
+		}
+		if pr.Probability != tt.want {
+			t.Errorf("%s: got %g, want %g", tt.line, pr.Probability, tt.want)
+		}
+	}
+
+	if _, err := parser.ParseLine("EXPECTEDHOPS FROM D TO A"); err == nil {
+		t.Error("expected error with no path from D to A")
+	}
+}
+
+func TestParser_TopKMinProb(t *testing.T) {
+	parser := CreateParser(buildTestGraph(t))
+
+	// Paths: A-B-D 0.63, A-C-D 0.48.
+	res, err := parser.ParseLine("TOPK FROM A TO D K 5 MIN_PROB 0.5")
+	if err != nil {
+		t.Fatalf("ParseLine failed: %v", err)
+	}
+	pr, ok := res.(result.PathsResult)
+	if !ok {
+		t.Fatalf("expected PathsResult, got %T", res)
+	}
+	if len(pr.Paths) != 1 || math.Abs(pr.Paths[0].Probability-0.63) > 0.0001 {
+		t.Errorf("expected only the 0.63 path, got %v", pr.Paths)
+	}
+
+	res, err = parser.ParseLine("topk from A to D k 5 min_prob 0.7")
+	if err != nil {
+		t.Fatalf("ParseLine failed: %v", err)
+	}
+	if n := len(res.(result.PathsResult).Paths); n != 0 {
+		t.Errorf("expected no paths above 0.7, got %d", n)
+	}
+
+	_, err = parser.ParseLine("TOPK FROM A TO D K 5 MIN_PROB 1.5")
+	var se SyntaxError
+	if !errors.As(err, &se) || se.Kind != "InvalidMinProb" {
+		t.Errorf("expected InvalidMinProb error, got %v", err)
+	}
+}
+
+func TestParser_TopKMaxHops(t *testing.T) {
+	g := buildTestGraph(t)
+	if err := g.AddEdge("eAD", "A", "D", 0.3, nil); err != nil {
+		t.Fatalf("AddEdge failed: %v", err)
+	}
+	parser := CreateParser(g)
+
+	// Paths: A-B-D 0.63, A-C-D 0.48, A-D 0.3.
+	res, err := parser.ParseLine("TOPK FROM A TO D K 5 MAXHOPS 1")
+	if err != nil {
+		t.Fatalf("ParseLine failed: %v", err)
+	}
+	pr := res.(result.PathsResult)
+	if len(pr.Paths) != 1 || !slices.Equal(pr.Paths[0].NodeIDs, []graph.NodeID{"A", "D"}) {
+		t.Errorf("expected only the direct path A-D, got %v", pr.Paths)
+	}
+
+	for _, input := range []string{"topk from A to D k 5 maxhops 2", "TOPK FROM A TO D K 5 MIN_PROB 0.1 MAXHOPS 2 TIMEOUT 2s"} {
+		res, err := parser.ParseLine(input)
+		if err != nil {
+			t.Fatalf("ParseLine(%q) failed: %v", input, err)
+		}
+		if n := len(res.(result.PathsResult).Paths); n != 3 {
+			t.Errorf("%q: expected 3 paths, got %d", input, n)
+		}
+	}
+
+	_, err = parser.ParseLine("TOPK FROM A TO D K 5 MAXHOPS 0")
+	var se SyntaxError
+	if !errors.As(err, &se) || se.Kind != "InvalidMaxHops" {
+		t.Errorf("expected InvalidMaxHops error, got %v", err)
+	}
+
+	_, err = parser.ParseLine("TOPK FROM A TO D K 5 FOO 3")
+	if !errors.As(err, &se) || se.Kind != "InvalidSyntax" {
+		t.Errorf("expected InvalidSyntax error, got %v", err)
+	}
+}
+
+func TestParser_TopKTimeout(t *testing.T) {
+	parser := CreateParser(buildTestGraph(t))
+
+	for _, input := range []string{"TOPK FROM A TO D K 5 TIMEOUT 2s", "topk from A to D k 5 min_prob 0.1 timeout 500ms"} {
+		res, err := parser.ParseLine(input)
+		if err != nil {
+			t.Fatalf("ParseLine(%q) failed: %v", input, err)
+		}
+		pr := res.(result.PathsResult)
+		if len(pr.Paths) != 2 || pr.Truncated {
+			t.Errorf("%q: expected both paths untruncated, got %d paths, truncated=%v", input, len(pr.Paths), pr.Truncated)
+		}
+	}
+
+	_, err := parser.ParseLine("TOPK FROM A TO D K 5 TIMEOUT 0ms")
+	var se SyntaxError
+	if !errors.As(err, &se) || se.Kind != "InvalidTimeout" {
+		t.Errorf("expected InvalidTimeout error, got %v", err)
+	}
+
+	if _, err := parser.ParseLine("TOPK FROM A TO D K 5 TIMEOUT 500"); err == nil {
+		t.Error("expected an error for a timeout without a unit")
+	}
+}
+
+func TestParser_ReachabilityTimeout(t *testing.T) {
+	parser := CreateParser(buildTestGraph(t))
+
+	for _, input := range []string{
+		"REACHABILITY FROM A TO D EXACT TIMEOUT 2s",
+		"reachability from A to D timeout 500ms",
+		"REACHABILITY FROM {B, C} TO D EXACT TIMEOUT 1m",
+	} {
+		res, err := parser.ParseLine(input)
+		if err != nil {
+			t.Fatalf("ParseLine(%q) failed: %v", input, err)
+		}
+		if _, ok := res.(result.ProbabilityResult); !ok {
+			t.Errorf("%q: expected ProbabilityResult, got %T", input, res)
+		}
+	}
+
+	for _, input := range []string{
+		"REACHABILITY FROM A TO D EXACT TIMEOUT 0s",
+		"REACHABILITY FROM A TO D MONTECARLO TIMEOUT 2s",
+	} {
+		_, err := parser.ParseLine(input)
+		var se SyntaxError
+		if !errors.As(err, &se) || se.Kind != "InvalidTimeout" {
+			t.Errorf("%q: expected InvalidTimeout error, got %v", input, err)
+		}
+	}
+}
+
+func TestParser_Sample(t *testing.T) {
+	parser := CreateParser(buildTestGraph(t))
+
+	res, err := parser.ParseLine("SAMPLE SEED 42")
+	if err != nil {
+		t.Fatalf("ParseLine failed: %v", err)
+	}
+	sw, ok := res.(result.SampledWorldResult)
+	if !ok {
+		t.Fatalf("expected SampledWorldResult, got %T", res)
+	}
+	all := slices.Concat(sw.ActiveEdges, sw.InactiveEdges)
+	slices.Sort(all)
+	if want := []graph.EdgeID{"eAB", "eAC", "eBD", "eCD"}; !slices.Equal(all, want) {
+		t.Errorf("expected every edge exactly once, got %v", all)
+	}
+
+	again, err := parser.ParseLine("sample seed 42")
+	if err != nil {
+		t.Fatalf("ParseLine failed: %v", err)
+	}
+	if again.String() != res.String() {
+		t.Errorf("same seed gave different worlds: %s vs %s", res, again)
+	}
+
+	if _, err := parser.ParseLine("SAMPLE"); err != nil {
+		t.Errorf("SEED should be optional: %v", err)
+	}
+}
+
+func TestParser_Concat(t *testing.T) {
+	parser := CreateParser(buildTestGraph(t))
+
+	res, err := parser.ParseLine("CONCAT ( MAXPATH FROM A TO B, MAXPATH FROM B TO D )")
+	if err != nil {
+		t.Fatalf("ParseLine failed: %v", err)
+	}
+	pr, ok := res.(result.PathResult)
+	if !ok {
+		t.Fatalf("expected PathResult, got %T", res)
+	}
+	want := []graph.NodeID{"A", "B", "D"}
+	if !slices.Equal(pr.Path.NodeIDs, want) {
+		t.Errorf("expected path %v, got %v", want, pr.Path.NodeIDs)
+	}
+	if math.Abs(pr.Path.Probability-0.63) > 0.0001 {
+		t.Errorf("expected probability 0.63, got %f", pr.Path.Probability)
+	}
+
+	_, err = parser.ParseLine("CONCAT ( MAXPATH FROM A TO B, MAXPATH FROM C TO D )")
+	var qe query.QueryError
+	if !errors.As(err, &qe) || qe.Kind != "PathMismatch" {
+		t.Errorf("expected PathMismatch error, got %v", err)
+	}
+
+	_, err = parser.ParseLine("CONCAT ( MAXPATH FROM A TO B, REACHABILITY FROM B TO D EXACT )")
+	if !errors.As(err, &qe) || qe.Kind != "TypeMismatch" {
+		t.Errorf("expected TypeMismatch error, got %v", err)
+	}
+}
+
+func TestParser_PropertyKeywordsCaseInsensitive(t *testing.T) {
+	baseGraph := graph.CreateProbAdjListGraph()
+	parser := CreateParser(baseGraph)
+
+	_, err := parser.ParseLine(`CREATE NODE n { flag: TRUE, other: FALSE }`)
+	if err != nil {
+		t.Fatalf("ParseLine failed: %v", err)
+	}
+
+	nodes := parser.SessionGraph.GetNodes()
+	var node *graph.Node
+	for _, n := range nodes {
+		if n.ID == "n" {
+			node = n
+			break
+		}
+	}
+	if node == nil {
+		t.Fatal("could not find node n")
+	}
+
+	if v := node.Props["flag"]; v.Kind != graph.BoolVal || !v.B {
+		t.Errorf("expected BoolVal true, got %+v", v)
+	}
+	if v := node.Props["other"]; v.Kind != graph.BoolVal || v.B {
+		t.Errorf("expected BoolVal false, got %+v", v)
+	}
+}
+
+// ── Case sensitivity and identifier tests ───────────────────────────────
+
+func TestParser_NodeNamesCaseSensitive(t *testing.T) {
+	baseGraph := graph.CreateProbAdjListGraph()
+	parser := CreateParser(baseGraph)
+
+	// Create two nodes whose names differ only in case
+	_, err := parser.ParseLine("CREATE NODE NodeA")
+	if err != nil {
+		t.Fatalf("ParseLine failed: %v", err)
+	}
+	_, err = parser.ParseLine("CREATE NODE nodea")
+	if err != nil {
+		t.Fatalf("ParseLine failed: %v", err)
+	}
+
+	if !parser.SessionGraph.ContainsNode("NodeA") {
+		t.Error("node NodeA should exist")
+	}
+	if !parser.SessionGraph.ContainsNode("nodea") {
+		t.Error("node nodea should exist")
+	}
+
+	// They must be distinct nodes
+	nodes := parser.SessionGraph.GetNodes()
+	if len(nodes) != 2 {
+		t.Errorf("expected 2 distinct nodes, got %d", len(nodes))
+	}
+}
+
+func TestParser_EdgeNamesCaseSensitive(t *testing.T) {
+	baseGraph := graph.CreateProbAdjListGraph()
+	baseGraph.AddNode("A", nil)
+	baseGraph.AddNode("B", nil)
+	baseGraph.AddNode("C", nil)
+	parser := CreateParser(baseGraph)
+
+	_, err := parser.ParseLine("CREATE EDGE MyEdge FROM A TO B PROB 0.9")
+	if err != nil {
+		t.Fatalf("ParseLine failed: %v", err)
+	}
+	_, err = parser.ParseLine("CREATE EDGE myedge FROM A TO C PROB 0.8")
+	if err != nil {
+		t.Fatalf("ParseLine failed: %v", err)
+	}
+
+	edgeAB, err := parser.SessionGraph.GetEdge("A", "B")
+	if err != nil {
+		t.Fatalf("GetEdge A->B failed: %v", err)
+	}
+	edgeAC, err := parser.SessionGraph.GetEdge("A", "C")
+	if err != nil {
+		t.Fatalf("GetEdge A->C failed: %v", err)
+	}
+
+	if edgeAB.ID != "MyEdge" {
+		t.Errorf("expected edge ID MyEdge, got %s", edgeAB.ID)
+	}
+	if edgeAC.ID != "myedge" {
+		t.Errorf("expected edge ID myedge, got %s", edgeAC.ID)
+	}
+}
+
+func TestParser_KeywordsCaseInsensitiveInStatements(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input string
+	}{
+		{"lowercase create", "create node X"},
+		{"uppercase CREATE", "CREATE NODE X"},
+		{"mixed case CrEaTe", "CrEaTe NoDe X"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			baseGraph := graph.CreateProbAdjListGraph()
+			parser := CreateParser(baseGraph)
+
+			_, err := parser.ParseLine(tc.input)
+			if err != nil {
+				t.Fatalf("ParseLine failed for %q: %v", tc.input, err)
+			}
+
+			if !parser.SessionGraph.ContainsNode("X") {
+				t.Errorf("node X should exist after %q", tc.input)
+			}
+		})
+	}
+}
+
+// ── Sensitivity query tests ─────────────────────────────────────────────
+
+func TestParser_SensitivityExact(t *testing.T) {
+	baseGraph := buildTestGraph(t)
+	parser := CreateParser(baseGraph)
+
+	res, err := parser.ParseLine("SENSITIVITY FROM A TO D EXACT")
+	if err != nil {
+		t.Fatalf("ParseLine failed: %v", err)
+	}
+
+	sensRes, ok := res.(result.SensitivityResult)
+	if !ok {
+		t.Fatalf("expected SensitivityResult, got %T", res)
+	}
+
+	path1 := 0.9 * 0.7
+	path2 := 0.8 * 0.6
+	wantBaseline := 1.0 - (1.0-path1)*(1.0-path2)
+
+	if math.Abs(sensRes.Baseline-wantBaseline) > 1e-9 {
+		t.Errorf("baseline: want %.10f, got %.10f", wantBaseline, sensRes.Baseline)
+	}
+	if len(sensRes.Impacts) != 4 {
+		t.Errorf("expected 4 impacts, got %d", len(sensRes.Impacts))
+	}
+}
+
+func TestParser_SensitivityDefaultModeIsExact(t *testing.T) {
+	baseGraph := buildTestGraph(t)
+	parser := CreateParser(baseGraph)
 
 	res, err := parser.ParseLine("SENSITIVITY FROM A TO D")
 	if err != nil {
@@ -1129,6 +2860,44 @@ func TestParser_SensitivitySortedDescending(t *testing.T) {
 	}
 }
 
+func TestParser_SensitivityMap(t *testing.T) {
+	baseGraph := buildTestGraph(t)
+	parser := CreateParser(baseGraph)
+
+	res, err := parser.ParseLine("SENSITIVITY MAP FROM A TO D")
+	if err != nil {
+		t.Fatalf("ParseLine failed: %v", err)
+	}
+	scoresRes, ok := res.(result.EdgeScoresResult)
+	if !ok {
+		t.Fatalf("expected EdgeScoresResult, got %T", res)
+	}
+	if len(scoresRes.Scores) != 4 {
+		t.Errorf("expected 4 scores, got %d", len(scoresRes.Scores))
+	}
+
+	// Without eAB only A->C->D remains: 0.8 * 0.6.
+	path1 := 0.9 * 0.7
+	path2 := 0.8 * 0.6
+	wantDrop := 1.0 - (1.0-path1)*(1.0-path2) - path2
+	if math.Abs(scoresRes.Scores["eAB"]-wantDrop) > 1e-9 {
+		t.Errorf("eAB drop: want %.10f, got %.10f", wantDrop, scoresRes.Scores["eAB"])
+	}
+
+	// MAP stays usable as a name.
+	if _, err := parser.ParseLine("CREATE NODE map"); err != nil {
+		t.Errorf("CREATE NODE map failed: %v", err)
+	}
+	for _, input := range []string{
+		"SENSITIVITY MAPS FROM A TO D",
+		"SENSITIVITY MAP FROM A TO D MONTECARLO",
+	} {
+		if _, err := parser.ParseLine(input); err == nil {
+			t.Errorf("%q: expected error", input)
+		}
+	}
+}
+
 func TestParser_SensitivityCaseInsensitive(t *testing.T) {
 	baseGraph := buildTestGraph(t)
 	for _, input := range []string{
@@ -1175,12 +2944,12 @@ func TestParser_KeywordsCaseInsensitiveInDelete(t *testing.T) {
 
 func TestParser_InvalidCharactersInNodeName(t *testing.T) {
 	invalidNames := []string{
-		"CREATE NODE node-name",   // hyphen
-		"CREATE NODE node.name",   // dot
-		"CREATE NODE node@name",   // at sign
-		"CREATE NODE node name",   // space (parses as two separate idents)
-		"CREATE NODE 123abc",      // starts with digit
-		"CREATE NODE node!",       // exclamation
+		"CREATE NODE node-name", // hyphen
+		"CREATE NODE node.name", // dot
+		"CREATE NODE node@name", // at sign
+		"CREATE NODE node name", // space (parses as two separate idents)
+		"CREATE NODE 123abc",    // starts with digit
+		"CREATE NODE node!",     // exclamation
 	}
 
 	for _, tc := range invalidNames {
@@ -1250,3 +3019,356 @@ func TestParser_KeywordAsNodeNameRejected(t *testing.T) {
 		})
 	}
 }
+
+func TestParser_ContextualKeywordsAsNames(t *testing.T) {
+	// Modifier words are only keywords in context, so they stay usable as
+	// node and edge IDs, in any case.
+	words := []string{
+		"source", "sink", "group", "in", "neighbors", "of", "do", "stat",
+		"import", "export", "json", "matrix", "script", "width", "steps", "seed",
+	}
+
+	for _, word := range words {
+		for _, id := range []string{word, strings.ToUpper(word)} {
+			t.Run(id, func(t *testing.T) {
+				parser := CreateParser(graph.CreateProbAdjListGraph())
+				for _, line := range []string{
+					"CREATE NODE a, " + id,
+					"CREATE EDGE " + id + " FROM a TO " + id + " PROB 0.5",
+					"REACHABILITY FROM a TO " + id + " EXACT",
+					"FOREACH " + id + " IN NEIGHBORS OF a DO REACHABILITY FROM a TO " + id + " EXACT",
+					"ALLPATHS FROM a TO " + id,
+					"ALLPATHS FROM a TO " + id + " STAT MEAN",
+					"MATRIX REACHABILITY FROM { a } TO { a, " + id + " } EXACT",
+					"CONFIDENCE FROM a TO " + id + " WIDTH 0.5 SEED 1",
+					"RANDOMWALK FROM " + id + " STEPS 2 SEED 1",
+					`AGGREGATE SCRIPT "function reduce(p) return p[1] end" ( REACHABILITY FROM a TO ` + id + ` EXACT )`,
+					"DELETE EDGE " + id,
+					"DELETE NODE " + id,
+					"CREATE GROUP " + id,
+					"CREATE NODE b GROUP " + id,
+				} {
+					if _, err := parser.ParseLine(line); err != nil {
+						t.Errorf("%q: %v", line, err)
+					}
+				}
+			})
+		}
+	}
+}
+
+// --- FIND query tests ---
+
+// buildPropertyGraph creates, through the parser, nodes with region/risk
+// properties and edges with mode/distance properties.
+func buildPropertyGraph(t *testing.T) Parser {
+	t.Helper()
+	parser := CreateParser(graph.CreateProbAdjListGraph())
+
+	commands := []string{
+		`CREATE NODE A { region: "US", risk: 0.9 }`,
+		`CREATE NODE B { region: "EU", risk: 0.2 }`,
+		`CREATE NODE C { region: "US", risk: 0.4 }`,
+		`CREATE NODE D`,
+		`CREATE EDGE eAB FROM A TO B PROB 0.9 { mode: "rail", distance: 500 }`,
+		`CREATE EDGE eBC FROM B TO C PROB 0.9 { mode: "road", distance: 120 }`,
+		`CREATE EDGE eCD FROM C TO D PROB 0.9`,
+	}
+	for _, cmd := range commands {
+		if _, err := parser.ParseLine(cmd); err != nil {
+			t.Fatalf("command %q failed: %v", cmd, err)
+		}
+	}
+	return parser
+}
+
+func TestParser_FindNodesWhere(t *testing.T) {
+	parser := buildPropertyGraph(t)
+
+	cases := []struct {
+		input string
+		want  []graph.NodeID
+	}{
+		{`FIND NODES WHERE region = "US"`, []graph.NodeID{"A", "C"}},
+		{`FIND NODES WHERE region != "US"`, []graph.NodeID{"B"}},
+		{`FIND NODES WHERE risk > 0.5`, []graph.NodeID{"A"}},
+		{`FIND NODES WHERE risk >= 0.4`, []graph.NodeID{"A", "C"}},
+		{`FIND NODES WHERE risk < 0.4`, []graph.NodeID{"B"}},
+		{`find nodes where risk <= 0.4`, []graph.NodeID{"B", "C"}},
+		{`FIND NODES WHERE region = "US" LIMIT 1`, []graph.NodeID{"A"}},
+		{`FIND NODES WHERE region = "US" limit 1 offset 1`, []graph.NodeID{"C"}},
+		{`FIND NODES WHERE region = "US" OFFSET 1 LIMIT 5`, []graph.NodeID{"C"}},
+		{`FIND NODES WHERE region = "US" OFFSET 2`, []graph.NodeID{}},
+		{`FIND NODES WHERE region CONTAINS "U"`, []graph.NodeID{"A", "B", "C"}},
+		{`FIND NODES WHERE region contains "S" LIMIT 1`, []graph.NodeID{"A"}},
+		{`FIND NODES WHERE region MATCHES "^E"`, []graph.NodeID{"B"}},
+		{`FIND NODES WHERE region MATCHES "^[A-Z]S$"`, []graph.NodeID{"A", "C"}},
+		{`FIND NODES WHERE risk CONTAINS "0"`, []graph.NodeID{}},
+	}
+
+	for _, tc := range cases {
+		res, err := parser.ParseLine(tc.input)
+		if err != nil {
+			t.Errorf("ParseLine failed for %q: %v", tc.input, err)
+			continue
+		}
+
+		nodeRes, ok := res.(result.NodeListResult)
+		if !ok {
+			t.Errorf("expected NodeListResult for %q, got %T", tc.input, res)
+			continue
+		}
+
+		if len(nodeRes.Nodes) != len(tc.want) {
+			t.Errorf("%q: expected %d nodes, got %d", tc.input, len(tc.want), len(nodeRes.Nodes))
+			continue
+		}
+		for i, n := range nodeRes.Nodes {
+			if n.ID != tc.want[i] {
+				t.Errorf("%q: expected node %s at position %d, got %s", tc.input, tc.want[i], i, n.ID)
+			}
+		}
+	}
+}
+
+func TestParser_FindNodesNullDistinctFromMissing(t *testing.T) {
+	parser := buildPropertyGraph(t)
+	if _, err := parser.ParseLine(`CREATE NODE E { region: null }`); err != nil {
+		t.Fatalf("CREATE NODE with null property failed: %v", err)
+	}
+
+	res, err := parser.ParseLine(`FIND NODES WHERE region = NULL`)
+	if err != nil {
+		t.Fatalf("ParseLine failed: %v", err)
+	}
+
+	nodes := res.(result.NodeListResult).Nodes
+	// D has no region property and must not match; only E has an explicit null.
+	if len(nodes) != 1 || nodes[0].ID != "E" {
+		t.Errorf("expected only node E, got %v", nodes)
+	}
+}
+
+func TestParser_FindEdgesWhere(t *testing.T) {
+	parser := buildPropertyGraph(t)
+
+	res, err := parser.ParseLine(`FIND EDGES WHERE distance >= 200`)
+	if err != nil {
+		t.Fatalf("ParseLine failed: %v", err)
+	}
+
+	edgeRes, ok := res.(result.EdgeListResult)
+	if !ok {
+		t.Fatalf("expected EdgeListResult, got %T", res)
+	}
+	if len(edgeRes.Edges) != 1 || edgeRes.Edges[0].ID != "eAB" {
+		t.Errorf("expected only eAB, got %v", edgeRes.Edges)
+	}
+
+	res, err = parser.ParseLine(`find edges where mode != "rail"`)
+	if err != nil {
+		t.Fatalf("ParseLine failed: %v", err)
+	}
+	edges := res.(result.EdgeListResult).Edges
+	if len(edges) != 1 || edges[0].ID != "eBC" {
+		t.Errorf("expected only eBC, got %v", edges)
+	}
+}
+
+func TestParser_FindNodesPropertyPath(t *testing.T) {
+	parser := buildPropertyGraph(t)
+	commands := []string{
+		`CREATE NODE E { tags: ["hub", "port"] }`,
+		`CREATE NODE F { tags: ["port"] }`,
+	}
+	for _, cmd := range commands {
+		if _, err := parser.ParseLine(cmd); err != nil {
+			t.Fatalf("command %q failed: %v", cmd, err)
+		}
+	}
+
+	cases := []struct {
+		input string
+		want  []graph.NodeID
+	}{
+		{`FIND NODES WHERE props["region"] = "US"`, []graph.NodeID{"A", "C"}},
+		{`FIND NODES WHERE props["tags"][0] = "port"`, []graph.NodeID{"F"}},
+		{`FIND NODES WHERE tags[1] = "port"`, []graph.NodeID{"E"}},
+		{`FIND NODES WHERE props["region"]["city"] = "NYC"`, []graph.NodeID{}},
+	}
+
+	for _, tc := range cases {
+		res, err := parser.ParseLine(tc.input)
+		if err != nil {
+			t.Errorf("ParseLine failed for %q: %v", tc.input, err)
+			continue
+		}
+
+		nodes := res.(result.NodeListResult).Nodes
+		if len(nodes) != len(tc.want) {
+			t.Errorf("%q: expected %v, got %v", tc.input, tc.want, nodes)
+			continue
+		}
+		for i, n := range nodes {
+			if n.ID != tc.want[i] {
+				t.Errorf("%q: expected node %s at position %d, got %s", tc.input, tc.want[i], i, n.ID)
+			}
+		}
+	}
+}
+
+func TestParser_FindPropertyPathTooDeep(t *testing.T) {
+	parser := buildPropertyGraph(t)
+
+	_, err := parser.ParseLine(`FIND NODES WHERE props["a"]["b"]["c"]["d"] = 1`)
+	var synErr SyntaxError
+	if !errors.As(err, &synErr) || synErr.Kind != "PathTooDeep" {
+		t.Errorf("expected PathTooDeep SyntaxError, got %v", err)
+	}
+
+	if _, err := parser.ParseLine(`FIND NODES WHERE props["a"]["b"]["c"] = 1`); err != nil {
+		t.Errorf("expected three levels to be accepted, got %v", err)
+	}
+}
+
+func TestParser_FindNodesInvalidSyntax(t *testing.T) {
+	parser := buildPropertyGraph(t)
+
+	testCases := []string{
+		`FIND NODES region = "US"`,                       // Missing WHERE
+		`FIND NODES WHERE region "US"`,                   // Missing operator
+		`FIND NODES WHERE region =`,                      // Missing value
+		`FIND NODES WHERE = "US"`,                        // Missing key
+		`FIND EDGES region = "US"`,                       // Missing WHERE
+		`FIND WHERE region = "US"`,                       // Missing NODES/EDGES
+		`FIND NODES WHERE region = "US" LIMIT 0`,         // Non-positive limit
+		`FIND NODES WHERE region = "US" LIMIT`,           // Missing limit
+		`FIND NODES WHERE region = "US" LIMIT 1 LIMIT 2`, // Repeated clause
+		`FIND EDGES WHERE mode = "rail" TOP 3`,           // Unknown clause
+		`FIND NODES WHERE region LIKE "US"`,              // Unknown operator
+		`FIND NODES WHERE region CONTAINS 1`,             // Non-string CONTAINS
+		`FIND NODES WHERE region MATCHES true`,           // Non-string MATCHES
+	}
+
+	for _, tc := range testCases {
+		if _, err := parser.ParseLine(tc); err == nil {
+			t.Errorf("expected error for invalid syntax %q, got nil", tc)
+		}
+	}
+}
+
+func TestParser_FindMatchesInvalidPattern(t *testing.T) {
+	parser := buildPropertyGraph(t)
+
+	_, err := parser.ParseLine(`FIND EDGES WHERE mode MATCHES "rail("`)
+	var se SyntaxError
+	if !errors.As(err, &se) || se.Kind != "InvalidPattern" {
+		t.Errorf("expected InvalidPattern syntax error, got %v", err)
+	}
+}
+
+func TestParser_ForEachNeighbors(t *testing.T) {
+	parser := CreateParser(buildTestGraph(t))
+
+	res, err := parser.ParseLine("FOREACH n IN NEIGHBORS OF A DO REACHABILITY FROM n TO D EXACT")
+	if err != nil {
+		t.Fatalf("ParseLine failed: %v", err)
+	}
+	mr, ok := res.(result.MultiResult)
+	if !ok {
+		t.Fatalf("expected MultiResult, got %T", res)
+	}
+
+	// One result per neighbour of A, in ID order: B (0.7), then C (0.6).
+	want := []float64{0.7, 0.6}
+	if len(mr.Results) != len(want) {
+		t.Fatalf("expected %d results, got %d", len(want), len(mr.Results))
+	}
+	for i, w := range want {
+		p := mr.Results[i].(result.ProbabilityResult).Probability
+		if math.Abs(p-w) > 1e-9 {
+			t.Errorf("result %d: expected %f, got %f", i, w, p)
+		}
+	}
+}
+
+func TestParser_ForEachNestedBody(t *testing.T) {
+	parser := CreateParser(buildTestGraph(t))
+
+	res, err := parser.ParseLine("foreach x in neighbors of A do MULTI ( MAXPATH FROM A TO x, CONNECTED FROM x TO D )")
+	if err != nil {
+		t.Fatalf("ParseLine failed: %v", err)
+	}
+	mr := res.(result.MultiResult)
+	if len(mr.Results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(mr.Results))
+	}
+	inner := mr.Results[1].(result.MultiResult)
+	if got := inner.Results[0].(result.PathResult).Path.NodeIDs; !slices.Equal(got, []graph.NodeID{"A", "C"}) {
+		t.Errorf("expected path A -> C, got %v", got)
+	}
+	if !inner.Results[1].(result.BooleanResult).Value {
+		t.Error("expected C to be connected to D")
+	}
+}
+
+func TestParser_ForEachNoNeighbors(t *testing.T) {
+	parser := CreateParser(buildTestGraph(t))
+
+	res, err := parser.ParseLine("FOREACH n IN NEIGHBORS OF D DO MAXPATH FROM n TO A")
+	if err != nil {
+		t.Fatalf("ParseLine failed: %v", err)
+	}
+	if n := len(res.(result.MultiResult).Results); n != 0 {
+		t.Errorf("expected no results for a sink, got %d", n)
+	}
+}
+
+func TestParser_ForEachErrors(t *testing.T) {
+	parser := CreateParser(buildTestGraph(t))
+
+	testCases := []string{
+		"FOREACH n IN NEIGHBORS OF Z DO MAXPATH FROM n TO D",  // Unknown source node
+		"FOREACH n IN NEIGHBORS OF A MAXPATH FROM n TO D",     // Missing DO
+		"FOREACH n IN NEIGHBORS OF A DO",                      // Missing body
+		"FOREACH n IN NEIGHBORS OF A DO CREATE NODE n",        // Body is not a query
+		"FOREACH n IN NEIGHBORS OF A DO MAXPATH FROM n TO D,", // Trailing token
+	}
+	for _, tc := range testCases {
+		if _, err := parser.ParseLine(tc); err == nil {
+			t.Errorf("expected error for %q, got nil", tc)
+		}
+	}
+}
+
+func TestParser_SyntaxErrorPosition(t *testing.T) {
+	parser := CreateParser(buildTestGraph(t))
+
+	cases := []struct {
+		input  string
+		column int
+		token  string
+	}{
+		{"MAXPATH FROM A TOO D", 16, "TOO"},
+		{"MAXPATH FROM A TO", 18, ""},
+		{"FOREACH n IN NEIGHBORS OF A DO MAXPATH FROM n D", 47, "D"},
+	}
+	for _, tc := range cases {
+		_, err := parser.ParseLine(tc.input)
+		var se SyntaxError
+		if !errors.As(err, &se) {
+			t.Errorf("%q: expected SyntaxError, got %v", tc.input, err)
+			continue
+		}
+		if se.Line != 1 || se.Column != tc.column || se.OffendingToken != tc.token {
+			t.Errorf("%q: expected 1:%d %q, got %d:%d %q", tc.input, tc.column, tc.token, se.Line, se.Column, se.OffendingToken)
+		}
+	}
+
+	// Errors found after parsing carry no position.
+	_, err := parser.ParseLine("TOPK FROM A TO D K 5 MIN_PROB 1.5")
+	var se SyntaxError
+	if !errors.As(err, &se) || se.Line != 0 || se.Column != 0 {
+		t.Errorf("expected a SyntaxError without position, got %+v", err)
+	}
+}
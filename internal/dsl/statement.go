@@ -1,6 +1,8 @@
 package dsl
 
 import (
+	"slices"
+
 	"github.com/ritamzico/pgraph/internal/graph"
 )
 
@@ -8,20 +10,42 @@ type Statement interface {
 	Execute(g graph.ProbabilisticGraphModel) error
 }
 
+// CreateNodeStatement adds each node with Props, attached to Group if it
+// is set. Nothing is added if Group does not exist.
 type CreateNodeStatement struct {
 	NodeIDs []graph.NodeID
 	Props   map[string]graph.Value
+	Group   string
 }
 
 func (s *CreateNodeStatement) Execute(g graph.ProbabilisticGraphModel) error {
+	if s.Group != "" && !slices.ContainsFunc(g.GetGroups(), func(group *graph.NodeGroup) bool {
+		return group.Name == s.Group
+	}) {
+		return graph.GroupDoesNotExist(s.Group)
+	}
 	for _, id := range s.NodeIDs {
 		if err := g.AddNode(id, s.Props); err != nil {
 			return err
 		}
+		if s.Group != "" {
+			if err := g.SetNodeGroup(id, s.Group); err != nil {
+				return err
+			}
+		}
 	}
 	return nil
 }
 
+type CreateGroupStatement struct {
+	Name  string
+	Props map[string]graph.Value
+}
+
+func (s *CreateGroupStatement) Execute(g graph.ProbabilisticGraphModel) error {
+	return g.AddGroup(s.Name, s.Props)
+}
+
 type DeleteNodeStatement struct {
 	NodeIDs []graph.NodeID
 }
@@ -35,22 +59,52 @@ func (s *DeleteNodeStatement) Execute(g graph.ProbabilisticGraphModel) error {
 	return nil
 }
 
+// CreateEdgeStatement adds an edge. If ProbLow or ProbHigh is set, the
+// edge's probability is that interval and Prob is ignored.
 type CreateEdgeStatement struct {
-	EdgeID graph.EdgeID
-	From   graph.NodeID
-	To     graph.NodeID
-	Prob   float64
-	Props  map[string]graph.Value
+	EdgeID            graph.EdgeID
+	From              graph.NodeID
+	To                graph.NodeID
+	Prob              float64
+	ProbLow, ProbHigh float64
+	Props             map[string]graph.Value
+	Bidirectional     bool
 }
 
 func (s *CreateEdgeStatement) Execute(g graph.ProbabilisticGraphModel) error {
-	return g.AddEdge(
-		s.EdgeID,
-		s.From,
-		s.To,
-		s.Prob,
-		s.Props,
-	)
+	edge := &graph.Edge{
+		ID:            s.EdgeID,
+		Probability:   s.Prob,
+		Bidirectional: s.Bidirectional,
+		ProbLow:       s.ProbLow,
+		ProbHigh:      s.ProbHigh,
+	}
+	if edge.HasProbabilityInterval() {
+		edge.Probability = (s.ProbLow + s.ProbHigh) / 2
+	}
+
+	var err error
+	if s.Bidirectional {
+		err = g.AddBidirectionalEdge(
+			s.EdgeID,
+			s.From,
+			s.To,
+			edge.Probability,
+			s.Props,
+		)
+	} else {
+		err = g.AddEdge(
+			s.EdgeID,
+			s.From,
+			s.To,
+			edge.Probability,
+			s.Props,
+		)
+	}
+	if err != nil {
+		return err
+	}
+	return graph.CopyProbabilityInterval(g, edge)
 }
 
 type DeleteEdgeStatement struct {
@@ -69,3 +123,115 @@ type DeleteEdgeByIDStatement struct {
 func (s *DeleteEdgeByIDStatement) Execute(g graph.ProbabilisticGraphModel) error {
 	return g.RemoveEdgeByID(s.EdgeID)
 }
+
+// UpdateEdgeStatement sets an edge's probability, on both halves of a
+// bidirectional edge.
+type UpdateEdgeStatement struct {
+	EdgeID graph.EdgeID
+	Prob   float64
+}
+
+func (s *UpdateEdgeStatement) Execute(g graph.ProbabilisticGraphModel) error {
+	return s.apply(g, g.UpdateEdge)
+}
+
+// apply makes the update through update, which the parser points at its
+// engine so that the incremental cache sees it.
+func (s *UpdateEdgeStatement) apply(g graph.ProbabilisticGraphModel, update func(graph.EdgeID, float64) error) error {
+	e, err := g.GetEdgeByID(s.EdgeID)
+	if err != nil {
+		return err
+	}
+	if err := update(e.ID, s.Prob); err != nil {
+		return err
+	}
+	if e.Bidirectional {
+		return update(e.TwinID(), s.Prob)
+	}
+	return nil
+}
+
+// TransposeStatement reverses every edge of the graph in place. Bidirectional
+// pairs are left untouched since they are already symmetric.
+type TransposeStatement struct{}
+
+func (s *TransposeStatement) Execute(g graph.ProbabilisticGraphModel) error {
+	var edges []*graph.Edge
+	for _, e := range g.GetEdges() {
+		if !e.Bidirectional {
+			edges = append(edges, e)
+		}
+	}
+
+	// Remove everything first so an A→B / B→A pair does not collide.
+	for _, e := range edges {
+		if err := g.RemoveEdgeByID(e.ID); err != nil {
+			return err
+		}
+	}
+	for _, e := range edges {
+		if err := g.AddEdge(e.ID, e.To, e.From, e.Probability, e.Props); err != nil {
+			return err
+		}
+		if err := graph.CopyProbabilityInterval(g, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ImportJSONStatement adds every group, node and edge of Graph to the
+// session graph. Nothing is added if any group name or node or edge ID is
+// already taken.
+type ImportJSONStatement struct {
+	Graph graph.ProbabilisticGraphModel
+}
+
+func (s *ImportJSONStatement) Execute(g graph.ProbabilisticGraphModel) error {
+	nodes := s.Graph.GetNodes()
+	edges := s.Graph.GetEdges()
+	existing := g.GetGroups()
+	for _, group := range s.Graph.GetGroups() {
+		if slices.ContainsFunc(existing, func(other *graph.NodeGroup) bool { return other.Name == group.Name }) {
+			return graph.GroupAlreadyExists(group.Name)
+		}
+	}
+	for _, node := range nodes {
+		if g.ContainsNode(node.ID) {
+			return graph.NodeAlreadyExists(node.ID)
+		}
+	}
+	for _, edge := range edges {
+		if g.ContainsEdgeByID(edge.ID) {
+			return graph.EdgeAlreadyExists(edge.ID)
+		}
+	}
+
+	// Every endpoint is a new node, so no edge can conflict from here on.
+	for _, node := range nodes {
+		if err := g.AddNode(node.ID, node.Props); err != nil {
+			return err
+		}
+	}
+	if err := graph.CopyGroups(g, s.Graph); err != nil {
+		return err
+	}
+	for _, edge := range edges {
+		var err error
+		if edge.IsReverseHalf() {
+			continue
+		}
+		if edge.Bidirectional {
+			err = g.AddBidirectionalEdge(edge.ID, edge.From, edge.To, edge.Probability, edge.Props)
+		} else {
+			err = g.AddEdge(edge.ID, edge.From, edge.To, edge.Probability, edge.Props)
+		}
+		if err != nil {
+			return err
+		}
+		if err := graph.CopyProbabilityInterval(g, edge); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package engine
+
+import (
+	"context"
+	"hash/fnv"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ritamzico/pgraph/internal/query"
+	"github.com/ritamzico/pgraph/internal/result"
+)
+
+// CachedInferenceEngine memoizes Engine's results by query and graph
+// version, so repeating a query on an unchanged graph skips inference. Any
+// mutation of Engine.Graph changes its version and so misses the cache.
+// Failed queries are not cached. Use it through a pointer; the zero value
+// of the cache state is ready to use.
+type CachedInferenceEngine struct {
+	Engine InferenceEngine
+
+	// TTL bounds how long an entry is served. Zero means entries live until
+	// evicted or made stale by a graph mutation.
+	TTL time.Duration
+
+	// MaxEntries caps the cache size; when full, expired entries are dropped
+	// first, then the least recently used. Zero means no limit.
+	MaxEntries int
+
+	Hits   atomic.Uint64
+	Misses atomic.Uint64
+
+	mu      sync.Mutex
+	entries map[uint64]cachedEntry
+	now     func() time.Time // for tests; nil means time.Now
+}
+
+type cachedEntry struct {
+	desc     string // full key, to rule out hash collisions
+	result   result.Result
+	storedAt time.Time
+	lastUsed time.Time
+}
+
+func (c *CachedInferenceEngine) Execute(q query.Query) (result.Result, error) {
+	return c.ExecuteWithContext(context.Background(), q)
+}
+
+func (c *CachedInferenceEngine) ExecuteWithContext(ctx context.Context, q query.Query) (result.Result, error) {
+	key, desc, ok := cacheKey(q, c.Engine.Graph.Version())
+	if !ok {
+		return c.Engine.ExecuteWithContext(ctx, q)
+	}
+	now := c.clock()
+
+	c.mu.Lock()
+	if e, ok := c.entries[key]; ok && e.desc == desc && !c.expired(e, now) {
+		e.lastUsed = now
+		c.entries[key] = e
+		c.mu.Unlock()
+		c.Hits.Add(1)
+		return e.result, nil
+	}
+	c.mu.Unlock()
+	c.Misses.Add(1)
+
+	r, err := c.Engine.ExecuteWithContext(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[uint64]cachedEntry)
+	}
+	if _, ok := c.entries[key]; !ok && c.MaxEntries > 0 && len(c.entries) >= c.MaxEntries {
+		c.evict(now)
+	}
+	c.entries[key] = cachedEntry{desc: desc, result: r, storedAt: now, lastUsed: now}
+	return r, nil
+}
+
+// Len returns the number of cached entries, including expired ones not yet
+// evicted.
+func (c *CachedInferenceEngine) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+// Clear drops every cached entry. The hit and miss counters are kept.
+func (c *CachedInferenceEngine) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = nil
+}
+
+func (c *CachedInferenceEngine) clock() time.Time {
+	if c.now != nil {
+		return c.now()
+	}
+	return time.Now()
+}
+
+func (c *CachedInferenceEngine) expired(e cachedEntry, now time.Time) bool {
+	return c.TTL > 0 && now.Sub(e.storedAt) >= c.TTL
+}
+
+// evict removes every expired entry or, if none has expired, the least
+// recently used one. The caller must hold c.mu.
+func (c *CachedInferenceEngine) evict(now time.Time) {
+	var (
+		lruKey  uint64
+		lruTime time.Time
+		found   bool
+		removed bool
+	)
+	for k, e := range c.entries {
+		if c.expired(e, now) {
+			delete(c.entries, k)
+			removed = true
+			continue
+		}
+		if !found || e.lastUsed.Before(lruTime) {
+			lruKey, lruTime, found = k, e.lastUsed, true
+		}
+	}
+	if !removed && found {
+		delete(c.entries, lruKey)
+	}
+}
+
+// cacheKey hashes the query's canonical form together with the graph
+// version. It also returns the unhashed description for collision checks.
+// It reports false for a query without a canonical form, such as
+// SequentialQuery, whose functions decide what runs.
+func cacheKey(q query.Query, version uint64) (uint64, string, bool) {
+	canonical, ok := query.Canonical(q)
+	if !ok {
+		return 0, "", false
+	}
+	desc := strconv.FormatUint(version, 10) + "|" + canonical
+	h := fnv.New64a()
+	h.Write([]byte(desc))
+	return h.Sum64(), desc, true
+}
@@ -0,0 +1,184 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ritamzico/pgraph/internal/graph"
+	"github.com/ritamzico/pgraph/internal/inference"
+	"github.com/ritamzico/pgraph/internal/query"
+	"github.com/ritamzico/pgraph/internal/result"
+)
+
+// countingQuery counts its executions and returns its label.
+type countingQuery struct {
+	Label string
+	calls *int `canonical:"-"`
+}
+
+func (q countingQuery) Execute(ctx context.Context, g graph.ProbabilisticGraphModel) (result.Result, error) {
+	*q.calls++
+	return result.ProbabilityResult{Probability: float64(len(q.Label))}, nil
+}
+
+func TestCachedEngine_HitsOnUnchangedGraph(t *testing.T) {
+	g := buildSparseGraph(t, 2)
+	c := &CachedInferenceEngine{Engine: InferenceEngine{Graph: g}}
+	calls := 0
+	q := countingQuery{Label: "a", calls: &calls}
+
+	for range 3 {
+		if _, err := c.Execute(q); err != nil {
+			t.Fatalf("Execute: %v", err)
+		}
+	}
+	if calls != 1 || c.Hits.Load() != 2 || c.Misses.Load() != 1 {
+		t.Errorf("calls=%d hits=%d misses=%d, want 1/2/1", calls, c.Hits.Load(), c.Misses.Load())
+	}
+
+	// Different parameters are a different entry.
+	if _, err := c.Execute(countingQuery{Label: "b", calls: &calls}); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("expected a miss for different parameters, calls=%d", calls)
+	}
+}
+
+func TestCachedEngine_MutationInvalidates(t *testing.T) {
+	g := buildSparseGraph(t, 2)
+	c := &CachedInferenceEngine{Engine: InferenceEngine{Graph: g}}
+	q := query.ReachabilityProbabilityQuery{Start: "n0", End: "n1", Mode: query.Exact}
+
+	r, err := c.Execute(q)
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if got := r.(result.ProbabilityResult).Probability; got != 0.5 {
+		t.Fatalf("expected 0.5, got %f", got)
+	}
+
+	if err := g.UpdateEdge("e", 0.9); err != nil {
+		t.Fatalf("UpdateEdge: %v", err)
+	}
+	r, err = c.Execute(q)
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if got := r.(result.ProbabilityResult).Probability; got != 0.9 {
+		t.Errorf("expected stale entry to be bypassed, got %f", got)
+	}
+	if c.Hits.Load() != 0 {
+		t.Errorf("expected no hits, got %d", c.Hits.Load())
+	}
+}
+
+func TestCachedEngine_TTLExpires(t *testing.T) {
+	now := time.Unix(0, 0)
+	c := &CachedInferenceEngine{
+		Engine: InferenceEngine{Graph: buildSparseGraph(t, 2)},
+		TTL:    time.Minute,
+		now:    func() time.Time { return now },
+	}
+	calls := 0
+	q := countingQuery{Label: "a", calls: &calls}
+
+	c.Execute(q)
+	now = now.Add(59 * time.Second)
+	c.Execute(q)
+	now = now.Add(time.Second)
+	c.Execute(q)
+
+	if calls != 2 {
+		t.Errorf("expected entry to expire after TTL, calls=%d", calls)
+	}
+}
+
+func TestCachedEngine_MaxEntriesEvictsLRU(t *testing.T) {
+	now := time.Unix(0, 0)
+	c := &CachedInferenceEngine{
+		Engine:     InferenceEngine{Graph: buildSparseGraph(t, 2)},
+		MaxEntries: 2,
+		now:        func() time.Time { return now },
+	}
+	calls := 0
+	q := func(label string) countingQuery { return countingQuery{Label: label, calls: &calls} }
+
+	for _, label := range []string{"a", "b", "a", "c"} {
+		now = now.Add(time.Second)
+		c.Execute(q(label))
+	}
+	if c.Len() != 2 {
+		t.Fatalf("expected 2 entries, got %d", c.Len())
+	}
+
+	// "b" was least recently used when "c" arrived.
+	before := calls
+	c.Execute(q("a"))
+	c.Execute(q("c"))
+	if calls != before {
+		t.Errorf("expected a and c to be cached")
+	}
+	c.Execute(q("b"))
+	if calls != before+1 {
+		t.Errorf("expected b to have been evicted")
+	}
+}
+
+func TestCachedEngine_KeyIsCanonical(t *testing.T) {
+	c := &CachedInferenceEngine{Engine: InferenceEngine{Graph: buildSparseGraph(t, 2)}}
+	calls := 0
+	nested := func(label string) query.Query {
+		// A fresh pointer each time, to an equal query.
+		return query.NotQuery{Inner: &countingQuery{Label: label, calls: &calls}}
+	}
+
+	for _, q := range []query.Query{nested("a"), nested("a"), nested("b")} {
+		if _, err := c.Execute(q); err != nil {
+			t.Fatalf("Execute: %v", err)
+		}
+	}
+	if calls != 2 || c.Hits.Load() != 1 {
+		t.Errorf("calls=%d hits=%d, want 2/1", calls, c.Hits.Load())
+	}
+
+	// The incremental cache a query carries does not change its result.
+	reach := query.ReachabilityProbabilityQuery{Start: "n0", End: "n1", Mode: query.Exact}
+	withCache := reach
+	withCache.Cache = inference.NewIncrementalReachabilityCache()
+	for _, q := range []query.Query{reach, withCache} {
+		if _, err := c.Execute(q); err != nil {
+			t.Fatalf("Execute: %v", err)
+		}
+	}
+	if c.Hits.Load() != 2 {
+		t.Errorf("expected the Cache field to be ignored, hits=%d", c.Hits.Load())
+	}
+}
+
+func TestCachedEngine_SkipsUncacheableAndErrors(t *testing.T) {
+	c := &CachedInferenceEngine{Engine: InferenceEngine{Graph: buildSparseGraph(t, 2)}}
+	calls := 0
+	seq := query.SequentialQuery{
+		First: countingQuery{Label: "a", calls: &calls},
+		Then: func(result.Result) (query.Query, error) {
+			return countingQuery{Label: "b", calls: &calls}, nil
+		},
+	}
+	c.Execute(seq)
+	c.Execute(seq)
+	if calls != 4 {
+		t.Errorf("expected SequentialQuery to bypass the cache, calls=%d", calls)
+	}
+
+	bad := query.MaxProbabilityPathQuery{Start: "n0", End: "missing"}
+	for range 2 {
+		if _, err := c.Execute(bad); err == nil {
+			t.Fatal("expected error")
+		}
+	}
+	if c.Len() != 0 {
+		t.Errorf("expected errors not to be cached, have %d entries", c.Len())
+	}
+}
@@ -2,20 +2,111 @@ package engine
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/ritamzico/pgraph/internal/graph"
+	"github.com/ritamzico/pgraph/internal/inference"
 	"github.com/ritamzico/pgraph/internal/query"
 	"github.com/ritamzico/pgraph/internal/result"
 )
 
+// InferenceEngine runs queries against Graph. Queries see the graph through
+// a graph.ReadOnlyGraph, so they cannot mutate it.
 type InferenceEngine struct {
 	Graph graph.ProbabilisticGraphModel
+
+	// MinEdgeDensity rejects expensive queries (see query.IsExpensive) on
+	// graphs whose edge density is below it. Zero disables the check.
+	MinEdgeDensity float64
+
+	incremental *inference.IncrementalReachabilityCache
+}
+
+// EnableIncrementalCache makes top-level exact reachability queries reuse
+// earlier results. Updating an edge through UpdateEdge only invalidates
+// results whose computation explored that edge; any other mutation of Graph
+// invalidates them all. Copies of the engine share the cache.
+func (ie *InferenceEngine) EnableIncrementalCache() {
+	if ie.incremental == nil {
+		ie.incremental = inference.NewIncrementalReachabilityCache()
+	}
+}
+
+// WithGraph returns a copy of ie that runs on g. An enabled incremental
+// cache is replaced by an empty one, since its results belong to ie.Graph.
+func (ie InferenceEngine) WithGraph(g graph.ProbabilisticGraphModel) InferenceEngine {
+	ie.Graph = g
+	if ie.incremental != nil {
+		ie.incremental = inference.NewIncrementalReachabilityCache()
+	}
+	return ie
+}
+
+// UpdateEdge sets the probability of edge id in Graph, keeping the
+// incremental cache's unaffected results.
+func (ie *InferenceEngine) UpdateEdge(id graph.EdgeID, prob float64) error {
+	before := ie.Graph.Version()
+	if err := ie.Graph.UpdateEdge(id, prob); err != nil {
+		return err
+	}
+	if ie.incremental != nil {
+		ie.incremental.EdgeUpdated(id, before, ie.Graph.Version())
+	}
+	return nil
 }
 
 func (ie *InferenceEngine) Execute(query query.Query) (result.Result, error) {
-	return query.Execute(context.Background(), ie.Graph)
+	return ie.ExecuteWithContext(context.Background(), query)
+}
+
+// ExecuteWithContext runs q. When built with the pgraph_otel tag, it also
+// records an OpenTelemetry span for the query.
+func (ie *InferenceEngine) ExecuteWithContext(ctx context.Context, q query.Query) (r result.Result, err error) {
+	ctx, span := startQuerySpan(ctx, q, ie.Graph)
+	defer func() { span.end(err) }()
+
+	if err = ie.checkDensity(q); err != nil {
+		return nil, err
+	}
+	if ie.incremental != nil {
+		switch rq := q.(type) {
+		case query.ReachabilityProbabilityQuery:
+			rq.Cache = ie.incremental
+			q = rq
+		case query.MatrixReachabilityQuery:
+			rq.Cache = ie.incremental
+			q = rq
+		}
+	}
+	return q.Execute(ctx, graph.CreateReadOnlyGraph(ie.Graph))
+}
+
+func (ie *InferenceEngine) checkDensity(q query.Query) error {
+	if ie.MinEdgeDensity <= 0 || !query.IsExpensive(q) {
+		return nil
+	}
+
+	density, ok := edgeDensity(ie.Graph)
+	if !ok || density >= ie.MinEdgeDensity {
+		return nil
+	}
+
+	return query.QueryError{
+		Kind: "LowDensityGraph",
+		Message: fmt.Sprintf(
+			"edge density %.4g is below the minimum %.4g; use MONTECARLO or lower the minimum",
+			density, ie.MinEdgeDensity,
+		),
+	}
 }
 
-func (ie *InferenceEngine) ExecuteWithContext(ctx context.Context, query query.Query) (result.Result, error) {
-	return query.Execute(ctx, ie.Graph)
+// edgeDensity returns |E| / (|V| * (|V|-1)), the fraction of possible
+// directed edges present. It reports false for graphs with fewer than two
+// nodes, where density is undefined.
+func edgeDensity(g graph.ProbabilisticGraphModel) (float64, bool) {
+	n := len(g.GetNodes())
+	if n < 2 {
+		return 0, false
+	}
+	return float64(len(g.GetEdges())) / float64(n*(n-1)), true
 }
@@ -0,0 +1,140 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ritamzico/pgraph/internal/graph"
+	"github.com/ritamzico/pgraph/internal/query"
+	"github.com/ritamzico/pgraph/internal/result"
+)
+
+// buildSparseGraph creates nodes n0..n(k-1) with a single edge n0 -> n1.
+func buildSparseGraph(t *testing.T, k int) graph.ProbabilisticGraphModel {
+	t.Helper()
+	g := graph.CreateProbAdjListGraph()
+	for i := range k {
+		if err := g.AddNode(graph.NodeID(fmt.Sprintf("n%d", i)), nil); err != nil {
+			t.Fatalf("failed to add node: %v", err)
+		}
+	}
+	if err := g.AddEdge("e", "n0", "n1", 0.5, nil); err != nil {
+		t.Fatalf("failed to add edge: %v", err)
+	}
+	return g
+}
+
+func TestExecute_RejectsExpensiveQueryOnSparseGraph(t *testing.T) {
+	// 10 nodes, 1 edge: density 1/90.
+	ie := InferenceEngine{Graph: buildSparseGraph(t, 10), MinEdgeDensity: 0.05}
+
+	_, err := ie.Execute(query.ReachabilityProbabilityQuery{Start: "n0", End: "n1", Mode: query.Exact})
+	var qe query.QueryError
+	if !errors.As(err, &qe) || qe.Kind != "LowDensityGraph" {
+		t.Fatalf("expected LowDensityGraph error, got %v", err)
+	}
+
+	// Nested exact inference is rejected too.
+	_, err = ie.Execute(query.MultiQuery{Queries: []query.Query{
+		query.MaxProbabilityPathQuery{Start: "n0", End: "n1"},
+		query.SensitivityQuery{Start: "n0", End: "n1", Mode: query.Exact},
+	}})
+	if !errors.As(err, &qe) || qe.Kind != "LowDensityGraph" {
+		t.Fatalf("expected LowDensityGraph error for nested query, got %v", err)
+	}
+
+	for _, q := range []query.Query{
+		query.PartialMultiQuery{Queries: []query.Query{
+			query.MaxProbabilityPathQuery{Start: "n0", End: "n1"},
+			query.ReachabilityProbabilityQuery{Start: "n0", End: "n1", Mode: query.Exact},
+		}},
+		query.TopKProbabilityValuesQuery{Start: "n0", End: "n1", K: 3},
+		query.ConfidenceQuery{Start: "n0", End: "n1", Width: 0.01, Seed: 1},
+	} {
+		if _, err := ie.Execute(q); !errors.As(err, &qe) || qe.Kind != "LowDensityGraph" {
+			t.Errorf("%T: expected LowDensityGraph error, got %v", q, err)
+		}
+	}
+}
+
+func TestExecute_AllowsCheapOrDenseQueries(t *testing.T) {
+	ie := InferenceEngine{Graph: buildSparseGraph(t, 10), MinEdgeDensity: 0.05}
+
+	if _, err := ie.Execute(query.MaxProbabilityPathQuery{Start: "n0", End: "n1"}); err != nil {
+		t.Errorf("cheap query should run: %v", err)
+	}
+	if _, err := ie.Execute(query.ReachabilityProbabilityQuery{Start: "n0", End: "n1", Mode: query.MonteCarlo, Seed: 1}); err != nil {
+		t.Errorf("Monte Carlo query should run: %v", err)
+	}
+
+	// 2 nodes, 1 edge: density 1/2.
+	ie.Graph = buildSparseGraph(t, 2)
+	if _, err := ie.Execute(query.ReachabilityProbabilityQuery{Start: "n0", End: "n1", Mode: query.Exact}); err != nil {
+		t.Errorf("dense graph should run: %v", err)
+	}
+
+	// Zero disables the check.
+	ie = InferenceEngine{Graph: buildSparseGraph(t, 10)}
+	if _, err := ie.Execute(query.ReachabilityProbabilityQuery{Start: "n0", End: "n1", Mode: query.Exact}); err != nil {
+		t.Errorf("check should be disabled by default: %v", err)
+	}
+}
+
+// mutatingQuery tries to add a node to the graph it is given.
+type mutatingQuery struct{}
+
+func (mutatingQuery) Execute(ctx context.Context, g graph.ProbabilisticGraphModel) (result.Result, error) {
+	return nil, g.AddNode("intruder", nil)
+}
+
+func TestExecute_GraphIsReadOnly(t *testing.T) {
+	g := buildSparseGraph(t, 2)
+	ie := InferenceEngine{Graph: g}
+
+	_, err := ie.Execute(mutatingQuery{})
+	var ge graph.GraphError
+	if !errors.As(err, &ge) || ge.Kind != "ReadOnly" {
+		t.Fatalf("expected ReadOnly error, got %v", err)
+	}
+	if g.ContainsNode("intruder") {
+		t.Error("query should not have mutated the graph")
+	}
+}
+
+func TestEnableIncrementalCache(t *testing.T) {
+	g := buildSparseGraph(t, 3)
+	if err := g.AddEdge("e12", "n1", "n2", 0.5, nil); err != nil {
+		t.Fatalf("failed to add edge: %v", err)
+	}
+	ie := InferenceEngine{Graph: g}
+	ie.EnableIncrementalCache()
+
+	reach := func(start, end graph.NodeID) float64 {
+		t.Helper()
+		r, err := ie.Execute(query.ReachabilityProbabilityQuery{Start: start, End: end, Mode: query.Exact})
+		if err != nil {
+			t.Fatalf("Execute failed: %v", err)
+		}
+		return r.(result.ProbabilityResult).Probability
+	}
+
+	if p := reach("n0", "n2"); p != 0.25 {
+		t.Errorf("expected 0.25, got %f", p)
+	}
+	if n := ie.incremental.Len(); n != 1 {
+		t.Fatalf("expected the result to be cached, got %d entries", n)
+	}
+
+	if err := ie.UpdateEdge("e12", 1); err != nil {
+		t.Fatalf("UpdateEdge failed: %v", err)
+	}
+	if p := reach("n0", "n2"); p != 0.5 {
+		t.Errorf("expected 0.5 after UpdateEdge, got %f", p)
+	}
+
+	if err := ie.UpdateEdge("missing", 0.5); err == nil {
+		t.Error("expected error updating a missing edge")
+	}
+}
@@ -0,0 +1,20 @@
+//go:build !pgraph_otel
+
+package engine
+
+import (
+	"context"
+
+	"github.com/ritamzico/pgraph/internal/graph"
+	"github.com/ritamzico/pgraph/internal/query"
+)
+
+// querySpan is a no-op without the pgraph_otel build tag, so that the
+// OpenTelemetry packages are not linked in.
+type querySpan struct{}
+
+func startQuerySpan(ctx context.Context, _ query.Query, _ graph.ProbabilisticGraphModel) (context.Context, querySpan) {
+	return ctx, querySpan{}
+}
+
+func (querySpan) end(error) {}
@@ -0,0 +1,117 @@
+//go:build pgraph_otel
+
+package engine
+
+import (
+	"context"
+	"reflect"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ritamzico/pgraph/internal/graph"
+	"github.com/ritamzico/pgraph/internal/query"
+)
+
+// tracerName identifies pgraph's spans to the TracerProvider.
+const tracerName = "github.com/ritamzico/pgraph"
+
+// querySpan wraps the span opened for one ExecuteWithContext call.
+type querySpan struct {
+	span trace.Span
+}
+
+// startQuerySpan opens a span named pgraph.query.<QueryType> using the
+// global TracerProvider, which is a no-op until the application registers
+// one with otel.SetTracerProvider.
+func startQuerySpan(ctx context.Context, q query.Query, g graph.ProbabilisticGraphModel) (context.Context, querySpan) {
+	attrs := append(queryAttributes(q),
+		attribute.Int("pgraph.graph.nodes", len(g.GetNodes())),
+		attribute.Int("pgraph.graph.edges", len(g.GetEdges())),
+	)
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "pgraph.query."+queryTypeName(q),
+		trace.WithAttributes(attrs...),
+	)
+	return ctx, querySpan{span: span}
+}
+
+func (s querySpan) end(err error) {
+	if err != nil {
+		s.span.RecordError(err)
+		s.span.SetStatus(codes.Error, err.Error())
+	}
+	s.span.End()
+}
+
+func queryTypeName(q query.Query) string {
+	t := reflect.TypeOf(q)
+	if t == nil {
+		return "nil"
+	}
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Name()
+}
+
+// queryAttributes returns the start and end nodes and inference mode of the
+// queries that have them. Composite queries get none; their subqueries run
+// inside the same span.
+func queryAttributes(q query.Query) []attribute.KeyValue {
+	switch q := q.(type) {
+	case query.MaxProbabilityPathQuery:
+		return endpointAttributes(q.Start, q.End)
+	case query.TopKProbabilityPathsQuery:
+		return endpointAttributes(q.Start, q.End)
+	case query.AllPathsQuery:
+		return endpointAttributes(q.Start, q.End)
+	case query.CountPathsQuery:
+		return endpointAttributes(q.Start, q.End)
+	case query.ExpectedHopsQuery:
+		return endpointAttributes(q.Start, q.End)
+	case query.AllPathsStatQuery:
+		return endpointAttributes(q.Start, q.End)
+	case query.ConnectedQuery:
+		return endpointAttributes(q.Start, q.End)
+	case query.ReliabilityPolynomialQuery:
+		return endpointAttributes(q.Start, q.End)
+	case query.ConfidenceQuery:
+		return append(endpointAttributes(q.Start, q.End), modeAttribute(query.MonteCarlo))
+	case query.ReachabilityProbabilityQuery:
+		return append(endpointAttributes(q.Start, q.End), modeAttribute(q.Mode))
+	case query.SensitivityQuery:
+		return append(endpointAttributes(q.Start, q.End), modeAttribute(q.Mode))
+	case query.SensitivityMapQuery:
+		return endpointAttributes(q.Start, q.End)
+	case query.MultiSourceSinkReachabilityQuery:
+		return []attribute.KeyValue{modeAttribute(q.Mode)}
+	case query.RandomWalkQuery:
+		return []attribute.KeyValue{attribute.String("pgraph.query.start", string(q.Start))}
+	default:
+		return nil
+	}
+}
+
+func endpointAttributes(start, end graph.NodeID) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("pgraph.query.start", string(start)),
+		attribute.String("pgraph.query.end", string(end)),
+	}
+}
+
+func modeAttribute(m query.InferenceMode) attribute.KeyValue {
+	var name string
+	switch m {
+	case query.Exact:
+		name = "exact"
+	case query.MonteCarlo:
+		name = "montecarlo"
+	case query.Both:
+		name = "both"
+	default:
+		name = "unknown"
+	}
+	return attribute.String("pgraph.query.mode", name)
+}
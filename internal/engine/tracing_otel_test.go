@@ -0,0 +1,113 @@
+//go:build pgraph_otel
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/ritamzico/pgraph/internal/query"
+)
+
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	rec := tracetest.NewSpanRecorder()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+	return rec
+}
+
+func spanAttr(attrs []attribute.KeyValue, key attribute.Key) (attribute.Value, bool) {
+	for _, kv := range attrs {
+		if kv.Key == key {
+			return kv.Value, true
+		}
+	}
+	return attribute.Value{}, false
+}
+
+func TestExecute_RecordsQuerySpan(t *testing.T) {
+	rec := recordSpans(t)
+	ie := InferenceEngine{Graph: buildSparseGraph(t, 3)}
+
+	if _, err := ie.Execute(query.ReachabilityProbabilityQuery{Start: "n0", End: "n1", Mode: query.Exact}); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+
+	spans := rec.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	s := spans[0]
+	if s.Name() != "pgraph.query.ReachabilityProbabilityQuery" {
+		t.Errorf("span name = %q", s.Name())
+	}
+	if s.Status().Code == codes.Error {
+		t.Errorf("unexpected error status: %v", s.Status())
+	}
+
+	want := map[attribute.Key]attribute.Value{
+		"pgraph.query.start": attribute.StringValue("n0"),
+		"pgraph.query.end":   attribute.StringValue("n1"),
+		"pgraph.query.mode":  attribute.StringValue("exact"),
+		"pgraph.graph.nodes": attribute.IntValue(3),
+		"pgraph.graph.edges": attribute.IntValue(1),
+	}
+	for key, v := range want {
+		got, ok := spanAttr(s.Attributes(), key)
+		if !ok || got != v {
+			t.Errorf("%s = %v (present %v), want %v", key, got.Emit(), ok, v.Emit())
+		}
+	}
+}
+
+func TestExecute_SpanRecordsError(t *testing.T) {
+	rec := recordSpans(t)
+	ie := InferenceEngine{Graph: buildSparseGraph(t, 10), MinEdgeDensity: 0.05}
+
+	_, err := ie.Execute(query.ReachabilityProbabilityQuery{Start: "n0", End: "n1", Mode: query.Exact})
+	var qe query.QueryError
+	if !errors.As(err, &qe) {
+		t.Fatalf("expected QueryError, got %v", err)
+	}
+
+	spans := rec.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	if spans[0].Status().Code != codes.Error {
+		t.Errorf("status = %v, want Error", spans[0].Status())
+	}
+	if len(spans[0].Events()) == 0 {
+		t.Error("expected the error to be recorded as a span event")
+	}
+}
+
+func TestExecute_SpanIsChildOfCallerSpan(t *testing.T) {
+	rec := recordSpans(t)
+	ie := InferenceEngine{Graph: buildSparseGraph(t, 3)}
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "request")
+	if _, err := ie.ExecuteWithContext(ctx, query.MaxProbabilityPathQuery{Start: "n0", End: "n1"}); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	parent.End()
+
+	for _, s := range rec.Ended() {
+		if s.Name() == "pgraph.query.MaxProbabilityPathQuery" {
+			if s.Parent().SpanID() != parent.SpanContext().SpanID() {
+				t.Error("query span is not a child of the caller's span")
+			}
+			return
+		}
+	}
+	t.Fatal("query span not recorded")
+}
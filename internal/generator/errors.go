@@ -0,0 +1,12 @@
+package generator
+
+import "fmt"
+
+type GeneratorError struct {
+	Kind    string
+	Message string
+}
+
+func (e GeneratorError) Error() string {
+	return fmt.Sprintf("generator error (%v): %v", e.Kind, e.Message)
+}
@@ -0,0 +1,105 @@
+// Package generator builds random graphs for experimenting with pgraph.
+// Nodes are named n0, n1, ... and edges e0, e1, ... in creation order, and
+// every edge gets a probability drawn uniformly from [MinEdgeProb, 1.0], so
+// a given rng seed always yields the same graph.
+package generator
+
+import (
+	"fmt"
+	"math/rand/v2"
+
+	"github.com/ritamzico/pgraph/internal/graph"
+)
+
+// MinEdgeProb is the lower bound of generated edge probabilities.
+const MinEdgeProb = 0.5
+
+// ErdosRenyi returns a directed G(n, p) graph: each of the n*(n-1) ordered
+// pairs of distinct nodes is joined by an edge independently with
+// probability p.
+func ErdosRenyi(n int, p float64, rng *rand.Rand) (*graph.ProbabilisticAdjacencyListGraph, error) {
+	if n < 0 {
+		return nil, invalidParameter("node count must be non-negative, got %d", n)
+	}
+	if p < 0 || p > 1 {
+		return nil, invalidParameter("edge probability must be in [0, 1], got %g", p)
+	}
+
+	b := newBuilder(n)
+	for from := range n {
+		for to := range n {
+			if from != to && rng.Float64() < p {
+				b.addEdge(from, to, rng)
+			}
+		}
+	}
+	return b.g, nil
+}
+
+// ScaleFree returns a Barabási–Albert preferential attachment graph. It
+// starts from m nodes with no edges; each later node adds edges to m
+// distinct earlier nodes, chosen with probability proportional to their
+// degree plus one so that the edgeless starting nodes can be picked. Edges
+// point from the newer node to the older one.
+func ScaleFree(n, m int, rng *rand.Rand) (*graph.ProbabilisticAdjacencyListGraph, error) {
+	if n < 0 {
+		return nil, invalidParameter("node count must be non-negative, got %d", n)
+	}
+	if m < 1 {
+		return nil, invalidParameter("edges per node must be positive, got %d", m)
+	}
+
+	b := newBuilder(n)
+	// Each node appears once, plus once per edge it is an endpoint of, so
+	// a uniform pick from targets is a degree-weighted pick of a node.
+	var targets []int
+	for node := range n {
+		if node >= m {
+			chosen := make(map[int]bool, m)
+			for len(chosen) < m {
+				chosen[targets[rng.IntN(len(targets))]] = true
+			}
+			// Add edges in node order, not map order, to stay reproducible.
+			for to := range node {
+				if chosen[to] {
+					b.addEdge(node, to, rng)
+					targets = append(targets, to, node)
+				}
+			}
+		}
+		targets = append(targets, node)
+	}
+	return b.g, nil
+}
+
+// builder adds numbered nodes and edges to a new graph.
+type builder struct {
+	g     *graph.ProbabilisticAdjacencyListGraph
+	edges int
+}
+
+func newBuilder(n int) *builder {
+	b := &builder{g: graph.CreateProbAdjListGraph()}
+	for i := range n {
+		// IDs are unique, so AddNode cannot fail.
+		_ = b.g.AddNode(nodeID(i), nil)
+	}
+	return b
+}
+
+func (b *builder) addEdge(from, to int, rng *rand.Rand) {
+	prob := MinEdgeProb + rng.Float64()*(1-MinEdgeProb)
+	_ = b.g.AddEdge(graph.EdgeID(fmt.Sprintf("e%d", b.edges)), nodeID(from), nodeID(to), prob, nil)
+	b.edges++
+}
+
+func nodeID(i int) graph.NodeID {
+	return graph.NodeID(fmt.Sprintf("n%d", i))
+}
+
+func invalidParameter(format string, args ...any) error {
+	return GeneratorError{
+		Kind:    "InvalidParameter",
+		Message: fmt.Sprintf(format, args...),
+	}
+}
@@ -0,0 +1,148 @@
+package generator
+
+import (
+	"errors"
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/ritamzico/pgraph/internal/graph"
+)
+
+func seeded(seed uint64) *rand.Rand {
+	return rand.New(rand.NewPCG(seed, seed))
+}
+
+func edgeProbs(g graph.ProbabilisticGraphModel) map[graph.EdgeID]float64 {
+	probs := make(map[graph.EdgeID]float64)
+	for _, e := range g.GetEdges() {
+		probs[e.ID] = e.Probability
+	}
+	return probs
+}
+
+func checkEdgeProbs(t *testing.T, g graph.ProbabilisticGraphModel) {
+	t.Helper()
+	for _, e := range g.GetEdges() {
+		if e.Probability < MinEdgeProb || e.Probability > 1 {
+			t.Errorf("edge %s: probability %f outside [%g, 1]", e.ID, e.Probability, MinEdgeProb)
+		}
+		if e.From == e.To {
+			t.Errorf("edge %s is a self-loop", e.ID)
+		}
+	}
+}
+
+func TestErdosRenyi(t *testing.T) {
+	g, err := ErdosRenyi(30, 0.2, seeded(1))
+	if err != nil {
+		t.Fatalf("ErdosRenyi failed: %v", err)
+	}
+	if n := len(g.GetNodes()); n != 30 {
+		t.Errorf("expected 30 nodes, got %d", n)
+	}
+	// 870 ordered pairs at p = 0.2 gives 174 edges on average.
+	if m := len(g.GetEdges()); m < 120 || m > 230 {
+		t.Errorf("expected about 174 edges, got %d", m)
+	}
+	checkEdgeProbs(t, g)
+}
+
+func TestErdosRenyi_Extremes(t *testing.T) {
+	empty, err := ErdosRenyi(10, 0, seeded(1))
+	if err != nil {
+		t.Fatalf("ErdosRenyi failed: %v", err)
+	}
+	if m := len(empty.GetEdges()); m != 0 {
+		t.Errorf("p = 0: expected no edges, got %d", m)
+	}
+
+	complete, err := ErdosRenyi(10, 1, seeded(1))
+	if err != nil {
+		t.Fatalf("ErdosRenyi failed: %v", err)
+	}
+	if m := len(complete.GetEdges()); m != 90 {
+		t.Errorf("p = 1: expected 90 edges, got %d", m)
+	}
+}
+
+func TestErdosRenyi_SeedReproducible(t *testing.T) {
+	a, _ := ErdosRenyi(20, 0.3, seeded(7))
+	b, _ := ErdosRenyi(20, 0.3, seeded(7))
+	pa, pb := edgeProbs(a), edgeProbs(b)
+	if len(pa) != len(pb) {
+		t.Fatalf("same seed gave %d and %d edges", len(pa), len(pb))
+	}
+	for id, p := range pa {
+		if pb[id] != p {
+			t.Errorf("edge %s: same seed gave probabilities %f and %f", id, p, pb[id])
+		}
+	}
+}
+
+func TestScaleFree(t *testing.T) {
+	const n, m = 50, 2
+	g, err := ScaleFree(n, m, seeded(3))
+	if err != nil {
+		t.Fatalf("ScaleFree failed: %v", err)
+	}
+	if got := len(g.GetNodes()); got != n {
+		t.Errorf("expected %d nodes, got %d", n, got)
+	}
+	if got := len(g.GetEdges()); got != (n-m)*m {
+		t.Errorf("expected %d edges, got %d", (n-m)*m, got)
+	}
+	checkEdgeProbs(t, g)
+
+	// Every node after the first m attaches to exactly m older nodes.
+	for i := m; i < n; i++ {
+		out, err := g.OutgoingEdges(nodeID(i))
+		if err != nil {
+			t.Fatalf("OutgoingEdges failed: %v", err)
+		}
+		if len(out) != m {
+			t.Errorf("node %s: expected %d outgoing edges, got %d", nodeID(i), m, len(out))
+		}
+	}
+
+	// Preferential attachment makes the oldest nodes hubs.
+	in0, _ := g.IncomingEdges("n0")
+	inLast, _ := g.IncomingEdges(nodeID(n - 1))
+	if len(in0) <= len(inLast) {
+		t.Errorf("expected n0 to have more incoming edges than %s, got %d and %d", nodeID(n-1), len(in0), len(inLast))
+	}
+}
+
+func TestGenerator_InvalidParameters(t *testing.T) {
+	tests := []struct {
+		name string
+		gen  func() error
+	}{
+		{"negative nodes", func() error { _, err := ErdosRenyi(-1, 0.5, seeded(1)); return err }},
+		{"edge prob above one", func() error { _, err := ErdosRenyi(5, 1.5, seeded(1)); return err }},
+		{"negative edge prob", func() error { _, err := ErdosRenyi(5, -0.1, seeded(1)); return err }},
+		{"scale-free negative nodes", func() error { _, err := ScaleFree(-1, 2, seeded(1)); return err }},
+		{"scale-free zero m", func() error { _, err := ScaleFree(5, 0, seeded(1)); return err }},
+	}
+	for _, tt := range tests {
+		var ge GeneratorError
+		if err := tt.gen(); !errors.As(err, &ge) || ge.Kind != "InvalidParameter" {
+			t.Errorf("%s: expected InvalidParameter error, got %v", tt.name, err)
+		}
+	}
+}
+
+func TestScaleFree_FewerNodesThanM(t *testing.T) {
+	g, err := ScaleFree(2, 3, seeded(1))
+	if err != nil {
+		t.Fatalf("ScaleFree failed: %v", err)
+	}
+	ids := make([]graph.NodeID, 0)
+	for _, node := range g.GetNodes() {
+		ids = append(ids, node.ID)
+	}
+	slices.Sort(ids)
+	if !slices.Equal(ids, []graph.NodeID{"n0", "n1"}) || len(g.GetEdges()) != 0 {
+		t.Errorf("expected nodes n0, n1 and no edges, got %v and %d edges", ids, len(g.GetEdges()))
+	}
+}
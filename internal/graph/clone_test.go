@@ -1,6 +1,9 @@
 package graph
 
-import "testing"
+import (
+	"fmt"
+	"testing"
+)
 
 func TestCloneWithEdges(t *testing.T) {
 	g := CreateProbAdjListGraph()
@@ -26,3 +29,294 @@ func TestCloneWithEdges(t *testing.T) {
 		t.Errorf("RemoveNode failed: %v", err)
 	}
 }
+
+func TestRemoveNode_ClearsNeighbourAdjacency(t *testing.T) {
+	g := CreateProbAdjListGraph()
+	for _, id := range []NodeID{"A", "B", "C"} {
+		g.AddNode(id, nil)
+	}
+	g.AddEdge("eAB", "A", "B", 0.9, nil)
+	g.AddEdge("eBC", "B", "C", 0.8, nil)
+	g.AddEdge("eBB", "B", "B", 0.5, nil)
+
+	if err := g.RemoveNode("B"); err != nil {
+		t.Fatalf("RemoveNode failed: %v", err)
+	}
+	if out, _ := g.OutgoingEdges("A"); len(out) != 0 {
+		t.Errorf("expected no edges out of A, got %d", len(out))
+	}
+	if in, _ := g.IncomingEdges("C"); len(in) != 0 {
+		t.Errorf("expected no edges into C, got %d", len(in))
+	}
+	if n := len(g.GetEdges()); n != 0 {
+		t.Errorf("expected no edges left, got %d", n)
+	}
+}
+
+func TestUpdateEdge(t *testing.T) {
+	g := CreateProbAdjListGraph()
+	g.AddNode("A", nil)
+	g.AddNode("B", nil)
+	g.AddEdge("eAB", "A", "B", 0.9, nil)
+
+	if err := g.UpdateEdge("eAB", 0.4); err != nil {
+		t.Fatalf("UpdateEdge failed: %v", err)
+	}
+
+	edge, _ := g.GetEdge("A", "B")
+	if edge.Probability != 0.4 {
+		t.Errorf("expected probability 0.4, got %f", edge.Probability)
+	}
+
+	if err := g.UpdateEdge("eAB", 1.2); err == nil {
+		t.Error("expected error for probability above 1")
+	}
+	if err := g.UpdateEdge("missing", 0.5); err == nil {
+		t.Error("expected error for nonexistent edge")
+	}
+}
+
+func TestApplyConditionEdgeProbabilitiesDoesNotMutateOriginal(t *testing.T) {
+	g := CreateProbAdjListGraph()
+	g.AddNode("A", nil)
+	g.AddNode("B", nil)
+	g.AddEdge("eAB", "A", "B", 0.9, nil)
+
+	conditioned, err := g.ApplyCondition(Condition{
+		EdgeProbabilities: map[EdgeID]float64{"eAB": 0.1},
+	})
+	if err != nil {
+		t.Fatalf("ApplyCondition failed: %v", err)
+	}
+
+	clonedEdge, _ := conditioned.GetEdge("A", "B")
+	if clonedEdge.Probability != 0.1 {
+		t.Errorf("expected conditioned probability 0.1, got %f", clonedEdge.Probability)
+	}
+
+	originalEdge, _ := g.GetEdge("A", "B")
+	if originalEdge.Probability != 0.9 {
+		t.Errorf("expected original probability 0.9, got %f", originalEdge.Probability)
+	}
+}
+
+func TestAddBidirectionalEdge(t *testing.T) {
+	g := CreateProbAdjListGraph()
+	g.AddNode("A", nil)
+	g.AddNode("B", nil)
+
+	if err := g.AddBidirectionalEdge("eAB", "A", "B", 0.7, nil); err != nil {
+		t.Fatalf("AddBidirectionalEdge failed: %v", err)
+	}
+
+	forward, err := g.GetEdge("A", "B")
+	if err != nil {
+		t.Fatalf("GetEdge(A, B) failed: %v", err)
+	}
+	reverse, err := g.GetEdge("B", "A")
+	if err != nil {
+		t.Fatalf("GetEdge(B, A) failed: %v", err)
+	}
+	if forward.ID != "eAB" || reverse.ID != "eAB"+ReverseEdgeSuffix {
+		t.Errorf("unexpected edge IDs %s, %s", forward.ID, reverse.ID)
+	}
+	if reverse.Probability != 0.7 {
+		t.Errorf("expected reverse probability 0.7, got %f", reverse.Probability)
+	}
+	if !g.ContainsBidirectionalEdge("eAB") || !g.ContainsBidirectionalEdge("eAB"+ReverseEdgeSuffix) {
+		t.Error("expected both halves to be reported as bidirectional")
+	}
+}
+
+func TestAddBidirectionalEdgeRollsBackOnConflict(t *testing.T) {
+	g := CreateProbAdjListGraph()
+	g.AddNode("A", nil)
+	g.AddNode("B", nil)
+	g.AddEdge("eBA", "B", "A", 0.5, nil)
+
+	if err := g.AddBidirectionalEdge("eAB", "A", "B", 0.7, nil); err == nil {
+		t.Fatal("expected error when reverse direction already has an edge")
+	}
+	if g.ContainsEdgeByID("eAB") {
+		t.Error("forward half should have been rolled back")
+	}
+}
+
+func TestApplyConditionEdgeProbabilityBidirectional(t *testing.T) {
+	g := CreateProbAdjListGraph()
+	g.AddNode("A", nil)
+	g.AddNode("B", nil)
+	g.AddBidirectionalEdge("eAB", "A", "B", 0.7, nil)
+
+	for _, id := range []EdgeID{"eAB", "eAB" + ReverseEdgeSuffix} {
+		conditioned, err := g.ApplyCondition(Condition{
+			EdgeProbabilities: map[EdgeID]float64{id: 0.2},
+		})
+		if err != nil {
+			t.Fatalf("ApplyCondition(%s) failed: %v", id, err)
+		}
+		forward, _ := conditioned.GetEdge("A", "B")
+		reverse, _ := conditioned.GetEdge("B", "A")
+		if forward.Probability != 0.2 || reverse.Probability != 0.2 {
+			t.Errorf("conditioning %s: expected both halves at 0.2, got %f and %f", id, forward.Probability, reverse.Probability)
+		}
+	}
+}
+
+func TestAddEdgeRejectsReverseSuffix(t *testing.T) {
+	g := CreateProbAdjListGraph()
+	g.AddNode("A", nil)
+	g.AddNode("B", nil)
+	g.AddNode("C", nil)
+	g.AddBidirectionalEdge("eAB", "A", "B", 0.7, nil)
+
+	for _, id := range []EdgeID{"eAB" + ReverseEdgeSuffix, "eBC" + ReverseEdgeSuffix} {
+		err := g.AddEdge(id, "B", "C", 0.5, nil)
+		if ge, ok := err.(GraphError); !ok || ge.Kind != "ReservedEdgeID" {
+			t.Errorf("AddEdge(%s): expected ReservedEdgeID, got %v", id, err)
+		}
+	}
+	err := g.AddBidirectionalEdge("eBC"+ReverseEdgeSuffix, "B", "C", 0.5, nil)
+	if ge, ok := err.(GraphError); !ok || ge.Kind != "ReservedEdgeID" {
+		t.Errorf("AddBidirectionalEdge: expected ReservedEdgeID, got %v", err)
+	}
+	if g.ContainsEdge("B", "C") || g.ContainsEdge("C", "B") {
+		t.Error("expected no edge between B and C")
+	}
+
+	forward, _ := g.GetEdge("A", "B")
+	reverse, _ := g.GetEdge("B", "A")
+	if forward.IsReverseHalf() || !reverse.IsReverseHalf() {
+		t.Errorf("IsReverseHalf: forward %v, reverse %v", forward.IsReverseHalf(), reverse.IsReverseHalf())
+	}
+}
+
+func TestRemoveBidirectionalEdgeRemovesBothHalves(t *testing.T) {
+	g := CreateProbAdjListGraph()
+	g.AddNode("A", nil)
+	g.AddNode("B", nil)
+	g.AddBidirectionalEdge("eAB", "A", "B", 0.7, nil)
+
+	if err := g.RemoveEdge("B", "A"); err != nil {
+		t.Fatalf("RemoveEdge failed: %v", err)
+	}
+	if g.ContainsEdge("A", "B") || g.ContainsEdge("B", "A") {
+		t.Error("expected both halves to be removed")
+	}
+	if len(g.GetEdges()) != 0 {
+		t.Errorf("expected no edges, got %d", len(g.GetEdges()))
+	}
+}
+
+func TestApplyConditionInactiveBidirectionalEdge(t *testing.T) {
+	g := CreateProbAdjListGraph()
+	g.AddNode("A", nil)
+	g.AddNode("B", nil)
+	g.AddBidirectionalEdge("eAB", "A", "B", 0.7, nil)
+
+	edge, _ := g.GetEdge("A", "B")
+	conditioned, err := g.ApplyCondition(Condition{
+		ForcedInactiveEdges: []*Edge{edge},
+	})
+	if err != nil {
+		t.Fatalf("ApplyCondition failed: %v", err)
+	}
+	if conditioned.ContainsEdge("A", "B") || conditioned.ContainsEdge("B", "A") {
+		t.Error("expected both halves to be inactive")
+	}
+	if !g.ContainsEdge("B", "A") {
+		t.Error("original graph should be unchanged")
+	}
+}
+
+func TestApplyConditionActiveBidirectionalEdge(t *testing.T) {
+	g := CreateProbAdjListGraph()
+	g.AddNode("A", nil)
+	g.AddNode("B", nil)
+	g.AddBidirectionalEdge("eAB", "A", "B", 0.7, nil)
+
+	edge, _ := g.GetEdge("A", "B")
+	conditioned, err := g.ApplyCondition(Condition{
+		ForcedActiveEdges: []*Edge{edge},
+	})
+	if err != nil {
+		t.Fatalf("ApplyCondition failed: %v", err)
+	}
+	for _, pair := range [][2]NodeID{{"A", "B"}, {"B", "A"}} {
+		e, err := conditioned.GetEdge(pair[0], pair[1])
+		if err != nil {
+			t.Fatalf("GetEdge(%s, %s): %v", pair[0], pair[1], err)
+		}
+		if e.Probability != 1 {
+			t.Errorf("%s->%s: expected probability 1, got %f", pair[0], pair[1], e.Probability)
+		}
+	}
+	if e, _ := g.GetEdge("B", "A"); e.Probability != 0.7 {
+		t.Error("original graph should be unchanged")
+	}
+}
+
+func TestGraphVersion_IncreasesOnMutation(t *testing.T) {
+	g := CreateProbAdjListGraph()
+	prev := g.Version()
+	check := func(step string) {
+		t.Helper()
+		if g.Version() <= prev {
+			t.Errorf("%s: version %d did not increase from %d", step, g.Version(), prev)
+		}
+		prev = g.Version()
+	}
+
+	for i := range 2 {
+		g.AddNode(NodeID(fmt.Sprint(i)), nil)
+		check("AddNode")
+	}
+	g.AddEdge("e", "0", "1", 0.5, nil)
+	check("AddEdge")
+	g.UpdateEdge("e", 0.6)
+	check("UpdateEdge")
+	g.RemoveEdge("0", "1")
+	check("RemoveEdge")
+	g.AddBidirectionalEdge("b", "0", "1", 0.5, nil)
+	check("AddBidirectionalEdge")
+	g.RemoveEdgeByID("b")
+	check("RemoveEdgeByID")
+	g.RemoveNode("0")
+	check("RemoveNode")
+}
+
+func TestGraphVersion_UnchangedByFailedMutation(t *testing.T) {
+	g := CreateProbAdjListGraph()
+	g.AddNode("A", nil)
+	v := g.Version()
+
+	g.AddNode("A", nil)
+	g.AddEdge("e", "A", "missing", 0.5, nil)
+	g.UpdateEdge("missing", 0.5)
+	g.RemoveEdge("A", "A")
+	g.RemoveNode("missing")
+
+	if g.Version() != v {
+		t.Errorf("failed mutations changed version from %d to %d", v, g.Version())
+	}
+}
+
+func TestGraphVersion_DistinctAcrossGraphs(t *testing.T) {
+	g := CreateProbAdjListGraph()
+	g.AddNode("A", nil)
+	clone := g.Clone()
+	if clone.Version() == g.Version() {
+		t.Error("clone shares a version with its source")
+	}
+
+	// Mutating both must not bring them back to a shared version.
+	g.AddNode("B", nil)
+	clone.AddNode("B", nil)
+	if clone.Version() == g.Version() {
+		t.Error("graphs in different states share a version")
+	}
+
+	if other := CreateProbAdjListGraph(); other.Version() == CreateProbAdjListGraph().Version() {
+		t.Error("new graphs share a version")
+	}
+}
@@ -5,4 +5,5 @@ type Condition struct {
 	ForcedInactiveEdges []*Edge
 	ForcedActiveNodes   []NodeID
 	ForcedInactiveNodes []NodeID
+	EdgeProbabilities   map[EdgeID]float64 // soft evidence: replacement probability per edge
 }
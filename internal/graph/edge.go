@@ -1,11 +1,53 @@
 package graph
 
+import "strings"
+
 type EdgeID string
 
+// ReverseEdgeSuffix is appended to a bidirectional edge's ID to name the
+// directed edge running in the opposite direction.
+const ReverseEdgeSuffix = "__rev"
+
 // Edges are independent Bernoulli random variables
 type Edge struct {
-	ID          EdgeID
-	From, To    NodeID
-	Probability float64
-	Props       map[string]Value
+	ID            EdgeID
+	From, To      NodeID
+	Probability   float64
+	Props         map[string]Value
+	Bidirectional bool // one half of a pair created by AddBidirectionalEdge
+
+	// ProbLow and ProbHigh bound Probability when it is only known to lie
+	// in an interval, in which case Probability is the midpoint. Both are
+	// zero for a point estimate; see UpdateEdgeInterval.
+	ProbLow, ProbHigh float64
+}
+
+// HasProbabilityInterval reports whether e's probability is an interval
+// rather than a point estimate.
+func (e *Edge) HasProbabilityInterval() bool {
+	return e.ProbLow != 0 || e.ProbHigh != 0
+}
+
+// ProbabilityBounds returns the interval e's probability lies in, which
+// for a point estimate is [Probability, Probability].
+func (e *Edge) ProbabilityBounds() (low, high float64) {
+	if e.HasProbabilityInterval() {
+		return e.ProbLow, e.ProbHigh
+	}
+	return e.Probability, e.Probability
+}
+
+// IsReverseHalf reports whether e is the reverse half of a bidirectional
+// edge, the one AddBidirectionalEdge named by appending ReverseEdgeSuffix.
+func (e *Edge) IsReverseHalf() bool {
+	return e.Bidirectional && strings.HasSuffix(string(e.ID), ReverseEdgeSuffix)
+}
+
+// TwinID returns the ID of the other half of a bidirectional edge. It is
+// only meaningful when e.Bidirectional is set.
+func (e *Edge) TwinID() EdgeID {
+	if base, ok := strings.CutSuffix(string(e.ID), ReverseEdgeSuffix); ok {
+		return EdgeID(base)
+	}
+	return e.ID + ReverseEdgeSuffix
 }
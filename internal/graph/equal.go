@@ -0,0 +1,100 @@
+package graph
+
+// Equal reports whether a and b hold the same nodes and edges: the same
+// groups with equal defaults, the same node IDs with equal properties and
+// groups, and the same edge IDs with equal endpoints, probabilities,
+// properties and bidirectional flags. A nil property map equals an empty
+// one. Versions are ignored.
+func Equal(a, b ProbabilisticGraphModel) bool {
+	aGroups, bGroups := a.GetGroups(), b.GetGroups()
+	if len(aGroups) != len(bGroups) {
+		return false
+	}
+	for i, group := range aGroups {
+		if group.Name != bGroups[i].Name || !propsEqual(group.DefaultProps, bGroups[i].DefaultProps) {
+			return false
+		}
+	}
+
+	aNodes, bNodes := a.GetNodes(), b.GetNodes()
+	if len(aNodes) != len(bNodes) {
+		return false
+	}
+	bByID := make(map[NodeID]*Node, len(bNodes))
+	for _, n := range bNodes {
+		bByID[n.ID] = n
+	}
+	for _, n := range aNodes {
+		other, ok := bByID[n.ID]
+		if !ok || !propsEqual(n.Props, other.Props) || groupName(n) != groupName(other) {
+			return false
+		}
+	}
+
+	aEdges, bEdges := a.GetEdges(), b.GetEdges()
+	if len(aEdges) != len(bEdges) {
+		return false
+	}
+	for _, e := range aEdges {
+		other, err := b.GetEdgeByID(e.ID)
+		if err != nil ||
+			other.From != e.From || other.To != e.To ||
+			other.Probability != e.Probability ||
+			other.ProbLow != e.ProbLow || other.ProbHigh != e.ProbHigh ||
+			other.Bidirectional != e.Bidirectional ||
+			!propsEqual(e.Props, other.Props) {
+			return false
+		}
+	}
+	return true
+}
+
+// Equal reports whether v and other have the same kind and value. Unlike
+// Compare, an int never equals a float.
+func (v Value) Equal(other Value) bool {
+	if v.Kind != other.Kind {
+		return false
+	}
+	switch v.Kind {
+	case IntVal:
+		return v.I == other.I
+	case FloatVal:
+		return v.F == other.F
+	case StringVal:
+		return v.S == other.S
+	case BoolVal:
+		return v.B == other.B
+	case ArrayVal:
+		if len(v.A) != len(other.A) {
+			return false
+		}
+		for i := range v.A {
+			if !v.A[i].Equal(other.A[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return true
+	}
+}
+
+func groupName(n *Node) string {
+	if n.Group == nil {
+		return ""
+	}
+	return n.Group.Name
+}
+
+func propsEqual(a, b map[string]Value) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		w, ok := b[k]
+		if !ok || !v.Equal(w) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,99 @@
+package graph
+
+import "testing"
+
+func buildEqualTestGraph(t *testing.T) *ProbabilisticAdjacencyListGraph {
+	t.Helper()
+	g := CreateProbAdjListGraph()
+	if err := g.AddNode("A", map[string]Value{
+		"region": {Kind: StringVal, S: "US"},
+		"tags":   {Kind: ArrayVal, A: []Value{{Kind: StringVal, S: "tier1"}}},
+	}); err != nil {
+		t.Fatalf("AddNode A: %v", err)
+	}
+	if err := g.AddNode("B", nil); err != nil {
+		t.Fatalf("AddNode B: %v", err)
+	}
+	if err := g.AddNode("C", map[string]Value{}); err != nil {
+		t.Fatalf("AddNode C: %v", err)
+	}
+	if err := g.AddEdge("eAB", "A", "B", 0.9, map[string]Value{"distance": {Kind: IntVal, I: 100}}); err != nil {
+		t.Fatalf("AddEdge eAB: %v", err)
+	}
+	if err := g.AddBidirectionalEdge("eBC", "B", "C", 0.5, nil); err != nil {
+		t.Fatalf("AddBidirectionalEdge eBC: %v", err)
+	}
+	return g
+}
+
+func TestEqual_Clone(t *testing.T) {
+	g := buildEqualTestGraph(t)
+	clone := g.Clone()
+	if !Equal(g, clone) || !Equal(clone, g) {
+		t.Fatal("expected a clone to equal the original")
+	}
+	if !Equal(g, CreateReadOnlyGraph(clone)) {
+		t.Error("expected equality to hold across graph implementations")
+	}
+	if !Equal(CreateProbAdjListGraph(), CreateProbAdjListGraph()) {
+		t.Error("expected empty graphs to be equal")
+	}
+}
+
+func TestEqual_NilAndEmptyProps(t *testing.T) {
+	a := buildEqualTestGraph(t)
+	b := CreateProbAdjListGraph()
+	b.AddNode("A", map[string]Value{
+		"region": {Kind: StringVal, S: "US"},
+		"tags":   {Kind: ArrayVal, A: []Value{{Kind: StringVal, S: "tier1"}}},
+	})
+	// B and C swap nil and empty property maps relative to a.
+	b.AddNode("B", map[string]Value{})
+	b.AddNode("C", nil)
+	b.AddEdge("eAB", "A", "B", 0.9, map[string]Value{"distance": {Kind: IntVal, I: 100}})
+	b.AddBidirectionalEdge("eBC", "B", "C", 0.5, map[string]Value{})
+
+	if !Equal(a, b) {
+		t.Error("expected nil and empty property maps to compare equal")
+	}
+}
+
+func TestEqual_MutatedClone(t *testing.T) {
+	mutations := []struct {
+		name   string
+		mutate func(g ProbabilisticGraphModel) error
+	}{
+		{"added node", func(g ProbabilisticGraphModel) error { return g.AddNode("D", nil) }},
+		{"removed node", func(g ProbabilisticGraphModel) error { return g.RemoveNode("C") }},
+		{"removed edge", func(g ProbabilisticGraphModel) error { return g.RemoveEdgeByID("eAB") }},
+		{"updated probability", func(g ProbabilisticGraphModel) error { return g.UpdateEdge("eAB", 0.8) }},
+		{"re-added edge reversed", func(g ProbabilisticGraphModel) error {
+			if err := g.RemoveEdgeByID("eAB"); err != nil {
+				return err
+			}
+			return g.AddEdge("eAB", "B", "A", 0.9, map[string]Value{"distance": {Kind: IntVal, I: 100}})
+		}},
+		{"changed edge property kind", func(g ProbabilisticGraphModel) error {
+			if err := g.RemoveEdgeByID("eAB"); err != nil {
+				return err
+			}
+			return g.AddEdge("eAB", "A", "B", 0.9, map[string]Value{"distance": {Kind: FloatVal, F: 100}})
+		}},
+		{"changed array element", func(g ProbabilisticGraphModel) error {
+			nodes := g.FilterNodes(func(n *Node) bool { return n.ID == "A" })
+			nodes[0].Props["tags"] = Value{Kind: ArrayVal, A: []Value{{Kind: StringVal, S: "tier2"}}}
+			return nil
+		}},
+	}
+
+	g := buildEqualTestGraph(t)
+	for _, m := range mutations {
+		clone := g.Clone()
+		if err := m.mutate(clone); err != nil {
+			t.Fatalf("%s: %v", m.name, err)
+		}
+		if Equal(g, clone) || Equal(clone, g) {
+			t.Errorf("%s: expected the mutated clone to differ from the original", m.name)
+		}
+	}
+}
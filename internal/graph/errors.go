@@ -32,6 +32,13 @@ func EdgeAlreadyExists(ID EdgeID) error {
 	}
 }
 
+func ReservedEdgeID(ID EdgeID) error {
+	return GraphError{
+		Kind:    "ReservedEdgeID",
+		Message: fmt.Sprintf("edge ID %v ends in %q, which is reserved for the reverse half of a bidirectional edge", ID, ReverseEdgeSuffix),
+	}
+}
+
 func EdgeDoesNotExist(fromID, toID NodeID) error {
 	return GraphError{
 		Kind:    "EdgeDoesNotExist",
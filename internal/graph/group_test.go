@@ -0,0 +1,126 @@
+package graph
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func buildGroupTestGraph(t *testing.T) *ProbabilisticAdjacencyListGraph {
+	t.Helper()
+	g := CreateProbAdjListGraph()
+	if err := g.AddGroup("us", map[string]Value{
+		"currency": {Kind: StringVal, S: "USD"},
+		"region":   {Kind: StringVal, S: "US"},
+	}); err != nil {
+		t.Fatalf("AddGroup: %v", err)
+	}
+	if err := g.AddNode("A", nil); err != nil {
+		t.Fatalf("AddNode: %v", err)
+	}
+	if err := g.AddNode("B", map[string]Value{"currency": {Kind: StringVal, S: "CAD"}}); err != nil {
+		t.Fatalf("AddNode: %v", err)
+	}
+	for _, id := range []NodeID{"A", "B"} {
+		if err := g.SetNodeGroup(id, "us"); err != nil {
+			t.Fatalf("SetNodeGroup %s: %v", id, err)
+		}
+	}
+	return g
+}
+
+func TestNodeProp_FallsBackToGroup(t *testing.T) {
+	g := buildGroupTestGraph(t)
+	a, b := g.nodeMap["A"], g.nodeMap["B"]
+
+	if v, ok := a.Prop("currency"); !ok || v.S != "USD" {
+		t.Errorf("A: expected inherited USD, got %v, %v", v, ok)
+	}
+	if v, ok := b.Prop("currency"); !ok || v.S != "CAD" {
+		t.Errorf("B: expected its own CAD to win, got %v, %v", v, ok)
+	}
+	if _, ok := a.Prop("missing"); ok {
+		t.Error("expected a key missing from node and group not to resolve")
+	}
+
+	if err := g.SetNodeGroup("A", ""); err != nil {
+		t.Fatalf("SetNodeGroup: %v", err)
+	}
+	if _, ok := a.Prop("currency"); ok {
+		t.Error("expected a detached node to lose its defaults")
+	}
+}
+
+func TestGroups_Errors(t *testing.T) {
+	g := buildGroupTestGraph(t)
+	var ge GraphError
+	if err := g.AddGroup("us", nil); !errors.As(err, &ge) || ge.Kind != "GroupAlreadyExists" {
+		t.Errorf("expected GroupAlreadyExists, got %v", err)
+	}
+	if err := g.SetNodeGroup("A", "eu"); !errors.As(err, &ge) || ge.Kind != "GroupDoesNotExist" {
+		t.Errorf("expected GroupDoesNotExist, got %v", err)
+	}
+	if err := g.SetNodeGroup("Z", "us"); err == nil {
+		t.Error("expected error for missing node")
+	}
+	if err := CreateReadOnlyGraph(g).AddGroup("eu", nil); !errors.As(err, &ge) || ge.Kind != "ReadOnly" {
+		t.Errorf("expected ReadOnly, got %v", err)
+	}
+}
+
+func TestGroups_CloneIsIndependent(t *testing.T) {
+	g := buildGroupTestGraph(t)
+	clone := g.Clone()
+	if !Equal(g, clone) {
+		t.Fatal("expected clone to equal original")
+	}
+
+	clone.GetGroups()[0].DefaultProps["currency"] = Value{Kind: StringVal, S: "EUR"}
+	if v, _ := g.nodeMap["A"].Prop("currency"); v.S != "USD" {
+		t.Errorf("changing the clone's group changed the original: %v", v)
+	}
+	if Equal(g, clone) {
+		t.Error("expected differing group defaults to make graphs unequal")
+	}
+}
+
+func TestGroups_PropertyIndexUsesDefaults(t *testing.T) {
+	g := buildGroupTestGraph(t)
+	g.EnablePropertyIndex("currency")
+	if err := g.AddNode("C", nil); err != nil {
+		t.Fatalf("AddNode: %v", err)
+	}
+
+	lookup := func(s string) []NodeID {
+		nodes, _ := g.NodesWithProperty("currency", Value{Kind: StringVal, S: s})
+		return sortedNodeIDs(nodes)
+	}
+	if got := lookup("USD"); !slices.Equal(got, []NodeID{"A"}) {
+		t.Errorf("expected [A] to inherit USD, got %v", got)
+	}
+
+	// Joining a group re-indexes the node under its defaults.
+	if err := g.SetNodeGroup("C", "us"); err != nil {
+		t.Fatalf("SetNodeGroup: %v", err)
+	}
+	if got := lookup("USD"); !slices.Equal(got, []NodeID{"A", "C"}) {
+		t.Errorf("expected [A C], got %v", got)
+	}
+}
+
+func TestGroups_CopiedByTransposeAndSubgraph(t *testing.T) {
+	g := buildGroupTestGraph(t)
+
+	tr := Transpose(g)
+	if v, ok := tr.nodeMap["A"].Prop("currency"); !ok || v.S != "USD" {
+		t.Errorf("Transpose: expected A to keep its group, got %v, %v", v, ok)
+	}
+
+	sub, err := InducedSubgraph(g, []NodeID{"A"})
+	if err != nil {
+		t.Fatalf("InducedSubgraph: %v", err)
+	}
+	if v, ok := sub.nodeMap["A"].Prop("currency"); !ok || v.S != "USD" {
+		t.Errorf("InducedSubgraph: expected A to keep its group, got %v, %v", v, ok)
+	}
+}
@@ -0,0 +1,13 @@
+package graph
+
+import "slices"
+
+// sortedNodeIDs returns the IDs of nodes, sorted.
+func sortedNodeIDs(nodes []*Node) []NodeID {
+	ids := make([]NodeID, len(nodes))
+	for i, n := range nodes {
+		ids[i] = n.ID
+	}
+	slices.Sort(ids)
+	return ids
+}
@@ -0,0 +1,45 @@
+package graph
+
+// HasProbabilityIntervals reports whether any edge of g has an interval
+// probability.
+func HasProbabilityIntervals(g ProbabilisticGraphModel) bool {
+	return len(g.FilterEdges((*Edge).HasProbabilityInterval)) > 0
+}
+
+// CopyProbabilityInterval gives the edge with e's ID in g, and its twin if
+// e is bidirectional, the probability interval of e. It does nothing if e
+// has a point probability.
+func CopyProbabilityInterval(g ProbabilisticGraphModel, e *Edge) error {
+	if !e.HasProbabilityInterval() {
+		return nil
+	}
+	if err := g.UpdateEdgeInterval(e.ID, e.ProbLow, e.ProbHigh); err != nil {
+		return err
+	}
+	if e.Bidirectional {
+		return g.UpdateEdgeInterval(e.TwinID(), e.ProbLow, e.ProbHigh)
+	}
+	return nil
+}
+
+// LowerBoundGraph returns a copy of g in which every interval edge takes
+// the lower bound of its interval as a point probability.
+func LowerBoundGraph(g ProbabilisticGraphModel) (ProbabilisticGraphModel, error) {
+	return boundGraph(g, func(e *Edge) float64 { return e.ProbLow })
+}
+
+// UpperBoundGraph returns a copy of g in which every interval edge takes
+// the upper bound of its interval as a point probability.
+func UpperBoundGraph(g ProbabilisticGraphModel) (ProbabilisticGraphModel, error) {
+	return boundGraph(g, func(e *Edge) float64 { return e.ProbHigh })
+}
+
+func boundGraph(g ProbabilisticGraphModel, bound func(*Edge) float64) (ProbabilisticGraphModel, error) {
+	clone := g.Clone()
+	for _, e := range clone.FilterEdges((*Edge).HasProbabilityInterval) {
+		if err := clone.UpdateEdge(e.ID, bound(e)); err != nil {
+			return nil, err
+		}
+	}
+	return clone, nil
+}
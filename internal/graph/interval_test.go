@@ -0,0 +1,110 @@
+package graph
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func buildIntervalGraph(t *testing.T) *ProbabilisticAdjacencyListGraph {
+	t.Helper()
+	g := CreateProbAdjListGraph()
+	g.AddNode("A", nil)
+	g.AddNode("B", nil)
+	g.AddNode("C", nil)
+	g.AddEdge("eAB", "A", "B", 0.5, nil)
+	g.AddEdge("eBC", "B", "C", 0.7, nil)
+	if err := g.UpdateEdgeInterval("eAB", 0.8, 0.9); err != nil {
+		t.Fatalf("UpdateEdgeInterval failed: %v", err)
+	}
+	return g
+}
+
+func TestUpdateEdgeInterval(t *testing.T) {
+	g := buildIntervalGraph(t)
+
+	edge, _ := g.GetEdgeByID("eAB")
+	if low, high := edge.ProbabilityBounds(); low != 0.8 || high != 0.9 {
+		t.Errorf("expected bounds [0.8, 0.9], got [%g, %g]", low, high)
+	}
+	if math.Abs(edge.Probability-0.85) > 1e-12 {
+		t.Errorf("expected midpoint 0.85, got %g", edge.Probability)
+	}
+	if !HasProbabilityIntervals(g) {
+		t.Error("expected the graph to report interval edges")
+	}
+
+	for _, bounds := range [][2]float64{{0.9, 0.8}, {-0.1, 0.5}, {0.5, 1.1}} {
+		var ge GraphError
+		err := g.UpdateEdgeInterval("eBC", bounds[0], bounds[1])
+		if !errors.As(err, &ge) || ge.Kind != "InvalidEdgeProbability" {
+			t.Errorf("[%g, %g]: expected InvalidEdgeProbability, got %v", bounds[0], bounds[1], err)
+		}
+	}
+
+	// A point update replaces the interval.
+	if err := g.UpdateEdge("eAB", 0.4); err != nil {
+		t.Fatalf("UpdateEdge failed: %v", err)
+	}
+	if edge.HasProbabilityInterval() || HasProbabilityIntervals(g) {
+		t.Error("expected UpdateEdge to clear the interval")
+	}
+}
+
+func TestProbabilityInterval_CopiedWithGraph(t *testing.T) {
+	g := buildIntervalGraph(t)
+
+	for name, copied := range map[string]ProbabilisticGraphModel{
+		"clone":     g.Clone(),
+		"transpose": Transpose(g),
+	} {
+		edge, err := copied.GetEdgeByID("eAB")
+		if err != nil {
+			t.Fatalf("%s: GetEdgeByID failed: %v", name, err)
+		}
+		if edge.ProbLow != 0.8 || edge.ProbHigh != 0.9 {
+			t.Errorf("%s: expected interval [0.8, 0.9], got [%g, %g]", name, edge.ProbLow, edge.ProbHigh)
+		}
+	}
+
+	// Hard evidence replaces the interval.
+	eAB, _ := g.GetEdgeByID("eAB")
+	conditioned, err := g.ApplyCondition(Condition{ForcedActiveEdges: []*Edge{eAB}})
+	if err != nil {
+		t.Fatalf("ApplyCondition failed: %v", err)
+	}
+	if HasProbabilityIntervals(conditioned) {
+		t.Error("expected a forced-active edge to lose its interval")
+	}
+}
+
+func TestBoundGraphs(t *testing.T) {
+	g := buildIntervalGraph(t)
+
+	low, err := LowerBoundGraph(g)
+	if err != nil {
+		t.Fatalf("LowerBoundGraph failed: %v", err)
+	}
+	high, err := UpperBoundGraph(g)
+	if err != nil {
+		t.Fatalf("UpperBoundGraph failed: %v", err)
+	}
+
+	for _, tt := range []struct {
+		g    ProbabilisticGraphModel
+		want float64
+	}{{low, 0.8}, {high, 0.9}} {
+		if HasProbabilityIntervals(tt.g) {
+			t.Error("expected a bound graph to have only point probabilities")
+		}
+		eAB, _ := tt.g.GetEdgeByID("eAB")
+		eBC, _ := tt.g.GetEdgeByID("eBC")
+		if eAB.Probability != tt.want || eBC.Probability != 0.7 {
+			t.Errorf("expected eAB = %g and eBC = 0.7, got %g and %g", tt.want, eAB.Probability, eBC.Probability)
+		}
+	}
+
+	if !HasProbabilityIntervals(g) {
+		t.Error("original graph should be unchanged")
+	}
+}
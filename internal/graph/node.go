@@ -5,4 +5,7 @@ type NodeID string
 type Node struct {
 	ID    NodeID
 	Props map[string]Value
+	// Group supplies defaults for properties missing from Props; see Prop.
+	// It is nil if the node is in no group.
+	Group *NodeGroup
 }
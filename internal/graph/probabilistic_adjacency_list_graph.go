@@ -4,13 +4,27 @@ import (
 	"fmt"
 	"maps"
 	"slices"
+	"strings"
+	"sync/atomic"
 )
 
+// versionCounter hands out graph versions. Sharing one counter across all
+// graphs means two distinct graphs, or two states of one graph, never
+// report the same Version.
+var versionCounter atomic.Uint64
+
+func nextVersion() uint64 {
+	return versionCounter.Add(1)
+}
+
 type ProbabilisticAdjacencyListGraph struct {
 	nodeMap map[NodeID]*Node
 	edgeMap map[EdgeID]*Edge
 	out     map[NodeID]map[NodeID]*Edge
 	in      map[NodeID]map[NodeID]*Edge
+	version uint64
+	index   propertyIndex // nil unless EnablePropertyIndex was called
+	groups  map[string]*NodeGroup
 }
 
 func CreateProbAdjListGraph() *ProbabilisticAdjacencyListGraph {
@@ -19,11 +33,16 @@ func CreateProbAdjListGraph() *ProbabilisticAdjacencyListGraph {
 		edgeMap: make(map[EdgeID]*Edge),
 		out:     make(map[NodeID]map[NodeID]*Edge),
 		in:      make(map[NodeID]map[NodeID]*Edge),
+		version: nextVersion(),
 	}
 
 	return graph
 }
 
+func (g *ProbabilisticAdjacencyListGraph) Version() uint64 {
+	return g.version
+}
+
 func (g *ProbabilisticAdjacencyListGraph) AddNode(ID NodeID, props map[string]Value) error {
 	if g.ContainsNode(ID) {
 		return NodeAlreadyExists(ID)
@@ -37,8 +56,10 @@ func (g *ProbabilisticAdjacencyListGraph) AddNode(ID NodeID, props map[string]Va
 	}
 
 	g.nodeMap[ID] = &newNode
+	g.indexNode(&newNode)
 	g.out[ID] = make(map[NodeID]*Edge)
 	g.in[ID] = make(map[NodeID]*Edge)
+	g.version = nextVersion()
 
 	return nil
 }
@@ -60,19 +81,17 @@ func (g *ProbabilisticAdjacencyListGraph) RemoveNode(ID NodeID) error {
 	}
 
 	// Now delete the node
+	g.unindexNode(g.nodeMap[ID])
 	delete(g.nodeMap, ID)
 
-	// Delete all outgoing edges from edgeMap
-	for _, edge := range outgoingEdges {
-		delete(g.edgeMap, edge.ID)
+	// Delete every incident edge, including its entry in the neighbour's
+	// adjacency map (a self-loop appears in both lists)
+	for _, edge := range slices.Concat(outgoingEdges, incomingEdges) {
+		g.deleteEdge(edge)
 	}
 	delete(g.out, ID)
-
-	// Delete all incoming edges from edgeMap (may overlap with outgoing)
-	for _, edge := range incomingEdges {
-		delete(g.edgeMap, edge.ID)
-	}
 	delete(g.in, ID)
+	g.version = nextVersion()
 
 	return nil
 }
@@ -81,12 +100,32 @@ func (g *ProbabilisticAdjacencyListGraph) GetNodes() []*Node {
 	return slices.Collect(maps.Values(g.nodeMap))
 }
 
+func (g *ProbabilisticAdjacencyListGraph) FilterNodes(pred func(*Node) bool) []*Node {
+	var nodes []*Node
+	for _, node := range g.nodeMap {
+		if pred(node) {
+			nodes = append(nodes, node)
+		}
+	}
+
+	return nodes
+}
+
 func (g *ProbabilisticAdjacencyListGraph) ContainsNode(node NodeID) bool {
 	_, ok := g.nodeMap[node]
 	return ok
 }
 
+// AddEdge adds a directed edge. IDs ending in ReverseEdgeSuffix are
+// reserved for AddBidirectionalEdge and are rejected.
 func (g *ProbabilisticAdjacencyListGraph) AddEdge(edgeID EdgeID, fromID, toID NodeID, prob float64, props map[string]Value) error {
+	if strings.HasSuffix(string(edgeID), ReverseEdgeSuffix) {
+		return ReservedEdgeID(edgeID)
+	}
+	return g.addEdge(edgeID, fromID, toID, prob, props)
+}
+
+func (g *ProbabilisticAdjacencyListGraph) addEdge(edgeID EdgeID, fromID, toID NodeID, prob float64, props map[string]Value) error {
 	if g.ContainsEdgeByID(edgeID) {
 		return EdgeAlreadyExists(edgeID)
 	}
@@ -99,11 +138,8 @@ func (g *ProbabilisticAdjacencyListGraph) AddEdge(edgeID EdgeID, fromID, toID No
 		return NodeDoesNotExist(toID)
 	}
 
-	if prob < 0 || prob > 1 {
-		return GraphError{
-			Kind:    "InvalidEdgeProbability",
-			Message: "probability must be between 0 and 1",
-		}
+	if err := validateProbability(prob); err != nil {
+		return err
 	}
 
 	propsCopy := maps.Clone(props)
@@ -119,6 +155,41 @@ func (g *ProbabilisticAdjacencyListGraph) AddEdge(edgeID EdgeID, fromID, toID No
 	g.out[fromID][toID] = newEdge
 	g.in[toID][fromID] = newEdge
 	g.edgeMap[edgeID] = newEdge
+	g.version = nextVersion()
+
+	return nil
+}
+
+// AddBidirectionalEdge adds edgeID from aID to bID and a second edge, named
+// by appending ReverseEdgeSuffix, from bID to aID. Both halves share the
+// probability and properties and are sampled independently; removing either
+// half removes both.
+func (g *ProbabilisticAdjacencyListGraph) AddBidirectionalEdge(edgeID EdgeID, aID, bID NodeID, prob float64, props map[string]Value) error {
+	reverseID := edgeID + ReverseEdgeSuffix
+	if g.ContainsEdgeByID(reverseID) {
+		return EdgeAlreadyExists(reverseID)
+	}
+
+	// AddEdge replaces an edge between the same endpoints; refuse instead so
+	// neither half silently overwrites an existing edge.
+	for _, pair := range [][2]NodeID{{aID, bID}, {bID, aID}} {
+		if existing, err := g.GetEdge(pair[0], pair[1]); err == nil {
+			return EdgeAlreadyExists(existing.ID)
+		}
+	}
+
+	if err := g.AddEdge(edgeID, aID, bID, prob, props); err != nil {
+		return err
+	}
+
+	if err := g.addEdge(reverseID, bID, aID, prob, props); err != nil {
+		_ = g.RemoveEdgeByID(edgeID)
+		return err
+	}
+
+	g.edgeMap[edgeID].Bidirectional = true
+	g.edgeMap[reverseID].Bidirectional = true
+	g.version = nextVersion()
 
 	return nil
 }
@@ -136,30 +207,89 @@ func (g *ProbabilisticAdjacencyListGraph) RemoveEdge(fromID, toID NodeID) error
 		return EdgeDoesNotExist(fromID, toID)
 	}
 
-	edgeID := g.out[fromID][toID].ID
+	return g.RemoveEdgeByID(g.out[fromID][toID].ID)
+}
+
+func (g *ProbabilisticAdjacencyListGraph) RemoveEdgeByID(edgeID EdgeID) error {
+	if !g.ContainsEdgeByID(edgeID) {
+		return EdgeDoesNotExistByID(edgeID)
+	}
+
+	edge := g.edgeMap[edgeID]
+	g.deleteEdge(edge)
 
-	delete(g.out[fromID], toID)
-	delete(g.in[toID], fromID)
-	delete(g.edgeMap, edgeID)
+	if edge.Bidirectional {
+		if twin, ok := g.edgeMap[edge.TwinID()]; ok {
+			g.deleteEdge(twin)
+		}
+	}
 
 	return nil
 }
 
-func (g *ProbabilisticAdjacencyListGraph) RemoveEdgeByID(edgeID EdgeID) error {
-	if !g.ContainsEdgeByID(edgeID) {
+func (g *ProbabilisticAdjacencyListGraph) deleteEdge(edge *Edge) {
+	delete(g.out[edge.From], edge.To)
+	delete(g.in[edge.To], edge.From)
+	delete(g.edgeMap, edge.ID)
+	g.version = nextVersion()
+}
+
+func (g *ProbabilisticAdjacencyListGraph) UpdateEdge(edgeID EdgeID, prob float64) error {
+	edge, ok := g.edgeMap[edgeID]
+	if !ok {
 		return EdgeDoesNotExistByID(edgeID)
 	}
 
-	fromID := g.edgeMap[edgeID].From
-	toID := g.edgeMap[edgeID].To
+	if err := validateProbability(prob); err != nil {
+		return err
+	}
 
-	delete(g.out[fromID], toID)
-	delete(g.in[toID], fromID)
-	delete(g.edgeMap, edgeID)
+	edge.Probability = prob
+	edge.ProbLow, edge.ProbHigh = 0, 0
+	g.version = nextVersion()
 
 	return nil
 }
 
+// UpdateEdgeInterval sets the probability of edgeID to the interval
+// [low, high], with the midpoint as its point estimate. Only edgeID is
+// changed, not the other half of a bidirectional pair.
+func (g *ProbabilisticAdjacencyListGraph) UpdateEdgeInterval(edgeID EdgeID, low, high float64) error {
+	edge, ok := g.edgeMap[edgeID]
+	if !ok {
+		return EdgeDoesNotExistByID(edgeID)
+	}
+
+	if err := validateProbability(low); err != nil {
+		return err
+	}
+	if err := validateProbability(high); err != nil {
+		return err
+	}
+	if low > high {
+		return GraphError{
+			Kind:    "InvalidEdgeProbability",
+			Message: fmt.Sprintf("probability interval [%g, %g] has its lower bound above its upper bound", low, high),
+		}
+	}
+
+	edge.Probability = (low + high) / 2
+	edge.ProbLow, edge.ProbHigh = low, high
+	g.version = nextVersion()
+
+	return nil
+}
+
+func validateProbability(prob float64) error {
+	if prob < 0 || prob > 1 {
+		return GraphError{
+			Kind:    "InvalidEdgeProbability",
+			Message: "probability must be between 0 and 1",
+		}
+	}
+	return nil
+}
+
 func (g *ProbabilisticAdjacencyListGraph) GetEdge(fromID, toID NodeID) (*Edge, error) {
 	if !g.ContainsNode(fromID) {
 		return nil, NodeDoesNotExist(fromID)
@@ -194,6 +324,19 @@ func (g *ProbabilisticAdjacencyListGraph) GetEdges() []*Edge {
 	return allEdges
 }
 
+func (g *ProbabilisticAdjacencyListGraph) FilterEdges(pred func(*Edge) bool) []*Edge {
+	var edges []*Edge
+	for _, neighbors := range g.out {
+		for _, edge := range neighbors {
+			if pred(edge) {
+				edges = append(edges, edge)
+			}
+		}
+	}
+
+	return edges
+}
+
 func (g *ProbabilisticAdjacencyListGraph) ContainsEdge(fromID, toID NodeID) bool {
 	_, ok := g.out[fromID][toID]
 	return ok
@@ -204,6 +347,11 @@ func (g *ProbabilisticAdjacencyListGraph) ContainsEdgeByID(edge EdgeID) bool {
 	return ok
 }
 
+func (g *ProbabilisticAdjacencyListGraph) ContainsBidirectionalEdge(edge EdgeID) bool {
+	e, ok := g.edgeMap[edge]
+	return ok && e.Bidirectional
+}
+
 func (g *ProbabilisticAdjacencyListGraph) OutgoingEdges(ID NodeID) ([]*Edge, error) {
 	if !g.ContainsNode(ID) {
 		return nil, NodeDoesNotExist(ID)
@@ -246,6 +394,7 @@ func (g *ProbabilisticAdjacencyListGraph) ApplyCondition(condition Condition) (P
 
 		delete(clone.out, id)
 		delete(clone.in, id)
+		clone.unindexNode(clone.nodeMap[id])
 		delete(clone.nodeMap, id)
 	}
 
@@ -263,8 +412,74 @@ func (g *ProbabilisticAdjacencyListGraph) ApplyCondition(condition Condition) (P
 			delete(clone.out[from], to)
 			delete(clone.in[to], from)
 		}
+
+		// Failure of a bidirectional edge is symmetric
+		if edge.Bidirectional {
+			if twin, ok := clone.edgeMap[edge.TwinID()]; ok && clone.ContainsEdge(twin.From, twin.To) {
+				delete(clone.out[twin.From], twin.To)
+				delete(clone.in[twin.To], twin.From)
+			}
+		}
+	}
+
+	for id, prob := range condition.EdgeProbabilities {
+		if err := clone.UpdateEdge(id, prob); err != nil {
+			return nil, err
+		}
+
+		// Both halves of a bidirectional edge share one probability
+		if e := clone.edgeMap[id]; e.Bidirectional {
+			if err := clone.UpdateEdge(e.TwinID(), prob); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Forced-active edges and nodes are applied last, so this hard evidence
+	// overrides any soft evidence on the same edge.
+	for _, edge := range condition.ForcedActiveEdges {
+		e, ok := clone.edgeMap[edge.ID]
+		if !ok {
+			return nil, GraphError{
+				Kind:    "InvalidCondition",
+				Message: fmt.Sprintf("edge %v from condition does not exist in graph", edge.ID),
+			}
+		}
+		if !clone.ContainsEdge(e.From, e.To) {
+			return nil, GraphError{
+				Kind:    "InvalidCondition",
+				Message: fmt.Sprintf("edge %v is forced active but made inactive by another condition", edge.ID),
+			}
+		}
+		e.Probability, e.ProbLow, e.ProbHigh = 1, 0, 0
+
+		// Like failure, activity of a bidirectional edge is symmetric
+		if e.Bidirectional {
+			if twin, ok := clone.edgeMap[e.TwinID()]; ok {
+				twin.Probability, twin.ProbLow, twin.ProbHigh = 1, 0, 0
+			}
+		}
+	}
+
+	for _, id := range condition.ForcedActiveNodes {
+		if _, ok := inactiveNodes[id]; ok {
+			return nil, GraphError{
+				Kind:    "InvalidCondition",
+				Message: fmt.Sprintf("node %v is forced both active and inactive", id),
+			}
+		}
+		if !clone.ContainsNode(id) {
+			return nil, GraphError{
+				Kind:    "InvalidCondition",
+				Message: fmt.Sprintf("node %v from condition does not exist in graph", id),
+			}
+		}
+		for _, e := range clone.out[id] {
+			e.Probability, e.ProbLow, e.ProbHigh = 1, 0, 0
+		}
 	}
 
+	clone.version = nextVersion()
 	return clone, nil
 }
 
@@ -274,6 +489,14 @@ func (g *ProbabilisticAdjacencyListGraph) Clone() ProbabilisticGraphModel {
 		edgeMap: make(map[EdgeID]*Edge),
 		out:     make(map[NodeID]map[NodeID]*Edge),
 		in:      make(map[NodeID]map[NodeID]*Edge),
+		version: nextVersion(),
+	}
+
+	for name, group := range g.groups {
+		if clone.groups == nil {
+			clone.groups = make(map[string]*NodeGroup, len(g.groups))
+		}
+		clone.groups[name] = &NodeGroup{Name: name, DefaultProps: maps.Clone(group.DefaultProps)}
 	}
 
 	for id, node := range g.nodeMap {
@@ -284,22 +507,32 @@ func (g *ProbabilisticAdjacencyListGraph) Clone() ProbabilisticGraphModel {
 			ID:    node.ID,
 			Props: newProps,
 		}
+		if node.Group != nil {
+			clone.nodeMap[id].Group = clone.groups[node.Group.Name]
+		}
 
 		// Initialize inner maps for all nodes
 		clone.out[id] = make(map[NodeID]*Edge)
 		clone.in[id] = make(map[NodeID]*Edge)
 	}
 
+	if g.index != nil {
+		clone.EnablePropertyIndex(g.IndexedPropertyKeys()...)
+	}
+
 	for id, edge := range g.edgeMap {
 		newProps := make(map[string]Value)
 		maps.Copy(newProps, edge.Props)
 
 		clone.edgeMap[id] = &Edge{
-			ID:          edge.ID,
-			From:        edge.From,
-			To:          edge.To,
-			Probability: edge.Probability,
-			Props:       newProps,
+			ID:            edge.ID,
+			From:          edge.From,
+			To:            edge.To,
+			Probability:   edge.Probability,
+			Props:         newProps,
+			Bidirectional: edge.Bidirectional,
+			ProbLow:       edge.ProbLow,
+			ProbHigh:      edge.ProbHigh,
 		}
 	}
 
@@ -4,16 +4,26 @@ type ProbabilisticGraphModel interface {
 	AddNode(ID NodeID, props map[string]Value) error
 	RemoveNode(ID NodeID) error
 	GetNodes() []*Node
+	FilterNodes(pred func(*Node) bool) []*Node
 	ContainsNode(ID NodeID) bool
 
+	AddGroup(name string, defaults map[string]Value) error
+	SetNodeGroup(ID NodeID, group string) error
+	GetGroups() []*NodeGroup
+
 	AddEdge(edgeID EdgeID, fromID, toID NodeID, prob float64, props map[string]Value) error
+	AddBidirectionalEdge(edgeID EdgeID, aID, bID NodeID, prob float64, props map[string]Value) error
 	RemoveEdge(fromID, toID NodeID) error
 	RemoveEdgeByID(ID EdgeID) error
+	UpdateEdge(ID EdgeID, prob float64) error
+	UpdateEdgeInterval(ID EdgeID, low, high float64) error
 	GetEdge(fromID, toID NodeID) (*Edge, error)
 	GetEdgeByID(id EdgeID) (*Edge, error)
 	GetEdges() []*Edge
+	FilterEdges(pred func(*Edge) bool) []*Edge
 	ContainsEdge(fromID, toID NodeID) bool
 	ContainsEdgeByID(edge EdgeID) bool
+	ContainsBidirectionalEdge(edge EdgeID) bool
 
 	OutgoingEdges(ID NodeID) ([]*Edge, error)
 	IncomingEdges(ID NodeID) ([]*Edge, error)
@@ -21,4 +31,9 @@ type ProbabilisticGraphModel interface {
 	ApplyCondition(condition Condition) (ProbabilisticGraphModel, error)
 
 	Clone() ProbabilisticGraphModel
+
+	// Version increases on every successful mutation and is untouched by
+	// failed ones. No two graphs, including a graph and its clone, share a
+	// version, so it identifies a graph state.
+	Version() uint64
 }
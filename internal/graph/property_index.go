@@ -0,0 +1,141 @@
+package graph
+
+import "slices"
+
+// PropertyIndexer is implemented by graphs that can look up nodes by the
+// value of an indexed property without scanning every node.
+type PropertyIndexer interface {
+	// NodesWithProperty returns the nodes whose property key compares
+	// equal to value, in no particular order. ok is false if key is not
+	// indexed, in which case the caller must fall back to FilterNodes.
+	NodesWithProperty(key string, value Value) (nodes []*Node, ok bool)
+}
+
+// propertyIndex maps each indexed property key to the set of nodes holding
+// each value of it.
+type propertyIndex map[string]map[indexKey]map[NodeID]struct{}
+
+// indexKey is a comparable form of a Value. Ints and floats share one
+// numeric form, as Compare treats 1 and 1.0 as equal.
+type indexKey struct {
+	kind ValueKind
+	f    float64
+	s    string
+	b    bool
+}
+
+// indexKeyOf returns the key for v. Arrays have none: Compare never finds
+// them equal to anything, so they are left out of the index.
+func indexKeyOf(v Value) (indexKey, bool) {
+	switch v.Kind {
+	case IntVal, FloatVal:
+		return indexKey{kind: FloatVal, f: v.float()}, true
+	case StringVal:
+		return indexKey{kind: StringVal, s: v.S}, true
+	case BoolVal:
+		return indexKey{kind: BoolVal, b: v.B}, true
+	case NullVal:
+		return indexKey{kind: NullVal}, true
+	default:
+		return indexKey{}, false
+	}
+}
+
+// EnablePropertyIndex indexes nodes by the given property keys, so that
+// NodesWithProperty answers equality lookups on them without a scan. The
+// index is kept up to date as nodes are added and removed, and carries
+// over to clones. Keys already indexed are left as they are.
+func (g *ProbabilisticAdjacencyListGraph) EnablePropertyIndex(keys ...string) {
+	if g.index == nil {
+		g.index = make(propertyIndex)
+	}
+	for _, key := range keys {
+		if _, ok := g.index[key]; ok {
+			continue
+		}
+		byValue := make(map[indexKey]map[NodeID]struct{})
+		g.index[key] = byValue
+		for id, node := range g.nodeMap {
+			v, ok := node.Prop(key)
+			addToIndex(byValue, id, v, ok)
+		}
+	}
+}
+
+// IndexedPropertyKeys returns the keys passed to EnablePropertyIndex,
+// sorted.
+func (g *ProbabilisticAdjacencyListGraph) IndexedPropertyKeys() []string {
+	keys := make([]string, 0, len(g.index))
+	for key := range g.index {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func (g *ProbabilisticAdjacencyListGraph) NodesWithProperty(key string, value Value) ([]*Node, bool) {
+	byValue, ok := g.index[key]
+	if !ok {
+		return nil, false
+	}
+	k, ok := indexKeyOf(value)
+	if !ok {
+		return nil, true
+	}
+
+	var nodes []*Node
+	for id := range byValue[k] {
+		node, ok := g.nodeMap[id]
+		if !ok {
+			continue
+		}
+		// Distinct large ints can share a float key; Compare is exact.
+		v, _ := node.Prop(key)
+		if c, ok := v.Compare(value); ok && c == 0 {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes, true
+}
+
+// indexNode adds node to every index whose key it has.
+func (g *ProbabilisticAdjacencyListGraph) indexNode(node *Node) {
+	for key, byValue := range g.index {
+		v, ok := node.Prop(key)
+		addToIndex(byValue, node.ID, v, ok)
+	}
+}
+
+// unindexNode removes node from every index.
+func (g *ProbabilisticAdjacencyListGraph) unindexNode(node *Node) {
+	for key, byValue := range g.index {
+		v, ok := node.Prop(key)
+		if !ok {
+			continue
+		}
+		k, ok := indexKeyOf(v)
+		if !ok {
+			continue
+		}
+		delete(byValue[k], node.ID)
+		if len(byValue[k]) == 0 {
+			delete(byValue, k)
+		}
+	}
+}
+
+func addToIndex(byValue map[indexKey]map[NodeID]struct{}, id NodeID, v Value, present bool) {
+	if !present {
+		return
+	}
+	k, ok := indexKeyOf(v)
+	if !ok {
+		return
+	}
+	ids, ok := byValue[k]
+	if !ok {
+		ids = make(map[NodeID]struct{})
+		byValue[k] = ids
+	}
+	ids[id] = struct{}{}
+}
@@ -0,0 +1,130 @@
+package graph
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestPropertyIndexLookup(t *testing.T) {
+	g := CreateProbAdjListGraph()
+	g.AddNode("A", map[string]Value{"region": {Kind: StringVal, S: "US"}, "tier": {Kind: IntVal, I: 1}})
+	g.AddNode("B", map[string]Value{"region": {Kind: StringVal, S: "EU"}, "tier": {Kind: FloatVal, F: 1}})
+	g.EnablePropertyIndex("region", "tier")
+	g.AddNode("C", map[string]Value{"region": {Kind: StringVal, S: "US"}, "tier": {Kind: NullVal}})
+	g.AddNode("D", nil)
+
+	cases := []struct {
+		key   string
+		value Value
+		want  []NodeID
+	}{
+		{"region", Value{Kind: StringVal, S: "US"}, []NodeID{"A", "C"}},
+		{"region", Value{Kind: StringVal, S: "APAC"}, []NodeID{}},
+		{"tier", Value{Kind: IntVal, I: 1}, []NodeID{"A", "B"}},
+		{"tier", Value{Kind: NullVal}, []NodeID{"C"}},
+		{"tier", Value{Kind: ArrayVal}, []NodeID{}},
+	}
+	for _, tc := range cases {
+		nodes, ok := g.NodesWithProperty(tc.key, tc.value)
+		if !ok {
+			t.Fatalf("%s: expected key to be indexed", tc.key)
+		}
+		if got := sortedNodeIDs(nodes); !slices.Equal(got, tc.want) {
+			t.Errorf("%s = %v: expected %v, got %v", tc.key, tc.value, tc.want, got)
+		}
+	}
+
+	if _, ok := g.NodesWithProperty("owner", Value{Kind: StringVal, S: "x"}); ok {
+		t.Error("expected an unindexed key to report ok=false")
+	}
+}
+
+func TestPropertyIndexFollowsMutations(t *testing.T) {
+	g := CreateProbAdjListGraph()
+	g.EnablePropertyIndex("region")
+	us := Value{Kind: StringVal, S: "US"}
+	g.AddNode("A", map[string]Value{"region": us})
+	g.AddNode("B", map[string]Value{"region": us})
+
+	if err := g.RemoveNode("A"); err != nil {
+		t.Fatalf("RemoveNode: %v", err)
+	}
+	nodes, _ := g.NodesWithProperty("region", us)
+	if got := sortedNodeIDs(nodes); !slices.Equal(got, []NodeID{"B"}) {
+		t.Errorf("expected [B] after removing A, got %v", got)
+	}
+
+	clone := g.Clone().(*ProbabilisticAdjacencyListGraph)
+	clone.AddNode("C", map[string]Value{"region": us})
+	nodes, ok := clone.NodesWithProperty("region", us)
+	if !ok {
+		t.Fatal("expected the clone to keep the index")
+	}
+	if got := sortedNodeIDs(nodes); !slices.Equal(got, []NodeID{"B", "C"}) {
+		t.Errorf("expected [B C] in the clone, got %v", got)
+	}
+	nodes, _ = g.NodesWithProperty("region", us)
+	if got := sortedNodeIDs(nodes); !slices.Equal(got, []NodeID{"B"}) {
+		t.Errorf("expected the original index to be unaffected, got %v", got)
+	}
+
+	ro := CreateReadOnlyGraph(g)
+	if _, ok := ro.NodesWithProperty("region", us); !ok {
+		t.Error("expected ReadOnlyGraph to delegate to the index")
+	}
+}
+
+func TestPropertyIndexApplyConditionInactiveNode(t *testing.T) {
+	g := CreateProbAdjListGraph()
+	eu := Value{Kind: StringVal, S: "eu"}
+	g.AddNode("A", map[string]Value{"region": eu})
+	g.AddNode("X", map[string]Value{"region": eu})
+	g.EnablePropertyIndex("region")
+
+	conditioned, err := g.ApplyCondition(Condition{ForcedInactiveNodes: []NodeID{"X"}})
+	if err != nil {
+		t.Fatalf("ApplyCondition: %v", err)
+	}
+	nodes, _ := conditioned.(*ProbabilisticAdjacencyListGraph).NodesWithProperty("region", eu)
+	if got := sortedNodeIDs(nodes); !slices.Equal(got, []NodeID{"A"}) {
+		t.Errorf("expected [A] with X inactive, got %v", got)
+	}
+	nodes, _ = g.NodesWithProperty("region", eu)
+	if got := sortedNodeIDs(nodes); !slices.Equal(got, []NodeID{"A", "X"}) {
+		t.Errorf("expected the original index to keep X, got %v", got)
+	}
+}
+
+// buildRegionGraph creates n nodes spread over 100 region values.
+func buildRegionGraph(b *testing.B, n int) *ProbabilisticAdjacencyListGraph {
+	b.Helper()
+	g := CreateProbAdjListGraph()
+	for i := range n {
+		props := map[string]Value{"region": {Kind: StringVal, S: fmt.Sprintf("r%d", i%100)}}
+		if err := g.AddNode(NodeID(fmt.Sprintf("n%d", i)), props); err != nil {
+			b.Fatalf("AddNode: %v", err)
+		}
+	}
+	return g
+}
+
+func BenchmarkFindByProperty_10000Nodes_Scan(b *testing.B) {
+	g := buildRegionGraph(b, 10000)
+	want := Value{Kind: StringVal, S: "r42"}
+	for b.Loop() {
+		g.FilterNodes(func(n *Node) bool {
+			c, ok := n.Props["region"].Compare(want)
+			return ok && c == 0
+		})
+	}
+}
+
+func BenchmarkFindByProperty_10000Nodes_Indexed(b *testing.B) {
+	g := buildRegionGraph(b, 10000)
+	g.EnablePropertyIndex("region")
+	want := Value{Kind: StringVal, S: "r42"}
+	for b.Loop() {
+		g.NodesWithProperty("region", want)
+	}
+}
@@ -0,0 +1,136 @@
+package graph
+
+import "fmt"
+
+// ReadOnlyGraph wraps a ProbabilisticGraphModel, delegating every read and
+// rejecting every mutation with a GraphError of kind "ReadOnly". Clone and
+// ApplyCondition still return independent, mutable graphs. Returned nodes and
+// edges are shared with the wrapped graph and must not be modified.
+type ReadOnlyGraph struct {
+	inner ProbabilisticGraphModel
+}
+
+func CreateReadOnlyGraph(g ProbabilisticGraphModel) ReadOnlyGraph {
+	if ro, ok := g.(ReadOnlyGraph); ok {
+		return ro
+	}
+	return ReadOnlyGraph{inner: g}
+}
+
+func readOnlyError(op string) error {
+	return GraphError{
+		Kind:    "ReadOnly",
+		Message: fmt.Sprintf("%s is not permitted on a read-only graph", op),
+	}
+}
+
+func (g ReadOnlyGraph) AddNode(ID NodeID, props map[string]Value) error {
+	return readOnlyError("AddNode")
+}
+
+func (g ReadOnlyGraph) RemoveNode(ID NodeID) error {
+	return readOnlyError("RemoveNode")
+}
+
+func (g ReadOnlyGraph) GetNodes() []*Node {
+	return g.inner.GetNodes()
+}
+
+func (g ReadOnlyGraph) FilterNodes(pred func(*Node) bool) []*Node {
+	return g.inner.FilterNodes(pred)
+}
+
+func (g ReadOnlyGraph) NodesWithProperty(key string, value Value) ([]*Node, bool) {
+	if pi, ok := g.inner.(PropertyIndexer); ok {
+		return pi.NodesWithProperty(key, value)
+	}
+	return nil, false
+}
+
+func (g ReadOnlyGraph) ContainsNode(ID NodeID) bool {
+	return g.inner.ContainsNode(ID)
+}
+
+func (g ReadOnlyGraph) AddGroup(name string, defaults map[string]Value) error {
+	return readOnlyError("AddGroup")
+}
+
+func (g ReadOnlyGraph) SetNodeGroup(ID NodeID, group string) error {
+	return readOnlyError("SetNodeGroup")
+}
+
+func (g ReadOnlyGraph) GetGroups() []*NodeGroup {
+	return g.inner.GetGroups()
+}
+
+func (g ReadOnlyGraph) AddEdge(edgeID EdgeID, fromID, toID NodeID, prob float64, props map[string]Value) error {
+	return readOnlyError("AddEdge")
+}
+
+func (g ReadOnlyGraph) AddBidirectionalEdge(edgeID EdgeID, aID, bID NodeID, prob float64, props map[string]Value) error {
+	return readOnlyError("AddBidirectionalEdge")
+}
+
+func (g ReadOnlyGraph) RemoveEdge(fromID, toID NodeID) error {
+	return readOnlyError("RemoveEdge")
+}
+
+func (g ReadOnlyGraph) RemoveEdgeByID(ID EdgeID) error {
+	return readOnlyError("RemoveEdgeByID")
+}
+
+func (g ReadOnlyGraph) UpdateEdge(ID EdgeID, prob float64) error {
+	return readOnlyError("UpdateEdge")
+}
+
+func (g ReadOnlyGraph) UpdateEdgeInterval(ID EdgeID, low, high float64) error {
+	return readOnlyError("UpdateEdgeInterval")
+}
+
+func (g ReadOnlyGraph) GetEdge(fromID, toID NodeID) (*Edge, error) {
+	return g.inner.GetEdge(fromID, toID)
+}
+
+func (g ReadOnlyGraph) GetEdgeByID(id EdgeID) (*Edge, error) {
+	return g.inner.GetEdgeByID(id)
+}
+
+func (g ReadOnlyGraph) GetEdges() []*Edge {
+	return g.inner.GetEdges()
+}
+
+func (g ReadOnlyGraph) FilterEdges(pred func(*Edge) bool) []*Edge {
+	return g.inner.FilterEdges(pred)
+}
+
+func (g ReadOnlyGraph) ContainsEdge(fromID, toID NodeID) bool {
+	return g.inner.ContainsEdge(fromID, toID)
+}
+
+func (g ReadOnlyGraph) ContainsEdgeByID(edge EdgeID) bool {
+	return g.inner.ContainsEdgeByID(edge)
+}
+
+func (g ReadOnlyGraph) ContainsBidirectionalEdge(edge EdgeID) bool {
+	return g.inner.ContainsBidirectionalEdge(edge)
+}
+
+func (g ReadOnlyGraph) OutgoingEdges(ID NodeID) ([]*Edge, error) {
+	return g.inner.OutgoingEdges(ID)
+}
+
+func (g ReadOnlyGraph) IncomingEdges(ID NodeID) ([]*Edge, error) {
+	return g.inner.IncomingEdges(ID)
+}
+
+func (g ReadOnlyGraph) ApplyCondition(condition Condition) (ProbabilisticGraphModel, error) {
+	return g.inner.ApplyCondition(condition)
+}
+
+func (g ReadOnlyGraph) Clone() ProbabilisticGraphModel {
+	return g.inner.Clone()
+}
+
+func (g ReadOnlyGraph) Version() uint64 {
+	return g.inner.Version()
+}
@@ -0,0 +1,73 @@
+package graph
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestReadOnlyGraphRejectsMutations(t *testing.T) {
+	g := CreateProbAdjListGraph()
+	g.AddNode("A", nil)
+	g.AddNode("B", nil)
+	g.AddEdge("eAB", "A", "B", 0.9, nil)
+
+	ro := CreateReadOnlyGraph(g)
+
+	mutations := map[string]error{
+		"AddNode":              ro.AddNode("C", nil),
+		"RemoveNode":           ro.RemoveNode("A"),
+		"AddEdge":              ro.AddEdge("eBA", "B", "A", 0.5, nil),
+		"AddBidirectionalEdge": ro.AddBidirectionalEdge("eX", "B", "A", 0.5, nil),
+		"RemoveEdge":           ro.RemoveEdge("A", "B"),
+		"RemoveEdgeByID":       ro.RemoveEdgeByID("eAB"),
+		"UpdateEdge":           ro.UpdateEdge("eAB", 0.1),
+	}
+	for op, err := range mutations {
+		var ge GraphError
+		if !errors.As(err, &ge) || ge.Kind != "ReadOnly" {
+			t.Errorf("%s: expected ReadOnly error, got %v", op, err)
+		}
+	}
+
+	if len(g.GetNodes()) != 2 || len(g.GetEdges()) != 1 {
+		t.Error("wrapped graph should be unchanged")
+	}
+	if edge, _ := g.GetEdge("A", "B"); edge.Probability != 0.9 {
+		t.Errorf("expected probability 0.9, got %f", edge.Probability)
+	}
+}
+
+func TestReadOnlyGraphDelegatesReads(t *testing.T) {
+	g := CreateProbAdjListGraph()
+	g.AddNode("A", nil)
+	g.AddNode("B", nil)
+	g.AddEdge("eAB", "A", "B", 0.9, nil)
+
+	ro := CreateReadOnlyGraph(g)
+
+	if !ro.ContainsNode("A") || !ro.ContainsEdge("A", "B") || !ro.ContainsEdgeByID("eAB") {
+		t.Error("expected reads to see the wrapped graph")
+	}
+	if out, err := ro.OutgoingEdges("A"); err != nil || len(out) != 1 {
+		t.Errorf("expected one outgoing edge, got %v (%v)", out, err)
+	}
+
+	// Later changes to the wrapped graph are visible through the view.
+	g.AddNode("C", nil)
+	if !ro.ContainsNode("C") {
+		t.Error("expected view to reflect the wrapped graph")
+	}
+}
+
+func TestReadOnlyGraphCloneIsMutable(t *testing.T) {
+	g := CreateProbAdjListGraph()
+	g.AddNode("A", nil)
+
+	clone := CreateReadOnlyGraph(g).Clone()
+	if err := clone.AddNode("B", nil); err != nil {
+		t.Fatalf("clone should be mutable: %v", err)
+	}
+	if g.ContainsNode("B") {
+		t.Error("mutating the clone should not affect the wrapped graph")
+	}
+}
@@ -0,0 +1,43 @@
+package graph
+
+// HistogramBuckets is the number of equal-width probability buckets in
+// GraphStats.ProbabilityHistogram.
+const HistogramBuckets = 10
+
+// GraphStats summarises the size of a graph and its edge probabilities.
+type GraphStats struct {
+	NodeCount      int
+	EdgeCount      int
+	MinProbability float64 // 0 if the graph has no edges
+	MaxProbability float64 // 0 if the graph has no edges
+
+	// ProbabilityHistogram[i] counts edges with probability in
+	// [i/10, (i+1)/10). The last bucket also includes 1.0.
+	ProbabilityHistogram [HistogramBuckets]int
+}
+
+func ComputeStats(g ProbabilisticGraphModel) GraphStats {
+	edges := g.GetEdges()
+	stats := GraphStats{
+		NodeCount: len(g.GetNodes()),
+		EdgeCount: len(edges),
+	}
+
+	for i, e := range edges {
+		p := e.Probability
+		if i == 0 || p < stats.MinProbability {
+			stats.MinProbability = p
+		}
+		if i == 0 || p > stats.MaxProbability {
+			stats.MaxProbability = p
+		}
+		stats.ProbabilityHistogram[histogramBucket(p)]++
+	}
+
+	return stats
+}
+
+func histogramBucket(p float64) int {
+	b := int(p * HistogramBuckets)
+	return min(max(b, 0), HistogramBuckets-1)
+}
@@ -0,0 +1,36 @@
+package graph
+
+import "testing"
+
+func TestComputeStats(t *testing.T) {
+	g := CreateProbAdjListGraph()
+	for _, id := range []NodeID{"A", "B", "C", "D"} {
+		g.AddNode(id, nil)
+	}
+	g.AddEdge("e1", "A", "B", 0.0, nil)
+	g.AddEdge("e2", "A", "C", 0.05, nil)
+	g.AddEdge("e3", "B", "C", 0.5, nil)
+	g.AddEdge("e4", "C", "D", 0.95, nil)
+	g.AddEdge("e5", "B", "D", 1.0, nil)
+
+	stats := ComputeStats(g)
+
+	if stats.NodeCount != 4 || stats.EdgeCount != 5 {
+		t.Errorf("expected 4 nodes and 5 edges, got %d and %d", stats.NodeCount, stats.EdgeCount)
+	}
+	if stats.MinProbability != 0.0 || stats.MaxProbability != 1.0 {
+		t.Errorf("expected min 0 and max 1, got %f and %f", stats.MinProbability, stats.MaxProbability)
+	}
+
+	want := [HistogramBuckets]int{2, 0, 0, 0, 0, 1, 0, 0, 0, 2}
+	if stats.ProbabilityHistogram != want {
+		t.Errorf("expected histogram %v, got %v", want, stats.ProbabilityHistogram)
+	}
+}
+
+func TestComputeStatsEmptyGraph(t *testing.T) {
+	stats := ComputeStats(CreateProbAdjListGraph())
+	if stats != (GraphStats{}) {
+		t.Errorf("expected zero stats, got %+v", stats)
+	}
+}
@@ -0,0 +1,30 @@
+package graph
+
+// Transpose returns a new graph with the same nodes as g and every edge
+// reversed. Edge IDs, probabilities and properties, and node groups, are
+// unchanged. A bidirectional pair is already symmetric and is copied as-is.
+func Transpose(g ProbabilisticGraphModel) *ProbabilisticAdjacencyListGraph {
+	t := CreateProbAdjListGraph()
+
+	for _, node := range g.GetNodes() {
+		// Node IDs are unique in g, so this cannot fail.
+		_ = t.AddNode(node.ID, node.Props)
+	}
+	// t starts with no groups, and has every node of g.
+	_ = CopyGroups(t, g)
+
+	for _, edge := range g.GetEdges() {
+		// Edges of g are valid, so their reversals cannot conflict in t.
+		if edge.Bidirectional {
+			if !edge.IsReverseHalf() {
+				_ = t.AddBidirectionalEdge(edge.ID, edge.From, edge.To, edge.Probability, edge.Props)
+				_ = CopyProbabilityInterval(t, edge)
+			}
+			continue
+		}
+		_ = t.AddEdge(edge.ID, edge.To, edge.From, edge.Probability, edge.Props)
+		_ = CopyProbabilityInterval(t, edge)
+	}
+
+	return t
+}
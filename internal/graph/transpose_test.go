@@ -0,0 +1,43 @@
+package graph
+
+import "testing"
+
+func TestTranspose(t *testing.T) {
+	g := CreateProbAdjListGraph()
+	g.AddNode("A", map[string]Value{"tier": {Kind: IntVal, I: 1}})
+	g.AddNode("B", nil)
+	g.AddNode("C", nil)
+	g.AddEdge("eAB", "A", "B", 0.9, map[string]Value{"mode": {Kind: StringVal, S: "rail"}})
+	g.AddEdge("eBA", "B", "A", 0.4, nil)
+	g.AddBidirectionalEdge("eBC", "B", "C", 0.6, nil)
+
+	tr := Transpose(g)
+
+	edge, err := tr.GetEdge("B", "A")
+	if err != nil {
+		t.Fatalf("expected reversed edge B -> A: %v", err)
+	}
+	if edge.ID != "eAB" || edge.Probability != 0.9 || edge.Props["mode"].S != "rail" {
+		t.Errorf("unexpected reversed edge %+v", edge)
+	}
+
+	edge, err = tr.GetEdge("A", "B")
+	if err != nil {
+		t.Fatalf("expected reversed edge A -> B: %v", err)
+	}
+	if edge.ID != "eBA" || edge.Probability != 0.4 {
+		t.Errorf("unexpected reversed edge %+v", edge)
+	}
+
+	if !tr.ContainsBidirectionalEdge("eBC") || !tr.ContainsEdge("C", "B") {
+		t.Error("expected bidirectional pair to be preserved")
+	}
+	if len(tr.GetEdges()) != len(g.GetEdges()) {
+		t.Errorf("expected %d edges, got %d", len(g.GetEdges()), len(tr.GetEdges()))
+	}
+
+	original, _ := g.GetEdge("A", "B")
+	if original.ID != "eAB" {
+		t.Error("original graph should be unchanged")
+	}
+}
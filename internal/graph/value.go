@@ -1,5 +1,12 @@
 package graph
 
+import (
+	"cmp"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
 type ValueKind int
 
 const (
@@ -7,12 +14,132 @@ const (
 	FloatVal
 	StringVal
 	BoolVal
+	ArrayVal
+	NullVal
 )
 
+func (k ValueKind) String() string {
+	switch k {
+	case IntVal:
+		return "int"
+	case FloatVal:
+		return "float"
+	case StringVal:
+		return "string"
+	case BoolVal:
+		return "bool"
+	case ArrayVal:
+		return "array"
+	case NullVal:
+		return "null"
+	default:
+		return fmt.Sprintf("kind(%d)", int(k))
+	}
+}
+
+// ParseValueKind is the inverse of ValueKind.String.
+func ParseValueKind(s string) (ValueKind, error) {
+	for k := IntVal; k <= NullVal; k++ {
+		if k.String() == s {
+			return k, nil
+		}
+	}
+	return 0, GraphError{
+		Kind:    "InvalidValueKind",
+		Message: fmt.Sprintf("unknown value kind %q", s),
+	}
+}
+
 type Value struct {
 	Kind ValueKind
 	I    int64
 	F    float64
 	S    string
 	B    bool
+	A    []Value // elements of an ArrayVal; all share the same Kind
+}
+
+// Compare orders v relative to other. Int and float values compare
+// numerically with each other, strings compare lexically, false sorts
+// before true and null equals only null. ok is false when the kinds are not
+// comparable.
+func (v Value) Compare(other Value) (c int, ok bool) {
+	switch {
+	case v.isNumeric() && other.isNumeric():
+		if v.Kind == IntVal && other.Kind == IntVal {
+			return cmp.Compare(v.I, other.I), true
+		}
+		return cmp.Compare(v.float(), other.float()), true
+	case v.Kind == StringVal && other.Kind == StringVal:
+		return cmp.Compare(v.S, other.S), true
+	case v.Kind == BoolVal && other.Kind == BoolVal:
+		return cmp.Compare(boolRank(v.B), boolRank(other.B)), true
+	case v.Kind == NullVal && other.Kind == NullVal:
+		return 0, true
+	default:
+		return 0, false
+	}
+}
+
+// Interface returns the value as the corresponding native Go type.
+func (v Value) Interface() any {
+	switch v.Kind {
+	case IntVal:
+		return v.I
+	case FloatVal:
+		return v.F
+	case StringVal:
+		return v.S
+	case BoolVal:
+		return v.B
+	case ArrayVal:
+		elems := make([]any, len(v.A))
+		for i, e := range v.A {
+			elems[i] = e.Interface()
+		}
+		return elems
+	default:
+		return nil
+	}
+}
+
+func (v Value) String() string {
+	switch v.Kind {
+	case IntVal:
+		return strconv.FormatInt(v.I, 10)
+	case FloatVal:
+		return strconv.FormatFloat(v.F, 'g', -1, 64)
+	case StringVal:
+		return strconv.Quote(v.S)
+	case BoolVal:
+		return strconv.FormatBool(v.B)
+	case ArrayVal:
+		parts := make([]string, len(v.A))
+		for i, e := range v.A {
+			parts[i] = e.String()
+		}
+		return "[" + strings.Join(parts, ", ") + "]"
+	case NullVal:
+		return "null"
+	default:
+		return fmt.Sprintf("<unknown kind %d>", v.Kind)
+	}
+}
+
+func (v Value) isNumeric() bool {
+	return v.Kind == IntVal || v.Kind == FloatVal
+}
+
+func (v Value) float() float64 {
+	if v.Kind == IntVal {
+		return float64(v.I)
+	}
+	return v.F
+}
+
+func boolRank(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
 }
@@ -0,0 +1,77 @@
+package inference
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	"github.com/ritamzico/pgraph/internal/graph"
+)
+
+// AllSimplePaths enumerates the simple paths from start to end of at most
+// maxLength edges and returns them sorted by probability, most probable
+// first. The search stops once maxPaths paths have been found; those are
+// the first in depth-first order (neighbours visited in node ID order), not
+// necessarily the most probable, so use TopKMaxProbabilityPaths when only
+// the best paths matter. Zero means no limit for either cap. When start
+// equals end the only simple path is the single-node one, with probability 1.
+func AllSimplePaths(g graph.ProbabilisticGraphModel, start, end graph.NodeID, maxPaths, maxLength int) ([]graph.Path, error) {
+	if !g.ContainsNode(start) {
+		return nil, graph.NodeDoesNotExist(start)
+	}
+	if !g.ContainsNode(end) {
+		return nil, graph.NodeDoesNotExist(end)
+	}
+	if maxPaths < 0 || maxLength < 0 {
+		return nil, InferenceError{
+			Kind:    "InvalidParameter",
+			Message: fmt.Sprintf("maxPaths and maxLength must be non-negative, got %d and %d", maxPaths, maxLength),
+		}
+	}
+
+	var paths []graph.Path
+	nodes := []graph.NodeID{start}
+	onPath := map[graph.NodeID]bool{start: true}
+
+	// visit returns false once maxPaths paths have been collected.
+	var visit func(current graph.NodeID, prob float64) (bool, error)
+	visit = func(current graph.NodeID, prob float64) (bool, error) {
+		if current == end {
+			paths = append(paths, graph.Path{NodeIDs: slices.Clone(nodes), Probability: prob})
+			return maxPaths == 0 || len(paths) < maxPaths, nil
+		}
+		if maxLength > 0 && len(nodes)-1 == maxLength {
+			return true, nil
+		}
+
+		edges, err := g.OutgoingEdges(current)
+		if err != nil {
+			return false, err
+		}
+		slices.SortFunc(edges, func(a, b *graph.Edge) int { return cmp.Compare(a.To, b.To) })
+
+		for _, edge := range edges {
+			if onPath[edge.To] {
+				continue
+			}
+			onPath[edge.To] = true
+			nodes = append(nodes, edge.To)
+			more, err := visit(edge.To, prob*edge.Probability)
+			nodes = nodes[:len(nodes)-1]
+			onPath[edge.To] = false
+			if err != nil || !more {
+				return more, err
+			}
+		}
+		return true, nil
+	}
+
+	if _, err := visit(start, 1); err != nil {
+		return nil, err
+	}
+
+	slices.SortStableFunc(paths, func(a, b graph.Path) int {
+		return cmp.Compare(b.Probability, a.Probability)
+	})
+	return paths, nil
+}
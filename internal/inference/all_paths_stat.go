@@ -0,0 +1,120 @@
+package inference
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/ritamzico/pgraph/internal/graph"
+)
+
+// MaxPathSegments bounds the work AllPathsStat does: the number of edges it
+// may follow, summed over the whole depth-first search.
+const MaxPathSegments = 1_000_000
+
+// PathStatistic selects the statistic AllPathsStat computes.
+type PathStatistic int
+
+const (
+	PathStatMean PathStatistic = iota
+	PathStatMax
+	PathStatMin
+	PathStatCount
+	// PathStatVariance is the population variance.
+	PathStatVariance
+)
+
+// AllPathsStat computes stat over value(path) for every simple path from
+// start to end. Paths are visited by depth-first search and folded into a
+// running accumulator, so they are never all held at once; the path passed
+// to value is only valid during the call. The search fails with a
+// TooManyPaths error once it has followed MaxPathSegments edges.
+//
+// PathStatCount is 0 when there are no paths; every other statistic fails
+// with a NoPath error. When start equals end the only simple path is the
+// single-node one, with probability 1.
+func AllPathsStat(g graph.ProbabilisticGraphModel, start, end graph.NodeID, stat PathStatistic, value func(graph.Path) float64) (float64, error) {
+	if !g.ContainsNode(start) {
+		return 0, graph.NodeDoesNotExist(start)
+	}
+	if !g.ContainsNode(end) {
+		return 0, graph.NodeDoesNotExist(end)
+	}
+	if stat < PathStatMean || stat > PathStatVariance {
+		return 0, InferenceError{
+			Kind:    "InvalidParameter",
+			Message: fmt.Sprintf("unknown path statistic %d", stat),
+		}
+	}
+
+	// Welford's algorithm keeps the mean and variance numerically stable.
+	var n int
+	var mean, m2 float64
+	lo, hi := math.Inf(1), math.Inf(-1)
+
+	nodes := []graph.NodeID{start}
+	onPath := map[graph.NodeID]bool{start: true}
+	segments := 0
+
+	var visit func(current graph.NodeID, prob float64) error
+	visit = func(current graph.NodeID, prob float64) error {
+		if current == end {
+			v := value(graph.Path{NodeIDs: nodes, Probability: prob})
+			n++
+			delta := v - mean
+			mean += delta / float64(n)
+			m2 += delta * (v - mean)
+			lo, hi = min(lo, v), max(hi, v)
+			return nil
+		}
+
+		edges, err := g.OutgoingEdges(current)
+		if err != nil {
+			return err
+		}
+
+		for _, edge := range edges {
+			if onPath[edge.To] {
+				continue
+			}
+			if segments++; segments > MaxPathSegments {
+				return InferenceError{
+					Kind:    "TooManyPaths",
+					Message: fmt.Sprintf("more than %d path segments from %v to %v", MaxPathSegments, start, end),
+				}
+			}
+			onPath[edge.To] = true
+			nodes = append(nodes, edge.To)
+			err := visit(edge.To, prob*edge.Probability)
+			nodes = nodes[:len(nodes)-1]
+			onPath[edge.To] = false
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := visit(start, 1); err != nil {
+		return 0, err
+	}
+
+	if stat == PathStatCount {
+		return float64(n), nil
+	}
+	if n == 0 {
+		return 0, InferenceError{
+			Kind:    "NoPath",
+			Message: fmt.Sprintf("no path from %v to %v", start, end),
+		}
+	}
+	switch stat {
+	case PathStatMax:
+		return hi, nil
+	case PathStatMin:
+		return lo, nil
+	case PathStatVariance:
+		return m2 / float64(n), nil
+	default:
+		return mean, nil
+	}
+}
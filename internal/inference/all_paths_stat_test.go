@@ -0,0 +1,110 @@
+package inference
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/ritamzico/pgraph/internal/graph"
+)
+
+func statPathProb(p graph.Path) float64 { return p.Probability }
+func statPathHops(p graph.Path) float64 { return float64(len(p.NodeIDs) - 1) }
+
+func TestAllPathsStat(t *testing.T) {
+	g := buildSensitivityTestGraph(t)
+	// Paths A-B-D (0.63), A-C-D (0.48) and A-D (0.1); the back edge D -> A
+	// must not be followed.
+	if err := g.AddEdge("eDA", "D", "A", 0.5, nil); err != nil {
+		t.Fatalf("AddEdge: %v", err)
+	}
+	if err := g.AddEdge("eAD", "A", "D", 0.1, nil); err != nil {
+		t.Fatalf("AddEdge: %v", err)
+	}
+
+	probs := []float64{0.63, 0.48, 0.1}
+	meanProb := (0.63 + 0.48 + 0.1) / 3
+	var varProb float64
+	for _, p := range probs {
+		varProb += (p - meanProb) * (p - meanProb) / 3
+	}
+
+	tests := []struct {
+		name  string
+		stat  PathStatistic
+		value func(graph.Path) float64
+		want  float64
+	}{
+		{"mean prob", PathStatMean, statPathProb, meanProb},
+		{"max prob", PathStatMax, statPathProb, 0.63},
+		{"min prob", PathStatMin, statPathProb, 0.1},
+		{"count", PathStatCount, statPathProb, 3},
+		{"variance prob", PathStatVariance, statPathProb, varProb},
+		{"mean hops", PathStatMean, statPathHops, 5.0 / 3},
+		{"variance hops", PathStatVariance, statPathHops, 2.0 / 9},
+	}
+	for _, tt := range tests {
+		got, err := AllPathsStat(g, "A", "D", tt.stat, tt.value)
+		if err != nil {
+			t.Fatalf("%s: AllPathsStat: %v", tt.name, err)
+		}
+		if math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAllPathsStat_NoPaths(t *testing.T) {
+	g := buildSensitivityTestGraph(t)
+
+	if got, err := AllPathsStat(g, "D", "A", PathStatCount, statPathProb); err != nil || got != 0 {
+		t.Errorf("count with no paths = %v, %v; want 0", got, err)
+	}
+	var ie InferenceError
+	if _, err := AllPathsStat(g, "D", "A", PathStatMean, statPathProb); !errors.As(err, &ie) || ie.Kind != "NoPath" {
+		t.Errorf("expected NoPath error, got %v", err)
+	}
+}
+
+func TestAllPathsStat_TooManyPaths(t *testing.T) {
+	// 20 layers of two nodes, each fully connected to the next: 2^20 paths.
+	g := graph.CreateProbAdjListGraph()
+	node := func(layer, i int) graph.NodeID { return graph.NodeID(fmt.Sprintf("n%d_%d", layer, i)) }
+	const layers = 20
+	for l := range layers {
+		for i := range 2 {
+			if err := g.AddNode(node(l, i), nil); err != nil {
+				t.Fatalf("AddNode: %v", err)
+			}
+			if l == 0 {
+				continue
+			}
+			for j := range 2 {
+				id := graph.EdgeID(fmt.Sprintf("e%d_%d_%d", l, j, i))
+				if err := g.AddEdge(id, node(l-1, j), node(l, i), 0.5, nil); err != nil {
+					t.Fatalf("AddEdge: %v", err)
+				}
+			}
+		}
+	}
+
+	var ie InferenceError
+	_, err := AllPathsStat(g, node(0, 0), node(layers-1, 0), PathStatCount, statPathProb)
+	if !errors.As(err, &ie) || ie.Kind != "TooManyPaths" {
+		t.Errorf("expected TooManyPaths error, got %v", err)
+	}
+}
+
+func TestAllPathsStat_Errors(t *testing.T) {
+	g := buildSensitivityTestGraph(t)
+	if _, err := AllPathsStat(g, "A", "Z", PathStatMean, statPathProb); err == nil {
+		t.Error("expected error for unknown end node")
+	}
+	if _, err := AllPathsStat(g, "Z", "A", PathStatMean, statPathProb); err == nil {
+		t.Error("expected error for unknown start node")
+	}
+	if _, err := AllPathsStat(g, "A", "D", PathStatistic(99), statPathProb); err == nil {
+		t.Error("expected error for unknown statistic")
+	}
+}
@@ -0,0 +1,74 @@
+package inference
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/ritamzico/pgraph/internal/graph"
+)
+
+func TestAllSimplePaths(t *testing.T) {
+	g := buildSensitivityTestGraph(t)
+	if err := g.AddEdge("eDA", "D", "A", 0.5, nil); err != nil {
+		t.Fatalf("AddEdge: %v", err)
+	}
+	if err := g.AddEdge("eAD", "A", "D", 0.1, nil); err != nil {
+		t.Fatalf("AddEdge: %v", err)
+	}
+
+	paths, err := AllSimplePaths(g, "A", "D", 0, 0)
+	if err != nil {
+		t.Fatalf("AllSimplePaths: %v", err)
+	}
+	want := [][]graph.NodeID{{"A", "B", "D"}, {"A", "C", "D"}, {"A", "D"}}
+	if len(paths) != len(want) {
+		t.Fatalf("got %d paths, want %d: %v", len(paths), len(want), paths)
+	}
+	for i, p := range paths {
+		if !slices.Equal(p.NodeIDs, want[i]) {
+			t.Errorf("path %d = %v, want %v", i, p.NodeIDs, want[i])
+		}
+	}
+	if math.Abs(paths[0].Probability-0.63) > 0.0001 || math.Abs(paths[1].Probability-0.48) > 0.0001 {
+		t.Errorf("unexpected probabilities %f, %f", paths[0].Probability, paths[1].Probability)
+	}
+
+	// The cap keeps the first paths found in node ID order: A-B-D, then A-C-D.
+	paths, err = AllSimplePaths(g, "A", "D", 2, 0)
+	if err != nil {
+		t.Fatalf("AllSimplePaths: %v", err)
+	}
+	if len(paths) != 2 || !slices.Equal(paths[1].NodeIDs, []graph.NodeID{"A", "C", "D"}) {
+		t.Errorf("MaxPaths 2 gave %v", paths)
+	}
+
+	paths, err = AllSimplePaths(g, "A", "D", 0, 1)
+	if err != nil {
+		t.Fatalf("AllSimplePaths: %v", err)
+	}
+	if len(paths) != 1 || !slices.Equal(paths[0].NodeIDs, []graph.NodeID{"A", "D"}) {
+		t.Errorf("MaxLength 1 gave %v", paths)
+	}
+
+	paths, err = AllSimplePaths(g, "B", "B", 0, 0)
+	if err != nil {
+		t.Fatalf("AllSimplePaths: %v", err)
+	}
+	if len(paths) != 1 || paths[0].Probability != 1 {
+		t.Errorf("start == end gave %v", paths)
+	}
+}
+
+func TestAllSimplePaths_Errors(t *testing.T) {
+	g := buildSensitivityTestGraph(t)
+	if _, err := AllSimplePaths(g, "A", "Z", 0, 0); err == nil {
+		t.Error("expected error for unknown end node")
+	}
+	if _, err := AllSimplePaths(g, "A", "D", -1, 0); err == nil {
+		t.Error("expected error for negative maxPaths")
+	}
+	if _, err := AllSimplePaths(g, "A", "D", 0, -1); err == nil {
+		t.Error("expected error for negative maxLength")
+	}
+}
@@ -0,0 +1,64 @@
+package inference
+
+import "github.com/ritamzico/pgraph/internal/graph"
+
+// BetweennessCentrality returns, for every node, the fraction of shortest
+// paths (by hop count) between other node pairs that pass through it, using
+// Brandes' algorithm. Edge probabilities are ignored. Scores are normalized
+// by (n-1)(n-2), the number of ordered pairs excluding the node itself, so
+// they lie in [0, 1].
+func BetweennessCentrality(g graph.ProbabilisticGraphModel) map[graph.NodeID]float64 {
+	nodes := g.GetNodes()
+	scores := make(map[graph.NodeID]float64, len(nodes))
+	for _, n := range nodes {
+		scores[n.ID] = 0
+	}
+
+	for _, s := range nodes {
+		stack := []graph.NodeID{}
+		preds := make(map[graph.NodeID][]graph.NodeID)
+		sigma := map[graph.NodeID]float64{s.ID: 1}
+		dist := map[graph.NodeID]int{s.ID: 0}
+		queue := []graph.NodeID{s.ID}
+
+		for len(queue) > 0 {
+			v := queue[0]
+			queue = queue[1:]
+			stack = append(stack, v)
+
+			// v came from GetNodes or an edge endpoint, so it exists.
+			edges, _ := g.OutgoingEdges(v)
+			for _, e := range edges {
+				w := e.To
+				if _, seen := dist[w]; !seen {
+					dist[w] = dist[v] + 1
+					queue = append(queue, w)
+				}
+				if dist[w] == dist[v]+1 {
+					sigma[w] += sigma[v]
+					preds[w] = append(preds[w], v)
+				}
+			}
+		}
+
+		delta := make(map[graph.NodeID]float64)
+		for i := len(stack) - 1; i >= 0; i-- {
+			w := stack[i]
+			for _, v := range preds[w] {
+				delta[v] += sigma[v] / sigma[w] * (1 + delta[w])
+			}
+			if w != s.ID {
+				scores[w] += delta[w]
+			}
+		}
+	}
+
+	if n := len(nodes); n > 2 {
+		norm := 1 / float64((n-1)*(n-2))
+		for id := range scores {
+			scores[id] *= norm
+		}
+	}
+
+	return scores
+}
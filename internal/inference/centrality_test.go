@@ -0,0 +1,46 @@
+package inference
+
+import (
+	"math"
+	"testing"
+
+	"github.com/ritamzico/pgraph/internal/graph"
+)
+
+func TestBetweennessCentrality_Diamond(t *testing.T) {
+	scores := BetweennessCentrality(buildSensitivityTestGraph(t))
+
+	// A -> D has two shortest paths, one through B and one through C, so
+	// each carries 1/2 of one pair out of (4-1)(4-2) = 6.
+	want := 1.0 / 12
+	if math.Abs(scores["B"]-want) > 0.0001 || math.Abs(scores["C"]-want) > 0.0001 {
+		t.Errorf("expected B and C to score %f, got %f and %f", want, scores["B"], scores["C"])
+	}
+	if scores["B"] != scores["C"] {
+		t.Errorf("expected B and C to be equal, got %f and %f", scores["B"], scores["C"])
+	}
+	for _, id := range []graph.NodeID{"A", "D"} {
+		if scores[id] >= scores["B"] {
+			t.Errorf("expected %s to score below B, got %f", id, scores[id])
+		}
+	}
+}
+
+func TestBetweennessCentrality_Chain(t *testing.T) {
+	g := graph.CreateProbAdjListGraph()
+	for _, n := range []graph.NodeID{"A", "B", "C"} {
+		g.AddNode(n, nil)
+	}
+	g.AddEdge("eAB", "A", "B", 0.5, nil)
+	g.AddEdge("eBC", "B", "C", 0.5, nil)
+
+	scores := BetweennessCentrality(g)
+
+	// B lies on the only A -> C path: 1 pair out of (3-1)(3-2) = 2.
+	if math.Abs(scores["B"]-0.5) > 0.0001 {
+		t.Errorf("expected B to score 0.5, got %f", scores["B"])
+	}
+	if scores["A"] != 0 || scores["C"] != 0 {
+		t.Errorf("expected endpoints to score 0, got A=%f C=%f", scores["A"], scores["C"])
+	}
+}
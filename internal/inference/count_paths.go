@@ -0,0 +1,62 @@
+package inference
+
+import (
+	"fmt"
+
+	"github.com/ritamzico/pgraph/internal/graph"
+)
+
+// CountSimplePaths counts the simple paths from start to end of at most
+// maxLength edges, ignoring probabilities. A maxLength of zero means no
+// limit. Paths are counted during a depth-first search rather than
+// collected, so memory use is proportional to the path length, though the
+// running time still grows with the number of paths. When start equals end
+// the only simple path is the empty one, and the count is 1.
+func CountSimplePaths(g graph.ProbabilisticGraphModel, start, end graph.NodeID, maxLength int) (int, error) {
+	if !g.ContainsNode(start) {
+		return 0, graph.NodeDoesNotExist(start)
+	}
+	if !g.ContainsNode(end) {
+		return 0, graph.NodeDoesNotExist(end)
+	}
+	if maxLength < 0 {
+		return 0, InferenceError{
+			Kind:    "InvalidParameter",
+			Message: fmt.Sprintf("maxLength must be non-negative, got %d", maxLength),
+		}
+	}
+
+	onPath := map[graph.NodeID]bool{start: true}
+
+	var count func(current graph.NodeID, depth int) (int, error)
+	count = func(current graph.NodeID, depth int) (int, error) {
+		if current == end {
+			return 1, nil
+		}
+		if maxLength > 0 && depth == maxLength {
+			return 0, nil
+		}
+
+		edges, err := g.OutgoingEdges(current)
+		if err != nil {
+			return 0, err
+		}
+
+		total := 0
+		for _, edge := range edges {
+			if onPath[edge.To] {
+				continue
+			}
+			onPath[edge.To] = true
+			n, err := count(edge.To, depth+1)
+			onPath[edge.To] = false
+			if err != nil {
+				return 0, err
+			}
+			total += n
+		}
+		return total, nil
+	}
+
+	return count(start, 0)
+}
@@ -0,0 +1,54 @@
+package inference
+
+import (
+	"testing"
+
+	"github.com/ritamzico/pgraph/internal/graph"
+)
+
+func TestCountSimplePaths(t *testing.T) {
+	g := buildSensitivityTestGraph(t)
+	// Back edge D -> A creates a cycle that must not be followed twice.
+	if err := g.AddEdge("eDA", "D", "A", 0.5, nil); err != nil {
+		t.Fatalf("AddEdge: %v", err)
+	}
+	if err := g.AddEdge("eAD", "A", "D", 0.1, nil); err != nil {
+		t.Fatalf("AddEdge: %v", err)
+	}
+
+	tests := []struct {
+		start, end graph.NodeID
+		maxLength  int
+		want       int
+	}{
+		{"A", "D", 0, 3}, // A-D, A-B-D, A-C-D
+		{"A", "D", 1, 1},
+		{"A", "D", 2, 3},
+		{"D", "B", 0, 1}, // D-A-B
+		{"B", "C", 0, 1}, // B-D-A-C
+		{"B", "C", 2, 0},
+		{"A", "A", 0, 1},
+	}
+	for _, tt := range tests {
+		got, err := CountSimplePaths(g, tt.start, tt.end, tt.maxLength)
+		if err != nil {
+			t.Fatalf("CountSimplePaths(%s, %s, %d): %v", tt.start, tt.end, tt.maxLength, err)
+		}
+		if got != tt.want {
+			t.Errorf("CountSimplePaths(%s, %s, %d) = %d, want %d", tt.start, tt.end, tt.maxLength, got, tt.want)
+		}
+	}
+}
+
+func TestCountSimplePaths_Errors(t *testing.T) {
+	g := buildSensitivityTestGraph(t)
+	if _, err := CountSimplePaths(g, "A", "Z", 0); err == nil {
+		t.Error("expected error for unknown end node")
+	}
+	if _, err := CountSimplePaths(g, "Z", "A", 0); err == nil {
+		t.Error("expected error for unknown start node")
+	}
+	if _, err := CountSimplePaths(g, "A", "D", -1); err == nil {
+		t.Error("expected error for negative maxLength")
+	}
+}
@@ -0,0 +1,49 @@
+package inference
+
+import (
+	"fmt"
+
+	"github.com/ritamzico/pgraph/internal/graph"
+)
+
+// DistinctProbabilitiesMaxPaths caps how many simple paths
+// TopKDistinctPathProbabilities enumerates.
+const DistinctProbabilitiesMaxPaths = 100_000
+
+// distinctProbabilityTolerance is how close two path probabilities must be
+// to count as the same value. Products of the same edge probabilities can
+// differ in the last bits depending on the order they are multiplied in.
+const distinctProbabilityTolerance = 1e-12
+
+// TopKDistinctPathProbabilities returns the k largest distinct
+// probabilities among the simple paths from start to end, in descending
+// order. It enumerates at most DistinctProbabilitiesMaxPaths paths in
+// depth-first order, as AllSimplePaths does, so on graphs with more paths
+// than that the result only reflects those found first.
+func TopKDistinctPathProbabilities(g graph.ProbabilisticGraphModel, start, end graph.NodeID, k int) ([]float64, error) {
+	if k <= 0 {
+		return nil, InferenceError{
+			Kind:    "InvalidParameter",
+			Message: fmt.Sprintf("k must be greater than 0, got %d", k),
+		}
+	}
+
+	paths, err := AllSimplePaths(g, start, end, DistinctProbabilitiesMaxPaths, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	// AllSimplePaths sorts paths by descending probability, so equal values
+	// are adjacent.
+	values := make([]float64, 0, k)
+	for _, p := range paths {
+		if n := len(values); n > 0 && values[n-1]-p.Probability <= distinctProbabilityTolerance {
+			continue
+		}
+		values = append(values, p.Probability)
+		if len(values) == k {
+			break
+		}
+	}
+	return values, nil
+}
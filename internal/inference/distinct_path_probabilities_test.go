@@ -0,0 +1,73 @@
+package inference
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/ritamzico/pgraph/internal/graph"
+)
+
+func TestTopKDistinctPathProbabilities(t *testing.T) {
+	// Two routes from A to D with the same probability, 0.5 * 0.8 = 0.8 *
+	// 0.5, and a direct edge of 0.3.
+	g := graph.CreateProbAdjListGraph()
+	for _, n := range []graph.NodeID{"A", "B", "C", "D"} {
+		if err := g.AddNode(n, nil); err != nil {
+			t.Fatalf("AddNode %s: %v", n, err)
+		}
+	}
+	for _, e := range []struct {
+		id       graph.EdgeID
+		from, to graph.NodeID
+		prob     float64
+	}{
+		{"eAB", "A", "B", 0.5},
+		{"eBD", "B", "D", 0.8},
+		{"eAC", "A", "C", 0.8},
+		{"eCD", "C", "D", 0.5},
+		{"eAD", "A", "D", 0.3},
+	} {
+		if err := g.AddEdge(e.id, e.from, e.to, e.prob, nil); err != nil {
+			t.Fatalf("AddEdge %s: %v", e.id, err)
+		}
+	}
+
+	values, err := TopKDistinctPathProbabilities(g, "A", "D", 5)
+	if err != nil {
+		t.Fatalf("TopKDistinctPathProbabilities: %v", err)
+	}
+	want := []float64{0.4, 0.3}
+	if len(values) != len(want) {
+		t.Fatalf("expected %v, got %v", want, values)
+	}
+	for i := range want {
+		if math.Abs(values[i]-want[i]) > 1e-12 {
+			t.Errorf("value %d: expected %f, got %f", i, want[i], values[i])
+		}
+	}
+
+	values, err = TopKDistinctPathProbabilities(g, "A", "D", 1)
+	if err != nil {
+		t.Fatalf("TopKDistinctPathProbabilities: %v", err)
+	}
+	if len(values) != 1 || math.Abs(values[0]-0.4) > 1e-12 {
+		t.Errorf("k = 1: expected [0.4], got %v", values)
+	}
+
+	values, err = TopKDistinctPathProbabilities(g, "D", "A", 3)
+	if err != nil || len(values) != 0 {
+		t.Errorf("no paths: expected no values, got %v, %v", values, err)
+	}
+}
+
+func TestTopKDistinctPathProbabilities_InvalidK(t *testing.T) {
+	g := buildSensitivityTestGraph(t)
+	for _, k := range []int{0, -1} {
+		_, err := TopKDistinctPathProbabilities(g, "A", "D", k)
+		var ie InferenceError
+		if !errors.As(err, &ie) || ie.Kind != "InvalidParameter" {
+			t.Errorf("k = %d: expected InvalidParameter, got %v", k, err)
+		}
+	}
+}
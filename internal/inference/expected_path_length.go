@@ -0,0 +1,66 @@
+package inference
+
+import (
+	"fmt"
+
+	"github.com/ritamzico/pgraph/internal/graph"
+)
+
+// ExpectedPathLength returns the average hop count of the simple paths from
+// start to end, each weighted by the product of its edge probabilities:
+//
+//	sum(P(path) * len(path)) / sum(P(path))
+//
+// Like CountSimplePaths it enumerates every simple path, so the running time
+// grows with the number of paths. When start equals end the only simple path
+// is the empty one, and the result is 0. It fails with a NoPath error if no
+// path has a non-zero probability.
+func ExpectedPathLength(g graph.ProbabilisticGraphModel, start, end graph.NodeID) (float64, error) {
+	if !g.ContainsNode(start) {
+		return 0, graph.NodeDoesNotExist(start)
+	}
+	if !g.ContainsNode(end) {
+		return 0, graph.NodeDoesNotExist(end)
+	}
+
+	onPath := map[graph.NodeID]bool{start: true}
+	var totalWeight, weightedHops float64
+
+	var walk func(current graph.NodeID, depth int, prob float64) error
+	walk = func(current graph.NodeID, depth int, prob float64) error {
+		if current == end {
+			totalWeight += prob
+			weightedHops += prob * float64(depth)
+			return nil
+		}
+
+		edges, err := g.OutgoingEdges(current)
+		if err != nil {
+			return err
+		}
+
+		for _, edge := range edges {
+			if onPath[edge.To] || edge.Probability == 0 {
+				continue
+			}
+			onPath[edge.To] = true
+			err := walk(edge.To, depth+1, prob*edge.Probability)
+			onPath[edge.To] = false
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := walk(start, 0, 1); err != nil {
+		return 0, err
+	}
+	if totalWeight == 0 {
+		return 0, InferenceError{
+			Kind:    "NoPath",
+			Message: fmt.Sprintf("no path from %v to %v has non-zero probability", start, end),
+		}
+	}
+	return weightedHops / totalWeight, nil
+}
@@ -0,0 +1,57 @@
+package inference
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestExpectedPathLength(t *testing.T) {
+	g := buildSensitivityTestGraph(t)
+
+	// Both paths have two hops.
+	if got, err := ExpectedPathLength(g, "A", "D"); err != nil || got != 2 {
+		t.Errorf("ExpectedPathLength(A, D) = %v, %v; want 2", got, err)
+	}
+	if got, err := ExpectedPathLength(g, "A", "A"); err != nil || got != 0 {
+		t.Errorf("ExpectedPathLength(A, A) = %v, %v; want 0", got, err)
+	}
+
+	// A-D (0.1, 1 hop), A-B-D (0.63, 2 hops), A-C-D (0.48, 2 hops). The back
+	// edge D -> A must not be followed.
+	if err := g.AddEdge("eAD", "A", "D", 0.1, nil); err != nil {
+		t.Fatalf("AddEdge: %v", err)
+	}
+	if err := g.AddEdge("eDA", "D", "A", 0.5, nil); err != nil {
+		t.Fatalf("AddEdge: %v", err)
+	}
+	want := (0.1*1 + 0.63*2 + 0.48*2) / (0.1 + 0.63 + 0.48)
+	got, err := ExpectedPathLength(g, "A", "D")
+	if err != nil {
+		t.Fatalf("ExpectedPathLength: %v", err)
+	}
+	if math.Abs(got-want) > 1e-9 {
+		t.Errorf("ExpectedPathLength(A, D) = %v, want %v", got, want)
+	}
+}
+
+func TestExpectedPathLength_Errors(t *testing.T) {
+	g := buildSensitivityTestGraph(t)
+	if _, err := ExpectedPathLength(g, "A", "Z"); err == nil {
+		t.Error("expected error for unknown end node")
+	}
+	if _, err := ExpectedPathLength(g, "Z", "A"); err == nil {
+		t.Error("expected error for unknown start node")
+	}
+
+	var ie InferenceError
+	if _, err := ExpectedPathLength(g, "D", "A"); !errors.As(err, &ie) || ie.Kind != "NoPath" {
+		t.Errorf("expected NoPath error with no path, got %v", err)
+	}
+	if err := g.AddEdge("eDA", "D", "A", 0, nil); err != nil {
+		t.Fatalf("AddEdge: %v", err)
+	}
+	if _, err := ExpectedPathLength(g, "D", "A"); !errors.As(err, &ie) || ie.Kind != "NoPath" {
+		t.Errorf("expected NoPath error with only a zero-probability path, got %v", err)
+	}
+}
@@ -1,57 +1,74 @@
 package inference
 
 import (
+	"context"
 	"fmt"
+	"slices"
 
 	"github.com/ritamzico/pgraph/internal/graph"
 )
 
+// dfsProbabilisticReachability returns the probability of reaching end from
+// current without revisiting any node on the current DFS stack. The boolean
+// result reports whether the value was truncated by a visited ancestor; such
+// values depend on the stack and must not be memoized, since the same node
+// reached via a different DFS path may see a different visited set. It
+// returns ctx's error as soon as ctx is done.
 func dfsProbabilisticReachability(
+	ctx context.Context,
 	g graph.ProbabilisticGraphModel,
 	current, end graph.NodeID,
 	visited map[graph.NodeID]bool,
 	memo map[graph.NodeID]float64,
-) (float64, error) {
+) (float64, bool, error) {
 	if current == end {
-		return 1.0, nil
+		return 1.0, false, nil
 	}
 
 	if val, ok := memo[current]; ok {
-		return val, nil
+		return val, false, nil
 	}
 
 	if visited[current] {
-		return 0.0, nil
+		return 0.0, true, nil
+	}
+	if err := ctx.Err(); err != nil {
+		return 0.0, false, err
 	}
 	visited[current] = true
 	defer delete(visited, current)
 
 	edges, err := g.OutgoingEdges(current)
 	if err != nil {
-		return 0.0, err
+		return 0.0, false, err
 	}
 
 	if len(edges) == 0 {
 		memo[current] = 0.0
-		return 0.0, nil
+		return 0.0, false, nil
 	}
 
 	failProb := 1.0
+	truncated := false
 
 	for _, edge := range edges {
-		childProb, err := dfsProbabilisticReachability(g, edge.To, end, visited, memo)
+		childProb, childTruncated, err := dfsProbabilisticReachability(ctx, g, edge.To, end, visited, memo)
 
 		if err != nil {
-			return 0.0, err
+			return 0.0, false, err
 		}
 
+		truncated = truncated || childTruncated
+
 		successViaEdge := edge.Probability * childProb
 		failProb *= 1.0 - successViaEdge
 	}
 
 	result := 1.0 - failProb
-	memo[current] = result
-	return result, nil
+	if !truncated {
+		memo[current] = result
+	}
+	return result, truncated, nil
 }
 
 func bfsDeterministicReachability(
@@ -104,3 +121,115 @@ func bfsDeterministicReachability(
 
 	return false, nil
 }
+
+// Ancestors returns every node from which target is reachable through at
+// least one edge, ignoring probabilities. target itself is included, so the
+// result is the node set of the subgraph that can reach it.
+func Ancestors(g graph.ProbabilisticGraphModel, target graph.NodeID) ([]graph.NodeID, error) {
+	if !g.ContainsNode(target) {
+		return nil, graph.NodeDoesNotExist(target)
+	}
+
+	visited := map[graph.NodeID]bool{target: true}
+	queue := []graph.NodeID{target}
+	result := []graph.NodeID{target}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		edges, err := g.IncomingEdges(current)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, edge := range edges {
+			if !visited[edge.From] {
+				visited[edge.From] = true
+				queue = append(queue, edge.From)
+				result = append(result, edge.From)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// Connected reports whether end is reachable from start with every edge
+// active. It is purely structural: edge probabilities, including zero, are
+// ignored.
+func Connected(g graph.ProbabilisticGraphModel, start, end graph.NodeID) (bool, error) {
+	allActive := make(map[*graph.Edge]bool)
+	for _, edge := range g.GetEdges() {
+		allActive[edge] = true
+	}
+	return bfsDeterministicReachability(g, start, end, allActive)
+}
+
+// UnreachableNodes returns, sorted by ID, every node that cannot be reached
+// from root with all edges active, ignoring probabilities. It is a quick
+// check for components accidentally left disconnected from a model's source.
+func UnreachableNodes(g graph.ProbabilisticGraphModel, root graph.NodeID) ([]graph.NodeID, error) {
+	if !g.ContainsNode(root) {
+		return nil, graph.NodeDoesNotExist(root)
+	}
+
+	visited := map[graph.NodeID]bool{root: true}
+	queue := []graph.NodeID{root}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		edges, err := g.OutgoingEdges(current)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, edge := range edges {
+			if !visited[edge.To] {
+				visited[edge.To] = true
+				queue = append(queue, edge.To)
+			}
+		}
+	}
+
+	var unreachable []graph.NodeID
+	for _, n := range g.GetNodes() {
+		if !visited[n.ID] {
+			unreachable = append(unreachable, n.ID)
+		}
+	}
+	slices.Sort(unreachable)
+	return unreachable, nil
+}
+
+// SourceNodes returns, sorted by ID, the nodes with no incoming edges: the
+// entry points of the graph, such as raw material suppliers.
+func SourceNodes(g graph.ProbabilisticGraphModel) []graph.NodeID {
+	return nodesWithoutEdge(g, func(e *graph.Edge) graph.NodeID { return e.To })
+}
+
+// SinkNodes returns, sorted by ID, the nodes with no outgoing edges: the
+// exit points of the graph, such as end customers.
+func SinkNodes(g graph.ProbabilisticGraphModel) []graph.NodeID {
+	return nodesWithoutEdge(g, func(e *graph.Edge) graph.NodeID { return e.From })
+}
+
+// nodesWithoutEdge returns the sorted IDs of nodes that are not endpoint(e)
+// for any edge e.
+func nodesWithoutEdge(g graph.ProbabilisticGraphModel, endpoint func(*graph.Edge) graph.NodeID) []graph.NodeID {
+	touched := make(map[graph.NodeID]bool)
+	for _, e := range g.GetEdges() {
+		touched[endpoint(e)] = true
+	}
+
+	var ids []graph.NodeID
+	for _, n := range g.GetNodes() {
+		if !touched[n.ID] {
+			ids = append(ids, n.ID)
+		}
+	}
+	slices.Sort(ids)
+	return ids
+}
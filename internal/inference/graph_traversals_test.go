@@ -0,0 +1,39 @@
+package inference
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/ritamzico/pgraph/internal/graph"
+)
+
+func TestSourceAndSinkNodes_Diamond(t *testing.T) {
+	g := buildSensitivityTestGraph(t)
+
+	if got := SourceNodes(g); !slices.Equal(got, []graph.NodeID{"A"}) {
+		t.Errorf("SourceNodes = %v, want [A]", got)
+	}
+	if got := SinkNodes(g); !slices.Equal(got, []graph.NodeID{"D"}) {
+		t.Errorf("SinkNodes = %v, want [D]", got)
+	}
+}
+
+func TestSourceAndSinkNodes_IsolatedAndBidirectional(t *testing.T) {
+	g := buildSensitivityTestGraph(t)
+	for _, id := range []graph.NodeID{"E", "F", "G"} {
+		if err := g.AddNode(id, nil); err != nil {
+			t.Fatalf("AddNode: %v", err)
+		}
+	}
+	// E is isolated; F and G are joined both ways.
+	if err := g.AddBidirectionalEdge("eFG", "F", "G", 0.5, nil); err != nil {
+		t.Fatalf("AddBidirectionalEdge: %v", err)
+	}
+
+	if got := SourceNodes(g); !slices.Equal(got, []graph.NodeID{"A", "E"}) {
+		t.Errorf("SourceNodes = %v, want [A E]", got)
+	}
+	if got := SinkNodes(g); !slices.Equal(got, []graph.NodeID{"D", "E"}) {
+		t.Errorf("SinkNodes = %v, want [D E]", got)
+	}
+}
@@ -0,0 +1,147 @@
+package inference
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ritamzico/pgraph/internal/graph"
+)
+
+type sourceTargetPair struct {
+	source, target graph.NodeID
+}
+
+// IncrementalReachabilityCache memoizes exact reachability probabilities
+// for one graph. Each computed pair keeps its DFS memo, which also answers
+// later queries from any node the DFS finished to the same target, and is
+// recorded as depending on every edge the DFS explored. EdgeUpdated then
+// drops only the pairs that explored the updated edge. Any other change of
+// the graph's version clears the cache. It is safe for concurrent use.
+type IncrementalReachabilityCache struct {
+	mu      sync.Mutex
+	version uint64
+	memos   map[sourceTargetPair]map[graph.NodeID]float64
+	deps    map[graph.EdgeID][]sourceTargetPair
+}
+
+func NewIncrementalReachabilityCache() *IncrementalReachabilityCache {
+	return &IncrementalReachabilityCache{
+		memos: make(map[sourceTargetPair]map[graph.NodeID]float64),
+		deps:  make(map[graph.EdgeID][]sourceTargetPair),
+	}
+}
+
+// Reachability is ReachabilityProbabilityContext, served from the cache
+// when an earlier DFS to end has already finished start.
+func (c *IncrementalReachabilityCache) Reachability(ctx context.Context, g graph.ProbabilisticGraphModel, start, end graph.NodeID) (float64, error) {
+	if start == end {
+		return ReachabilityProbabilityContext(ctx, g, start, end)
+	}
+
+	version := g.Version()
+	c.mu.Lock()
+	c.sync(version)
+	prob, ok := c.lookup(start, end)
+	c.mu.Unlock()
+	if ok {
+		return prob, nil
+	}
+
+	rg := &recordingGraph{ProbabilisticGraphModel: g, explored: make(map[graph.EdgeID]bool)}
+	memo := make(map[graph.NodeID]float64)
+	prob, _, err := dfsProbabilisticReachability(ctx, rg, start, end, make(map[graph.NodeID]bool), memo)
+	if err != nil {
+		return 0, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// The graph may have changed during the DFS; only store results for the
+	// version they were computed on.
+	if c.version == version {
+		pair := sourceTargetPair{start, end}
+		if _, ok := c.memos[pair]; !ok {
+			c.memos[pair] = memo
+			for id := range rg.explored {
+				c.deps[id] = append(c.deps[id], pair)
+			}
+		}
+	}
+	return prob, nil
+}
+
+// EdgeUpdated reports that edge id's probability changed, moving the graph
+// from version before to version after. Pairs whose DFS explored the edge
+// are dropped and the rest are kept for the new version. If the cache did
+// not hold before, it is cleared instead, since other changes may have been
+// missed.
+func (c *IncrementalReachabilityCache) EdgeUpdated(id graph.EdgeID, before, after uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.version != before {
+		c.reset(after)
+		return
+	}
+	for _, pair := range c.deps[id] {
+		delete(c.memos, pair)
+	}
+	delete(c.deps, id)
+	c.version = after
+}
+
+// Len returns the number of cached source-target pairs.
+func (c *IncrementalReachabilityCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.memos)
+}
+
+// sync clears the cache unless it holds version. The caller must hold c.mu.
+func (c *IncrementalReachabilityCache) sync(version uint64) {
+	if c.version != version {
+		c.reset(version)
+	}
+}
+
+// reset empties the cache for version. The caller must hold c.mu.
+func (c *IncrementalReachabilityCache) reset(version uint64) {
+	c.version = version
+	clear(c.memos)
+	clear(c.deps)
+}
+
+// lookup finds start's probability of reaching end in any memo for end.
+// A memo entry is only written for a node whose DFS was not cut short by
+// the stack, so it does not depend on which source the DFS began from. The
+// caller must hold c.mu.
+func (c *IncrementalReachabilityCache) lookup(start, end graph.NodeID) (float64, bool) {
+	if memo, ok := c.memos[sourceTargetPair{start, end}]; ok {
+		if prob, ok := memo[start]; ok {
+			return prob, true
+		}
+	}
+	for pair, memo := range c.memos {
+		if pair.target != end {
+			continue
+		}
+		if prob, ok := memo[start]; ok {
+			return prob, true
+		}
+	}
+	return 0, false
+}
+
+// recordingGraph notes every edge returned by OutgoingEdges, which is every
+// edge a DFS explores.
+type recordingGraph struct {
+	graph.ProbabilisticGraphModel
+	explored map[graph.EdgeID]bool
+}
+
+func (g *recordingGraph) OutgoingEdges(id graph.NodeID) ([]*graph.Edge, error) {
+	edges, err := g.ProbabilisticGraphModel.OutgoingEdges(id)
+	for _, e := range edges {
+		g.explored[e.ID] = true
+	}
+	return edges, err
+}
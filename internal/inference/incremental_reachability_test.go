@@ -0,0 +1,134 @@
+package inference
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/ritamzico/pgraph/internal/graph"
+)
+
+// buildIncrementalTestGraph is the sensitivity diamond A->{B,C}->D plus a
+// separate edge eEF from E to F.
+func buildIncrementalTestGraph(t *testing.T) graph.ProbabilisticGraphModel {
+	t.Helper()
+	g := buildSensitivityTestGraph(t)
+	for _, n := range []graph.NodeID{"E", "F"} {
+		if err := g.AddNode(n, nil); err != nil {
+			t.Fatalf("AddNode %s: %v", n, err)
+		}
+	}
+	if err := g.AddEdge("eEF", "E", "F", 0.5, nil); err != nil {
+		t.Fatalf("AddEdge eEF: %v", err)
+	}
+	return g
+}
+
+func cachedReachability(t *testing.T, c *IncrementalReachabilityCache, g graph.ProbabilisticGraphModel, start, end graph.NodeID) float64 {
+	t.Helper()
+	got, err := c.Reachability(context.Background(), g, start, end)
+	if err != nil {
+		t.Fatalf("Reachability %s->%s: %v", start, end, err)
+	}
+	want, err := ReachabilityProbability(g, start, end)
+	if err != nil {
+		t.Fatalf("ReachabilityProbability %s->%s: %v", start, end, err)
+	}
+	if math.Abs(got-want) > 1e-12 {
+		t.Errorf("%s->%s: cache returned %f, want %f", start, end, got, want)
+	}
+	return got
+}
+
+func TestIncrementalReachabilityCache_ReusesMemo(t *testing.T) {
+	g := buildIncrementalTestGraph(t)
+	c := NewIncrementalReachabilityCache()
+
+	cachedReachability(t, c, g, "A", "D")
+	if c.Len() != 1 {
+		t.Fatalf("expected 1 cached pair, got %d", c.Len())
+	}
+
+	// B was finished by the DFS from A, so B->D needs no new entry.
+	cachedReachability(t, c, g, "B", "D")
+	if c.Len() != 1 {
+		t.Errorf("expected B->D to come from A->D's memo, got %d cached pairs", c.Len())
+	}
+
+	cachedReachability(t, c, g, "D", "D")
+	cachedReachability(t, c, g, "E", "F")
+	if c.Len() != 2 {
+		t.Errorf("expected 2 cached pairs, got %d", c.Len())
+	}
+}
+
+func TestIncrementalReachabilityCache_EdgeUpdated(t *testing.T) {
+	g := buildIncrementalTestGraph(t)
+	c := NewIncrementalReachabilityCache()
+	cachedReachability(t, c, g, "A", "D")
+	cachedReachability(t, c, g, "E", "F")
+
+	update := func(id graph.EdgeID, prob float64) {
+		t.Helper()
+		before := g.Version()
+		if err := g.UpdateEdge(id, prob); err != nil {
+			t.Fatalf("UpdateEdge %s: %v", id, err)
+		}
+		c.EdgeUpdated(id, before, g.Version())
+	}
+
+	// eEF is outside the diamond, so A->D survives.
+	update("eEF", 0.25)
+	if c.Len() != 1 {
+		t.Fatalf("expected only A->D to remain, got %d cached pairs", c.Len())
+	}
+	if p := cachedReachability(t, c, g, "E", "F"); p != 0.25 {
+		t.Errorf("expected updated E->F probability 0.25, got %f", p)
+	}
+
+	update("eCD", 0.1)
+	if c.Len() != 1 {
+		t.Fatalf("expected only E->F to remain, got %d cached pairs", c.Len())
+	}
+	cachedReachability(t, c, g, "A", "D")
+}
+
+func TestIncrementalReachabilityCache_OtherMutationClears(t *testing.T) {
+	g := buildIncrementalTestGraph(t)
+	c := NewIncrementalReachabilityCache()
+	cachedReachability(t, c, g, "A", "D")
+	cachedReachability(t, c, g, "E", "F")
+
+	// A new edge changes A->D without EdgeUpdated being told.
+	if err := g.AddEdge("eAD", "A", "D", 0.5, nil); err != nil {
+		t.Fatalf("AddEdge: %v", err)
+	}
+	cachedReachability(t, c, g, "A", "D")
+	if c.Len() != 1 {
+		t.Errorf("expected the mutation to clear the cache, got %d cached pairs", c.Len())
+	}
+
+	// EdgeUpdated for a version the cache never held clears it too.
+	c.EdgeUpdated("eEF", 0, g.Version())
+	if c.Len() != 0 {
+		t.Errorf("expected an unknown prior version to clear the cache, got %d cached pairs", c.Len())
+	}
+}
+
+func TestIncrementalReachabilityCache_ErrorsNotCached(t *testing.T) {
+	g := buildIncrementalTestGraph(t)
+	c := NewIncrementalReachabilityCache()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := c.Reachability(ctx, g, "A", "D"); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if _, err := c.Reachability(context.Background(), g, "Z", "D"); err == nil {
+		t.Error("expected error for missing start node")
+	}
+	if c.Len() != 0 {
+		t.Errorf("expected failed computations not to be cached, got %d cached pairs", c.Len())
+	}
+}
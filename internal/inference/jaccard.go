@@ -0,0 +1,40 @@
+package inference
+
+import (
+	"math"
+
+	"github.com/ritamzico/pgraph/internal/graph"
+)
+
+// JaccardProbabilityEpsilon is how far apart two edges' probabilities may
+// be for JaccardEdgeSimilarity to count them as the same edge.
+const JaccardProbabilityEpsilon = 1e-9
+
+// JaccardEdgeSimilarity returns |E_a ∩ E_b| / |E_a ∪ E_b| for the edge sets
+// of a and b. Edges are matched by their endpoints, not their IDs, and only
+// count as shared if their probabilities differ by at most
+// JaccardProbabilityEpsilon; a matched pair that differs by more counts as
+// two distinct edges. Two graphs without edges have similarity 1.
+func JaccardEdgeSimilarity(a, b graph.ProbabilisticGraphModel) float64 {
+	type endpoints struct{ from, to graph.NodeID }
+
+	bProbs := make(map[endpoints]float64)
+	for _, e := range b.GetEdges() {
+		bProbs[endpoints{e.From, e.To}] = e.Probability
+	}
+
+	aEdges := a.GetEdges()
+	shared := 0
+	for _, e := range aEdges {
+		p, ok := bProbs[endpoints{e.From, e.To}]
+		if ok && math.Abs(p-e.Probability) <= JaccardProbabilityEpsilon {
+			shared++
+		}
+	}
+
+	union := len(aEdges) + len(bProbs) - shared
+	if union == 0 {
+		return 1
+	}
+	return float64(shared) / float64(union)
+}
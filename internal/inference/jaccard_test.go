@@ -0,0 +1,60 @@
+package inference
+
+import (
+	"math"
+	"testing"
+
+	"github.com/ritamzico/pgraph/internal/graph"
+)
+
+func TestJaccardEdgeSimilarity(t *testing.T) {
+	base := buildSensitivityTestGraph(t)
+
+	if got := JaccardEdgeSimilarity(base, base.Clone()); got != 1 {
+		t.Errorf("clone: expected 1, got %f", got)
+	}
+
+	// Removing one of four edges leaves 3 shared out of 4.
+	removed := base.Clone()
+	if err := removed.RemoveEdgeByID("eCD"); err != nil {
+		t.Fatalf("RemoveEdgeByID: %v", err)
+	}
+	if got := JaccardEdgeSimilarity(base, removed); math.Abs(got-0.75) > 1e-12 {
+		t.Errorf("removed edge: expected 0.75, got %f", got)
+	}
+
+	// A changed probability turns one shared edge into two distinct ones:
+	// 3 shared out of 5.
+	updated := base.Clone()
+	if err := updated.UpdateEdge("eAB", 0.5); err != nil {
+		t.Fatalf("UpdateEdge: %v", err)
+	}
+	if got := JaccardEdgeSimilarity(base, updated); math.Abs(got-0.6) > 1e-12 {
+		t.Errorf("updated edge: expected 0.6, got %f", got)
+	}
+	if got := JaccardEdgeSimilarity(updated, base); math.Abs(got-0.6) > 1e-12 {
+		t.Errorf("updated edge, swapped: expected 0.6, got %f", got)
+	}
+
+	// Edges match by endpoints, not ID.
+	renamed := base.Clone()
+	if err := renamed.RemoveEdgeByID("eAB"); err != nil {
+		t.Fatalf("RemoveEdgeByID: %v", err)
+	}
+	if err := renamed.AddEdge("other", "A", "B", 0.9, nil); err != nil {
+		t.Fatalf("AddEdge: %v", err)
+	}
+	if got := JaccardEdgeSimilarity(base, renamed); got != 1 {
+		t.Errorf("renamed edge: expected 1, got %f", got)
+	}
+}
+
+func TestJaccardEdgeSimilarity_NoEdges(t *testing.T) {
+	empty := graph.CreateProbAdjListGraph()
+	if got := JaccardEdgeSimilarity(empty, graph.CreateProbAdjListGraph()); got != 1 {
+		t.Errorf("two empty graphs: expected 1, got %f", got)
+	}
+	if got := JaccardEdgeSimilarity(empty, buildSensitivityTestGraph(t)); got != 0 {
+		t.Errorf("empty against non-empty: expected 0, got %f", got)
+	}
+}
@@ -4,13 +4,101 @@ import (
 	"container/heap"
 	"fmt"
 	"math"
+	"slices"
 
 	"github.com/ritamzico/pgraph/internal/graph"
 )
 
 // MaxProbabilityPath finds the path with the highest probability from start to end in a directed graph.
 // It uses a modified Dijkstra's algorithm to find the path with the highest probability.
+// Of several equally probable paths it returns the lexicographically smallest
+// node ID sequence.
 func MaxProbabilityPath(g graph.ProbabilisticGraphModel, start graph.NodeID, end graph.NodeID) (graph.Path, error) {
+	return maxProductPath(g, start, end, func(e *graph.Edge) (float64, error) {
+		return e.Probability, nil
+	})
+}
+
+// MaxPropertyPath is MaxProbabilityPath maximising the product of each edge's
+// numeric property key instead of its probability. Every edge the search
+// reaches must have key set to an int or float in [0, 1], since larger
+// values would give Dijkstra negative weights; MaxBottleneckPath accepts
+// any non-negative value. The returned path's Probability is still its
+// probability, not the product of key.
+func MaxPropertyPath(g graph.ProbabilisticGraphModel, start graph.NodeID, end graph.NodeID, key string) (graph.Path, error) {
+	return maxProductPath(g, start, end, func(e *graph.Edge) (float64, error) {
+		w, err := propertyWeight(e, key)
+		if err != nil {
+			return 0, err
+		}
+		if w > 1 {
+			return 0, InferenceError{
+				Kind:    "InvalidWeight",
+				Message: fmt.Sprintf("edge %v: %q must be in [0, 1] for a product, got %g; use a bottleneck path for larger weights", e.ID, key, w),
+			}
+		}
+		return w, nil
+	})
+}
+
+// MaxBottleneckPath finds the path from start to end whose smallest value
+// of the numeric edge property key is largest, such as the path with the
+// most capacity. Every edge the search reaches must have key set to a
+// non-negative int or float. Ties, and the returned path's Probability,
+// are as for MaxProbabilityPath.
+func MaxBottleneckPath(g graph.ProbabilisticGraphModel, start graph.NodeID, end graph.NodeID, key string) (graph.Path, error) {
+	// Dijkstra minimises the largest negated weight, which never decreases
+	// along a path.
+	return bestPath(g, start, end, math.Inf(-1), func(cost float64, e *graph.Edge) (float64, error) {
+		w, err := propertyWeight(e, key)
+		if err != nil {
+			return 0, err
+		}
+		return max(cost, -w), nil
+	})
+}
+
+// propertyWeight returns e's numeric property key, which must be
+// non-negative.
+func propertyWeight(e *graph.Edge, key string) (float64, error) {
+	v, ok := e.Props[key]
+	var w float64
+	switch {
+	case ok && v.Kind == graph.FloatVal:
+		w = v.F
+	case ok && v.Kind == graph.IntVal:
+		w = float64(v.I)
+	default:
+		return 0, InferenceError{
+			Kind:    "InvalidWeight",
+			Message: fmt.Sprintf("edge %v has no numeric %q property", e.ID, key),
+		}
+	}
+	if w < 0 || math.IsNaN(w) {
+		return 0, InferenceError{
+			Kind:    "InvalidWeight",
+			Message: fmt.Sprintf("edge %v: %q must not be negative, got %g", e.ID, key, w),
+		}
+	}
+	return w, nil
+}
+
+// maxProductPath runs Dijkstra on -log(weight(edge)), so the path found
+// maximises the product of edge weights.
+func maxProductPath(g graph.ProbabilisticGraphModel, start graph.NodeID, end graph.NodeID, weightOf func(*graph.Edge) (float64, error)) (graph.Path, error) {
+	return bestPath(g, start, end, 0, func(cost float64, e *graph.Edge) (float64, error) {
+		w, err := weightOf(e)
+		if err != nil {
+			return 0, err
+		}
+		return cost - math.Log(w), nil
+	})
+}
+
+// bestPath runs Dijkstra from start, where the path to start costs origin
+// and extend gives the cost of a path followed by one more edge. extend
+// must never decrease a cost.
+func bestPath(g graph.ProbabilisticGraphModel, start graph.NodeID, end graph.NodeID, origin float64, extend func(float64, *graph.Edge) (float64, error)) (graph.Path, error) {
 	if !g.ContainsNode(start) {
 		return graph.Path{}, graph.GraphError{
 			Kind: "NodeDoesNotExist",
@@ -26,19 +114,19 @@ func MaxProbabilityPath(g graph.ProbabilisticGraphModel, start graph.NodeID, end
 	}
 
 	dist := make(map[graph.NodeID]float64)
-	prev := make(map[graph.NodeID]graph.NodeID)
+	prev := make(map[graph.NodeID]*graph.Edge)
 
 	for _, node := range g.GetNodes() {
 		dist[node.ID] = math.Inf(1)
 	}
-	dist[start] = 0.0
+	dist[start] = origin
 
 	pq := &PriorityQueue{}
 	heap.Init(pq)
 
 	heap.Push(pq, &PQItem{
 		ID:       start,
-		Priority: 0.0,
+		Priority: origin,
 	})
 
 	for pq.Len() > 0 {
@@ -60,12 +148,25 @@ func MaxProbabilityPath(g graph.ProbabilisticGraphModel, start graph.NodeID, end
 		}
 
 		for _, edge := range outgoingEdges {
-			weight := -math.Log(edge.Probability) // Convert probability to negative log for max-heap
-			alt := dist[u] + weight
+			alt, err := extend(dist[u], edge)
+			if err != nil {
+				return graph.Path{}, err
+			}
 
-			if alt < dist[edge.To] {
+			// A zero-probability edge has infinite weight, so alt < dist never
+			// holds for it. Still record a predecessor for nodes that are otherwise
+			// undiscovered so that structurally connected targets yield a path
+			// with probability 0 rather than no path at all.
+			_, discovered := prev[edge.To]
+			if alt == dist[edge.To] && discovered && precedes(prev, start, u, edge.To) {
+				// Same distance by a lexicographically smaller route; the
+				// node is already queued at this priority.
+				prev[edge.To] = edge
+				continue
+			}
+			if alt < dist[edge.To] || (!discovered && edge.To != start) {
 				dist[edge.To] = alt
-				prev[edge.To] = u
+				prev[edge.To] = edge
 
 				heap.Push(pq, &PQItem{
 					ID:       edge.To,
@@ -76,18 +177,26 @@ func MaxProbabilityPath(g graph.ProbabilisticGraphModel, start graph.NodeID, end
 	}
 
 	// No path found
-	if math.IsInf(dist[end], 1) {
+	if _, ok := prev[end]; !ok && end != start {
 		return graph.Path{}, nil
 	}
 
-	// Reconstruct path
+	pathSlice, prob := tracePath(prev, start, end)
+	return graph.Path{NodeIDs: pathSlice, Probability: prob}, nil
+}
+
+// tracePath follows prev back from end to start and returns the path's
+// nodes in order along with its probability.
+func tracePath(prev map[graph.NodeID]*graph.Edge, start, end graph.NodeID) ([]graph.NodeID, float64) {
 	var pathSlice []graph.NodeID
+	prob := 1.0
 	for at := end; ; {
 		pathSlice = append(pathSlice, at)
 		if at == start {
 			break
 		}
-		at = prev[at]
+		prob *= prev[at].Probability
+		at = prev[at].From
 	}
 
 	// Reverse path
@@ -95,8 +204,94 @@ func MaxProbabilityPath(g graph.ProbabilisticGraphModel, start graph.NodeID, end
 		pathSlice[i], pathSlice[j] = pathSlice[j], pathSlice[i]
 	}
 
-	// Convert back to probability
-	prob := math.Exp(-dist[end])
+	return pathSlice, prob
+}
 
-	return graph.Path{NodeIDs: pathSlice, Probability: prob}, nil
+// precedes reports whether reaching to through u gives a lexicographically
+// smaller path than the one prev currently records for to. A route through
+// to itself is never smaller, since the recorded path is its prefix, so
+// this cannot make prev cyclic.
+func precedes(prev map[graph.NodeID]*graph.Edge, start, u, to graph.NodeID) bool {
+	via, _ := tracePath(prev, start, u)
+	current, _ := tracePath(prev, start, to)
+	return slices.Compare(append(via, to), current) < 0
+}
+
+// MaxProbabilityPathMaxHops is MaxProbabilityPath restricted to paths of at
+// most maxHops edges; zero or less means no limit. Dijkstra cannot bound
+// hops, so it runs a Bellman-Ford style relaxation in layers, where layer h
+// holds the most probable walk of exactly h edges to each node. Ties go to
+// the walk with fewer hops, then to the lexicographically smallest. A most
+// probable walk with the fewest hops never repeats a node, so the result is
+// a simple path.
+func MaxProbabilityPathMaxHops(g graph.ProbabilisticGraphModel, start graph.NodeID, end graph.NodeID, maxHops int) (graph.Path, error) {
+	if maxHops <= 0 {
+		return MaxProbabilityPath(g, start, end)
+	}
+	if !g.ContainsNode(start) {
+		return graph.Path{}, graph.NodeDoesNotExist(start)
+	}
+	if !g.ContainsNode(end) {
+		return graph.Path{}, graph.NodeDoesNotExist(end)
+	}
+
+	// A simple path has fewer edges than the graph has nodes.
+	maxHops = min(maxHops, len(g.GetNodes())-1)
+
+	// layers[h][v] is the best walk of h edges from start to v, if any.
+	type step struct {
+		prob float64
+		from graph.NodeID
+	}
+	layers := []map[graph.NodeID]step{{start: {prob: 1}}}
+	walk := func(h int, v graph.NodeID) []graph.NodeID {
+		nodes := make([]graph.NodeID, h+1)
+		for ; h >= 0; h-- {
+			nodes[h] = v
+			v = layers[h][v].from
+		}
+		return nodes
+	}
+
+	for h := 1; h <= maxHops; h++ {
+		prevLayer := layers[h-1]
+		layer := make(map[graph.NodeID]step)
+		for u, s := range prevLayer {
+			if u == end {
+				continue
+			}
+			edges, err := g.OutgoingEdges(u)
+			if err != nil {
+				return graph.Path{}, err
+			}
+			for _, e := range edges {
+				prob := s.prob * e.Probability
+				best, ok := layer[e.To]
+				if ok && (prob < best.prob || prob == best.prob &&
+					slices.Compare(walk(h-1, u), walk(h-1, best.from)) >= 0) {
+					continue
+				}
+				layer[e.To] = step{prob: prob, from: u}
+			}
+		}
+		if len(layer) == 0 {
+			break
+		}
+		layers = append(layers, layer)
+	}
+
+	bestHops := -1
+	for h := range layers {
+		s, ok := layers[h][end]
+		if !ok {
+			continue
+		}
+		if bestHops < 0 || s.prob > layers[bestHops][end].prob {
+			bestHops = h
+		}
+	}
+	if bestHops < 0 {
+		return graph.Path{}, nil
+	}
+	return graph.Path{NodeIDs: walk(bestHops, end), Probability: layers[bestHops][end].prob}, nil
 }
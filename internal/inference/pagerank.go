@@ -0,0 +1,76 @@
+package inference
+
+import (
+	"fmt"
+
+	"github.com/ritamzico/pgraph/internal/graph"
+)
+
+const (
+	DefaultPageRankDamping    = 0.85
+	DefaultPageRankIterations = 100
+)
+
+// ProbabilisticPageRank computes PageRank by power iteration, where a walker
+// at a node follows each outgoing edge in proportion to its probability. With
+// probability 1-dampingFactor, or when a node has no edge of positive
+// probability, the walker jumps to a uniformly random node. Scores sum to 1.
+func ProbabilisticPageRank(g graph.ProbabilisticGraphModel, dampingFactor float64, iterations int) (map[graph.NodeID]float64, error) {
+	if dampingFactor < 0.0 || dampingFactor > 1.0 {
+		return nil, InferenceError{
+			Kind:    "InvalidParameter",
+			Message: fmt.Sprintf("damping factor must be between 0 and 1, got %f", dampingFactor),
+		}
+	}
+	if iterations < 1 {
+		return nil, InferenceError{
+			Kind:    "InvalidParameter",
+			Message: fmt.Sprintf("iterations must be positive, got %d", iterations),
+		}
+	}
+
+	nodes := g.GetNodes()
+	n := float64(len(nodes))
+	rank := make(map[graph.NodeID]float64, len(nodes))
+	if len(nodes) == 0 {
+		return rank, nil
+	}
+
+	outgoing := make(map[graph.NodeID][]*graph.Edge, len(nodes))
+	outWeight := make(map[graph.NodeID]float64, len(nodes))
+	for _, node := range nodes {
+		rank[node.ID] = 1 / n
+		edges, err := g.OutgoingEdges(node.ID)
+		if err != nil {
+			return nil, err
+		}
+		outgoing[node.ID] = edges
+		for _, e := range edges {
+			outWeight[node.ID] += e.Probability
+		}
+	}
+
+	for range iterations {
+		next := make(map[graph.NodeID]float64, len(nodes))
+		dangling := 0.0
+
+		for _, node := range nodes {
+			r := rank[node.ID]
+			if outWeight[node.ID] == 0 {
+				dangling += r
+				continue
+			}
+			for _, e := range outgoing[node.ID] {
+				next[e.To] += dampingFactor * r * e.Probability / outWeight[node.ID]
+			}
+		}
+
+		base := (1-dampingFactor)/n + dampingFactor*dangling/n
+		for _, node := range nodes {
+			next[node.ID] += base
+		}
+		rank = next
+	}
+
+	return rank, nil
+}
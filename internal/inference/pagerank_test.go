@@ -0,0 +1,66 @@
+package inference
+
+import (
+	"math"
+	"testing"
+
+	"github.com/ritamzico/pgraph/internal/graph"
+)
+
+func TestProbabilisticPageRank_SumsToOne(t *testing.T) {
+	ranks, err := ProbabilisticPageRank(buildSensitivityTestGraph(t), DefaultPageRankDamping, DefaultPageRankIterations)
+	if err != nil {
+		t.Fatalf("ProbabilisticPageRank failed: %v", err)
+	}
+
+	total := 0.0
+	for _, r := range ranks {
+		total += r
+	}
+	if math.Abs(total-1.0) > 0.0001 {
+		t.Errorf("expected ranks to sum to 1, got %f", total)
+	}
+
+	// D collects every walk through the diamond; A only receives teleports.
+	if ranks["D"] <= ranks["B"] || ranks["D"] <= ranks["C"] || ranks["A"] >= ranks["B"] {
+		t.Errorf("unexpected ranks %v", ranks)
+	}
+	// eAB (0.9) outweighs eAC (0.8), so B gains more from A than C does.
+	if ranks["B"] <= ranks["C"] {
+		t.Errorf("expected B to outrank C, got B=%f C=%f", ranks["B"], ranks["C"])
+	}
+}
+
+func TestProbabilisticPageRank_WeightsByProbability(t *testing.T) {
+	g := graph.CreateProbAdjListGraph()
+	for _, n := range []graph.NodeID{"A", "B", "C"} {
+		g.AddNode(n, nil)
+	}
+	g.AddEdge("eAB", "A", "B", 0.9, nil)
+	g.AddEdge("eAC", "A", "C", 0.1, nil)
+	g.AddEdge("eBA", "B", "A", 1.0, nil)
+	g.AddEdge("eCB", "C", "B", 1.0, nil)
+
+	ranks, err := ProbabilisticPageRank(g, 1.0, 1000)
+	if err != nil {
+		t.Fatalf("ProbabilisticPageRank failed: %v", err)
+	}
+
+	// Without teleporting this is a plain Markov chain with stationary
+	// distribution A = B = 1/2.1 and C = 0.1/2.1.
+	for id, want := range map[graph.NodeID]float64{"A": 1 / 2.1, "B": 1 / 2.1, "C": 0.1 / 2.1} {
+		if math.Abs(ranks[id]-want) > 0.0001 {
+			t.Errorf("%s: expected %f, got %f", id, want, ranks[id])
+		}
+	}
+}
+
+func TestProbabilisticPageRank_InvalidParameters(t *testing.T) {
+	g := buildSensitivityTestGraph(t)
+	if _, err := ProbabilisticPageRank(g, 1.5, 10); err == nil {
+		t.Error("expected error for damping above 1")
+	}
+	if _, err := ProbabilisticPageRank(g, 0.85, 0); err == nil {
+		t.Error("expected error for zero iterations")
+	}
+}
@@ -1,6 +1,10 @@
 package inference
 
-import "github.com/ritamzico/pgraph/internal/graph"
+import (
+	"slices"
+
+	"github.com/ritamzico/pgraph/internal/graph"
+)
 
 type PQItem struct {
 	ID       graph.NodeID
@@ -37,3 +41,30 @@ func (pq *PriorityQueue) Pop() any {
 	*pq = old[0 : n-1]
 	return item
 }
+
+// pathHeap is a max-heap of candidate paths in TopKMaxProbabilityPaths,
+// ordered by probability. Equally probable paths are taken in lexicographic
+// order of their node IDs, so the result does not depend on the order the
+// candidates were found in.
+type pathHeap []graph.Path
+
+func (h pathHeap) Len() int { return len(h) }
+
+func (h pathHeap) Less(i, j int) bool {
+	if h[i].Probability != h[j].Probability {
+		return h[i].Probability > h[j].Probability
+	}
+	return slices.Compare(h[i].NodeIDs, h[j].NodeIDs) < 0
+}
+
+func (h pathHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *pathHeap) Push(x any) { *h = append(*h, x.(graph.Path)) }
+
+func (h *pathHeap) Pop() any {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
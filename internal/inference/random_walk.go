@@ -0,0 +1,68 @@
+package inference
+
+import (
+	"cmp"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+
+	"github.com/ritamzico/pgraph/internal/graph"
+)
+
+// RandomWalk simulates a walk of up to steps edges from start. At each node
+// the next edge is chosen with probability proportional to its edge
+// probability, normalized over the node's outgoing edges. The walk ends early
+// at a node with no outgoing edge of positive probability. The returned
+// path's Probability is the chance of taking exactly this walk.
+func RandomWalk(g graph.ProbabilisticGraphModel, start graph.NodeID, steps int, rng *rand.Rand) (graph.Path, error) {
+	if !g.ContainsNode(start) {
+		return graph.Path{}, graph.NodeDoesNotExist(start)
+	}
+	if steps < 0 {
+		return graph.Path{}, InferenceError{
+			Kind:    "InvalidParameter",
+			Message: fmt.Sprintf("steps must be non-negative, got %d", steps),
+		}
+	}
+
+	path := graph.Path{NodeIDs: []graph.NodeID{start}, Probability: 1.0}
+	current := start
+
+	for range steps {
+		edges, err := g.OutgoingEdges(current)
+		if err != nil {
+			return graph.Path{}, err
+		}
+
+		// Edge order from the graph is unspecified; sort so a seeded walk
+		// is reproducible.
+		slices.SortFunc(edges, func(a, b *graph.Edge) int { return cmp.Compare(a.ID, b.ID) })
+
+		var next *graph.Edge
+		total := 0.0
+		for _, e := range edges {
+			if e.Probability > 0 {
+				total += e.Probability
+				next = e // fallback for rounding at the top of the range
+			}
+		}
+		if next == nil {
+			break
+		}
+
+		r := rng.Float64() * total
+		for _, e := range edges {
+			if e.Probability > 0 && r < e.Probability {
+				next = e
+				break
+			}
+			r -= e.Probability
+		}
+
+		path.Probability *= next.Probability / total
+		path.NodeIDs = append(path.NodeIDs, next.To)
+		current = next.To
+	}
+
+	return path, nil
+}
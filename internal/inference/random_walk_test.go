@@ -0,0 +1,70 @@
+package inference
+
+import (
+	"math"
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/ritamzico/pgraph/internal/graph"
+)
+
+func TestRandomWalk_StopsAtSink(t *testing.T) {
+	g := buildSensitivityTestGraph(t)
+	rng := rand.New(rand.NewPCG(1, 2))
+
+	path, err := RandomWalk(g, "A", 10, rng)
+	if err != nil {
+		t.Fatalf("RandomWalk failed: %v", err)
+	}
+
+	// Every walk in the diamond is A -> B|C -> D, then D has no out-edges.
+	if len(path.NodeIDs) != 3 || path.NodeIDs[0] != "A" || path.NodeIDs[2] != "D" {
+		t.Fatalf("unexpected walk %v", path.NodeIDs)
+	}
+
+	// From A the choice is normalized over 0.9 + 0.8; the second step is forced.
+	want := 0.9 / 1.7
+	if path.NodeIDs[1] == "C" {
+		want = 0.8 / 1.7
+	}
+	if math.Abs(path.Probability-want) > 0.0001 {
+		t.Errorf("expected walk probability %f, got %f", want, path.Probability)
+	}
+}
+
+func TestRandomWalk_SeedIsReproducible(t *testing.T) {
+	g := graph.CreateProbAdjListGraph()
+	g.AddNode("A", nil)
+	g.AddNode("B", nil)
+	g.AddEdge("eAB", "A", "B", 0.5, nil)
+	g.AddEdge("eBA", "B", "A", 0.5, nil)
+	g.AddNode("C", nil)
+	g.AddEdge("eAC", "A", "C", 0.5, nil)
+	g.AddEdge("eCA", "C", "A", 0.5, nil)
+
+	first, err := RandomWalk(g, "A", 20, rand.New(rand.NewPCG(7, 7)))
+	if err != nil {
+		t.Fatalf("RandomWalk failed: %v", err)
+	}
+	second, _ := RandomWalk(g, "A", 20, rand.New(rand.NewPCG(7, 7)))
+
+	if len(first.NodeIDs) != 21 {
+		t.Errorf("expected 21 nodes for 20 steps, got %d", len(first.NodeIDs))
+	}
+	if !slices.Equal(first.NodeIDs, second.NodeIDs) {
+		t.Errorf("same seed gave different walks: %v vs %v", first.NodeIDs, second.NodeIDs)
+	}
+}
+
+func TestRandomWalk_Errors(t *testing.T) {
+	g := buildSensitivityTestGraph(t)
+	rng := rand.New(rand.NewPCG(1, 2))
+
+	if _, err := RandomWalk(g, "Z", 3, rng); err == nil {
+		t.Error("expected error for unknown start node")
+	}
+	if _, err := RandomWalk(g, "A", -1, rng); err == nil {
+		t.Error("expected error for negative steps")
+	}
+}
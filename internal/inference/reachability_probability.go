@@ -1,6 +1,7 @@
 package inference
 
 import (
+	"context"
 	"fmt"
 	"math"
 	"math/rand/v2"
@@ -12,10 +13,19 @@ import (
 )
 
 func ReachabilityProbability(g graph.ProbabilisticGraphModel, start, end graph.NodeID) (float64, error) {
+	return ReachabilityProbabilityContext(context.Background(), g, start, end)
+}
+
+// ReachabilityProbabilityContext is ReachabilityProbability that gives up
+// once ctx is done, returning ctx's error. ctx is checked as each node is
+// entered, so a cancelled search stops promptly even on graphs where the
+// exact computation would take a long time.
+func ReachabilityProbabilityContext(ctx context.Context, g graph.ProbabilisticGraphModel, start, end graph.NodeID) (float64, error) {
 	visited := make(map[graph.NodeID]bool)
 	memo := make(map[graph.NodeID]float64)
 
-	return dfsProbabilisticReachability(g, start, end, visited, memo)
+	prob, _, err := dfsProbabilisticReachability(ctx, g, start, end, visited, memo)
+	return prob, err
 }
 
 func ReachabilityProbabilityMonteCarlo(
@@ -30,11 +40,83 @@ func ReachabilityProbabilityMonteCarlo(
 		return result.SampleResult{}, fmt.Errorf("numSamples must be greater than 0")
 	}
 
+	successes, err := countReachableSamples(g, start, end, numSamples, seed)
+	if err != nil {
+		return result.SampleResult{}, err
+	}
+	return sampleResultFromCounts(successes, numSamples), nil
+}
+
+// Limits for ReachabilityProbabilityTargetCI: the first round's sample count
+// and the total after which it gives up.
+const (
+	targetCIInitialSamples = 1000
+	targetCIMaxSamples     = 1 << 24
+)
+
+// ReachabilityProbabilityTargetCI estimates the probability that end is
+// reachable from start by Monte Carlo, sampling until the 95% confidence
+// interval is at most targetWidth wide. It starts with 1000 samples and
+// doubles the total each round, keeping earlier samples. It fails if the
+// target is not met within targetCIMaxSamples samples or ctx is cancelled
+// between rounds.
+func ReachabilityProbabilityTargetCI(
+	ctx context.Context,
+	g graph.ProbabilisticGraphModel,
+	start, end graph.NodeID,
+	targetWidth float64,
+	seed uint64,
+) (result.SampleResult, error) {
+	if !(targetWidth > 0 && targetWidth <= 1) {
+		return result.SampleResult{}, InferenceError{
+			Kind:    "InvalidParameter",
+			Message: fmt.Sprintf("target CI width must be in (0, 1], got %g", targetWidth),
+		}
+	}
+
+	successes, total := 0, 0
+	batch := targetCIInitialSamples
+	for round := uint64(0); ; round++ {
+		if err := ctx.Err(); err != nil {
+			return result.SampleResult{}, err
+		}
+
+		// Each round needs fresh samples; workers within a round already
+		// offset the seed by their ID, so scramble it per round instead.
+		s, err := countReachableSamples(g, start, end, batch, seed^(round*0x9e3779b97f4a7c15))
+		if err != nil {
+			return result.SampleResult{}, err
+		}
+		successes += s
+		total += batch
+
+		r := sampleResultFromCounts(successes, total)
+		if r.CI95High-r.CI95Low <= targetWidth {
+			return r, nil
+		}
+		if total >= targetCIMaxSamples {
+			return result.SampleResult{}, InferenceError{
+				Kind: "NotConverged",
+				Message: fmt.Sprintf("95%% CI width %g still above %g after %d samples",
+					r.CI95High-r.CI95Low, targetWidth, total),
+			}
+		}
+		batch = total
+	}
+}
+
+// countReachableSamples samples numSamples worlds across GOMAXPROCS workers
+// and returns how many of them connect start to end.
+func countReachableSamples(
+	g graph.ProbabilisticGraphModel,
+	start, end graph.NodeID,
+	numSamples int,
+	seed uint64,
+) (int, error) {
 	numWorkers := min(runtime.GOMAXPROCS(0), numSamples)
 
 	type workerResult struct {
 		successes int
-		trials    int
 		err       error
 	}
 
@@ -75,35 +157,36 @@ func ReachabilityProbabilityMonteCarlo(
 				}
 			}
 
-			results <- workerResult{
-				successes: successes,
-				trials:    trials,
-			}
+			results <- workerResult{successes: successes}
 		}(w, trials)
 	}
 
 	totalSuccesses := 0
-	totalTrials := 0
-
+	var firstErr error
 	for i := 0; i < numWorkers; i++ {
 		r := <-results
-		if r.err != nil {
-			return result.SampleResult{}, r.err
+		if r.err != nil && firstErr == nil {
+			firstErr = r.err
 		}
 		totalSuccesses += r.successes
-		totalTrials += r.trials
 	}
+	if firstErr != nil {
+		return 0, firstErr
+	}
+	return totalSuccesses, nil
+}
 
-	p := float64(totalSuccesses) / float64(totalTrials)
+func sampleResultFromCounts(successes, trials int) result.SampleResult {
+	p := float64(successes) / float64(trials)
 	variance := p * (1 - p)
-	stderr := math.Sqrt(variance / float64(totalTrials))
+	stderr := math.Sqrt(variance / float64(trials))
 
 	return result.SampleResult{
 		Estimate:   p,
-		NumSamples: numSamples,
+		NumSamples: trials,
 		Variance:   variance,
 		StdErr:     stderr,
 		CI95Low:    p - sampling.CI95ZScore*stderr,
 		CI95High:   p + sampling.CI95ZScore*stderr,
-	}, nil
+	}
 }
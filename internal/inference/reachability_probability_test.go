@@ -0,0 +1,48 @@
+package inference
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestReachabilityProbabilityTargetCI_MeetsWidth(t *testing.T) {
+	g := buildSensitivityTestGraph(t)
+
+	for _, width := range []float64{0.05, 0.02, 0.01} {
+		r, err := ReachabilityProbabilityTargetCI(context.Background(), g, "A", "D", width, 42)
+		if err != nil {
+			t.Fatalf("width %g: %v", width, err)
+		}
+		if got := r.CI95High - r.CI95Low; got > width {
+			t.Errorf("width %g: CI width %g exceeds target", width, got)
+		}
+		// Sample counts follow the doubling schedule from 1000.
+		n := r.NumSamples / targetCIInitialSamples
+		if r.NumSamples%targetCIInitialSamples != 0 || n&(n-1) != 0 {
+			t.Errorf("width %g: %d samples is not 1000 times a power of two", width, r.NumSamples)
+		}
+		if math.Abs(r.Estimate-0.8076) > width {
+			t.Errorf("width %g: estimate %f too far from 0.8076", width, r.Estimate)
+		}
+	}
+}
+
+func TestReachabilityProbabilityTargetCI_Errors(t *testing.T) {
+	g := buildSensitivityTestGraph(t)
+
+	for _, width := range []float64{0, -0.1, 1.5, math.NaN()} {
+		_, err := ReachabilityProbabilityTargetCI(context.Background(), g, "A", "D", width, 1)
+		var ie InferenceError
+		if !errors.As(err, &ie) || ie.Kind != "InvalidParameter" {
+			t.Errorf("width %g: expected InvalidParameter, got %v", width, err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := ReachabilityProbabilityTargetCI(ctx, g, "A", "D", 0.02, 1); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
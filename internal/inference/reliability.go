@@ -0,0 +1,43 @@
+package inference
+
+import (
+	"github.com/ritamzico/pgraph/internal/graph"
+	"github.com/ritamzico/pgraph/internal/result"
+)
+
+// ReliabilityPolynomialSteps is the number of intervals R(p) is evaluated
+// over, giving points at p = 0, 1/steps, ..., 1.
+const ReliabilityPolynomialSteps = 10
+
+// ReliabilityPolynomial evaluates the network reliability polynomial R(p):
+// the exact reachability probability from start to end when every edge is
+// given the same probability p. The graph's own probabilities are ignored.
+func ReliabilityPolynomial(g graph.ProbabilisticGraphModel, start, end graph.NodeID) (result.PolynomialResult, error) {
+	for _, id := range []graph.NodeID{start, end} {
+		if !g.ContainsNode(id) {
+			return result.PolynomialResult{}, graph.NodeDoesNotExist(id)
+		}
+	}
+
+	edges := g.GetEdges()
+	points := make([]result.PolynomialPoint, 0, ReliabilityPolynomialSteps+1)
+
+	for i := 0; i <= ReliabilityPolynomialSteps; i++ {
+		p := float64(i) / ReliabilityPolynomialSteps
+
+		uniform := g.Clone()
+		for _, e := range edges {
+			if err := uniform.UpdateEdge(e.ID, p); err != nil {
+				return result.PolynomialResult{}, err
+			}
+		}
+
+		r, err := ReachabilityProbability(uniform, start, end)
+		if err != nil {
+			return result.PolynomialResult{}, err
+		}
+		points = append(points, result.PolynomialPoint{P: p, Reliability: r})
+	}
+
+	return result.PolynomialResult{Points: points}, nil
+}
@@ -0,0 +1,39 @@
+package inference
+
+import (
+	"math"
+	"testing"
+)
+
+func TestReliabilityPolynomial_Diamond(t *testing.T) {
+	g := buildSensitivityTestGraph(t)
+
+	res, err := ReliabilityPolynomial(g, "A", "D")
+	if err != nil {
+		t.Fatalf("ReliabilityPolynomial failed: %v", err)
+	}
+	if len(res.Points) != ReliabilityPolynomialSteps+1 {
+		t.Fatalf("expected %d points, got %d", ReliabilityPolynomialSteps+1, len(res.Points))
+	}
+
+	// Two edge-disjoint paths of length two: R(p) = 1 - (1 - p^2)^2.
+	for i, pt := range res.Points {
+		p := float64(i) / ReliabilityPolynomialSteps
+		want := 1 - math.Pow(1-p*p, 2)
+		if math.Abs(pt.P-p) > 0.0001 || math.Abs(pt.Reliability-want) > 0.0001 {
+			t.Errorf("point %d: expected (%f, %f), got (%f, %f)", i, p, want, pt.P, pt.Reliability)
+		}
+	}
+
+	// The input graph keeps its own probabilities.
+	edge, _ := g.GetEdge("A", "B")
+	if edge.Probability != 0.9 {
+		t.Errorf("expected original probability 0.9, got %f", edge.Probability)
+	}
+}
+
+func TestReliabilityPolynomial_UnknownNode(t *testing.T) {
+	if _, err := ReliabilityPolynomial(buildSensitivityTestGraph(t), "A", "Z"); err == nil {
+		t.Error("expected error for unknown node")
+	}
+}
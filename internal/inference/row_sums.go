@@ -0,0 +1,49 @@
+package inference
+
+import (
+	"math"
+	"slices"
+
+	"github.com/ritamzico/pgraph/internal/graph"
+)
+
+// DefaultRowSumEpsilon is how far a row sum may be from 1 before
+// InvalidRowSums reports it, absorbing floating point rounding.
+const DefaultRowSumEpsilon = 1e-9
+
+// RowSum returns the sum of the probabilities of id's outgoing edges: the
+// node's row of the graph read as a transition matrix.
+func RowSum(g graph.ProbabilisticGraphModel, id graph.NodeID) (float64, error) {
+	if !g.ContainsNode(id) {
+		return 0, graph.NodeDoesNotExist(id)
+	}
+	edges, err := g.OutgoingEdges(id)
+	if err != nil {
+		return 0, err
+	}
+	sum := 0.0
+	for _, e := range edges {
+		sum += e.Probability
+	}
+	return sum, nil
+}
+
+// InvalidRowSums returns, sorted by ID, the nodes whose RowSum differs from
+// 1 by more than epsilon, i.e. the rows that keep the graph from being a
+// valid Markov chain. A node without outgoing edges sums to 0 and is
+// reported; an absorbing state needs a self-loop of probability 1.
+func InvalidRowSums(g graph.ProbabilisticGraphModel, epsilon float64) []graph.NodeID {
+	sums := make(map[graph.NodeID]float64)
+	for _, e := range g.GetEdges() {
+		sums[e.From] += e.Probability
+	}
+
+	var ids []graph.NodeID
+	for _, n := range g.GetNodes() {
+		if math.Abs(sums[n.ID]-1) > epsilon {
+			ids = append(ids, n.ID)
+		}
+	}
+	slices.Sort(ids)
+	return ids
+}
@@ -0,0 +1,75 @@
+package inference
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/ritamzico/pgraph/internal/graph"
+)
+
+func TestRowSum(t *testing.T) {
+	g := buildSensitivityTestGraph(t)
+
+	tests := []struct {
+		node graph.NodeID
+		want float64
+	}{
+		{"A", 1.7},
+		{"B", 0.7},
+		{"D", 0},
+	}
+	for _, tt := range tests {
+		got, err := RowSum(g, tt.node)
+		if err != nil {
+			t.Fatalf("RowSum %s: %v", tt.node, err)
+		}
+		if math.Abs(got-tt.want) > 1e-12 {
+			t.Errorf("RowSum %s = %f, want %f", tt.node, got, tt.want)
+		}
+	}
+
+	if _, err := RowSum(g, "Z"); err == nil {
+		t.Error("expected error for missing node")
+	}
+}
+
+func TestInvalidRowSums(t *testing.T) {
+	g := graph.CreateProbAdjListGraph()
+	for _, id := range []graph.NodeID{"A", "B", "C"} {
+		if err := g.AddNode(id, nil); err != nil {
+			t.Fatalf("AddNode: %v", err)
+		}
+	}
+	// A and C are valid rows (C is absorbing); B sums to 0.95.
+	edges := []struct {
+		id       graph.EdgeID
+		from, to graph.NodeID
+		prob     float64
+	}{
+		{"eAB", "A", "B", 0.3},
+		{"eAC", "A", "C", 0.7},
+		{"eBA", "B", "A", 0.5},
+		{"eBC", "B", "C", 0.45},
+		{"eCC", "C", "C", 1},
+	}
+	for _, e := range edges {
+		if err := g.AddEdge(e.id, e.from, e.to, e.prob, nil); err != nil {
+			t.Fatalf("AddEdge %s: %v", e.id, err)
+		}
+	}
+
+	if got := InvalidRowSums(g, DefaultRowSumEpsilon); !slices.Equal(got, []graph.NodeID{"B"}) {
+		t.Errorf("InvalidRowSums = %v, want [B]", got)
+	}
+	if got := InvalidRowSums(g, 0.1); len(got) != 0 {
+		t.Errorf("InvalidRowSums with epsilon 0.1 = %v, want none", got)
+	}
+
+	if err := g.AddNode("D", nil); err != nil {
+		t.Fatalf("AddNode: %v", err)
+	}
+	if got := InvalidRowSums(g, 0.1); !slices.Equal(got, []graph.NodeID{"D"}) {
+		t.Errorf("expected the node without outgoing edges to be reported, got %v", got)
+	}
+}
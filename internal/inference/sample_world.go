@@ -0,0 +1,32 @@
+package inference
+
+import (
+	"cmp"
+	"math/rand/v2"
+	"slices"
+
+	"github.com/ritamzico/pgraph/internal/graph"
+)
+
+// SampleWorld draws one possible world: each edge is independently active
+// with its probability. Edges are visited in ID order, so the same rng
+// state always yields the same world. Both returned slices are sorted by ID.
+// A bidirectional edge is drawn once and reported under its own ID; its
+// reverse half is internal and never appears.
+func SampleWorld(g graph.ProbabilisticGraphModel, rng *rand.Rand) (active, inactive []graph.EdgeID) {
+	edges := g.FilterEdges(func(e *graph.Edge) bool { return !e.IsReverseHalf() })
+	slices.SortFunc(edges, func(a, b *graph.Edge) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	active = []graph.EdgeID{}
+	inactive = []graph.EdgeID{}
+	for _, e := range edges {
+		if rng.Float64() <= e.Probability {
+			active = append(active, e.ID)
+		} else {
+			inactive = append(inactive, e.ID)
+		}
+	}
+	return active, inactive
+}
@@ -0,0 +1,48 @@
+package inference
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/ritamzico/pgraph/internal/graph"
+)
+
+func TestSampleWorld_Frequencies(t *testing.T) {
+	g := buildSensitivityTestGraph(t)
+	rng := rand.New(rand.NewPCG(3, 4))
+
+	const trials = 20000
+	counts := make(map[graph.EdgeID]int)
+	for range trials {
+		active, inactive := SampleWorld(g, rng)
+		if len(active)+len(inactive) != 4 {
+			t.Fatalf("expected 4 edges, got %v and %v", active, inactive)
+		}
+		for _, id := range active {
+			counts[id]++
+		}
+	}
+
+	for _, e := range g.GetEdges() {
+		freq := float64(counts[e.ID]) / trials
+		if math.Abs(freq-e.Probability) > 0.02 {
+			t.Errorf("edge %s active %.3f of the time, want ~%.1f", e.ID, freq, e.Probability)
+		}
+	}
+}
+
+func TestSampleWorld_BidirectionalEdgeReportedOnce(t *testing.T) {
+	g := graph.CreateProbAdjListGraph()
+	g.AddNode("B", nil)
+	g.AddNode("C", nil)
+	if err := g.AddBidirectionalEdge("bc", "B", "C", 0.5, nil); err != nil {
+		t.Fatalf("AddBidirectionalEdge: %v", err)
+	}
+
+	active, inactive := SampleWorld(g, rand.New(rand.NewPCG(1, 2)))
+	all := append(active, inactive...)
+	if len(all) != 1 || all[0] != "bc" {
+		t.Errorf("expected only edge bc, got %v", all)
+	}
+}
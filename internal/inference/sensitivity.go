@@ -36,7 +36,9 @@ func sensitivityAnalysis(
 		return result.SensitivityResult{}, err
 	}
 
-	edges := g.GetEdges()
+	// Forcing either half of a bidirectional edge inactive removes both, so
+	// the pair is scored once under its forward ID.
+	edges := g.FilterEdges(func(e *graph.Edge) bool { return !e.IsReverseHalf() })
 	if len(edges) == 0 {
 		return result.SensitivityResult{Baseline: baseline}, nil
 	}
@@ -1,7 +1,12 @@
 package inference
 
 import (
+	"container/heap"
+	"context"
 	"fmt"
+	"runtime"
+	"strings"
+	"sync"
 
 	"github.com/ritamzico/pgraph/internal/graph"
 )
@@ -32,95 +37,201 @@ func pathProbability(g graph.ProbabilisticGraphModel, nodes []graph.NodeID) floa
 	return prob
 }
 
+// PathProbability returns the product of the edge probabilities along
+// nodes. Every node and every consecutive edge must exist.
+func PathProbability(g graph.ProbabilisticGraphModel, nodes []graph.NodeID) (float64, error) {
+	for _, n := range nodes {
+		if !g.ContainsNode(n) {
+			return 0, graph.NodeDoesNotExist(n)
+		}
+	}
+	for i := 0; i < len(nodes)-1; i++ {
+		if !g.ContainsEdge(nodes[i], nodes[i+1]) {
+			return 0, graph.EdgeDoesNotExist(nodes[i], nodes[i+1])
+		}
+	}
+	return pathProbability(g, nodes), nil
+}
+
+// pathKey identifies a path by its node sequence.
+func pathKey(nodes []graph.NodeID) string {
+	var sb strings.Builder
+	for _, n := range nodes {
+		sb.WriteString(string(n))
+		sb.WriteByte(0)
+	}
+	return sb.String()
+}
+
+// TopKOptions tunes TopKMaxProbabilityPathsWithOptions.
+type TopKOptions struct {
+	// Parallelism bounds how many spur paths are computed concurrently
+	// within one iteration. Zero or less means runtime.GOMAXPROCS(0); one
+	// runs them serially.
+	Parallelism int
+
+	// MinProbability stops the search at the first path less probable than
+	// it, so fewer than k paths may be returned. Zero disables the cutoff.
+	MinProbability float64
+
+	// MaxHops limits paths to at most that many edges. Zero or less means
+	// no limit.
+	MaxHops int
+}
+
 // TopKMaxProbabilityPaths finds the top k most probable paths from start to end.
 // It uses MaxProbabilityPath and the Yen's K-Shortest Paths algorithm.
+// Ties between equally probable paths go to the lexicographically smallest
+// node ID sequence, so the result is the same on every run.
 func TopKMaxProbabilityPaths(g graph.ProbabilisticGraphModel, start graph.NodeID, end graph.NodeID, k int) ([]graph.Path, error) {
+	return TopKMaxProbabilityPathsWithOptions(g, start, end, k, TopKOptions{})
+}
+
+// TopKMaxProbabilityPathsWithOptions is TopKMaxProbabilityPaths with
+// configurable parallelism. The result does not depend on opts.
+func TopKMaxProbabilityPathsWithOptions(g graph.ProbabilisticGraphModel, start graph.NodeID, end graph.NodeID, k int, opts TopKOptions) ([]graph.Path, error) {
+	paths, _, err := TopKMaxProbabilityPathsContext(context.Background(), g, start, end, k, opts)
+	return paths, err
+}
+
+// TopKMaxProbabilityPathsContext is TopKMaxProbabilityPathsWithOptions that
+// stops early once ctx is done. ctx is checked before each path is found,
+// so on expiry it returns the paths found so far, which are still the most
+// probable ones, with truncated set and a nil error.
+func TopKMaxProbabilityPathsContext(ctx context.Context, g graph.ProbabilisticGraphModel, start graph.NodeID, end graph.NodeID, k int, opts TopKOptions) (paths []graph.Path, truncated bool, err error) {
 	if k <= 0 {
-		return nil, fmt.Errorf("k must be greater than 0")
+		return nil, false, fmt.Errorf("k must be greater than 0")
+	}
+	if ctx.Err() != nil {
+		return nil, true, nil
+	}
+
+	parallelism := opts.Parallelism
+	if parallelism <= 0 {
+		parallelism = runtime.GOMAXPROCS(0)
 	}
 
 	var results []graph.Path
-	var candidates []graph.Path
+	candidates := &pathHeap{}
+	// seen holds every path already accepted or queued as a candidate.
+	seen := make(map[string]struct{})
 
-	firstPath, err := MaxProbabilityPath(g, start, end)
+	firstPath, err := MaxProbabilityPathMaxHops(g, start, end, opts.MaxHops)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
-	if len(firstPath.NodeIDs) == 0 {
-		return nil, nil
+	if len(firstPath.NodeIDs) == 0 || firstPath.Probability < opts.MinProbability {
+		return nil, false, nil
 	}
 
 	results = append(results, firstPath)
+	seen[pathKey(firstPath.NodeIDs)] = struct{}{}
 
 	for i := 1; i < k; i++ {
-		prevPath := results[i-1]
-
-		for spurIdx := 0; spurIdx < len(prevPath.NodeIDs)-1; spurIdx++ {
-			spurNode := prevPath.NodeIDs[spurIdx]
-			rootPathNodes := prevPath.NodeIDs[:spurIdx+1]
-
-			gClone := g.Clone()
+		if ctx.Err() != nil {
+			return results, true, nil
+		}
 
-			// Remove edges that would recreate previous paths
-			for _, p := range results {
-				if len(p.NodeIDs) > spurIdx &&
-					equalNodePrefix(p.NodeIDs, rootPathNodes) {
+		prevPath := results[i-1]
+		spurs := make([][]graph.NodeID, len(prevPath.NodeIDs)-1)
 
-					from := p.NodeIDs[spurIdx]
-					to := p.NodeIDs[spurIdx+1]
-					_ = gClone.RemoveEdge(from, to)
-				}
+		if parallelism == 1 {
+			for spurIdx := range spurs {
+				spurs[spurIdx] = spurPath(g, results, prevPath, spurIdx, end, opts.MaxHops)
+			}
+		} else {
+			var wg sync.WaitGroup
+			sem := make(chan struct{}, parallelism)
+			for spurIdx := range spurs {
+				wg.Add(1)
+				sem <- struct{}{}
+				go func() {
+					defer wg.Done()
+					defer func() { <-sem }()
+					spurs[spurIdx] = spurPath(g, results, prevPath, spurIdx, end, opts.MaxHops)
+				}()
 			}
+			wg.Wait()
+		}
 
-			// Spur path
-			spurPath, err := MaxProbabilityPath(gClone, spurNode, end)
-			if err != nil || len(spurPath.NodeIDs) == 0 {
+		// Queue in spur order so the seen check matches a serial run
+		for _, fullNodes := range spurs {
+			if fullNodes == nil {
 				continue
 			}
 
-			// Combine root + spur (avoid duplicating spurNode)
-			fullNodes := append(
-				append([]graph.NodeID{}, rootPathNodes[:len(rootPathNodes)-1]...),
-				spurPath.NodeIDs...,
-			)
-
-			fullProb := pathProbability(g, fullNodes)
-
-			// Check for duplicates in candidates before adding
-			isDuplicate := false
-			for _, c := range candidates {
-				if len(c.NodeIDs) == len(fullNodes) && equalNodePrefix(c.NodeIDs, fullNodes) {
-					isDuplicate = true
-					break
-				}
+			// Skip paths already accepted or queued
+			key := pathKey(fullNodes)
+			if _, dup := seen[key]; dup {
+				continue
 			}
+			seen[key] = struct{}{}
 
-			if !isDuplicate {
-				candidates = append(candidates, graph.Path{
-					NodeIDs:     fullNodes,
-					Probability: fullProb,
-				})
-			}
+			heap.Push(candidates, graph.Path{
+				NodeIDs:     fullNodes,
+				Probability: pathProbability(g, fullNodes),
+			})
 		}
 
-		if len(candidates) == 0 {
+		if candidates.Len() == 0 {
 			break
 		}
 
-		// Pick best candidate
-		bestIdx := 0
-		for j := 1; j < len(candidates); j++ {
-			if candidates[j].Probability > candidates[bestIdx].Probability {
-				bestIdx = j
-			}
+		// Take the best candidate; paths arrive in non-increasing probability,
+		// so once one falls below the cutoff every later one does too
+		best := heap.Pop(candidates).(graph.Path)
+		if best.Probability < opts.MinProbability {
+			break
+		}
+		results = append(results, best)
+	}
+
+	return results, false, nil
+}
+
+// spurPath returns the full root+spur path deviating from prevPath at
+// spurIdx, or nil if there is none. A positive maxHops bounds the full path's
+// edges, leaving the spur the hops the root has not used. It only reads g
+// and results, so calls for different spurIdx may run concurrently.
+func spurPath(g graph.ProbabilisticGraphModel, results []graph.Path, prevPath graph.Path, spurIdx int, end graph.NodeID, maxHops int) []graph.NodeID {
+	spurHops := 0
+	if maxHops > 0 {
+		spurHops = maxHops - spurIdx
+		if spurHops <= 0 {
+			return nil
 		}
+	}
+	spurNode := prevPath.NodeIDs[spurIdx]
+	rootPathNodes := prevPath.NodeIDs[:spurIdx+1]
+
+	gClone := g.Clone()
 
-		results = append(results, candidates[bestIdx])
+	// Remove edges that would recreate previous paths
+	for _, p := range results {
+		if len(p.NodeIDs) > spurIdx &&
+			equalNodePrefix(p.NodeIDs, rootPathNodes) {
+
+			from := p.NodeIDs[spurIdx]
+			to := p.NodeIDs[spurIdx+1]
+			_ = gClone.RemoveEdge(from, to)
+		}
+	}
+
+	// Remove root path nodes (other than the spur node) so the spur path
+	// cannot loop back through the root and produce a non-simple path
+	for _, n := range rootPathNodes[:len(rootPathNodes)-1] {
+		_ = gClone.RemoveNode(n)
+	}
 
-		// Remove chosen candidate
-		candidates = append(candidates[:bestIdx], candidates[bestIdx+1:]...)
+	spur, err := MaxProbabilityPathMaxHops(gClone, spurNode, end, spurHops)
+	if err != nil || len(spur.NodeIDs) == 0 {
+		return nil
 	}
 
-	return results, nil
+	// Combine root + spur (avoid duplicating spurNode)
+	return append(
+		append([]graph.NodeID{}, rootPathNodes[:len(rootPathNodes)-1]...),
+		spur.NodeIDs...,
+	)
 }
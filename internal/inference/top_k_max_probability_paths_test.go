@@ -0,0 +1,263 @@
+package inference
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ritamzico/pgraph/internal/graph"
+)
+
+// buildLayeredGraph creates n nodes v0..v(n-1), each with edges to the next
+// fanout nodes, with seeded random probabilities. With cyclic set, every
+// third node also has an edge back to the one two before it.
+func buildLayeredGraph(tb testing.TB, n, fanout int, cyclic bool) graph.ProbabilisticGraphModel {
+	tb.Helper()
+	rng := rand.New(rand.NewPCG(1, 2))
+	g := graph.CreateProbAdjListGraph()
+	for i := range n {
+		if err := g.AddNode(graph.NodeID(fmt.Sprintf("v%d", i)), nil); err != nil {
+			tb.Fatalf("AddNode: %v", err)
+		}
+	}
+	for i := range n {
+		for j := i + 1; j < n && j <= i+fanout; j++ {
+			id := graph.EdgeID(fmt.Sprintf("e%d_%d", i, j))
+			from, to := graph.NodeID(fmt.Sprintf("v%d", i)), graph.NodeID(fmt.Sprintf("v%d", j))
+			if err := g.AddEdge(id, from, to, 0.5+rng.Float64()/2, nil); err != nil {
+				tb.Fatalf("AddEdge: %v", err)
+			}
+		}
+	}
+	if cyclic {
+		for i := 3; i < n; i += 3 {
+			from, to := graph.NodeID(fmt.Sprintf("v%d", i)), graph.NodeID(fmt.Sprintf("v%d", i-2))
+			if err := g.AddEdge(graph.EdgeID(fmt.Sprintf("back%d", i)), from, to, 0.9, nil); err != nil {
+				tb.Fatalf("AddEdge: %v", err)
+			}
+		}
+	}
+	return g
+}
+
+func TestTopKMaxProbabilityPaths_OrderedAndDistinct(t *testing.T) {
+	g := buildLayeredGraph(t, 12, 3, false)
+	paths, err := TopKMaxProbabilityPaths(g, "v0", "v11", 50)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(paths) != 50 {
+		t.Fatalf("expected 50 paths, got %d", len(paths))
+	}
+
+	seen := make(map[string]bool)
+	for i, p := range paths {
+		if i > 0 && p.Probability > paths[i-1].Probability {
+			t.Errorf("path %d (%f) more probable than path %d (%f)", i, p.Probability, i-1, paths[i-1].Probability)
+		}
+		key := pathKey(p.NodeIDs)
+		if seen[key] {
+			t.Errorf("duplicate path %v", p.NodeIDs)
+		}
+		seen[key] = true
+	}
+}
+
+func TestTopKMaxProbabilityPathsContext_Deadline(t *testing.T) {
+	g := buildLayeredGraph(t, 100, 4, false)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	const k = 1_000_000
+	paths, truncated, err := TopKMaxProbabilityPathsContext(ctx, g, "v0", "v99", k, TopKOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !truncated {
+		t.Fatal("expected the search to be truncated")
+	}
+	if len(paths) == 0 || len(paths) >= k {
+		t.Fatalf("expected some but not all paths, got %d", len(paths))
+	}
+
+	// The partial result is the prefix a complete search would return.
+	want, err := TopKMaxProbabilityPaths(g, "v0", "v99", len(paths))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("truncated paths differ from the top %d", len(paths))
+	}
+}
+
+func TestTopKMaxProbabilityPathsContext_Cancelled(t *testing.T) {
+	g := buildLayeredGraph(t, 12, 3, false)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	paths, truncated, err := TopKMaxProbabilityPathsContext(ctx, g, "v0", "v11", 5, TopKOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !truncated || len(paths) != 0 {
+		t.Errorf("expected no paths and truncated, got %d paths, truncated=%v", len(paths), truncated)
+	}
+
+	_, truncated, err = TopKMaxProbabilityPathsContext(context.Background(), g, "v0", "v11", 5, TopKOptions{})
+	if err != nil || truncated {
+		t.Errorf("expected a complete search, got truncated=%v, err=%v", truncated, err)
+	}
+}
+
+func BenchmarkTopK_1000_100nodes(b *testing.B) {
+	g := buildLayeredGraph(b, 100, 4, false)
+	for b.Loop() {
+		if _, err := TopKMaxProbabilityPaths(g, "v0", "v99", 1000); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestTopKMaxProbabilityPaths_ParallelMatchesSerial(t *testing.T) {
+	g := buildLayeredGraph(t, 15, 3, false)
+	serial, err := TopKMaxProbabilityPathsWithOptions(g, "v0", "v14", 40, TopKOptions{Parallelism: 1})
+	if err != nil {
+		t.Fatalf("serial: %v", err)
+	}
+	for _, p := range []int{0, 2, 8} {
+		parallel, err := TopKMaxProbabilityPathsWithOptions(g, "v0", "v14", 40, TopKOptions{Parallelism: p})
+		if err != nil {
+			t.Fatalf("parallelism %d: %v", p, err)
+		}
+		if !reflect.DeepEqual(parallel, serial) {
+			t.Errorf("parallelism %d: result differs from serial run", p)
+		}
+	}
+}
+
+func TestTopKMaxProbabilityPaths_MinProbability(t *testing.T) {
+	g := buildLayeredGraph(t, 12, 3, false)
+	all, err := TopKMaxProbabilityPaths(g, "v0", "v11", 30)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cutoff := all[9].Probability
+
+	filtered, err := TopKMaxProbabilityPathsWithOptions(g, "v0", "v11", 30, TopKOptions{MinProbability: cutoff})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var want []graph.Path
+	for _, p := range all {
+		if p.Probability >= cutoff {
+			want = append(want, p)
+		}
+	}
+	if !reflect.DeepEqual(filtered, want) {
+		t.Errorf("expected the %d paths at or above %f, got %d", len(want), cutoff, len(filtered))
+	}
+}
+
+// buildSymmetricGraph joins S to T through each of the given middle nodes,
+// every edge with probability 0.5, so all paths are equally probable.
+func buildSymmetricGraph(t *testing.T, middles []graph.NodeID) graph.ProbabilisticGraphModel {
+	t.Helper()
+	g := graph.CreateProbAdjListGraph()
+	for _, n := range append([]graph.NodeID{"S", "T"}, middles...) {
+		if err := g.AddNode(n, nil); err != nil {
+			t.Fatalf("AddNode %s: %v", n, err)
+		}
+	}
+	for _, m := range middles {
+		if err := g.AddEdge(graph.EdgeID("eS"+m), "S", m, 0.5, nil); err != nil {
+			t.Fatalf("AddEdge: %v", err)
+		}
+		if err := g.AddEdge(graph.EdgeID("e"+m+"T"), m, "T", 0.5, nil); err != nil {
+			t.Fatalf("AddEdge: %v", err)
+		}
+	}
+	return g
+}
+
+func TestTopKMaxProbabilityPaths_TiesInLexicographicOrder(t *testing.T) {
+	middles := []graph.NodeID{"E", "B", "D", "A", "C"}
+	want := [][]graph.NodeID{
+		{"S", "A", "T"},
+		{"S", "B", "T"},
+		{"S", "C", "T"},
+	}
+
+	// Map iteration order varies between graphs and runs, so repeat to
+	// catch any dependence on it.
+	for range 20 {
+		g := buildSymmetricGraph(t, middles)
+		paths, err := TopKMaxProbabilityPaths(g, "S", "T", len(want))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var got [][]graph.NodeID
+		for _, p := range paths {
+			got = append(got, p.NodeIDs)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+}
+
+func TestMaxProbabilityPathMaxHops_MatchesBruteForce(t *testing.T) {
+	g := buildLayeredGraph(t, 12, 3, true)
+	for maxHops := 4; maxHops <= 11; maxHops++ {
+		all, err := AllSimplePaths(g, "v0", "v11", 0, maxHops)
+		if err != nil {
+			t.Fatalf("AllSimplePaths: %v", err)
+		}
+		got, err := MaxProbabilityPathMaxHops(g, "v0", "v11", maxHops)
+		if err != nil {
+			t.Fatalf("MaxProbabilityPathMaxHops: %v", err)
+		}
+		if len(got.NodeIDs)-1 > maxHops {
+			t.Errorf("maxHops %d: path %v is too long", maxHops, got.NodeIDs)
+		}
+		if math.Abs(got.Probability-all[0].Probability) > 1e-12 {
+			t.Errorf("maxHops %d: expected probability %f, got %f", maxHops, all[0].Probability, got.Probability)
+		}
+	}
+
+	// v11 is at least four hops from v0.
+	if p, err := MaxProbabilityPathMaxHops(g, "v0", "v11", 3); err != nil || len(p.NodeIDs) != 0 {
+		t.Errorf("expected no path within 3 hops, got %v, %v", p.NodeIDs, err)
+	}
+	if _, err := MaxProbabilityPathMaxHops(g, "v0", "missing", 3); err == nil {
+		t.Error("expected error for missing node")
+	}
+}
+
+func TestTopKMaxProbabilityPaths_MaxHops(t *testing.T) {
+	g := buildLayeredGraph(t, 12, 3, true)
+	const k, maxHops = 20, 5
+	all, err := AllSimplePaths(g, "v0", "v11", 0, maxHops)
+	if err != nil {
+		t.Fatalf("AllSimplePaths: %v", err)
+	}
+
+	paths, err := TopKMaxProbabilityPathsWithOptions(g, "v0", "v11", k, TopKOptions{MaxHops: maxHops})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(paths) != min(k, len(all)) {
+		t.Fatalf("expected %d paths, got %d", min(k, len(all)), len(paths))
+	}
+	for i, p := range paths {
+		if len(p.NodeIDs)-1 > maxHops {
+			t.Errorf("path %v has more than %d hops", p.NodeIDs, maxHops)
+		}
+		if math.Abs(p.Probability-all[i].Probability) > 1e-12 {
+			t.Errorf("path %d: expected probability %f, got %f", i, all[i].Probability, p.Probability)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package query
+
+import (
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Canonical returns a string that is equal for two queries exactly when
+// they have the same type and parameters. Pointers are followed, so queries
+// holding equal values behind different pointers are equal, and map entries
+// are written in key order. Struct fields tagged `canonical:"-"`, such as a
+// shared cache, are skipped. It reports false if q holds a function, a
+// channel or a cyclic value, whose behaviour a string cannot capture.
+func Canonical(q Query) (string, bool) {
+	var b strings.Builder
+	if !writeCanonical(&b, reflect.ValueOf(q), make(map[uintptr]bool)) {
+		return "", false
+	}
+	return b.String(), true
+}
+
+// writeCanonical writes v to b. onPath holds the pointers being followed,
+// to detect cycles.
+func writeCanonical(b *strings.Builder, v reflect.Value, onPath map[uintptr]bool) bool {
+	if !v.IsValid() {
+		b.WriteString("nil")
+		return true
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		b.WriteString(strconv.FormatBool(v.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		b.WriteString(strconv.FormatInt(v.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		b.WriteString(strconv.FormatUint(v.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		b.WriteString(strconv.FormatFloat(v.Float(), 'g', -1, 64))
+	case reflect.String:
+		b.WriteString(strconv.Quote(v.String()))
+
+	case reflect.Pointer:
+		if v.IsNil() {
+			b.WriteString("nil")
+			return true
+		}
+		if onPath[v.Pointer()] {
+			return false
+		}
+		onPath[v.Pointer()] = true
+		defer delete(onPath, v.Pointer())
+		b.WriteByte('&')
+		return writeCanonical(b, v.Elem(), onPath)
+
+	case reflect.Interface:
+		if v.IsNil() {
+			b.WriteString("nil")
+			return true
+		}
+		return writeCanonical(b, v.Elem(), onPath)
+
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			b.WriteString("nil")
+			return true
+		}
+		b.WriteByte('[')
+		for i := range v.Len() {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			if !writeCanonical(b, v.Index(i), onPath) {
+				return false
+			}
+		}
+		b.WriteByte(']')
+
+	case reflect.Map:
+		if v.IsNil() {
+			b.WriteString("nil")
+			return true
+		}
+		entries := make([]string, 0, v.Len())
+		for iter := v.MapRange(); iter.Next(); {
+			var e strings.Builder
+			if !writeCanonical(&e, iter.Key(), onPath) {
+				return false
+			}
+			e.WriteByte(':')
+			if !writeCanonical(&e, iter.Value(), onPath) {
+				return false
+			}
+			entries = append(entries, e.String())
+		}
+		slices.Sort(entries)
+		b.WriteString(v.Type().String())
+		b.WriteByte('{')
+		b.WriteString(strings.Join(entries, ","))
+		b.WriteByte('}')
+
+	case reflect.Struct:
+		t := v.Type()
+		b.WriteString(t.String())
+		b.WriteByte('{')
+		for i := range t.NumField() {
+			f := t.Field(i)
+			if f.Tag.Get("canonical") == "-" {
+				continue
+			}
+			b.WriteString(f.Name)
+			b.WriteByte(':')
+			if !writeCanonical(b, v.Field(i), onPath) {
+				return false
+			}
+			b.WriteByte(';')
+		}
+		b.WriteByte('}')
+
+	default:
+		// Funcs, channels and unsafe pointers; only a nil func is known.
+		if v.Kind() != reflect.Func || !v.IsNil() {
+			return false
+		}
+		b.WriteString("nil")
+	}
+	return true
+}
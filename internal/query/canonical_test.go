@@ -0,0 +1,45 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/ritamzico/pgraph/internal/graph"
+	"github.com/ritamzico/pgraph/internal/inference"
+	"github.com/ritamzico/pgraph/internal/result"
+)
+
+func TestCanonical(t *testing.T) {
+	reach := func(end string) Query {
+		return ReachabilityProbabilityQuery{Start: "A", End: graph.NodeID(end), Mode: Exact}
+	}
+	withCache := ReachabilityProbabilityQuery{Start: "A", End: "B", Mode: Exact, Cache: inference.NewIncrementalReachabilityCache()}
+
+	same := [][2]Query{
+		{reach("B"), reach("B")},
+		{reach("B"), withCache},
+		{NotQuery{Inner: &ReachabilityProbabilityQuery{Start: "A", End: "B", Mode: Exact}}, NotQuery{Inner: &ReachabilityProbabilityQuery{Start: "A", End: "B", Mode: Exact}}},
+	}
+	for _, pair := range same {
+		a, okA := Canonical(pair[0])
+		b, okB := Canonical(pair[1])
+		if !okA || !okB || a != b {
+			t.Errorf("expected equal canonical forms, got %q (%v) and %q (%v)", a, okA, b, okB)
+		}
+	}
+
+	a, _ := Canonical(reach("B"))
+	b, _ := Canonical(reach("C"))
+	if a == b {
+		t.Errorf("expected different ends to differ, both gave %q", a)
+	}
+	a, _ = Canonical(reach("B"))
+	b, _ = Canonical(NotQuery{Inner: reach("B")})
+	if a == b {
+		t.Errorf("expected different query types to differ, both gave %q", a)
+	}
+
+	seq := SequentialQuery{First: reach("B"), Then: func(result.Result) (Query, error) { return nil, nil }}
+	if _, ok := Canonical(seq); ok {
+		t.Error("expected a query holding a function to have no canonical form")
+	}
+}
@@ -3,9 +3,11 @@ package query
 import (
 	"context"
 	"fmt"
+	"slices"
 	"sync"
 
 	"github.com/ritamzico/pgraph/internal/graph"
+	"github.com/ritamzico/pgraph/internal/inference"
 	"github.com/ritamzico/pgraph/internal/result"
 )
 
@@ -22,6 +24,20 @@ func executeConcurrent(
 	g graph.ProbabilisticGraphModel,
 	queries []Query,
 	reduce reducerFunc,
+) (result.Result, error) {
+	return executeConcurrentUntil(ctx, g, queries, nil, reduce)
+}
+
+// executeConcurrentUntil is executeConcurrent with an early exit: after each
+// result arrives, determined (if non-nil) is called with the results so far,
+// and once it returns true the remaining queries are cancelled and reduce
+// receives only the results received, in query order.
+func executeConcurrentUntil(
+	ctx context.Context,
+	g graph.ProbabilisticGraphModel,
+	queries []Query,
+	determined func(received []result.Result) bool,
+	reduce reducerFunc,
 ) (result.Result, error) {
 	if len(queries) == 0 {
 		return nil, QueryError{
@@ -52,12 +68,28 @@ func executeConcurrent(
 		close(resCh)
 	}()
 
+	var received []result.Result
 	for rw := range resCh {
 		if rw.err != nil {
 			cancel()
 			return nil, rw.err
 		}
 		results[rw.index] = rw.res
+
+		if determined == nil {
+			continue
+		}
+		received = append(received, rw.res)
+		if len(received) < len(queries) && determined(received) {
+			cancel()
+			partial := make([]result.Result, 0, len(received))
+			for _, r := range results {
+				if r != nil {
+					partial = append(partial, r)
+				}
+			}
+			return reduce(partial)
+		}
 	}
 
 	return reduce(results)
@@ -93,28 +125,76 @@ func (q MultiQuery) Execute(ctx context.Context, g graph.ProbabilisticGraphModel
 	})
 }
 
+// PartialMultiQuery is MultiQuery with a choice of how to handle failures.
+// With FailOnError set it behaves exactly like MultiQuery. Otherwise every
+// subquery runs to completion, and each one that fails contributes an
+// ErrorResult in its position of the MultiResult instead of failing the
+// whole query. Cancellation of ctx itself is still returned as an error.
+type PartialMultiQuery struct {
+	Queries     []Query
+	FailOnError bool
+}
+
+func (q PartialMultiQuery) Execute(ctx context.Context, g graph.ProbabilisticGraphModel) (result.Result, error) {
+	if q.FailOnError {
+		return MultiQuery{Queries: q.Queries}.Execute(ctx, g)
+	}
+
+	if len(q.Queries) == 0 {
+		return nil, QueryError{
+			Kind:    "InvalidStructure",
+			Message: "query requires at least one subquery",
+		}
+	}
+
+	results := make([]result.Result, len(q.Queries))
+	var wg sync.WaitGroup
+	wg.Add(len(q.Queries))
+	for i, sub := range q.Queries {
+		go func(i int, sub Query) {
+			defer wg.Done()
+			r, err := sub.Execute(ctx, g)
+			if err != nil {
+				r = result.ErrorResult{Err: err}
+			}
+			results[i] = r
+		}(i, sub)
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return result.MultiResult{Results: results}, nil
+}
+
 type AggregateQuery struct {
 	Queries []Query
 	Reducer Reducer
+
+	// ShortCircuit lets a Reducer that implements ReducerMonitor cancel the
+	// remaining subqueries once the results so far determine the answer. The
+	// cancelled subqueries' results, including any errors, are then never
+	// seen. It has no effect on other reducers.
+	ShortCircuit bool
 }
 
 func (q AggregateQuery) Execute(ctx context.Context, g graph.ProbabilisticGraphModel) (result.Result, error) {
-	multiQuery := MultiQuery{Queries: q.Queries}
-	queryResults, err := multiQuery.Execute(ctx, g)
-
-	if err != nil {
-		return nil, err
+	var determined func([]result.Result) bool
+	if m, ok := q.Reducer.(ReducerMonitor); ok && q.ShortCircuit {
+		total := len(q.Queries)
+		determined = func(received []result.Result) bool {
+			return m.Determined(received, total)
+		}
 	}
 
-	multiResult, ok := queryResults.(result.MultiResult)
-	if !ok {
-		return nil, QueryError{
-			Kind:    "TypeMismatch",
-			Message: fmt.Sprintf("inner query expected ProbabilisticResult, got %T", queryResults),
+	reduce := q.Reducer.Reduce
+	if cr, ok := q.Reducer.(ContextReducer); ok {
+		reduce = func(results []result.Result) (result.Result, error) {
+			return cr.ReduceContext(ctx, results)
 		}
 	}
-
-	return q.Reducer.Reduce(multiResult.Results)
+	return executeConcurrentUntil(ctx, g, q.Queries, determined, reduce)
 }
 
 type SequentialQuery struct {
@@ -136,6 +216,53 @@ func (q SequentialQuery) Execute(ctx context.Context, g graph.ProbabilisticGraph
 	return thenQuery.Execute(ctx, g)
 }
 
+// ForEachQuery runs a query once for each node that SourceNode has an
+// outgoing edge to, in node ID order, and returns a MultiResult with one
+// result per neighbour. Body builds the query for one neighbour, with
+// Variable bound to it. Like SequentialQuery.Then, Body is a function, so
+// ForEachQuery results are not cached.
+type ForEachQuery struct {
+	SourceNode graph.NodeID
+	Variable   string
+	Body       func(neighbor graph.NodeID) (Query, error)
+}
+
+func (q ForEachQuery) Execute(ctx context.Context, g graph.ProbabilisticGraphModel) (result.Result, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	edges, err := g.OutgoingEdges(q.SourceNode)
+	if err != nil {
+		return nil, err
+	}
+	neighbors := make([]graph.NodeID, 0, len(edges))
+	for _, e := range edges {
+		neighbors = append(neighbors, e.To)
+	}
+	slices.Sort(neighbors)
+	neighbors = slices.Compact(neighbors)
+
+	if len(neighbors) == 0 {
+		return result.MultiResult{}, nil
+	}
+
+	queries := make([]Query, len(neighbors))
+	for i, n := range neighbors {
+		body, err := q.Body(n)
+		if err != nil {
+			return nil, fmt.Errorf("%s = %s: %w", q.Variable, n, err)
+		}
+		queries[i] = body
+	}
+
+	return executeConcurrent(ctx, g, queries, func(results []result.Result) (result.Result, error) {
+		return result.MultiResult{Results: results}, nil
+	})
+}
+
 type ThresholdQuery struct {
 	Inner     Query
 	Threshold float64
@@ -167,6 +294,115 @@ func (q ThresholdQuery) Execute(ctx context.Context, g graph.ProbabilisticGraphM
 	}, nil
 }
 
+// AssertQuery checks that Inner's result compares to Value with Op, one of
+// the comparison operators. A probabilistic result is compared by its
+// probability and a NumberResult by its count. It returns
+// BooleanResult{true} when the condition holds and an AssertionError when it
+// does not.
+type AssertQuery struct {
+	Inner Query
+	Op    FilterOp
+	Value float64
+}
+
+func (q AssertQuery) Execute(ctx context.Context, g graph.ProbabilisticGraphModel) (result.Result, error) {
+	queryResult, err := q.Inner.Execute(ctx, g)
+	if err != nil {
+		return nil, err
+	}
+
+	var got float64
+	switch r := queryResult.(type) {
+	case result.ProbabilisticResult:
+		got = r.ProbabilityValue()
+	case result.NumberResult:
+		got = float64(r.Value)
+	default:
+		return nil, QueryError{
+			Kind:    "TypeMismatch",
+			Message: fmt.Sprintf("inner query expected ProbabilisticResult or NumberResult, got %T", queryResult),
+		}
+	}
+
+	var holds bool
+	switch q.Op {
+	case OpEq:
+		holds = got == q.Value
+	case OpNeq:
+		holds = got != q.Value
+	case OpGt:
+		holds = got > q.Value
+	case OpLt:
+		holds = got < q.Value
+	case OpGte:
+		holds = got >= q.Value
+	case OpLte:
+		holds = got <= q.Value
+	default:
+		return nil, QueryError{
+			Kind:    "InvalidOperator",
+			Message: fmt.Sprintf("ASSERT does not support %s", q.Op),
+		}
+	}
+	if !holds {
+		return nil, AssertionError{
+			Message: fmt.Sprintf("expected %s %g, got %g", q.Op, q.Value, got),
+		}
+	}
+	return result.BooleanResult{Value: true}, nil
+}
+
+// XorQuery returns the probability that an odd number of Queries'
+// events occur, treating them as independent. For two events this is
+// P(A) + P(B) - 2*P(A)*P(B).
+type XorQuery struct {
+	Queries []Query
+}
+
+func (q XorQuery) Execute(ctx context.Context, g graph.ProbabilisticGraphModel) (result.Result, error) {
+	return executeConcurrent(ctx, g, q.Queries, func(results []result.Result) (result.Result, error) {
+		odd := 0.0
+
+		for _, r := range results {
+			pr, ok := r.(result.ProbabilisticResult)
+			if !ok {
+				return nil, QueryError{
+					Kind:    "TypeMismatch",
+					Message: fmt.Sprintf("inner query expected ProbabilisticResult, got %T", r),
+				}
+			}
+			// The parity flips exactly when this event occurs.
+			p := pr.ProbabilityValue()
+			odd = odd*(1.0-p) + (1.0-odd)*p
+		}
+
+		return result.NewProbabilityResult(odd), nil
+	})
+}
+
+// NotQuery returns the complement of Inner's probability. Inner must
+// produce a ProbabilisticResult.
+type NotQuery struct {
+	Inner Query
+}
+
+func (q NotQuery) Execute(ctx context.Context, g graph.ProbabilisticGraphModel) (result.Result, error) {
+	queryResult, err := q.Inner.Execute(ctx, g)
+	if err != nil {
+		return nil, err
+	}
+
+	probabilisticResult, ok := queryResult.(result.ProbabilisticResult)
+	if !ok {
+		return nil, QueryError{
+			Kind:    "TypeMismatch",
+			Message: fmt.Sprintf("inner query expected ProbabilisticResult, got %T", queryResult),
+		}
+	}
+
+	return result.NewProbabilityResult(1.0 - probabilisticResult.ProbabilityValue()), nil
+}
+
 type AndQuery struct {
 	Queries []Query
 }
@@ -186,7 +422,7 @@ func (q AndQuery) Execute(ctx context.Context, g graph.ProbabilisticGraphModel)
 			probability *= pr.ProbabilityValue()
 		}
 
-		return result.ProbabilityResult{Probability: probability}, nil
+		return result.NewProbabilityResult(probability), nil
 	})
 }
 
@@ -209,6 +445,140 @@ func (q OrQuery) Execute(ctx context.Context, g graph.ProbabilisticGraphModel) (
 			probability *= 1.0 - pr.ProbabilityValue()
 		}
 
-		return result.ProbabilityResult{Probability: 1.0 - probability}, nil
+		return result.NewProbabilityResult(1.0 - probability), nil
+	})
+}
+
+// ConcatPathQuery joins the paths returned by First and Second end to end.
+// Both must produce a PathResult, and First's path must end where Second's
+// begins. If either finds no path, the result is an empty path.
+type ConcatPathQuery struct {
+	First  Query
+	Second Query
+}
+
+func (q ConcatPathQuery) Execute(ctx context.Context, g graph.ProbabilisticGraphModel) (result.Result, error) {
+	return executeConcurrent(ctx, g, []Query{q.First, q.Second}, func(results []result.Result) (result.Result, error) {
+		paths := make([]graph.Path, 2)
+		for i, r := range results {
+			pr, ok := r.(result.PathResult)
+			if !ok {
+				return nil, QueryError{
+					Kind:    "TypeMismatch",
+					Message: fmt.Sprintf("CONCAT requires path queries, got %T", r),
+				}
+			}
+			paths[i] = pr.Path
+		}
+
+		first, second := paths[0], paths[1]
+		if len(first.NodeIDs) == 0 || len(second.NodeIDs) == 0 {
+			return result.PathResult{}, nil
+		}
+
+		last := first.NodeIDs[len(first.NodeIDs)-1]
+		if last != second.NodeIDs[0] {
+			return nil, QueryError{
+				Kind:    "PathMismatch",
+				Message: fmt.Sprintf("first path ends at %v but second path starts at %v", last, second.NodeIDs[0]),
+			}
+		}
+
+		return result.PathResult{Path: graph.Path{
+			NodeIDs:     slices.Concat(first.NodeIDs, second.NodeIDs[1:]),
+			Probability: first.Probability * second.Probability,
+		}}, nil
+	})
+}
+
+// NodeSet is one side of a MatrixReachabilityQuery: either the listed
+// Nodes or, if Group is set, the members of that group.
+type NodeSet struct {
+	Nodes []graph.NodeID
+	Group string
+}
+
+// resolve returns the set's nodes, a group's members sorted by ID. Listed
+// nodes must exist.
+func (s NodeSet) resolve(g graph.ProbabilisticGraphModel) ([]graph.NodeID, error) {
+	if s.Group == "" {
+		for _, id := range s.Nodes {
+			if !g.ContainsNode(id) {
+				return nil, graph.NodeDoesNotExist(id)
+			}
+		}
+		return s.Nodes, nil
+	}
+	if !slices.ContainsFunc(g.GetGroups(), func(group *graph.NodeGroup) bool { return group.Name == s.Group }) {
+		return nil, graph.GroupDoesNotExist(s.Group)
+	}
+
+	var ids []graph.NodeID
+	for _, n := range g.FilterNodes(func(n *graph.Node) bool { return n.Group != nil && n.Group.Name == s.Group }) {
+		ids = append(ids, n.ID)
+	}
+	if len(ids) == 0 {
+		return nil, QueryError{
+			Kind:    "InvalidParameter",
+			Message: fmt.Sprintf("group %v has no nodes", s.Group),
+		}
+	}
+	slices.Sort(ids)
+	return ids, nil
+}
+
+// MatrixReachabilityQuery computes the reachability probability of every
+// target from every source, running one ReachabilityProbabilityQuery per
+// pair concurrently. The result is a MatrixResult with a row per source
+// and a column per target.
+type MatrixReachabilityQuery struct {
+	Sources, Targets NodeSet
+	Mode             InferenceMode
+
+	// Cache is passed on to every pair's query; see
+	// ReachabilityProbabilityQuery.
+	Cache *inference.IncrementalReachabilityCache `canonical:"-"`
+}
+
+func (q MatrixReachabilityQuery) Execute(ctx context.Context, g graph.ProbabilisticGraphModel) (result.Result, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	sources, err := q.Sources.resolve(g)
+	if err != nil {
+		return nil, err
+	}
+	targets, err := q.Targets.resolve(g)
+	if err != nil {
+		return nil, err
+	}
+
+	queries := make([]Query, 0, len(sources)*len(targets))
+	for _, s := range sources {
+		for _, t := range targets {
+			queries = append(queries, ReachabilityProbabilityQuery{Start: s, End: t, Mode: q.Mode, Cache: q.Cache})
+		}
+	}
+
+	return executeConcurrent(ctx, g, queries, func(results []result.Result) (result.Result, error) {
+		values := make([][]float64, len(sources))
+		for i := range sources {
+			values[i] = make([]float64, len(targets))
+			for j := range targets {
+				r := results[i*len(targets)+j]
+				pr, ok := r.(result.ProbabilisticResult)
+				if !ok {
+					return nil, QueryError{
+						Kind:    "TypeMismatch",
+						Message: fmt.Sprintf("inner query expected ProbabilisticResult, got %T", r),
+					}
+				}
+				values[i][j] = pr.ProbabilityValue()
+			}
+		}
+		return result.MatrixResult{Rows: sources, Cols: targets, Values: values}, nil
 	})
 }
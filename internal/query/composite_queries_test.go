@@ -2,7 +2,9 @@ package query
 
 import (
 	"context"
+	"errors"
 	"math"
+	"slices"
 	"testing"
 
 	"github.com/ritamzico/pgraph/internal/graph"
@@ -310,6 +312,204 @@ func TestThresholdQuery_NonProbabilisticInnerQuery(t *testing.T) {
 	}
 }
 
+func TestAssertQuery(t *testing.T) {
+	g := buildLinearGraph(t, 0.9, 0.8)
+	reach := ReachabilityProbabilityQuery{Start: "A", End: "C", Mode: Exact}
+	count := CountPathsQuery{Start: "A", End: "C"}
+
+	passing := []AssertQuery{
+		{Inner: reach, Op: OpGte, Value: 0.72},
+		{Inner: reach, Op: OpGt, Value: 0.5},
+		{Inner: reach, Op: OpLt, Value: 0.8},
+		{Inner: count, Op: OpEq, Value: 1},
+		{Inner: count, Op: OpNeq, Value: 2},
+	}
+	for _, q := range passing {
+		res, err := q.Execute(context.Background(), g)
+		if err != nil {
+			t.Errorf("%s %v: unexpected error: %v", q.Op, q.Value, err)
+			continue
+		}
+		if b, ok := res.(result.BooleanResult); !ok || !b.Value {
+			t.Errorf("%s %v: expected BooleanResult{true}, got %v", q.Op, q.Value, res)
+		}
+	}
+
+	var ae AssertionError
+	if _, err := (AssertQuery{Inner: reach, Op: OpGte, Value: 0.8}).Execute(context.Background(), g); !errors.As(err, &ae) {
+		t.Errorf("expected AssertionError, got %v", err)
+	}
+	_, err := AssertQuery{Inner: count, Op: OpGt, Value: 1}.Execute(context.Background(), g)
+	if !errors.As(err, &ae) || ae.Message != "expected > 1, got 1" {
+		t.Errorf("expected AssertionError, got %v", err)
+	}
+}
+
+func TestAssertQuery_InvalidInner(t *testing.T) {
+	g := buildLinearGraph(t, 0.9, 0.8)
+
+	var qe QueryError
+	_, err := AssertQuery{Inner: TopKProbabilityPathsQuery{Start: "A", End: "C", K: 2}, Op: OpGt, Value: 0.5}.Execute(context.Background(), g)
+	if !errors.As(err, &qe) || qe.Kind != "TypeMismatch" {
+		t.Errorf("expected TypeMismatch, got %v", err)
+	}
+	_, err = AssertQuery{Inner: CountPathsQuery{Start: "A", End: "C"}, Op: OpContains, Value: 1}.Execute(context.Background(), g)
+	if !errors.As(err, &qe) || qe.Kind != "InvalidOperator" {
+		t.Errorf("expected InvalidOperator, got %v", err)
+	}
+	// Errors from the inner query are passed through.
+	if _, err := (AssertQuery{Inner: ReachabilityProbabilityQuery{Start: "A", End: "Z", Mode: Exact}, Op: OpGt, Value: 0}).Execute(context.Background(), g); err == nil {
+		t.Error("expected error for missing node")
+	}
+}
+
+func TestMatrixReachabilityQuery(t *testing.T) {
+	g := buildDiamondGraph(t)
+	if err := g.AddGroup("ends", nil); err != nil {
+		t.Fatalf("AddGroup: %v", err)
+	}
+	for _, id := range []graph.NodeID{"D", "C"} {
+		if err := g.SetNodeGroup(id, "ends"); err != nil {
+			t.Fatalf("SetNodeGroup %s: %v", id, err)
+		}
+	}
+
+	q := MatrixReachabilityQuery{
+		Sources: NodeSet{Nodes: []graph.NodeID{"A", "B"}},
+		Targets: NodeSet{Group: "ends"},
+		Mode:    Exact,
+	}
+	res, err := q.Execute(context.Background(), g)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	m, ok := res.(result.MatrixResult)
+	if !ok {
+		t.Fatalf("expected MatrixResult, got %T", res)
+	}
+
+	// Group members come in ID order.
+	if !slices.Equal(m.Rows, []graph.NodeID{"A", "B"}) || !slices.Equal(m.Cols, []graph.NodeID{"C", "D"}) {
+		t.Fatalf("expected rows [A B] and cols [C D], got %v and %v", m.Rows, m.Cols)
+	}
+	want := [][]float64{{0.8, 0.8076}, {0, 0.7}}
+	for i := range want {
+		for j := range want[i] {
+			if math.Abs(m.Values[i][j]-want[i][j]) > 0.0001 {
+				t.Errorf("%s->%s: expected %f, got %f", m.Rows[i], m.Cols[j], want[i][j], m.Values[i][j])
+			}
+		}
+	}
+}
+
+func TestMatrixReachabilityQuery_InvalidSets(t *testing.T) {
+	g := buildDiamondGraph(t)
+	if err := g.AddGroup("empty", nil); err != nil {
+		t.Fatalf("AddGroup: %v", err)
+	}
+	sources := NodeSet{Nodes: []graph.NodeID{"A"}}
+
+	var ge graph.GraphError
+	_, err := MatrixReachabilityQuery{Sources: sources, Targets: NodeSet{Group: "missing"}}.Execute(context.Background(), g)
+	if !errors.As(err, &ge) || ge.Kind != "GroupDoesNotExist" {
+		t.Errorf("expected GroupDoesNotExist error, got %v", err)
+	}
+
+	var qe QueryError
+	_, err = MatrixReachabilityQuery{Sources: sources, Targets: NodeSet{Group: "empty"}}.Execute(context.Background(), g)
+	if !errors.As(err, &qe) || qe.Kind != "InvalidParameter" {
+		t.Errorf("expected InvalidParameter error, got %v", err)
+	}
+
+	_, err = MatrixReachabilityQuery{Sources: sources, Targets: NodeSet{Nodes: []graph.NodeID{"Z"}}}.Execute(context.Background(), g)
+	if !errors.As(err, &ge) || ge.Kind != "NodeDoesNotExists" {
+		t.Errorf("expected NodeDoesNotExist error, got %v", err)
+	}
+}
+
+func TestXorQuery_TwoReachabilityQueries(t *testing.T) {
+	g := buildLinearGraph(t, 0.9, 0.8)
+
+	q1 := ReachabilityProbabilityQuery{Start: "A", End: "B", Mode: Exact}
+	q2 := ReachabilityProbabilityQuery{Start: "B", End: "C", Mode: Exact}
+
+	xorQuery := XorQuery{Queries: []Query{q1, q2}}
+
+	res, err := xorQuery.Execute(context.Background(), g)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	probRes, ok := res.(result.ProbabilityResult)
+	if !ok {
+		t.Fatalf("expected ProbabilityResult, got %T", res)
+	}
+
+	// XOR of two events: P(A) + P(B) - 2*P(A)*P(B) = 0.9 + 0.8 - 1.44 = 0.26
+	expectedProb := 0.9 + 0.8 - 2*0.9*0.8
+	if math.Abs(probRes.Probability-expectedProb) > 0.0001 {
+		t.Errorf("expected probability %f, got %f", expectedProb, probRes.Probability)
+	}
+}
+
+func TestXorQuery_MultipleQueries(t *testing.T) {
+	g := buildLinearGraph(t, 0.9, 0.8)
+
+	q1 := ReachabilityProbabilityQuery{Start: "A", End: "B", Mode: Exact}
+	q2 := ReachabilityProbabilityQuery{Start: "B", End: "C", Mode: Exact}
+	q3 := ReachabilityProbabilityQuery{Start: "A", End: "C", Mode: Exact}
+
+	res, err := XorQuery{Queries: []Query{q1, q2, q3}}.Execute(context.Background(), g)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	probRes, ok := res.(result.ProbabilityResult)
+	if !ok {
+		t.Fatalf("expected ProbabilityResult, got %T", res)
+	}
+
+	// Odd parity of independent events: (1 - prod(1 - 2*p_i)) / 2
+	expectedProb := (1 - (1-2*0.9)*(1-2*0.8)*(1-2*0.72)) / 2
+	if math.Abs(probRes.Probability-expectedProb) > 0.0001 {
+		t.Errorf("expected probability %f, got %f", expectedProb, probRes.Probability)
+	}
+}
+
+func TestNotQuery_ComplementsProbability(t *testing.T) {
+	g := buildLinearGraph(t, 0.9, 0.8)
+
+	notQuery := NotQuery{Inner: ReachabilityProbabilityQuery{Start: "A", End: "C", Mode: Exact}}
+
+	res, err := notQuery.Execute(context.Background(), g)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	probRes, ok := res.(result.ProbabilityResult)
+	if !ok {
+		t.Fatalf("expected ProbabilityResult, got %T", res)
+	}
+
+	// NOT(0.9 * 0.8) = 1 - 0.72 = 0.28
+	expectedProb := 1.0 - 0.9*0.8
+	if math.Abs(probRes.Probability-expectedProb) > 0.0001 {
+		t.Errorf("expected probability %f, got %f", expectedProb, probRes.Probability)
+	}
+}
+
+func TestNotQuery_NonProbabilisticInnerQuery(t *testing.T) {
+	g := buildLinearGraph(t, 0.9, 0.8)
+
+	notQuery := NotQuery{Inner: TopKProbabilityPathsQuery{Start: "A", End: "C", K: 2}}
+
+	_, err := notQuery.Execute(context.Background(), g)
+	var qe QueryError
+	if !errors.As(err, &qe) || qe.Kind != "TypeMismatch" {
+		t.Errorf("expected TypeMismatch error, got %v", err)
+	}
+}
+
 func TestNestedCompositeQueries(t *testing.T) {
 	g := buildDiamondGraph(t)
 
@@ -636,3 +836,173 @@ func TestAggregateQuery_SingleQuery(t *testing.T) {
 		t.Errorf("expected 0.72, got %f", probRes.Probability)
 	}
 }
+
+// fixedQuery returns a fixed probability.
+type fixedQuery struct{ p float64 }
+
+func (q fixedQuery) Execute(ctx context.Context, g graph.ProbabilisticGraphModel) (result.Result, error) {
+	return result.ProbabilityResult{Probability: q.p}, nil
+}
+
+// failingQuery always fails with err.
+type failingQuery struct{ err error }
+
+func (q failingQuery) Execute(ctx context.Context, g graph.ProbabilisticGraphModel) (result.Result, error) {
+	return nil, q.err
+}
+
+// blockingQuery runs until its context is cancelled, then closes cancelled.
+type blockingQuery struct{ cancelled chan struct{} }
+
+func (q blockingQuery) Execute(ctx context.Context, g graph.ProbabilisticGraphModel) (result.Result, error) {
+	<-ctx.Done()
+	close(q.cancelled)
+	return nil, ctx.Err()
+}
+
+func TestAggregateQuery_ShortCircuit(t *testing.T) {
+	g := buildDiamondGraph(t)
+
+	tests := []struct {
+		name    string
+		reducer Reducer
+		decider float64
+	}{
+		{"max", MaxProbabilityReducer{}, 1},
+		{"min", MinProbabilityReducer{}, 0},
+		{"geomean", GeometricMeanProbabilityReducer{}, 0},
+		{"product", ProductProbabilityReducer{}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blocker := blockingQuery{cancelled: make(chan struct{})}
+			agg := AggregateQuery{
+				Queries:      []Query{fixedQuery{0.5}, blocker, fixedQuery{tt.decider}},
+				Reducer:      tt.reducer,
+				ShortCircuit: true,
+			}
+
+			res, err := agg.Execute(context.Background(), g)
+			if err != nil {
+				t.Fatalf("Execute failed: %v", err)
+			}
+			if got := res.(result.ProbabilityResult).Probability; got != tt.decider {
+				t.Errorf("expected %f, got %f", tt.decider, got)
+			}
+			<-blocker.cancelled
+		})
+	}
+}
+
+func TestAggregateQuery_ShortCircuitNotDetermined(t *testing.T) {
+	g := buildDiamondGraph(t)
+
+	queries := []Query{fixedQuery{0.5}, fixedQuery{0.9}, fixedQuery{0.7}}
+	for _, reducer := range []Reducer{MaxProbabilityReducer{}, CountAboveThresholdReducer{Threshold: 0.6}} {
+		withShortCircuit, err := AggregateQuery{Queries: queries, Reducer: reducer, ShortCircuit: true}.Execute(context.Background(), g)
+		if err != nil {
+			t.Fatalf("%T: Execute failed: %v", reducer, err)
+		}
+		without, err := AggregateQuery{Queries: queries, Reducer: reducer}.Execute(context.Background(), g)
+		if err != nil {
+			t.Fatalf("%T: Execute failed: %v", reducer, err)
+		}
+		if withShortCircuit != without {
+			t.Errorf("%T: short circuit changed the result: %v vs %v", reducer, withShortCircuit, without)
+		}
+	}
+}
+
+func TestPartialMultiQuery_ContinuesAfterError(t *testing.T) {
+	g := buildDiamondGraph(t)
+	boom := errors.New("boom")
+
+	res, err := PartialMultiQuery{
+		Queries: []Query{fixedQuery{0.5}, failingQuery{boom}, fixedQuery{0.7}},
+	}.Execute(context.Background(), g)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	results := res.(result.MultiResult).Results
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+	if got := results[0].(result.ProbabilityResult).Probability; got != 0.5 {
+		t.Errorf("results[0]: expected 0.5, got %f", got)
+	}
+	errRes, ok := results[1].(result.ErrorResult)
+	if !ok || !errors.Is(errRes.Err, boom) {
+		t.Errorf("results[1]: expected ErrorResult wrapping boom, got %v", results[1])
+	}
+	if got := results[2].(result.ProbabilityResult).Probability; got != 0.7 {
+		t.Errorf("results[2]: expected 0.7, got %f", got)
+	}
+}
+
+func TestPartialMultiQuery_FailOnError(t *testing.T) {
+	g := buildDiamondGraph(t)
+	boom := errors.New("boom")
+
+	_, err := PartialMultiQuery{
+		Queries:     []Query{fixedQuery{0.5}, failingQuery{boom}},
+		FailOnError: true,
+	}.Execute(context.Background(), g)
+	if !errors.Is(err, boom) {
+		t.Errorf("expected boom, got %v", err)
+	}
+}
+
+func TestPartialMultiQuery_ContextCancelled(t *testing.T) {
+	g := buildDiamondGraph(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := PartialMultiQuery{Queries: []Query{fixedQuery{0.5}}}.Execute(ctx, g)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestForEachQuery_BindsEachNeighbor(t *testing.T) {
+	g := buildDiamondGraph(t)
+
+	var bound []graph.NodeID
+	q := ForEachQuery{
+		SourceNode: "A",
+		Variable:   "n",
+		Body: func(n graph.NodeID) (Query, error) {
+			bound = append(bound, n)
+			return ReachabilityProbabilityQuery{Start: n, End: "D", Mode: Exact}, nil
+		},
+	}
+	res, err := q.Execute(context.Background(), g)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	if want := []graph.NodeID{"B", "C"}; !slices.Equal(bound, want) {
+		t.Errorf("expected bodies for %v, got %v", want, bound)
+	}
+	results := res.(result.MultiResult).Results
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	if p := results[0].(result.ProbabilityResult).Probability; math.Abs(p-0.7) > 1e-9 {
+		t.Errorf("expected 0.7 for B, got %f", p)
+	}
+}
+
+func TestForEachQuery_BodyError(t *testing.T) {
+	g := buildDiamondGraph(t)
+	boom := errors.New("boom")
+
+	_, err := ForEachQuery{
+		SourceNode: "A",
+		Variable:   "n",
+		Body:       func(graph.NodeID) (Query, error) { return nil, boom },
+	}.Execute(context.Background(), g)
+	if !errors.Is(err, boom) {
+		t.Errorf("expected boom, got %v", err)
+	}
+}
@@ -10,3 +10,13 @@ type QueryError struct {
 func (e QueryError) Error() string {
 	return fmt.Sprintf("query error (%v): %v", e.Kind, e.Message)
 }
+
+// AssertionError is returned by AssertQuery when its condition does not
+// hold.
+type AssertionError struct {
+	Message string
+}
+
+func (e AssertionError) Error() string {
+	return fmt.Sprintf("assertion failed: %v", e.Message)
+}
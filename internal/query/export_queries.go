@@ -0,0 +1,33 @@
+package query
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	"github.com/ritamzico/pgraph/internal/graph"
+	"github.com/ritamzico/pgraph/internal/result"
+	"github.com/ritamzico/pgraph/internal/serialization"
+)
+
+// ExportJSONQuery returns the graph in the serialization JSON format,
+// compacted onto a single line so that it can be pasted into IMPORT JSON.
+type ExportJSONQuery struct{}
+
+func (q ExportJSONQuery) Execute(ctx context.Context, g graph.ProbabilisticGraphModel) (result.Result, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	var indented bytes.Buffer
+	if err := serialization.WriteJSON(g, &indented); err != nil {
+		return nil, err
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, indented.Bytes()); err != nil {
+		return nil, err
+	}
+	return result.StringResult{Value: compact.String()}, nil
+}
@@ -0,0 +1,263 @@
+package query
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ritamzico/pgraph/internal/graph"
+	"github.com/ritamzico/pgraph/internal/result"
+)
+
+type FilterOp int
+
+const (
+	OpEq FilterOp = iota
+	OpNeq
+	OpGt
+	OpLt
+	OpGte
+	OpLte
+	// OpContains matches strings that contain the filter value.
+	OpContains
+	// OpMatches matches strings that the filter's Pattern matches anywhere.
+	OpMatches
+)
+
+func (op FilterOp) String() string {
+	switch op {
+	case OpEq:
+		return "="
+	case OpNeq:
+		return "!="
+	case OpGt:
+		return ">"
+	case OpLt:
+		return "<"
+	case OpGte:
+		return ">="
+	case OpLte:
+		return "<="
+	case OpContains:
+		return "CONTAINS"
+	case OpMatches:
+		return "MATCHES"
+	default:
+		return fmt.Sprintf("FilterOp(%d)", int(op))
+	}
+}
+
+// Regexp is a compiled MATCHES pattern. %#v prints it as its source, so
+// that the result cache gives equal FIND queries the same key.
+type Regexp struct {
+	*regexp.Regexp
+}
+
+func (r Regexp) GoString() string {
+	if r.Regexp == nil {
+		return "nil"
+	}
+	return strconv.Quote(r.String())
+}
+
+// CompilePattern compiles the pattern of a MATCHES filter.
+func CompilePattern(pattern string) (Regexp, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return Regexp{}, err
+	}
+	return Regexp{re}, nil
+}
+
+// pattern returns the compiled pattern of a MATCHES filter on value,
+// compiling value now if the query was built without one.
+func pattern(re Regexp, op FilterOp, value graph.Value) (Regexp, error) {
+	if op != OpMatches || re.Regexp != nil {
+		return re, nil
+	}
+	if value.Kind != graph.StringVal {
+		return Regexp{}, QueryError{Kind: "InvalidPattern", Message: "MATCHES needs a string pattern"}
+	}
+	re, err := CompilePattern(value.S)
+	if err != nil {
+		return Regexp{}, QueryError{Kind: "InvalidPattern", Message: fmt.Sprintf("invalid pattern %q: %v", value.S, err)}
+	}
+	return re, nil
+}
+
+// MaxPropertyPathDepth is the number of segments, including the property
+// key itself, that a filter's property path may have.
+const MaxPropertyPathDepth = 3
+
+// propLookup finds a node's or edge's property by key.
+type propLookup func(key string) (graph.Value, bool)
+
+// mapLookup looks properties up in props alone.
+func mapLookup(props map[string]graph.Value) propLookup {
+	return func(key string) (graph.Value, bool) {
+		v, ok := props[key]
+		return v, ok
+	}
+}
+
+// getValue resolves path against prop. The first segment names a property;
+// each later segment is a decimal index into the array reached so far.
+// graph.Value has no object kind yet, so a non-numeric segment after the
+// first never resolves.
+func getValue(prop propLookup, path []string) (graph.Value, bool) {
+	v, ok := prop(path[0])
+	if !ok {
+		return graph.Value{}, false
+	}
+	for _, seg := range path[1:] {
+		if v.Kind != graph.ArrayVal {
+			return graph.Value{}, false
+		}
+		i, err := strconv.Atoi(seg)
+		if err != nil || i < 0 || i >= len(v.A) {
+			return graph.Value{}, false
+		}
+		v = v.A[i]
+	}
+	return v, true
+}
+
+// matchProperty reports whether the value at path in prop satisfies op
+// against value, or for OpMatches against re. A path that does not resolve
+// never matches. Values of incomparable kinds are treated as unequal, so
+// only OpNeq matches them. OpContains and OpMatches only match strings.
+func matchProperty(prop propLookup, path []string, op FilterOp, value graph.Value, re Regexp) bool {
+	v, ok := getValue(prop, path)
+	if !ok {
+		return false
+	}
+
+	switch op {
+	case OpContains:
+		return v.Kind == graph.StringVal && value.Kind == graph.StringVal && strings.Contains(v.S, value.S)
+	case OpMatches:
+		return v.Kind == graph.StringVal && re.MatchString(v.S)
+	}
+
+	c, comparable := v.Compare(value)
+	if !comparable {
+		return op == OpNeq
+	}
+
+	switch op {
+	case OpEq:
+		return c == 0
+	case OpNeq:
+		return c != 0
+	case OpGt:
+		return c > 0
+	case OpLt:
+		return c < 0
+	case OpGte:
+		return c >= 0
+	case OpLte:
+		return c <= 0
+	default:
+		return false
+	}
+}
+
+// page returns the items, in order, after skipping offset of them, and at
+// most limit of those if limit is positive. A negative offset skips none.
+func page[T any](items []T, limit, offset int) []T {
+	items = items[min(max(offset, 0), len(items)):]
+	if limit > 0 && limit < len(items) {
+		items = items[:limit]
+	}
+	return items
+}
+
+type FindNodesQuery struct {
+	Key string
+	// Path holds further segments below Key, each an index into an array
+	// property; see getValue.
+	Path  []string
+	Op    FilterOp
+	Value graph.Value
+	// Pattern is Value compiled, for OpMatches. If it is unset, Execute
+	// compiles Value itself.
+	Pattern Regexp
+
+	// Limit caps the number of matches returned, after skipping the first
+	// Offset in ID order. Zero means no limit.
+	Limit, Offset int
+}
+
+func (q FindNodesQuery) Execute(ctx context.Context, g graph.ProbabilisticGraphModel) (result.Result, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	// An equality filter on a top-level property can use the graph's
+	// property index, if it has one for Key. Properties a node inherits
+	// from its group are matched too.
+	var nodes []*graph.Node
+	indexed := false
+	if pi, ok := g.(graph.PropertyIndexer); ok && q.Op == OpEq && len(q.Path) == 0 {
+		nodes, indexed = pi.NodesWithProperty(q.Key, q.Value)
+	}
+	if !indexed {
+		re, err := pattern(q.Pattern, q.Op, q.Value)
+		if err != nil {
+			return nil, err
+		}
+		path := append([]string{q.Key}, q.Path...)
+		nodes = g.FilterNodes(func(n *graph.Node) bool {
+			return matchProperty(n.Prop, path, q.Op, q.Value, re)
+		})
+	}
+
+	slices.SortFunc(nodes, func(a, b *graph.Node) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	return result.NodeListResult{Nodes: page(nodes, q.Limit, q.Offset)}, nil
+}
+
+type FindEdgesQuery struct {
+	Key string
+	// Path holds further segments below Key, each an index into an array
+	// property; see getValue.
+	Path  []string
+	Op    FilterOp
+	Value graph.Value
+	// Pattern is Value compiled, for OpMatches, as for FindNodesQuery.
+	Pattern Regexp
+
+	// Limit and Offset page through the matches as for FindNodesQuery.
+	Limit, Offset int
+}
+
+func (q FindEdgesQuery) Execute(ctx context.Context, g graph.ProbabilisticGraphModel) (result.Result, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	re, err := pattern(q.Pattern, q.Op, q.Value)
+	if err != nil {
+		return nil, err
+	}
+	path := append([]string{q.Key}, q.Path...)
+	edges := g.FilterEdges(func(e *graph.Edge) bool {
+		return matchProperty(mapLookup(e.Props), path, q.Op, q.Value, re)
+	})
+
+	slices.SortFunc(edges, func(a, b *graph.Edge) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	return result.EdgeListResult{Edges: page(edges, q.Limit, q.Offset)}, nil
+}
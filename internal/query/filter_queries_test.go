@@ -0,0 +1,323 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/ritamzico/pgraph/internal/graph"
+	"github.com/ritamzico/pgraph/internal/result"
+)
+
+// buildPropertyGraph creates nodes with region/risk properties and edges with mode/distance properties
+func buildPropertyGraph(t *testing.T) graph.ProbabilisticGraphModel {
+	t.Helper()
+	g := graph.CreateProbAdjListGraph()
+
+	nodes := []struct {
+		id    graph.NodeID
+		props map[string]graph.Value
+	}{
+		{"A", map[string]graph.Value{
+			"region": {Kind: graph.StringVal, S: "US"},
+			"risk":   {Kind: graph.FloatVal, F: 0.9},
+		}},
+		{"B", map[string]graph.Value{
+			"region": {Kind: graph.StringVal, S: "EU"},
+			"risk":   {Kind: graph.FloatVal, F: 0.2},
+		}},
+		{"C", map[string]graph.Value{
+			"region": {Kind: graph.StringVal, S: "US"},
+			"risk":   {Kind: graph.IntVal, I: 1},
+		}},
+		{"D", map[string]graph.Value{
+			"region": {Kind: graph.NullVal},
+		}},
+		{"E", nil},
+	}
+	for _, n := range nodes {
+		if err := g.AddNode(n.id, n.props); err != nil {
+			t.Fatalf("failed to add node %s: %v", n.id, err)
+		}
+	}
+
+	edges := []struct {
+		id       graph.EdgeID
+		from, to graph.NodeID
+		props    map[string]graph.Value
+	}{
+		{"eAB", "A", "B", map[string]graph.Value{
+			"mode":     {Kind: graph.StringVal, S: "rail"},
+			"distance": {Kind: graph.IntVal, I: 500},
+		}},
+		{"eBC", "B", "C", map[string]graph.Value{
+			"mode":     {Kind: graph.StringVal, S: "road"},
+			"distance": {Kind: graph.IntVal, I: 120},
+		}},
+		{"eCD", "C", "D", nil},
+	}
+	for _, e := range edges {
+		if err := g.AddEdge(e.id, e.from, e.to, 0.9, e.props); err != nil {
+			t.Fatalf("failed to add edge %s: %v", e.id, err)
+		}
+	}
+
+	return g
+}
+
+func TestFindNodesQuery_Operators(t *testing.T) {
+	g := buildPropertyGraph(t)
+
+	cases := []struct {
+		name  string
+		key   string
+		op    FilterOp
+		value graph.Value
+		want  []graph.NodeID
+	}{
+		{"eq string", "region", OpEq, graph.Value{Kind: graph.StringVal, S: "US"}, []graph.NodeID{"A", "C"}},
+		{"neq string", "region", OpNeq, graph.Value{Kind: graph.StringVal, S: "US"}, []graph.NodeID{"B", "D"}},
+		{"gt float", "risk", OpGt, graph.Value{Kind: graph.FloatVal, F: 0.5}, []graph.NodeID{"A", "C"}},
+		{"lt float", "risk", OpLt, graph.Value{Kind: graph.FloatVal, F: 0.5}, []graph.NodeID{"B"}},
+		{"gte int vs float", "risk", OpGte, graph.Value{Kind: graph.IntVal, I: 1}, []graph.NodeID{"C"}},
+		{"lte float", "risk", OpLte, graph.Value{Kind: graph.FloatVal, F: 0.9}, []graph.NodeID{"A", "B"}},
+		{"missing key", "owner", OpEq, graph.Value{Kind: graph.StringVal, S: "x"}, []graph.NodeID{}},
+		{"eq null", "region", OpEq, graph.Value{Kind: graph.NullVal}, []graph.NodeID{"D"}},
+		{"neq null", "region", OpNeq, graph.Value{Kind: graph.NullVal}, []graph.NodeID{"A", "B", "C"}},
+		{"contains", "region", OpContains, graph.Value{Kind: graph.StringVal, S: "S"}, []graph.NodeID{"A", "C"}},
+		{"contains non-string", "risk", OpContains, graph.Value{Kind: graph.StringVal, S: "0"}, []graph.NodeID{}},
+		{"matches", "region", OpMatches, graph.Value{Kind: graph.StringVal, S: "^(E|X)"}, []graph.NodeID{"B"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			q := FindNodesQuery{Key: tc.key, Op: tc.op, Value: tc.value}
+			res, err := q.Execute(context.Background(), g)
+			if err != nil {
+				t.Fatalf("Execute failed: %v", err)
+			}
+
+			nodeRes, ok := res.(result.NodeListResult)
+			if !ok {
+				t.Fatalf("expected NodeListResult, got %T", res)
+			}
+
+			got := nodeIDs(nodeRes.Nodes)
+			if len(got) != len(tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, got)
+			}
+			for i := range got {
+				if got[i] != tc.want[i] {
+					t.Errorf("expected %v, got %v", tc.want, got)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestGetValue(t *testing.T) {
+	props := map[string]graph.Value{
+		"region": {Kind: graph.StringVal, S: "US"},
+		"tags": {Kind: graph.ArrayVal, A: []graph.Value{
+			{Kind: graph.StringVal, S: "hub"},
+			{Kind: graph.StringVal, S: "port"},
+		}},
+	}
+
+	cases := []struct {
+		name   string
+		path   []string
+		want   string
+		wantOK bool
+	}{
+		{"top level", []string{"region"}, "US", true},
+		{"array index", []string{"tags", "1"}, "port", true},
+		{"missing key", []string{"owner"}, "", false},
+		{"index out of range", []string{"tags", "2"}, "", false},
+		{"negative index", []string{"tags", "-1"}, "", false},
+		{"object key on array", []string{"tags", "name"}, "", false},
+		{"index into scalar", []string{"region", "0"}, "", false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			v, ok := getValue(mapLookup(props), tc.path)
+			if ok != tc.wantOK {
+				t.Fatalf("expected ok=%v, got %v", tc.wantOK, ok)
+			}
+			if ok && v.S != tc.want {
+				t.Errorf("expected %q, got %q", tc.want, v.S)
+			}
+		})
+	}
+}
+
+func TestFindNodesQuery_IncomparableKinds(t *testing.T) {
+	g := buildPropertyGraph(t)
+
+	q := FindNodesQuery{Key: "region", Op: OpGt, Value: graph.Value{Kind: graph.IntVal, I: 1}}
+	res, err := q.Execute(context.Background(), g)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	if n := len(res.(result.NodeListResult).Nodes); n != 0 {
+		t.Errorf("expected no matches comparing string to int, got %d", n)
+	}
+}
+
+func TestFindQuery_Pattern(t *testing.T) {
+	g := buildPropertyGraph(t)
+
+	re, err := CompilePattern("^r")
+	if err != nil {
+		t.Fatalf("CompilePattern failed: %v", err)
+	}
+	// Pattern is used in place of Value when set.
+	q := FindEdgesQuery{Key: "mode", Op: OpMatches, Value: graph.Value{Kind: graph.StringVal, S: "x"}, Pattern: re}
+	res, err := q.Execute(context.Background(), g)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if n := len(res.(result.EdgeListResult).Edges); n != 2 {
+		t.Errorf("expected both moded edges to match ^r, got %d", n)
+	}
+
+	bad := FindNodesQuery{Key: "region", Op: OpMatches, Value: graph.Value{Kind: graph.StringVal, S: "("}}
+	var qe QueryError
+	if _, err := bad.Execute(context.Background(), g); !errors.As(err, &qe) || qe.Kind != "InvalidPattern" {
+		t.Errorf("expected InvalidPattern error, got %v", err)
+	}
+}
+
+func TestRegexp_GoString(t *testing.T) {
+	a, _ := CompilePattern("^a+$")
+	b, _ := CompilePattern("^a+$")
+	qa := FindNodesQuery{Key: "k", Op: OpMatches, Pattern: a}
+	qb := FindNodesQuery{Key: "k", Op: OpMatches, Pattern: b}
+	if fmt.Sprintf("%#v", qa) != fmt.Sprintf("%#v", qb) {
+		t.Errorf("equal patterns format differently: %#v and %#v", qa, qb)
+	}
+	if s := fmt.Sprintf("%#v", Regexp{}); s != "nil" {
+		t.Errorf("expected unset pattern to format as nil, got %s", s)
+	}
+}
+
+func TestFindNodesQuery_ContextCancelled(t *testing.T) {
+	g := buildPropertyGraph(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	q := FindNodesQuery{Key: "region", Op: OpEq, Value: graph.Value{Kind: graph.StringVal, S: "US"}}
+	if _, err := q.Execute(ctx, g); err == nil {
+		t.Error("expected error for cancelled context")
+	}
+}
+
+func TestFindEdgesQuery_Operators(t *testing.T) {
+	g := buildPropertyGraph(t)
+
+	cases := []struct {
+		name  string
+		key   string
+		op    FilterOp
+		value graph.Value
+		want  []graph.EdgeID
+	}{
+		{"eq string", "mode", OpEq, graph.Value{Kind: graph.StringVal, S: "rail"}, []graph.EdgeID{"eAB"}},
+		{"neq string", "mode", OpNeq, graph.Value{Kind: graph.StringVal, S: "rail"}, []graph.EdgeID{"eBC"}},
+		{"gt int", "distance", OpGt, graph.Value{Kind: graph.IntVal, I: 100}, []graph.EdgeID{"eAB", "eBC"}},
+		{"lt float", "distance", OpLt, graph.Value{Kind: graph.FloatVal, F: 200.5}, []graph.EdgeID{"eBC"}},
+		{"missing key", "capacity", OpGte, graph.Value{Kind: graph.IntVal, I: 0}, []graph.EdgeID{}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			q := FindEdgesQuery{Key: tc.key, Op: tc.op, Value: tc.value}
+			res, err := q.Execute(context.Background(), g)
+			if err != nil {
+				t.Fatalf("Execute failed: %v", err)
+			}
+
+			edgeRes, ok := res.(result.EdgeListResult)
+			if !ok {
+				t.Fatalf("expected EdgeListResult, got %T", res)
+			}
+
+			if len(edgeRes.Edges) != len(tc.want) {
+				t.Fatalf("expected %v, got %d edges", tc.want, len(edgeRes.Edges))
+			}
+			for i, e := range edgeRes.Edges {
+				if e.ID != tc.want[i] {
+					t.Errorf("expected %v at position %d, got %s", tc.want[i], i, e.ID)
+				}
+			}
+		})
+	}
+}
+
+func TestFindQueries_Paging(t *testing.T) {
+	g := buildPropertyGraph(t)
+	// Matches every node and edge that has the property: nodes A-D and
+	// edges eAB, eBC.
+	all := graph.Value{Kind: graph.StringVal, S: "none"}
+
+	cases := []struct {
+		limit, offset int
+		wantNodes     []graph.NodeID
+		wantEdges     []graph.EdgeID
+	}{
+		{0, 0, []graph.NodeID{"A", "B", "C", "D"}, []graph.EdgeID{"eAB", "eBC"}},
+		{2, 0, []graph.NodeID{"A", "B"}, []graph.EdgeID{"eAB", "eBC"}},
+		{2, 1, []graph.NodeID{"B", "C"}, []graph.EdgeID{"eBC"}},
+		{0, 3, []graph.NodeID{"D"}, nil},
+		{3, 10, []graph.NodeID{}, nil},
+	}
+
+	for _, tc := range cases {
+		res, err := FindNodesQuery{Key: "region", Op: OpNeq, Value: all, Limit: tc.limit, Offset: tc.offset}.Execute(context.Background(), g)
+		if err != nil {
+			t.Fatalf("Execute failed: %v", err)
+		}
+		if got := nodeIDs(res.(result.NodeListResult).Nodes); !slices.Equal(got, tc.wantNodes) {
+			t.Errorf("nodes LIMIT %d OFFSET %d: expected %v, got %v", tc.limit, tc.offset, tc.wantNodes, got)
+		}
+
+		res, err = FindEdgesQuery{Key: "mode", Op: OpNeq, Value: all, Limit: tc.limit, Offset: tc.offset}.Execute(context.Background(), g)
+		if err != nil {
+			t.Fatalf("Execute failed: %v", err)
+		}
+		var got []graph.EdgeID
+		for _, e := range res.(result.EdgeListResult).Edges {
+			got = append(got, e.ID)
+		}
+		if !slices.Equal(got, tc.wantEdges) {
+			t.Errorf("edges LIMIT %d OFFSET %d: expected %v, got %v", tc.limit, tc.offset, tc.wantEdges, got)
+		}
+	}
+}
+
+func TestFindEdgesQuery_RespectsCondition(t *testing.T) {
+	g := buildPropertyGraph(t)
+	eAB, err := g.GetEdgeByID("eAB")
+	if err != nil {
+		t.Fatalf("GetEdgeByID failed: %v", err)
+	}
+
+	q := ConditionalQuery{
+		Condition: graph.Condition{ForcedInactiveEdges: []*graph.Edge{eAB}},
+		Inner:     FindEdgesQuery{Key: "distance", Op: OpGt, Value: graph.Value{Kind: graph.IntVal, I: 0}},
+	}
+	res, err := q.Execute(context.Background(), g)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	edges := res.(result.EdgeListResult).Edges
+	if len(edges) != 1 || edges[0].ID != "eBC" {
+		t.Errorf("expected only eBC after forcing eAB inactive, got %v", edges)
+	}
+}
@@ -1,6 +1,9 @@
 package query
 
 import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
 	"testing"
 
 	"github.com/ritamzico/pgraph/internal/graph"
@@ -179,3 +182,48 @@ func buildComplexGraph(t *testing.T) graph.ProbabilisticGraphModel {
 
 	return g
 }
+
+// buildRandomGraph creates nodes n0..n(n-1) and, for each ordered pair,
+// an edge with probability 0.5 with chance density. The same seed always
+// gives the same graph. Dense cyclic graphs of 40 or so nodes are far too
+// large for exact reachability to finish quickly.
+func buildRandomGraph(t *testing.T, n int, density float64, seed uint64) graph.ProbabilisticGraphModel {
+	t.Helper()
+	g := graph.CreateProbAdjListGraph()
+	rng := rand.New(rand.NewPCG(seed, seed))
+
+	for i := range n {
+		if err := g.AddNode(graph.NodeID(fmt.Sprintf("n%d", i)), nil); err != nil {
+			t.Fatalf("failed to add node n%d: %v", i, err)
+		}
+	}
+	for i := range n {
+		for j := range n {
+			if i == j || rng.Float64() >= density {
+				continue
+			}
+			id := graph.EdgeID(fmt.Sprintf("e%d_%d", i, j))
+			if err := g.AddEdge(id, graph.NodeID(fmt.Sprintf("n%d", i)), graph.NodeID(fmt.Sprintf("n%d", j)), 0.5, nil); err != nil {
+				t.Fatalf("failed to add edge %s: %v", id, err)
+			}
+		}
+	}
+
+	return g
+}
+
+// nodeIDs returns the IDs of nodes, in order.
+func nodeIDs(nodes []*graph.Node) []graph.NodeID {
+	ids := make([]graph.NodeID, len(nodes))
+	for i, n := range nodes {
+		ids[i] = n.ID
+	}
+	return ids
+}
+
+// sortedNodeIDs returns the IDs of nodes, sorted.
+func sortedNodeIDs(nodes []*graph.Node) []graph.NodeID {
+	ids := nodeIDs(nodes)
+	slices.Sort(ids)
+	return ids
+}
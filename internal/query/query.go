@@ -10,3 +10,78 @@ import (
 type Query interface {
 	Execute(ctx context.Context, g graph.ProbabilisticGraphModel) (result.Result, error)
 }
+
+// IsExpensive reports whether q, or any query nested in it, uses exact
+// inference or path enumeration, whose cost grows exponentially with the
+// number of paths, or samples to a target confidence width, whose cost
+// grows with the inverse square of the width.
+// SequentialQuery is opaque until run and is reported by its first step only.
+func IsExpensive(q Query) bool {
+	switch q := q.(type) {
+	case ReachabilityProbabilityQuery:
+		return q.Mode != MonteCarlo
+	case MultiSourceSinkReachabilityQuery:
+		return q.Mode != MonteCarlo
+	case MatrixReachabilityQuery:
+		return q.Mode != MonteCarlo
+	case SensitivityQuery:
+		return q.Mode == Exact
+	case SensitivityMapQuery:
+		return true
+	case ReliabilityPolynomialQuery:
+		return true
+	case CountPathsQuery:
+		return q.MaxLength == 0
+	case ExpectedHopsQuery:
+		return true
+	case AllPathsStatQuery:
+		return true
+	case AllPathsQuery:
+		return q.MaxPaths == 0 && q.MaxLength == 0
+	case TopKProbabilityValuesQuery:
+		// The path cap bounds the paths kept, not the depth-first search.
+		return true
+	case ConfidenceQuery:
+		return true
+	case ConditionalQuery:
+		return IsExpensive(q.Inner)
+	case ThresholdQuery:
+		return IsExpensive(q.Inner)
+	case AssertQuery:
+		return IsExpensive(q.Inner)
+	case NotQuery:
+		return IsExpensive(q.Inner)
+	case ConcatPathQuery:
+		return IsExpensive(q.First) || IsExpensive(q.Second)
+	case SequentialQuery:
+		return IsExpensive(q.First)
+	case MultiQuery:
+		return anyExpensive(q.Queries)
+	case PartialMultiQuery:
+		return anyExpensive(q.Queries)
+	case AndQuery:
+		return anyExpensive(q.Queries)
+	case OrQuery:
+		return anyExpensive(q.Queries)
+	case XorQuery:
+		return anyExpensive(q.Queries)
+	case AggregateQuery:
+		return anyExpensive(q.Queries)
+	case ForEachQuery:
+		// Every iteration runs the same kind of query, so binding the
+		// source node is representative.
+		body, err := q.Body(q.SourceNode)
+		return err == nil && IsExpensive(body)
+	default:
+		return false
+	}
+}
+
+func anyExpensive(queries []Query) bool {
+	for _, q := range queries {
+		if IsExpensive(q) {
+			return true
+		}
+	}
+	return false
+}
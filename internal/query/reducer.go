@@ -1,7 +1,9 @@
 package query
 
 import (
+	"context"
 	"fmt"
+	"math"
 
 	"github.com/ritamzico/pgraph/internal/graph"
 	"github.com/ritamzico/pgraph/internal/result"
@@ -11,6 +13,39 @@ type Reducer interface {
 	Reduce([]result.Result) (result.Result, error)
 }
 
+// ReducerMonitor is implemented by reducers that can tell from some of the
+// results that the rest cannot change the answer, so that an AggregateQuery
+// with ShortCircuit set can stop early. Reducing just the received results
+// must then give the same answer as reducing all of them.
+type ReducerMonitor interface {
+	Reducer
+
+	// Determined reports whether received, a subset of total results in
+	// arrival order, already fixes the reduced value.
+	Determined(received []result.Result, total int) bool
+}
+
+// ContextReducer is implemented by reducers that do enough work of their
+// own to need the query's context, such as running a user script. An
+// AggregateQuery calls ReduceContext instead of Reduce, so that the
+// reduction honours the query's cancellation and deadline.
+type ContextReducer interface {
+	Reducer
+
+	ReduceContext(ctx context.Context, results []result.Result) (result.Result, error)
+}
+
+// anyProbability reports whether some probabilistic result in results
+// satisfies pred. Other result types are ignored; Reduce reports them.
+func anyProbability(results []result.Result, pred func(float64) bool) bool {
+	for _, res := range results {
+		if pr, ok := res.(result.ProbabilisticResult); ok && pred(pr.ProbabilityValue()) {
+			return true
+		}
+	}
+	return false
+}
+
 type MeanProbabilityReducer struct{}
 
 func (r MeanProbabilityReducer) Reduce(results []result.Result) (result.Result, error) {
@@ -18,19 +53,75 @@ func (r MeanProbabilityReducer) Reduce(results []result.Result) (result.Result,
 	var count int
 
 	for _, res := range results {
-		p, ok := res.(result.ProbabilityResult)
+		pr, ok := res.(result.ProbabilisticResult)
 		if !ok {
-			return nil, fmt.Errorf("expected ProbabilityResult, got %T", res)
+			return nil, fmt.Errorf("expected ProbabilisticResult, got %T", res)
 		}
-		sum += p.Probability
+		sum += pr.ProbabilityValue()
 		count++
 	}
 
-	return result.ProbabilityResult{
-		Probability: sum / float64(count),
-	}, nil
+	return result.NewProbabilityResult(sum / float64(count)), nil
 }
 
+type GeometricMeanProbabilityReducer struct{}
+
+func (r GeometricMeanProbabilityReducer) Reduce(results []result.Result) (result.Result, error) {
+	var logSum float64
+
+	for _, res := range results {
+		pr, ok := res.(result.ProbabilisticResult)
+		if !ok {
+			return nil, fmt.Errorf("expected ProbabilisticResult, got %T", res)
+		}
+		p := pr.ProbabilityValue()
+		if p <= 0 {
+			// log(0) is -Inf; a single zero factor makes the geometric mean zero.
+			return result.NewProbabilityResult(0.0), nil
+		}
+		logSum += math.Log(p)
+	}
+
+	return result.NewProbabilityResult(math.Exp(logSum / float64(len(results)))), nil
+}
+
+// Determined reports whether any result is zero, which makes the geometric
+// mean zero.
+func (r GeometricMeanProbabilityReducer) Determined(received []result.Result, total int) bool {
+	return anyProbability(received, func(p float64) bool { return p <= 0 })
+}
+
+// ProductProbabilityReducer multiplies the probabilities together. The
+// product is accumulated in log space so that many small factors do not
+// lose precision on the way to a result near the underflow threshold.
+type ProductProbabilityReducer struct{}
+
+func (r ProductProbabilityReducer) Reduce(results []result.Result) (result.Result, error) {
+	var logSum float64
+
+	for _, res := range results {
+		pr, ok := res.(result.ProbabilisticResult)
+		if !ok {
+			return nil, fmt.Errorf("expected ProbabilisticResult, got %T", res)
+		}
+		p := pr.ProbabilityValue()
+		if p <= 0 {
+			return result.NewProbabilityResult(0.0), nil
+		}
+		logSum += math.Log(p)
+	}
+
+	return result.NewProbabilityResult(math.Exp(logSum)), nil
+}
+
+// Determined reports whether any result is zero, which makes the product
+// zero.
+func (r ProductProbabilityReducer) Determined(received []result.Result, total int) bool {
+	return anyProbability(received, func(p float64) bool { return p <= 0 })
+}
+
+// BestPathReducer does not implement ReducerMonitor: among paths of equal
+// probability the first query's wins, so it must see every result.
 type BestPathReducer struct{}
 
 func (r BestPathReducer) Reduce(results []result.Result) (result.Result, error) {
@@ -67,7 +158,12 @@ func (r MaxProbabilityReducer) Reduce(results []result.Result) (result.Result, e
 		}
 	}
 
-	return result.ProbabilityResult{Probability: maxProb}, nil
+	return result.NewProbabilityResult(maxProb), nil
+}
+
+// Determined reports whether any result is 1, the highest possible value.
+func (r MaxProbabilityReducer) Determined(received []result.Result, total int) bool {
+	return anyProbability(received, func(p float64) bool { return p >= 1 })
 }
 
 type MinProbabilityReducer struct{}
@@ -85,9 +181,16 @@ func (r MinProbabilityReducer) Reduce(results []result.Result) (result.Result, e
 		}
 	}
 
-	return result.ProbabilityResult{Probability: minProb}, nil
+	return result.NewProbabilityResult(minProb), nil
+}
+
+// Determined reports whether any result is 0, the lowest possible value.
+func (r MinProbabilityReducer) Determined(received []result.Result, total int) bool {
+	return anyProbability(received, func(p float64) bool { return p <= 0 })
 }
 
+// CountAboveThresholdReducer does not implement ReducerMonitor: the fraction
+// it returns depends on every result.
 type CountAboveThresholdReducer struct {
 	Threshold float64
 }
@@ -105,7 +208,5 @@ func (r CountAboveThresholdReducer) Reduce(results []result.Result) (result.Resu
 		}
 	}
 
-	return result.ProbabilityResult{
-		Probability: float64(count) / float64(len(results)),
-	}, nil
+	return result.NewProbabilityResult(float64(count) / float64(len(results))), nil
 }
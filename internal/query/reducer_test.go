@@ -45,6 +45,26 @@ func TestMeanProbabilityReducer_SingleResult(t *testing.T) {
 	}
 }
 
+func TestMeanProbabilityReducer_AcceptsBooleanResult(t *testing.T) {
+	r := MeanProbabilityReducer{}
+	results := []result.Result{
+		result.BooleanResult{Value: true},
+		result.ProbabilityResult{Probability: 0.4},
+		result.BooleanResult{Value: false},
+	}
+
+	res, err := r.Reduce(results)
+	if err != nil {
+		t.Fatalf("Reduce failed: %v", err)
+	}
+
+	// (1.0 + 0.4 + 0.0) / 3
+	prob := res.(result.ProbabilityResult).Probability
+	if math.Abs(prob-1.4/3) > 0.0001 {
+		t.Errorf("expected %f, got %f", 1.4/3, prob)
+	}
+}
+
 func TestMeanProbabilityReducer_TypeMismatch(t *testing.T) {
 	r := MeanProbabilityReducer{}
 	results := []result.Result{
@@ -57,6 +77,191 @@ func TestMeanProbabilityReducer_TypeMismatch(t *testing.T) {
 	}
 }
 
+// --- GeometricMeanProbabilityReducer ---
+
+func TestGeometricMeanProbabilityReducer_TwoResults(t *testing.T) {
+	r := GeometricMeanProbabilityReducer{}
+	results := []result.Result{
+		result.ProbabilityResult{Probability: 0.9},
+		result.ProbabilityResult{Probability: 0.4},
+	}
+
+	res, err := r.Reduce(results)
+	if err != nil {
+		t.Fatalf("Reduce failed: %v", err)
+	}
+
+	// sqrt(0.9 * 0.4) = 0.6
+	prob := res.(result.ProbabilityResult).Probability
+	if math.Abs(prob-0.6) > 0.0001 {
+		t.Errorf("expected 0.6, got %f", prob)
+	}
+}
+
+func TestGeometricMeanProbabilityReducer_ZeroProbability(t *testing.T) {
+	r := GeometricMeanProbabilityReducer{}
+	results := []result.Result{
+		result.ProbabilityResult{Probability: 0.9},
+		result.ProbabilityResult{Probability: 0.0},
+		result.ProbabilityResult{Probability: 0.8},
+	}
+
+	res, err := r.Reduce(results)
+	if err != nil {
+		t.Fatalf("Reduce failed: %v", err)
+	}
+
+	prob := res.(result.ProbabilityResult).Probability
+	if prob != 0.0 {
+		t.Errorf("expected 0.0 when any probability is zero, got %f", prob)
+	}
+}
+
+func TestGeometricMeanProbabilityReducer_AllOnes(t *testing.T) {
+	r := GeometricMeanProbabilityReducer{}
+	results := []result.Result{
+		result.ProbabilityResult{Probability: 1.0},
+		result.ProbabilityResult{Probability: 1.0},
+	}
+
+	res, err := r.Reduce(results)
+	if err != nil {
+		t.Fatalf("Reduce failed: %v", err)
+	}
+
+	prob := res.(result.ProbabilityResult).Probability
+	if math.Abs(prob-1.0) > 0.0001 {
+		t.Errorf("expected 1.0, got %f", prob)
+	}
+}
+
+func TestGeometricMeanProbabilityReducer_BelowArithmeticMean(t *testing.T) {
+	probs := []float64{1.0, 0.9, 0.1, 0.5}
+	results := make([]result.Result, len(probs))
+	for i, p := range probs {
+		results[i] = result.ProbabilityResult{Probability: p}
+	}
+
+	geoRes, err := GeometricMeanProbabilityReducer{}.Reduce(results)
+	if err != nil {
+		t.Fatalf("geometric Reduce failed: %v", err)
+	}
+	meanRes, err := MeanProbabilityReducer{}.Reduce(results)
+	if err != nil {
+		t.Fatalf("arithmetic Reduce failed: %v", err)
+	}
+
+	geo := geoRes.(result.ProbabilityResult).Probability
+	mean := meanRes.(result.ProbabilityResult).Probability
+
+	want := math.Pow(1.0*0.9*0.1*0.5, 0.25)
+	if math.Abs(geo-want) > 0.0001 {
+		t.Errorf("expected geometric mean %f, got %f", want, geo)
+	}
+	if geo >= mean {
+		t.Errorf("expected geometric mean (%f) < arithmetic mean (%f) for unequal inputs", geo, mean)
+	}
+}
+
+func TestGeometricMeanProbabilityReducer_AcceptsProbabilisticResult(t *testing.T) {
+	r := GeometricMeanProbabilityReducer{}
+	results := []result.Result{
+		result.ProbabilityResult{Probability: 0.5},
+		result.SampleResult{Estimate: 0.5},
+	}
+
+	res, err := r.Reduce(results)
+	if err != nil {
+		t.Fatalf("Reduce failed: %v", err)
+	}
+
+	prob := res.(result.ProbabilityResult).Probability
+	if math.Abs(prob-0.5) > 0.0001 {
+		t.Errorf("expected 0.5, got %f", prob)
+	}
+}
+
+func TestGeometricMeanProbabilityReducer_TypeMismatch(t *testing.T) {
+	r := GeometricMeanProbabilityReducer{}
+	results := []result.Result{
+		result.PathsResult{Paths: nil},
+	}
+
+	_, err := r.Reduce(results)
+	if err == nil {
+		t.Error("expected error for non-ProbabilisticResult input")
+	}
+}
+
+// --- ProductProbabilityReducer ---
+
+func TestProductProbabilityReducer_TwoResults(t *testing.T) {
+	r := ProductProbabilityReducer{}
+	results := []result.Result{
+		result.ProbabilityResult{Probability: 0.9},
+		result.ProbabilityResult{Probability: 0.4},
+	}
+
+	res, err := r.Reduce(results)
+	if err != nil {
+		t.Fatalf("Reduce failed: %v", err)
+	}
+
+	prob := res.(result.ProbabilityResult).Probability
+	if math.Abs(prob-0.36) > 0.0001 {
+		t.Errorf("expected 0.36, got %f", prob)
+	}
+}
+
+func TestProductProbabilityReducer_ZeroProbability(t *testing.T) {
+	r := ProductProbabilityReducer{}
+	results := []result.Result{
+		result.ProbabilityResult{Probability: 0.9},
+		result.ProbabilityResult{Probability: 0.0},
+	}
+
+	res, err := r.Reduce(results)
+	if err != nil {
+		t.Fatalf("Reduce failed: %v", err)
+	}
+
+	prob := res.(result.ProbabilityResult).Probability
+	if prob != 0.0 {
+		t.Errorf("expected 0.0 when any probability is zero, got %g", prob)
+	}
+}
+
+func TestProductProbabilityReducer_NearUnderflow(t *testing.T) {
+	// 0.3^580 is about 1.4e-303, just above the smallest normal float64
+	// (about 2.2e-308).
+	const n = 580
+	results := make([]result.Result, n)
+	for i := range results {
+		results[i] = result.ProbabilityResult{Probability: 0.3}
+	}
+
+	res, err := ProductProbabilityReducer{}.Reduce(results)
+	if err != nil {
+		t.Fatalf("Reduce failed: %v", err)
+	}
+
+	prob := res.(result.ProbabilityResult).Probability
+	want := math.Pow(0.3, n)
+	if want < 0x1p-1022 {
+		t.Fatalf("test product %g is not a normal float64", want)
+	}
+	if math.Abs(prob-want)/want > 1e-9 {
+		t.Errorf("expected %g, got %g (relative error %g)", want, prob, math.Abs(prob-want)/want)
+	}
+}
+
+func TestProductProbabilityReducer_TypeMismatch(t *testing.T) {
+	_, err := ProductProbabilityReducer{}.Reduce([]result.Result{result.PathsResult{Paths: nil}})
+	if err == nil {
+		t.Error("expected error for non-probabilistic input")
+	}
+}
+
 // --- BestPathReducer ---
 
 func TestBestPathReducer_SelectsHighest(t *testing.T) {
@@ -181,6 +386,25 @@ func TestMaxProbabilityReducer_AcceptsProbabilisticResult(t *testing.T) {
 	}
 }
 
+func TestMaxProbabilityReducer_AcceptsBooleanResult(t *testing.T) {
+	r := MaxProbabilityReducer{}
+	results := []result.Result{
+		result.BooleanResult{Value: false},
+		result.ProbabilityResult{Probability: 0.4},
+		result.BooleanResult{Value: true},
+	}
+
+	res, err := r.Reduce(results)
+	if err != nil {
+		t.Fatalf("Reduce failed: %v", err)
+	}
+
+	prob := res.(result.ProbabilityResult).Probability
+	if math.Abs(prob-1.0) > 0.0001 {
+		t.Errorf("expected 1.0, got %f", prob)
+	}
+}
+
 func TestMaxProbabilityReducer_TypeMismatch(t *testing.T) {
 	r := MaxProbabilityReducer{}
 	results := []result.Result{
@@ -267,10 +491,29 @@ func TestMinProbabilityReducer_AcceptsProbabilisticResult(t *testing.T) {
 	}
 }
 
-func TestMinProbabilityReducer_TypeMismatch(t *testing.T) {
+func TestMinProbabilityReducer_AcceptsBooleanResult(t *testing.T) {
 	r := MinProbabilityReducer{}
 	results := []result.Result{
 		result.BooleanResult{Value: true},
+		result.ProbabilityResult{Probability: 0.4},
+		result.BooleanResult{Value: false},
+	}
+
+	res, err := r.Reduce(results)
+	if err != nil {
+		t.Fatalf("Reduce failed: %v", err)
+	}
+
+	prob := res.(result.ProbabilityResult).Probability
+	if math.Abs(prob-0.0) > 0.0001 {
+		t.Errorf("expected 0.0, got %f", prob)
+	}
+}
+
+func TestMinProbabilityReducer_TypeMismatch(t *testing.T) {
+	r := MinProbabilityReducer{}
+	results := []result.Result{
+		result.PathsResult{Paths: nil},
 	}
 
 	_, err := r.Reduce(results)
@@ -358,6 +601,26 @@ func TestCountAboveThresholdReducer_ExactThreshold(t *testing.T) {
 	}
 }
 
+func TestCountAboveThresholdReducer_BooleanResults(t *testing.T) {
+	r := CountAboveThresholdReducer{Threshold: 0.5}
+	results := []result.Result{
+		result.BooleanResult{Value: true},
+		result.BooleanResult{Value: false},
+		result.BooleanResult{Value: true},
+		result.BooleanResult{Value: false},
+	}
+
+	res, err := r.Reduce(results)
+	if err != nil {
+		t.Fatalf("Reduce failed: %v", err)
+	}
+
+	prob := res.(result.ProbabilityResult).Probability
+	if math.Abs(prob-0.5) > 0.0001 {
+		t.Errorf("expected 0.5, got %f", prob)
+	}
+}
+
 func TestCountAboveThresholdReducer_TypeMismatch(t *testing.T) {
 	r := CountAboveThresholdReducer{Threshold: 0.5}
 	results := []result.Result{
@@ -1,15 +1,28 @@
 package query
 
 import (
+	"cmp"
 	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"time"
 
 	"github.com/ritamzico/pgraph/internal/graph"
 	"github.com/ritamzico/pgraph/internal/inference"
 	"github.com/ritamzico/pgraph/internal/result"
 )
 
+// MaxProbabilityPathQuery finds the most probable path from Start to End.
+// With WeightProperty set, it instead maximises the product of that numeric
+// edge property, or with Bottleneck its smallest value along the path; see
+// inference.MaxPropertyPath and inference.MaxBottleneckPath.
 type MaxProbabilityPathQuery struct {
-	Start, End graph.NodeID
+	Start, End     graph.NodeID
+	WeightProperty string
+	Bottleneck     bool
 }
 
 func (q MaxProbabilityPathQuery) Execute(ctx context.Context, g graph.ProbabilisticGraphModel) (result.Result, error) {
@@ -19,7 +32,16 @@ func (q MaxProbabilityPathQuery) Execute(ctx context.Context, g graph.Probabilis
 	default:
 	}
 
-	path, err := inference.MaxProbabilityPath(g, q.Start, q.End)
+	var path graph.Path
+	var err error
+	switch {
+	case q.WeightProperty != "" && q.Bottleneck:
+		path, err = inference.MaxBottleneckPath(g, q.Start, q.End, q.WeightProperty)
+	case q.WeightProperty != "":
+		path, err = inference.MaxPropertyPath(g, q.Start, q.End, q.WeightProperty)
+	default:
+		path, err = inference.MaxProbabilityPath(g, q.Start, q.End)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -29,9 +51,16 @@ func (q MaxProbabilityPathQuery) Execute(ctx context.Context, g graph.Probabilis
 	}, nil
 }
 
+// TopKProbabilityPathsQuery finds up to K most probable paths. With MinProb
+// set, paths less probable than it are omitted. With Timeout set, the search
+// stops after that long and returns the paths found so far, marked
+// Truncated. A non-zero MaxHops leaves out paths of more edges than it.
 type TopKProbabilityPathsQuery struct {
 	Start, End graph.NodeID
 	K          int
+	MinProb    float64
+	MaxHops    int
+	Timeout    time.Duration
 }
 
 func (q TopKProbabilityPathsQuery) Execute(ctx context.Context, g graph.ProbabilisticGraphModel) (result.Result, error) {
@@ -41,27 +70,285 @@ func (q TopKProbabilityPathsQuery) Execute(ctx context.Context, g graph.Probabil
 	default:
 	}
 
-	paths, err := inference.TopKMaxProbabilityPaths(g, q.Start, q.End, q.K)
+	searchCtx := ctx
+	if q.Timeout > 0 {
+		var cancel context.CancelFunc
+		searchCtx, cancel = context.WithTimeout(ctx, q.Timeout)
+		defer cancel()
+	}
+
+	paths, truncated, err := inference.TopKMaxProbabilityPathsContext(searchCtx, g, q.Start, q.End, q.K, inference.TopKOptions{MinProbability: q.MinProb, MaxHops: q.MaxHops})
 	if err != nil {
 		return nil, err
 	}
+	// Only the query's own timeout yields a partial result; cancellation
+	// by the caller is an error as for any other query.
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
 	return result.PathsResult{
-		Paths: paths,
+		Paths:     paths,
+		Truncated: truncated,
 	}, nil
 }
 
+// TopKProbabilityValuesQuery returns the K largest distinct probabilities
+// among the simple paths from Start to End, without the paths themselves;
+// see inference.TopKDistinctPathProbabilities.
+type TopKProbabilityValuesQuery struct {
+	Start, End graph.NodeID
+	K          int
+}
+
+func (q TopKProbabilityValuesQuery) Execute(ctx context.Context, g graph.ProbabilisticGraphModel) (result.Result, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	values, err := inference.TopKDistinctPathProbabilities(g, q.Start, q.End, q.K)
+	if err != nil {
+		return nil, err
+	}
+	return result.ValuesResult{Values: values}, nil
+}
+
+// PathProbabilityQuery evaluates the probability of one explicit path; see
+// inference.PathProbability.
+type PathProbabilityQuery struct {
+	Nodes []graph.NodeID
+}
+
+func (q PathProbabilityQuery) Execute(ctx context.Context, g graph.ProbabilisticGraphModel) (result.Result, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	p, err := inference.PathProbability(g, q.Nodes)
+	if err != nil {
+		return nil, err
+	}
+	return result.NewProbabilityResult(p), nil
+}
+
+// AllPathsQuery returns the simple paths from Start to End, most probable
+// first. MaxPaths and MaxLength cap the search (zero for no limit); see
+// inference.AllSimplePaths.
+type AllPathsQuery struct {
+	Start, End graph.NodeID
+	MaxPaths   int
+	MaxLength  int
+}
+
+func (q AllPathsQuery) Execute(ctx context.Context, g graph.ProbabilisticGraphModel) (result.Result, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	paths, err := inference.AllSimplePaths(g, q.Start, q.End, q.MaxPaths, q.MaxLength)
+	if err != nil {
+		return nil, err
+	}
+	return result.PathsResult{Paths: paths}, nil
+}
+
+// PathMeasure is the per-path value AllPathsStatQuery aggregates.
+type PathMeasure int
+
+const (
+	MeasureProbability PathMeasure = iota
+	MeasureHops
+)
+
+// AllPathsStatQuery computes Stat over the Measure of every simple path from
+// Start to End without storing the paths; see inference.AllPathsStat. The
+// statistic is returned as a ProbabilityResult's Probability, even when it
+// is a hop count or a number of paths.
+type AllPathsStatQuery struct {
+	Start, End graph.NodeID
+	Stat       inference.PathStatistic
+	Measure    PathMeasure
+}
+
+func (q AllPathsStatQuery) Execute(ctx context.Context, g graph.ProbabilisticGraphModel) (result.Result, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	value := func(p graph.Path) float64 { return p.Probability }
+	if q.Measure == MeasureHops {
+		value = func(p graph.Path) float64 { return float64(len(p.NodeIDs) - 1) }
+	}
+
+	v, err := inference.AllPathsStat(g, q.Start, q.End, q.Stat, value)
+	if err != nil {
+		return nil, err
+	}
+	return result.NewProbabilityResult(v), nil
+}
+
+// CountPathsQuery counts the simple paths from Start to End of at most
+// MaxLength edges (zero for no limit); see inference.CountSimplePaths.
+type CountPathsQuery struct {
+	Start, End graph.NodeID
+	MaxLength  int
+}
+
+func (q CountPathsQuery) Execute(ctx context.Context, g graph.ProbabilisticGraphModel) (result.Result, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	n, err := inference.CountSimplePaths(g, q.Start, q.End, q.MaxLength)
+	if err != nil {
+		return nil, err
+	}
+	return result.NumberResult{Value: n}, nil
+}
+
+// ExpectedHopsQuery computes the probability-weighted average hop count of
+// the simple paths from Start to End; see inference.ExpectedPathLength. The
+// result is a ProbabilityResult whose Probability holds the hop count, as
+// NumberResult only holds integer counts.
+type ExpectedHopsQuery struct {
+	Start, End graph.NodeID
+}
+
+func (q ExpectedHopsQuery) Execute(ctx context.Context, g graph.ProbabilisticGraphModel) (result.Result, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	hops, err := inference.ExpectedPathLength(g, q.Start, q.End)
+	if err != nil {
+		return nil, err
+	}
+	return result.NewProbabilityResult(hops), nil
+}
+
+// ConnectedQuery reports whether End is structurally reachable from Start;
+// see inference.Connected.
+type ConnectedQuery struct {
+	Start, End graph.NodeID
+}
+
+func (q ConnectedQuery) Execute(ctx context.Context, g graph.ProbabilisticGraphModel) (result.Result, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	connected, err := inference.Connected(g, q.Start, q.End)
+	if err != nil {
+		return nil, err
+	}
+	return result.BooleanResult{Value: connected}, nil
+}
+
+// UnreachableQuery lists the nodes that Root cannot reach through any path,
+// sorted by ID; see inference.UnreachableNodes.
+type UnreachableQuery struct {
+	Root graph.NodeID
+}
+
+func (q UnreachableQuery) Execute(ctx context.Context, g graph.ProbabilisticGraphModel) (result.Result, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	ids, err := inference.UnreachableNodes(g, q.Root)
+	if err != nil {
+		return nil, err
+	}
+	return nodeListResult(g, ids), nil
+}
+
+// SourceNodesQuery lists the nodes with no incoming edges, sorted by ID;
+// see inference.SourceNodes.
+type SourceNodesQuery struct{}
+
+func (q SourceNodesQuery) Execute(ctx context.Context, g graph.ProbabilisticGraphModel) (result.Result, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	return nodeListResult(g, inference.SourceNodes(g)), nil
+}
+
+// SinkNodesQuery lists the nodes with no outgoing edges, sorted by ID; see
+// inference.SinkNodes.
+type SinkNodesQuery struct{}
+
+func (q SinkNodesQuery) Execute(ctx context.Context, g graph.ProbabilisticGraphModel) (result.Result, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	return nodeListResult(g, inference.SinkNodes(g)), nil
+}
+
+// nodeListResult looks up the nodes with the given IDs, returning them
+// sorted by ID.
+func nodeListResult(g graph.ProbabilisticGraphModel, ids []graph.NodeID) result.NodeListResult {
+	want := make(map[graph.NodeID]bool, len(ids))
+	for _, id := range ids {
+		want[id] = true
+	}
+	nodes := g.FilterNodes(func(n *graph.Node) bool { return want[n.ID] })
+	slices.SortFunc(nodes, func(a, b *graph.Node) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return result.NodeListResult{Nodes: nodes}
+}
+
 type InferenceMode int
 
 const (
 	Exact InferenceMode = iota
 	MonteCarlo
+	// Both runs Exact and MonteCarlo concurrently and compares them. Only
+	// ReachabilityProbabilityQuery supports it.
+	Both
 )
 
+// DefaultMonteCarloSamples is the number of worlds a Monte Carlo
+// reachability query samples when it does not set Samples.
+const DefaultMonteCarloSamples = 10000
+
+// ReachabilityProbabilityQuery computes the probability that End is
+// reachable from Start. A non-zero Timeout bounds exact inference, in Exact
+// and Both modes; if it expires the query fails with a Timeout QueryError.
+// Samples sets the Monte Carlo sample count, DefaultMonteCarloSamples if
+// zero.
 type ReachabilityProbabilityQuery struct {
 	Start, End graph.NodeID
 	Mode       InferenceMode
 	Seed       uint64
+	Timeout    time.Duration
+	Samples    int
+
+	// Cache, if set, serves and stores exact results. The engine sets it
+	// when its incremental cache is enabled.
+	Cache *inference.IncrementalReachabilityCache `canonical:"-"`
 }
 
 func (q ReachabilityProbabilityQuery) Execute(ctx context.Context, g graph.ProbabilisticGraphModel) (result.Result, error) {
@@ -76,30 +363,269 @@ func (q ReachabilityProbabilityQuery) Execute(ctx context.Context, g graph.Proba
 
 	switch q.Mode {
 	case Exact:
-		probability, err = inference.ReachabilityProbability(g, q.Start, q.End)
+		if graph.HasProbabilityIntervals(g) {
+			return q.exactInterval(ctx, g)
+		}
+
+		probability, err = q.exact(ctx, g)
 		if err != nil {
 			return nil, err
 		}
 
-		return result.ProbabilityResult{
-			Probability: probability,
-		}, nil
+		return result.NewProbabilityResult(probability), nil
 	case MonteCarlo:
-		sampleResult, err := inference.ReachabilityProbabilityMonteCarlo(g, q.Start, q.End, 10000, q.Seed)
+		sampleResult, err := inference.ReachabilityProbabilityMonteCarlo(g, q.Start, q.End, q.samples(), q.Seed)
 		if err != nil {
 			return nil, err
 		}
 
 		return sampleResult, nil
 
+	case Both:
+		var sampleResult result.SampleResult
+		var sampleErr error
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			sampleResult, sampleErr = inference.ReachabilityProbabilityMonteCarlo(g, q.Start, q.End, q.samples(), q.Seed)
+		}()
+
+		probability, err = q.exact(ctx, g)
+		<-done
+		if err != nil {
+			return nil, err
+		}
+		if sampleErr != nil {
+			return nil, sampleErr
+		}
+
+		return result.ComparisonResult{
+			Exact:     result.NewProbabilityResult(probability),
+			Sample:    sampleResult,
+			Deviation: math.Abs(probability - sampleResult.Estimate),
+		}, nil
+
 	default:
 		return nil, QueryError{
 			Kind:    "InvalidMode",
-			Message: "inference mode should be query.Exact or query.MonteCarlo",
+			Message: "inference mode should be query.Exact, query.MonteCarlo or query.Both",
 		}
 	}
 }
 
+func (q ReachabilityProbabilityQuery) samples() int {
+	if q.Samples == 0 {
+		return DefaultMonteCarloSamples
+	}
+	return q.Samples
+}
+
+// exactInterval bounds the reachability of a graph with interval edge
+// probabilities. Reachability only grows with each edge's probability, so
+// setting every edge to the bottom, then the top, of its interval gives the
+// bounds.
+func (q ReachabilityProbabilityQuery) exactInterval(ctx context.Context, g graph.ProbabilisticGraphModel) (result.Result, error) {
+	lowGraph, err := graph.LowerBoundGraph(g)
+	if err != nil {
+		return nil, err
+	}
+	highGraph, err := graph.UpperBoundGraph(g)
+	if err != nil {
+		return nil, err
+	}
+
+	// The bound graphs are fresh copies that the cache would never see
+	// again.
+	q.Cache = nil
+	low, err := q.exact(ctx, lowGraph)
+	if err != nil {
+		return nil, err
+	}
+	high, err := q.exact(ctx, highGraph)
+	if err != nil {
+		return nil, err
+	}
+	return result.ProbabilityIntervalResult{Low: low, High: high}, nil
+}
+
+// exact runs exact inference, bounded by q.Timeout if it is set.
+func (q ReachabilityProbabilityQuery) exact(ctx context.Context, g graph.ProbabilisticGraphModel) (float64, error) {
+	reachability := inference.ReachabilityProbabilityContext
+	if q.Cache != nil {
+		reachability = q.Cache.Reachability
+	}
+	if q.Timeout <= 0 {
+		return reachability(ctx, g, q.Start, q.End)
+	}
+
+	exactCtx, cancel := context.WithTimeout(ctx, q.Timeout)
+	defer cancel()
+
+	probability, err := reachability(exactCtx, g, q.Start, q.End)
+	// Cancellation by the caller is reported as is; only the query's own
+	// deadline becomes a Timeout error.
+	if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+		return 0, QueryError{
+			Kind:    "Timeout",
+			Message: fmt.Sprintf("exact reachability from %v to %v did not finish within %v", q.Start, q.End, q.Timeout),
+		}
+	}
+	return probability, err
+}
+
+// MultiSourceSinkReachabilityQuery is the probability that at least one of
+// Sinks is reachable from at least one of Sources. It joins Sources to a
+// virtual super-source, and Sinks to a virtual super-sink, by edges of
+// probability 1 on a clone of the graph, and runs an ordinary
+// ReachabilityProbabilityQuery between the two. A node in both sets makes
+// the result 1.
+type MultiSourceSinkReachabilityQuery struct {
+	Sources, Sinks []graph.NodeID
+	Mode           InferenceMode
+	Seed           uint64
+	Timeout        time.Duration
+}
+
+func (q MultiSourceSinkReachabilityQuery) Execute(ctx context.Context, g graph.ProbabilisticGraphModel) (result.Result, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	if len(q.Sources) == 0 || len(q.Sinks) == 0 {
+		return nil, QueryError{
+			Kind:    "InvalidParameter",
+			Message: "sources and sinks must each contain at least one node",
+		}
+	}
+
+	augmented := g.Clone()
+	source := unusedNodeID(augmented, "__source")
+	sink := unusedNodeID(augmented, "__sink")
+	if err := augmented.AddNode(source, nil); err != nil {
+		return nil, err
+	}
+	if err := augmented.AddNode(sink, nil); err != nil {
+		return nil, err
+	}
+
+	edge := 0
+	addVirtualEdge := func(from, to graph.NodeID) error {
+		for {
+			id := graph.EdgeID(fmt.Sprintf("__virtual_%d", edge))
+			edge++
+			if !augmented.ContainsEdgeByID(id) {
+				return augmented.AddEdge(id, from, to, 1.0, nil)
+			}
+		}
+	}
+	for _, id := range q.Sources {
+		if augmented.ContainsEdge(source, id) {
+			continue
+		}
+		if err := addVirtualEdge(source, id); err != nil {
+			return nil, err
+		}
+	}
+	for _, id := range q.Sinks {
+		if augmented.ContainsEdge(id, sink) {
+			continue
+		}
+		if err := addVirtualEdge(id, sink); err != nil {
+			return nil, err
+		}
+	}
+
+	return ReachabilityProbabilityQuery{
+		Start:   source,
+		End:     sink,
+		Mode:    q.Mode,
+		Seed:    q.Seed,
+		Timeout: q.Timeout,
+	}.Execute(ctx, augmented)
+}
+
+// unusedNodeID returns base, suffixed with underscores until no node in g
+// has that ID.
+func unusedNodeID(g graph.ProbabilisticGraphModel, base graph.NodeID) graph.NodeID {
+	id := base
+	for g.ContainsNode(id) {
+		id += "_"
+	}
+	return id
+}
+
+// ConfidenceQuery estimates reachability by Monte Carlo, sampling until the
+// 95% confidence interval is at most Width wide; see
+// inference.ReachabilityProbabilityTargetCI.
+type ConfidenceQuery struct {
+	Start, End graph.NodeID
+	Width      float64
+	Seed       uint64
+}
+
+func (q ConfidenceQuery) Execute(ctx context.Context, g graph.ProbabilisticGraphModel) (result.Result, error) {
+	return inference.ReachabilityProbabilityTargetCI(ctx, g, q.Start, q.End, q.Width, q.Seed)
+}
+
+// RandomWalkQuery simulates one random walk of up to Steps edges from Start;
+// see inference.RandomWalk. The same Seed always yields the same walk.
+type RandomWalkQuery struct {
+	Start graph.NodeID
+	Steps int
+	Seed  uint64
+}
+
+func (q RandomWalkQuery) Execute(ctx context.Context, g graph.ProbabilisticGraphModel) (result.Result, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	rng := rand.New(rand.NewPCG(q.Seed, q.Seed^0xda942042e4dd58b5))
+	path, err := inference.RandomWalk(g, q.Start, q.Steps, rng)
+	if err != nil {
+		return nil, err
+	}
+	return result.PathResult{Path: path}, nil
+}
+
+// SampleWorldQuery draws one possible world; see inference.SampleWorld. The
+// same Seed always yields the same world.
+type SampleWorldQuery struct {
+	Seed uint64
+}
+
+func (q SampleWorldQuery) Execute(ctx context.Context, g graph.ProbabilisticGraphModel) (result.Result, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	rng := rand.New(rand.NewPCG(q.Seed, q.Seed^0xda942042e4dd58b5))
+	active, inactive := inference.SampleWorld(g, rng)
+	return result.SampledWorldResult{Seed: q.Seed, ActiveEdges: active, InactiveEdges: inactive}, nil
+}
+
+// ReliabilityPolynomialQuery evaluates the reliability polynomial between
+// Start and End; see inference.ReliabilityPolynomial.
+type ReliabilityPolynomialQuery struct {
+	Start, End graph.NodeID
+}
+
+func (q ReliabilityPolynomialQuery) Execute(ctx context.Context, g graph.ProbabilisticGraphModel) (result.Result, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	return inference.ReliabilityPolynomial(g, q.Start, q.End)
+}
+
 type SensitivityQuery struct {
 	Start, End graph.NodeID
 	Mode       InferenceMode
@@ -124,3 +650,50 @@ func (q SensitivityQuery) Execute(ctx context.Context, g graph.ProbabilisticGrap
 		}
 	}
 }
+
+// SensitivityMapQuery scores every edge by how much the exact reachability
+// probability from Start to End drops when that edge is forced inactive.
+// The baseline and each edge's ConditionalQuery run concurrently.
+type SensitivityMapQuery struct {
+	Start, End graph.NodeID
+}
+
+func (q SensitivityMapQuery) Execute(ctx context.Context, g graph.ProbabilisticGraphModel) (result.Result, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	reach := ReachabilityProbabilityQuery{Start: q.Start, End: q.End, Mode: Exact}
+	// A bidirectional pair fails as one, so its reverse half is not scored.
+	edges := g.FilterEdges(func(e *graph.Edge) bool { return !e.IsReverseHalf() })
+	queries := make([]Query, 0, len(edges)+1)
+	queries = append(queries, reach)
+	for _, e := range edges {
+		queries = append(queries, ConditionalQuery{
+			Condition: graph.Condition{ForcedInactiveEdges: []*graph.Edge{e}},
+			Inner:     reach,
+		})
+	}
+
+	return executeConcurrent(ctx, g, queries, func(results []result.Result) (result.Result, error) {
+		probs := make([]float64, len(results))
+		for i, r := range results {
+			pr, ok := r.(result.ProbabilisticResult)
+			if !ok {
+				return nil, QueryError{
+					Kind:    "TypeMismatch",
+					Message: fmt.Sprintf("inner query expected ProbabilisticResult, got %T", r),
+				}
+			}
+			probs[i] = pr.ProbabilityValue()
+		}
+
+		scores := make(map[graph.EdgeID]float64, len(edges))
+		for i, e := range edges {
+			scores[e.ID] = probs[0] - probs[i+1]
+		}
+		return result.EdgeScoresResult{Scores: scores}, nil
+	})
+}
@@ -2,9 +2,14 @@ package query
 
 import (
 	"context"
+	"errors"
 	"math"
+	"slices"
 	"testing"
+	"time"
 
+	"github.com/ritamzico/pgraph/internal/graph"
+	"github.com/ritamzico/pgraph/internal/inference"
 	"github.com/ritamzico/pgraph/internal/result"
 )
 
@@ -74,6 +79,52 @@ func TestMaxProbabilityPathQuery_NoPath(t *testing.T) {
 	}
 }
 
+func TestMaxProbabilityPathQuery_ZeroProbabilityEdge(t *testing.T) {
+	g := buildLinearGraph(t, 0.9, 0.0)
+	q := MaxProbabilityPathQuery{Start: "A", End: "C"}
+
+	res, err := q.Execute(context.Background(), g)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	pathRes, ok := res.(result.PathResult)
+	if !ok {
+		t.Fatalf("expected PathResult, got %T", res)
+	}
+
+	expected := []graph.NodeID{"A", "B", "C"}
+	if !slices.Equal(pathRes.Path.NodeIDs, expected) {
+		t.Errorf("expected path %v, got %v", expected, pathRes.Path.NodeIDs)
+	}
+
+	if pathRes.Path.Probability != 0.0 {
+		t.Errorf("expected probability 0.0, got %f", pathRes.Path.Probability)
+	}
+}
+
+func TestMaxProbabilityPathQuery_ZeroProbabilityEdgeNotPreferred(t *testing.T) {
+	g := buildDiamondGraph(t)
+	if err := g.AddEdge("eAD", "A", "D", 0.0, nil); err != nil {
+		t.Fatalf("failed to add edge A->D: %v", err)
+	}
+	q := MaxProbabilityPathQuery{Start: "A", End: "D"}
+
+	res, err := q.Execute(context.Background(), g)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	pathRes := res.(result.PathResult)
+	expected := []graph.NodeID{"A", "B", "D"}
+	if !slices.Equal(pathRes.Path.NodeIDs, expected) {
+		t.Errorf("expected path %v, got %v", expected, pathRes.Path.NodeIDs)
+	}
+	if math.Abs(pathRes.Path.Probability-0.63) > 0.0001 {
+		t.Errorf("expected probability 0.63, got %f", pathRes.Path.Probability)
+	}
+}
+
 func TestMaxProbabilityPathQuery_SameNode(t *testing.T) {
 	g := buildLinearGraph(t, 0.9, 0.8)
 	q := MaxProbabilityPathQuery{Start: "A", End: "A"}
@@ -97,6 +148,113 @@ func TestMaxProbabilityPathQuery_SameNode(t *testing.T) {
 	}
 }
 
+// buildWeightedDiamondGraph is buildDiamondGraph with a "reliability"
+// property on every edge that favours A->C->D over the more probable
+// A->B->D.
+func buildWeightedDiamondGraph(t *testing.T, reliabilityAB graph.Value) graph.ProbabilisticGraphModel {
+	t.Helper()
+	g := graph.CreateProbAdjListGraph()
+	for _, n := range []graph.NodeID{"A", "B", "C", "D"} {
+		if err := g.AddNode(n, nil); err != nil {
+			t.Fatalf("failed to add node %s: %v", n, err)
+		}
+	}
+	high := graph.Value{Kind: graph.FloatVal, F: 0.9}
+	edges := []struct {
+		id, from, to string
+		prob         float64
+		reliability  graph.Value
+	}{
+		{"eAB", "A", "B", 0.9, reliabilityAB},
+		{"eAC", "A", "C", 0.8, high},
+		{"eBD", "B", "D", 0.7, high},
+		{"eCD", "C", "D", 0.6, graph.Value{Kind: graph.IntVal, I: 1}},
+	}
+	for _, e := range edges {
+		props := map[string]graph.Value{"reliability": e.reliability}
+		if err := g.AddEdge(graph.EdgeID(e.id), graph.NodeID(e.from), graph.NodeID(e.to), e.prob, props); err != nil {
+			t.Fatalf("failed to add edge %s: %v", e.id, err)
+		}
+	}
+	return g
+}
+
+func TestMaxProbabilityPathQuery_WeightProperty(t *testing.T) {
+	g := buildWeightedDiamondGraph(t, graph.Value{Kind: graph.FloatVal, F: 0.2})
+
+	res, err := MaxProbabilityPathQuery{Start: "A", End: "D", WeightProperty: "reliability"}.Execute(context.Background(), g)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	path := res.(result.PathResult).Path
+	if !slices.Equal(path.NodeIDs, []graph.NodeID{"A", "C", "D"}) {
+		t.Errorf("expected path A->C->D, got %v", path.NodeIDs)
+	}
+	// The result still reports the path's probability, not its weight.
+	if math.Abs(path.Probability-0.48) > 1e-9 {
+		t.Errorf("expected probability 0.48, got %f", path.Probability)
+	}
+
+	res, err = MaxProbabilityPathQuery{Start: "A", End: "D"}.Execute(context.Background(), g)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if p := res.(result.PathResult).Path; !slices.Equal(p.NodeIDs, []graph.NodeID{"A", "B", "D"}) {
+		t.Errorf("expected the default to follow probability through B, got %v", p.NodeIDs)
+	}
+}
+
+func TestMaxProbabilityPathQuery_Bottleneck(t *testing.T) {
+	// A->B carries 5, so A->B->D has bottleneck 0.9, as does A->C->D; the
+	// tie goes to the lexicographically smaller path.
+	g := buildWeightedDiamondGraph(t, graph.Value{Kind: graph.IntVal, I: 5})
+
+	res, err := MaxProbabilityPathQuery{Start: "A", End: "D", WeightProperty: "reliability", Bottleneck: true}.Execute(context.Background(), g)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if p := res.(result.PathResult).Path; !slices.Equal(p.NodeIDs, []graph.NodeID{"A", "B", "D"}) {
+		t.Errorf("expected path A->B->D, got %v", p.NodeIDs)
+	}
+
+	g = buildWeightedDiamondGraph(t, graph.Value{Kind: graph.FloatVal, F: 0.2})
+	res, err = MaxProbabilityPathQuery{Start: "A", End: "D", WeightProperty: "reliability", Bottleneck: true}.Execute(context.Background(), g)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if p := res.(result.PathResult).Path; !slices.Equal(p.NodeIDs, []graph.NodeID{"A", "C", "D"}) {
+		t.Errorf("expected path A->C->D, got %v", p.NodeIDs)
+	}
+
+	g = buildWeightedDiamondGraph(t, graph.Value{Kind: graph.IntVal, I: -1})
+	_, err = MaxProbabilityPathQuery{Start: "A", End: "D", WeightProperty: "reliability", Bottleneck: true}.Execute(context.Background(), g)
+	var ie inference.InferenceError
+	if !errors.As(err, &ie) || ie.Kind != "InvalidWeight" {
+		t.Errorf("expected InvalidWeight error for a negative weight, got %v", err)
+	}
+}
+
+func TestMaxProbabilityPathQuery_InvalidWeightProperty(t *testing.T) {
+	tests := []struct {
+		name  string
+		value graph.Value
+		key   string
+	}{
+		{"missing", graph.Value{Kind: graph.FloatVal, F: 0.5}, "bandwidth"},
+		{"string", graph.Value{Kind: graph.StringVal, S: "high"}, "reliability"},
+		{"above one", graph.Value{Kind: graph.FloatVal, F: 1.5}, "reliability"},
+		{"negative", graph.Value{Kind: graph.IntVal, I: -1}, "reliability"},
+	}
+	for _, tt := range tests {
+		g := buildWeightedDiamondGraph(t, tt.value)
+		_, err := MaxProbabilityPathQuery{Start: "A", End: "D", WeightProperty: tt.key}.Execute(context.Background(), g)
+		var ie inference.InferenceError
+		if !errors.As(err, &ie) || ie.Kind != "InvalidWeight" {
+			t.Errorf("%s: expected InvalidWeight error, got %v", tt.name, err)
+		}
+	}
+}
+
 func TestTopKProbabilityPathsQuery_DiamondGraph(t *testing.T) {
 	g := buildDiamondGraph(t)
 	q := TopKProbabilityPathsQuery{Start: "A", End: "D", K: 2}
@@ -188,6 +346,55 @@ func TestTopKProbabilityPathsQuery_ComplexGraph(t *testing.T) {
 	}
 }
 
+func TestTopKProbabilityPathsQuery_SimplePathsOnly(t *testing.T) {
+	g := graph.CreateProbAdjListGraph()
+	for _, n := range []graph.NodeID{"A", "B", "C", "D"} {
+		if err := g.AddNode(n, nil); err != nil {
+			t.Fatalf("failed to add node %s: %v", n, err)
+		}
+	}
+	edges := []struct {
+		id       graph.EdgeID
+		from, to graph.NodeID
+		prob     float64
+	}{
+		{"eAB", "A", "B", 0.9},
+		{"eBC", "B", "C", 0.9},
+		{"eCD", "C", "D", 0.9},
+		{"eBD", "B", "D", 0.1},
+		{"eCA", "C", "A", 0.9},
+	}
+	for _, e := range edges {
+		if err := g.AddEdge(e.id, e.from, e.to, e.prob, nil); err != nil {
+			t.Fatalf("failed to add edge %s: %v", e.id, err)
+		}
+	}
+
+	q := TopKProbabilityPathsQuery{Start: "A", End: "D", K: 5}
+	res, err := q.Execute(context.Background(), g)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	pathsRes := res.(result.PathsResult)
+
+	// Only A -> B -> C -> D and A -> B -> D are simple; spurring through the
+	// C -> A back-edge must not produce A -> B -> C -> A -> B -> D.
+	if len(pathsRes.Paths) != 2 {
+		t.Fatalf("expected 2 simple paths, got %d: %+v", len(pathsRes.Paths), pathsRes.Paths)
+	}
+
+	for i, p := range pathsRes.Paths {
+		seen := make(map[graph.NodeID]bool)
+		for _, n := range p.NodeIDs {
+			if seen[n] {
+				t.Errorf("path %d revisits node %s: %v", i, n, p.NodeIDs)
+			}
+			seen[n] = true
+		}
+	}
+}
+
 func TestReachabilityProbabilityQuery_Exact_LinearGraph(t *testing.T) {
 	g := buildLinearGraph(t, 0.9, 0.8)
 	q := ReachabilityProbabilityQuery{Start: "A", End: "C", Mode: Exact}
@@ -234,6 +441,50 @@ func TestReachabilityProbabilityQuery_Exact_DiamondGraph(t *testing.T) {
 	}
 }
 
+func TestReachabilityProbabilityQuery_Exact_CycleMemoization(t *testing.T) {
+	// B and C form a cycle reachable from A along both branches. The value of
+	// a node computed while its cycle partner is on the DFS stack must not be
+	// cached, otherwise the result depends on map iteration order.
+	g := graph.CreateProbAdjListGraph()
+	for _, n := range []graph.NodeID{"A", "B", "C", "D"} {
+		if err := g.AddNode(n, nil); err != nil {
+			t.Fatalf("failed to add node %s: %v", n, err)
+		}
+	}
+	edges := []struct {
+		id       graph.EdgeID
+		from, to graph.NodeID
+	}{
+		{"eAB", "A", "B"},
+		{"eAC", "A", "C"},
+		{"eBC", "B", "C"},
+		{"eCB", "C", "B"},
+		{"eBD", "B", "D"},
+	}
+	for _, e := range edges {
+		if err := g.AddEdge(e.id, e.from, e.to, 0.5, nil); err != nil {
+			t.Fatalf("failed to add edge %s: %v", e.id, err)
+		}
+	}
+
+	// P(B) = 0.5 (via D), P(C) = 0.5 * 0.5 (via B -> D)
+	// P(A) = 1 - (1 - 0.5*0.5) * (1 - 0.5*0.25) = 0.34375
+	expected := 0.34375
+	q := ReachabilityProbabilityQuery{Start: "A", End: "D", Mode: Exact}
+
+	for i := 0; i < 20; i++ {
+		res, err := q.Execute(context.Background(), g)
+		if err != nil {
+			t.Fatalf("Execute failed: %v", err)
+		}
+
+		probRes := res.(result.ProbabilityResult)
+		if math.Abs(probRes.Probability-expected) > 0.0001 {
+			t.Fatalf("run %d: expected probability %f, got %f", i, expected, probRes.Probability)
+		}
+	}
+}
+
 func TestReachabilityProbabilityQuery_Exact_NoPath(t *testing.T) {
 	g := buildDisconnectedGraph(t)
 	q := ReachabilityProbabilityQuery{Start: "A", End: "X", Mode: Exact}
@@ -329,6 +580,60 @@ func TestReachabilityProbabilityQuery_MonteCarlo_DiamondGraph(t *testing.T) {
 	}
 }
 
+func TestReachabilityProbabilityQuery_Both_DiamondGraph(t *testing.T) {
+	g := buildDiamondGraph(t)
+	q := ReachabilityProbabilityQuery{Start: "A", End: "D", Mode: Both}
+
+	res, err := q.Execute(context.Background(), g)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	cmp, ok := res.(result.ComparisonResult)
+	if !ok {
+		t.Fatalf("expected ComparisonResult, got %T", res)
+	}
+
+	expectedProb := 1.0 - (1.0-0.9*0.7)*(1.0-0.8*0.6)
+	if math.Abs(cmp.Exact.Probability-expectedProb) > 0.0001 {
+		t.Errorf("expected exact probability %f, got %f", expectedProb, cmp.Exact.Probability)
+	}
+	if cmp.Sample.NumSamples == 0 {
+		t.Error("expected Monte Carlo sample to be populated")
+	}
+	if want := math.Abs(cmp.Exact.Probability - cmp.Sample.Estimate); math.Abs(cmp.Deviation-want) > 1e-12 {
+		t.Errorf("expected deviation %f, got %f", want, cmp.Deviation)
+	}
+	if cmp.Deviation > 0.1 {
+		t.Errorf("Monte Carlo estimate deviates by %f", cmp.Deviation)
+	}
+	if cmp.ProbabilityValue() != cmp.Exact.Probability {
+		t.Errorf("expected ProbabilityValue to be the exact probability")
+	}
+}
+
+func TestReachabilityProbabilityQuery_Exact_DegenerateInterval(t *testing.T) {
+	g := buildDiamondGraph(t)
+
+	res, err := ReachabilityProbabilityQuery{Start: "A", End: "D", Mode: Exact}.Execute(context.Background(), g)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	pr := res.(result.ProbabilityResult)
+	if low, high := pr.CI95(); low != pr.Probability || high != pr.Probability {
+		t.Errorf("expected interval [%f, %f], got [%f, %f]", pr.Probability, pr.Probability, low, high)
+	}
+
+	inside := result.SampleResult{Estimate: 0.8, CI95Low: pr.Probability - 0.01, CI95High: pr.Probability + 0.01}
+	outside := result.SampleResult{Estimate: 0.5, CI95Low: 0.45, CI95High: 0.55}
+	if !result.Indistinguishable(pr, inside) {
+		t.Error("expected a sample interval containing the exact value to be indistinguishable")
+	}
+	if result.Indistinguishable(pr, outside) {
+		t.Error("expected a sample interval excluding the exact value to be distinguishable")
+	}
+}
+
 func TestReachabilityProbabilityQuery_ContextCancellation(t *testing.T) {
 	g := buildComplexGraph(t)
 	q := ReachabilityProbabilityQuery{Start: "A", End: "F", Mode: Exact}
@@ -341,3 +646,237 @@ func TestReachabilityProbabilityQuery_ContextCancellation(t *testing.T) {
 		t.Error("expected error when context is cancelled")
 	}
 }
+
+func TestReachabilityProbabilityQuery_Timeout(t *testing.T) {
+	g := buildRandomGraph(t, 40, 0.2, 1)
+
+	for _, mode := range []InferenceMode{Exact, Both} {
+		q := ReachabilityProbabilityQuery{Start: "n0", End: "n39", Mode: mode, Timeout: time.Millisecond}
+
+		start := time.Now()
+		_, err := q.Execute(context.Background(), g)
+		elapsed := time.Since(start)
+
+		var qe QueryError
+		if !errors.As(err, &qe) || qe.Kind != "Timeout" {
+			t.Fatalf("mode %d: expected Timeout error, got %v", mode, err)
+		}
+		// Both also waits for its Monte Carlo half, which the timeout
+		// does not bound.
+		if mode == Exact && elapsed > time.Second {
+			t.Errorf("query took %v to time out", elapsed)
+		}
+	}
+}
+
+func TestReachabilityProbabilityQuery_TimeoutNotReached(t *testing.T) {
+	q := ReachabilityProbabilityQuery{Start: "A", End: "D", Mode: Exact, Timeout: time.Minute}
+	res, err := q.Execute(context.Background(), buildDiamondGraph(t))
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	want := 1 - (1-0.63)*(1-0.48)
+	if p := res.(result.ProbabilityResult).Probability; math.Abs(p-want) > 0.0001 {
+		t.Errorf("expected probability %f, got %f", want, p)
+	}
+}
+
+func TestReachabilityProbabilityQuery_CallerCancellationIsNotTimeout(t *testing.T) {
+	g := buildRandomGraph(t, 40, 0.2, 1)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+
+	q := ReachabilityProbabilityQuery{Start: "n0", End: "n39", Mode: Exact, Timeout: time.Minute}
+	_, err := q.Execute(ctx, g)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the caller's context error, got %v", err)
+	}
+}
+
+func TestMultiSourceSinkReachabilityQuery_Exact(t *testing.T) {
+	g := graph.CreateReadOnlyGraph(buildDiamondGraph(t))
+
+	tests := []struct {
+		name           string
+		sources, sinks []graph.NodeID
+		want           float64
+	}{
+		// B->D and C->D are independent: 1 - 0.3*0.4.
+		{"two sources", []graph.NodeID{"B", "C"}, []graph.NodeID{"D"}, 0.88},
+		// A->B and A->C are independent: 1 - 0.1*0.2.
+		{"two sinks", []graph.NodeID{"A"}, []graph.NodeID{"B", "C"}, 0.98},
+		{"overlapping sets", []graph.NodeID{"A", "B"}, []graph.NodeID{"B"}, 1},
+		{"duplicates", []graph.NodeID{"B", "B", "C"}, []graph.NodeID{"D", "D"}, 0.88},
+		{"single pair", []graph.NodeID{"A"}, []graph.NodeID{"D"}, 1 - (1-0.63)*(1-0.48)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := MultiSourceSinkReachabilityQuery{Sources: tt.sources, Sinks: tt.sinks, Mode: Exact}
+			res, err := q.Execute(context.Background(), g)
+			if err != nil {
+				t.Fatalf("Execute failed: %v", err)
+			}
+			probRes, ok := res.(result.ProbabilityResult)
+			if !ok {
+				t.Fatalf("expected ProbabilityResult, got %T", res)
+			}
+			if math.Abs(probRes.Probability-tt.want) > 0.0001 {
+				t.Errorf("expected probability %f, got %f", tt.want, probRes.Probability)
+			}
+		})
+	}
+
+	if n := len(g.GetNodes()); n != 4 {
+		t.Errorf("expected the graph to keep 4 nodes, got %d", n)
+	}
+}
+
+func TestMultiSourceSinkReachabilityQuery_VirtualIDsAvoidCollisions(t *testing.T) {
+	g := buildDiamondGraph(t)
+	if err := g.AddNode("__source", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.AddNode("__sink", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.AddEdge("__virtual_0", "__source", "__sink", 1.0, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	q := MultiSourceSinkReachabilityQuery{Sources: []graph.NodeID{"B", "C"}, Sinks: []graph.NodeID{"D"}, Mode: Exact}
+	res, err := q.Execute(context.Background(), g)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if p := res.(result.ProbabilityResult).Probability; math.Abs(p-0.88) > 0.0001 {
+		t.Errorf("expected probability 0.88, got %f", p)
+	}
+}
+
+func TestMultiSourceSinkReachabilityQuery_MonteCarlo(t *testing.T) {
+	g := buildDiamondGraph(t)
+	q := MultiSourceSinkReachabilityQuery{Sources: []graph.NodeID{"B", "C"}, Sinks: []graph.NodeID{"D"}, Mode: MonteCarlo, Seed: 1}
+
+	res, err := q.Execute(context.Background(), g)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	sample, ok := res.(result.SampleResult)
+	if !ok {
+		t.Fatalf("expected SampleResult, got %T", res)
+	}
+	if math.Abs(sample.Estimate-0.88) > 0.02 {
+		t.Errorf("expected estimate near 0.88, got %f", sample.Estimate)
+	}
+}
+
+func TestMultiSourceSinkReachabilityQuery_Errors(t *testing.T) {
+	g := buildDiamondGraph(t)
+
+	_, err := MultiSourceSinkReachabilityQuery{Sources: []graph.NodeID{"A", "Z"}, Sinks: []graph.NodeID{"D"}}.Execute(context.Background(), g)
+	var ge graph.GraphError
+	if !errors.As(err, &ge) {
+		t.Errorf("expected GraphError for unknown node, got %v", err)
+	}
+
+	_, err = MultiSourceSinkReachabilityQuery{Sinks: []graph.NodeID{"D"}}.Execute(context.Background(), g)
+	var qe QueryError
+	if !errors.As(err, &qe) || qe.Kind != "InvalidParameter" {
+		t.Errorf("expected InvalidParameter for empty sources, got %v", err)
+	}
+}
+
+func TestSensitivityMapQuery_MatchesSensitivityAnalysis(t *testing.T) {
+	g := buildDiamondGraph(t)
+
+	res, err := SensitivityMapQuery{Start: "A", End: "D"}.Execute(context.Background(), g)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	scores, ok := res.(result.EdgeScoresResult)
+	if !ok {
+		t.Fatalf("expected EdgeScoresResult, got %T", res)
+	}
+
+	want, err := inference.SensitivityAnalysis(g, "A", "D")
+	if err != nil {
+		t.Fatalf("SensitivityAnalysis failed: %v", err)
+	}
+	if len(scores.Scores) != len(want.Impacts) {
+		t.Fatalf("expected %d scores, got %d", len(want.Impacts), len(scores.Scores))
+	}
+	for _, imp := range want.Impacts {
+		if math.Abs(scores.Scores[imp.EdgeID]-imp.Delta) > 1e-9 {
+			t.Errorf("edge %s: expected drop %f, got %f", imp.EdgeID, imp.Delta, scores.Scores[imp.EdgeID])
+		}
+	}
+
+	ranked := scores.Ranked()
+	for i := 1; i < len(ranked); i++ {
+		if scores.Scores[ranked[i]] > scores.Scores[ranked[i-1]] {
+			t.Errorf("ranking not descending at position %d", i)
+		}
+	}
+}
+
+func TestSensitivityMapQuery_BidirectionalEdgeScoredOnce(t *testing.T) {
+	g := graph.CreateProbAdjListGraph()
+	for _, id := range []graph.NodeID{"A", "B", "C"} {
+		g.AddNode(id, nil)
+	}
+	g.AddEdge("ab", "A", "B", 0.9, nil)
+	if err := g.AddBidirectionalEdge("bc", "B", "C", 0.8, nil); err != nil {
+		t.Fatalf("AddBidirectionalEdge failed: %v", err)
+	}
+
+	res, err := SensitivityMapQuery{Start: "A", End: "C"}.Execute(context.Background(), g)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	scores := res.(result.EdgeScoresResult).Scores
+	if len(scores) != 2 {
+		t.Fatalf("expected scores for ab and bc only, got %v", scores)
+	}
+	if math.Abs(scores["bc"]-0.72) > 1e-9 {
+		t.Errorf("expected bc drop 0.72, got %f", scores["bc"])
+	}
+}
+
+func TestSensitivityMapQuery_MissingNode(t *testing.T) {
+	g := buildDiamondGraph(t)
+
+	if _, err := (SensitivityMapQuery{Start: "Z", End: "D"}).Execute(context.Background(), g); err == nil {
+		t.Error("expected error for missing start node")
+	}
+}
+
+func TestReachabilityProbabilityQuery_IntervalEdges(t *testing.T) {
+	g := buildLinearGraph(t, 0.9, 0.8)
+	if err := g.UpdateEdgeInterval("eAB", 0.7, 0.9); err != nil {
+		t.Fatalf("UpdateEdgeInterval failed: %v", err)
+	}
+
+	res, err := ReachabilityProbabilityQuery{Start: "A", End: "C", Mode: Exact}.Execute(context.Background(), g)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	interval, ok := res.(result.ProbabilityIntervalResult)
+	if !ok {
+		t.Fatalf("expected ProbabilityIntervalResult, got %T", res)
+	}
+	if math.Abs(interval.Low-0.7*0.8) > 1e-9 || math.Abs(interval.High-0.9*0.8) > 1e-9 {
+		t.Errorf("expected [%f, %f], got [%f, %f]", 0.7*0.8, 0.9*0.8, interval.Low, interval.High)
+	}
+	if math.Abs(interval.ProbabilityValue()-0.8*0.8) > 1e-9 {
+		t.Errorf("expected midpoint %f, got %f", 0.8*0.8, interval.ProbabilityValue())
+	}
+
+	// Point-probability graphs still give a plain ProbabilityResult.
+	res, err = ReachabilityProbabilityQuery{Start: "A", End: "C", Mode: Exact}.Execute(context.Background(), buildLinearGraph(t, 0.9, 0.8))
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if _, ok := res.(result.ProbabilityResult); !ok {
+		t.Errorf("expected ProbabilityResult, got %T", res)
+	}
+}
@@ -0,0 +1,104 @@
+package query
+
+import (
+	"context"
+
+	"github.com/ritamzico/pgraph/internal/graph"
+	"github.com/ritamzico/pgraph/internal/inference"
+	"github.com/ritamzico/pgraph/internal/result"
+)
+
+// EdgeHistogramQuery buckets edge probabilities into graph.HistogramBuckets
+// equal-width buckets over [0, 1].
+type EdgeHistogramQuery struct{}
+
+func (q EdgeHistogramQuery) Execute(ctx context.Context, g graph.ProbabilisticGraphModel) (result.Result, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	stats := graph.ComputeStats(g)
+	buckets := make([]result.BucketResult, graph.HistogramBuckets)
+	for i, count := range stats.ProbabilityHistogram {
+		buckets[i] = result.BucketResult{
+			Low:   float64(i) / graph.HistogramBuckets,
+			High:  float64(i+1) / graph.HistogramBuckets,
+			Count: count,
+		}
+	}
+	return result.HistogramResult{Buckets: buckets}, nil
+}
+
+// BetweennessCentralityQuery scores every node by normalized betweenness
+// centrality; see inference.BetweennessCentrality.
+type BetweennessCentralityQuery struct{}
+
+func (q BetweennessCentralityQuery) Execute(ctx context.Context, g graph.ProbabilisticGraphModel) (result.Result, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	return result.NodeScoresResult{Scores: inference.BetweennessCentrality(g)}, nil
+}
+
+// PageRankQuery scores every node by probability-weighted PageRank; see
+// inference.ProbabilisticPageRank.
+type PageRankQuery struct {
+	Damping    float64
+	Iterations int
+}
+
+func (q PageRankQuery) Execute(ctx context.Context, g graph.ProbabilisticGraphModel) (result.Result, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	scores, err := inference.ProbabilisticPageRank(g, q.Damping, q.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	return result.NodeScoresResult{Scores: scores}, nil
+}
+
+// RowSumQuery sums the probabilities of Node's outgoing edges; see
+// inference.RowSum.
+type RowSumQuery struct {
+	Node graph.NodeID
+}
+
+func (q RowSumQuery) Execute(ctx context.Context, g graph.ProbabilisticGraphModel) (result.Result, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	sum, err := inference.RowSum(g, q.Node)
+	if err != nil {
+		return nil, err
+	}
+	return result.NewProbabilityResult(sum), nil
+}
+
+// ValidateRowSumsQuery lists the nodes whose outgoing edge probabilities
+// do not sum to 1 within Epsilon, sorted by ID; see
+// inference.InvalidRowSums.
+type ValidateRowSumsQuery struct {
+	Epsilon float64
+}
+
+func (q ValidateRowSumsQuery) Execute(ctx context.Context, g graph.ProbabilisticGraphModel) (result.Result, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	return nodeListResult(g, inference.InvalidRowSums(g, q.Epsilon)), nil
+}
@@ -0,0 +1,40 @@
+package query
+
+import (
+	"context"
+
+	"github.com/ritamzico/pgraph/internal/graph"
+	"github.com/ritamzico/pgraph/internal/inference"
+	"github.com/ritamzico/pgraph/internal/result"
+)
+
+// SubgraphQuery extracts the subgraph induced by a set of nodes. If
+// AncestorsOf is set, the set is every node that can reach it (including
+// itself); otherwise Nodes is used as given.
+type SubgraphQuery struct {
+	Nodes       []graph.NodeID
+	AncestorsOf graph.NodeID
+}
+
+func (q SubgraphQuery) Execute(ctx context.Context, g graph.ProbabilisticGraphModel) (result.Result, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	nodes := q.Nodes
+	if q.AncestorsOf != "" {
+		var err error
+		nodes, err = inference.Ancestors(g, q.AncestorsOf)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sub, err := graph.InducedSubgraph(g, nodes)
+	if err != nil {
+		return nil, err
+	}
+	return result.GraphResult{Graph: sub}, nil
+}
@@ -0,0 +1,81 @@
+package query
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/ritamzico/pgraph/internal/graph"
+	"github.com/ritamzico/pgraph/internal/result"
+)
+
+func TestSubgraphQuery_Nodes(t *testing.T) {
+	g := buildDiamondGraph(t)
+
+	res, err := SubgraphQuery{Nodes: []graph.NodeID{"A", "B", "D"}}.Execute(context.Background(), g)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	gr, ok := res.(result.GraphResult)
+	if !ok {
+		t.Fatalf("expected GraphResult, got %T", res)
+	}
+
+	if got := sortedNodeIDs(gr.Graph.GetNodes()); !slices.Equal(got, []graph.NodeID{"A", "B", "D"}) {
+		t.Errorf("unexpected nodes %v", got)
+	}
+	if len(gr.Graph.GetEdges()) != 2 {
+		t.Errorf("expected edges eAB and eBD only, got %d edges", len(gr.Graph.GetEdges()))
+	}
+	if !gr.Graph.ContainsEdgeByID("eAB") || !gr.Graph.ContainsEdgeByID("eBD") {
+		t.Error("expected edges eAB and eBD")
+	}
+
+	// The source graph is untouched.
+	if len(g.GetEdges()) != 4 {
+		t.Errorf("source graph modified: %d edges", len(g.GetEdges()))
+	}
+}
+
+func TestSubgraphQuery_AncestorsOf(t *testing.T) {
+	g := buildDiamondGraph(t)
+	if err := g.AddNode("E", nil); err != nil {
+		t.Fatalf("AddNode failed: %v", err)
+	}
+	if err := g.AddEdge("eDE", "D", "E", 0.5, nil); err != nil {
+		t.Fatalf("AddEdge failed: %v", err)
+	}
+
+	res, err := SubgraphQuery{AncestorsOf: "C"}.Execute(context.Background(), g)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	gr := res.(result.GraphResult)
+
+	if got := sortedNodeIDs(gr.Graph.GetNodes()); !slices.Equal(got, []graph.NodeID{"A", "C"}) {
+		t.Errorf("unexpected ancestors %v", got)
+	}
+
+	res, err = SubgraphQuery{AncestorsOf: "D"}.Execute(context.Background(), g)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	gr = res.(result.GraphResult)
+	if got := sortedNodeIDs(gr.Graph.GetNodes()); !slices.Equal(got, []graph.NodeID{"A", "B", "C", "D"}) {
+		t.Errorf("unexpected ancestors %v", got)
+	}
+	if len(gr.Graph.GetEdges()) != 4 {
+		t.Errorf("expected 4 edges, got %d", len(gr.Graph.GetEdges()))
+	}
+}
+
+func TestSubgraphQuery_UnknownNode(t *testing.T) {
+	g := buildDiamondGraph(t)
+
+	if _, err := (SubgraphQuery{Nodes: []graph.NodeID{"A", "Z"}}).Execute(context.Background(), g); err == nil {
+		t.Error("expected error for unknown node")
+	}
+	if _, err := (SubgraphQuery{AncestorsOf: "Z"}).Execute(context.Background(), g); err == nil {
+		t.Error("expected error for unknown ancestor target")
+	}
+}
@@ -0,0 +1,27 @@
+package result
+
+import "fmt"
+
+// ComparisonResult pairs an exact reachability probability with a Monte
+// Carlo estimate of the same quantity, for validating sampling settings.
+type ComparisonResult struct {
+	Exact     ProbabilityResult
+	Sample    SampleResult
+	Deviation float64 // |Exact.Probability - Sample.Estimate|
+}
+
+func (r ComparisonResult) Kind() Kind { return ComparisonResultKind }
+
+// ProbabilityValue returns the exact probability.
+func (r ComparisonResult) ProbabilityValue() float64 {
+	return r.Exact.Probability
+}
+
+func (r ComparisonResult) String() string {
+	within := "outside"
+	if r.Exact.Probability >= r.Sample.CI95Low && r.Exact.Probability <= r.Sample.CI95High {
+		within = "within"
+	}
+	return fmt.Sprintf("Exact: %.6f\n%s\nDeviation: %.6f (exact value is %s the 95%% CI)",
+		r.Exact.Probability, r.Sample, r.Deviation, within)
+}
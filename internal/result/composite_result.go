@@ -31,6 +31,15 @@ type BooleanResult struct {
 
 func (r BooleanResult) Kind() Kind { return BooleanResultKind }
 
+// ProbabilityValue maps true to 1.0 and false to 0.0 so boolean results can
+// be combined by probabilistic reducers and composites.
+func (r BooleanResult) ProbabilityValue() float64 {
+	if r.Value {
+		return 1.0
+	}
+	return 0.0
+}
+
 func (r BooleanResult) String() string {
 	if r.Value {
 		return "Result: true"
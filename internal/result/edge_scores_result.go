@@ -0,0 +1,43 @@
+package result
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/ritamzico/pgraph/internal/graph"
+)
+
+// EdgeScoresResult holds a per-edge score, such as the reachability drop
+// when the edge fails.
+type EdgeScoresResult struct {
+	Scores map[graph.EdgeID]float64
+}
+
+func (r EdgeScoresResult) Kind() Kind { return EdgeScoresResultKind }
+
+// Ranked returns the edge IDs ordered by descending score, ties broken by ID.
+func (r EdgeScoresResult) Ranked() []graph.EdgeID {
+	ids := slices.Collect(maps.Keys(r.Scores))
+	slices.SortFunc(ids, func(a, b graph.EdgeID) int {
+		if c := cmp.Compare(r.Scores[b], r.Scores[a]); c != 0 {
+			return c
+		}
+		return cmp.Compare(a, b)
+	})
+	return ids
+}
+
+func (r EdgeScoresResult) String() string {
+	if len(r.Scores) == 0 {
+		return "No edges to score."
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Edge scores (%d):", len(r.Scores))
+	for i, id := range r.Ranked() {
+		fmt.Fprintf(&b, "\n  %d. %-20s %.6f", i+1, string(id), r.Scores[id])
+	}
+	return b.String()
+}
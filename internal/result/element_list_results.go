@@ -0,0 +1,61 @@
+package result
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/ritamzico/pgraph/internal/graph"
+)
+
+type NodeListResult struct {
+	Nodes []*graph.Node
+}
+
+func (r NodeListResult) Kind() Kind { return NodeListResultKind }
+
+func (r NodeListResult) String() string {
+	if len(r.Nodes) == 0 {
+		return "No nodes found."
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Nodes (%d):", len(r.Nodes))
+	for i, n := range r.Nodes {
+		fmt.Fprintf(&b, "\n  %d. %s%s", i+1, string(n.ID), formatProps(n.Props))
+	}
+	return b.String()
+}
+
+type EdgeListResult struct {
+	Edges []*graph.Edge
+}
+
+func (r EdgeListResult) Kind() Kind { return EdgeListResultKind }
+
+func (r EdgeListResult) String() string {
+	if len(r.Edges) == 0 {
+		return "No edges found."
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Edges (%d):", len(r.Edges))
+	for i, e := range r.Edges {
+		fmt.Fprintf(&b, "\n  %d. %s: %s -> %s [p=%.3f]%s",
+			i+1, string(e.ID), string(e.From), string(e.To), e.Probability, formatProps(e.Props))
+	}
+	return b.String()
+}
+
+// formatProps renders a property map as " { key: value, ... }" with keys in
+// sorted order, or an empty string when there are no properties.
+func formatProps(props map[string]graph.Value) string {
+	if len(props) == 0 {
+		return ""
+	}
+	keys := slices.Sorted(maps.Keys(props))
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = fmt.Sprintf("%s: %s", k, props[k])
+	}
+	return " { " + strings.Join(parts, ", ") + " }"
+}
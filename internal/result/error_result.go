@@ -0,0 +1,15 @@
+package result
+
+import "fmt"
+
+// ErrorResult stands in for a subquery that failed, so a composite result
+// can report the failure in place without discarding its siblings.
+type ErrorResult struct {
+	Err error
+}
+
+func (r ErrorResult) Kind() Kind { return ErrorResultKind }
+
+func (r ErrorResult) String() string {
+	return fmt.Sprintf("Error: %v", r.Err)
+}
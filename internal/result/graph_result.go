@@ -0,0 +1,36 @@
+package result
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/ritamzico/pgraph/internal/graph"
+)
+
+// GraphResult holds a graph produced by a query, such as an extracted
+// subgraph. The graph is independent of the one the query ran against.
+type GraphResult struct {
+	Graph graph.ProbabilisticGraphModel
+}
+
+func (r GraphResult) Kind() Kind { return GraphResultKind }
+
+func (r GraphResult) String() string {
+	nodes := r.Graph.GetNodes()
+	edges := r.Graph.GetEdges()
+	slices.SortFunc(nodes, func(a, b *graph.Node) int { return cmp.Compare(a.ID, b.ID) })
+	slices.SortFunc(edges, func(a, b *graph.Edge) int { return cmp.Compare(a.ID, b.ID) })
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Graph (%d nodes, %d edges):", len(nodes), len(edges))
+	for _, n := range nodes {
+		fmt.Fprintf(&b, "\n  %s%s", string(n.ID), formatProps(n.Props))
+	}
+	for _, e := range edges {
+		fmt.Fprintf(&b, "\n  %s: %s -> %s [p=%.3f]%s",
+			string(e.ID), string(e.From), string(e.To), e.Probability, formatProps(e.Props))
+	}
+	return b.String()
+}
@@ -0,0 +1,41 @@
+package result
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BucketResult is one histogram bucket covering [Low, High).
+type BucketResult struct {
+	Low   float64
+	High  float64
+	Count int
+}
+
+type HistogramResult struct {
+	Buckets []BucketResult
+}
+
+func (r HistogramResult) Kind() Kind { return HistogramResultKind }
+
+func (r HistogramResult) String() string {
+	total := 0
+	for _, bk := range r.Buckets {
+		total += bk.Count
+	}
+	if total == 0 {
+		return "No edges to histogram."
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Edge probability histogram (%d edges):", total)
+	for i, bk := range r.Buckets {
+		closing := ")"
+		if i == len(r.Buckets)-1 {
+			closing = "]"
+		}
+		fmt.Fprintf(&b, "\n  [%.1f, %.1f%s %5d  %s",
+			bk.Low, bk.High, closing, bk.Count, strings.Repeat("#", bk.Count*40/total))
+	}
+	return b.String()
+}
@@ -0,0 +1,40 @@
+package result
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ritamzico/pgraph/internal/graph"
+)
+
+// MatrixResult holds a value for every pair of a row node and a column
+// node, such as the reachability probability from each source to each
+// target. Values[i][j] belongs to Rows[i] and Cols[j].
+type MatrixResult struct {
+	Rows   []graph.NodeID
+	Cols   []graph.NodeID
+	Values [][]float64
+}
+
+func (r MatrixResult) Kind() Kind { return MatrixResultKind }
+
+func (r MatrixResult) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Matrix (%d x %d):\n", len(r.Rows), len(r.Cols))
+	tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprint(tw, "\t")
+	for _, col := range r.Cols {
+		fmt.Fprintf(tw, "%s\t", col)
+	}
+	fmt.Fprintln(tw)
+	for i, row := range r.Rows {
+		fmt.Fprintf(tw, "%s\t", row)
+		for _, v := range r.Values[i] {
+			fmt.Fprintf(tw, "%.6f\t", v)
+		}
+		fmt.Fprintln(tw)
+	}
+	tw.Flush()
+	return strings.TrimRight(b.String(), "\n")
+}
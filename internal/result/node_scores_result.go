@@ -0,0 +1,42 @@
+package result
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/ritamzico/pgraph/internal/graph"
+)
+
+// NodeScoresResult holds a per-node score, such as a centrality measure.
+type NodeScoresResult struct {
+	Scores map[graph.NodeID]float64
+}
+
+func (r NodeScoresResult) Kind() Kind { return NodeScoresResultKind }
+
+// Ranked returns the node IDs ordered by descending score, ties broken by ID.
+func (r NodeScoresResult) Ranked() []graph.NodeID {
+	ids := slices.Collect(maps.Keys(r.Scores))
+	slices.SortFunc(ids, func(a, b graph.NodeID) int {
+		if c := cmp.Compare(r.Scores[b], r.Scores[a]); c != 0 {
+			return c
+		}
+		return cmp.Compare(a, b)
+	})
+	return ids
+}
+
+func (r NodeScoresResult) String() string {
+	if len(r.Scores) == 0 {
+		return "No nodes to score."
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Node scores (%d):", len(r.Scores))
+	for i, id := range r.Ranked() {
+		fmt.Fprintf(&b, "\n  %d. %-20s %.6f", i+1, string(id), r.Scores[id])
+	}
+	return b.String()
+}
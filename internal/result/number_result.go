@@ -0,0 +1,14 @@
+package result
+
+import "fmt"
+
+// NumberResult holds a count, such as the number of paths between two nodes.
+type NumberResult struct {
+	Value int
+}
+
+func (r NumberResult) Kind() Kind { return NumberResultKind }
+
+func (r NumberResult) String() string {
+	return fmt.Sprintf("Result: %d", r.Value)
+}
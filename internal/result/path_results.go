@@ -25,6 +25,10 @@ func (r PathResult) String() string {
 
 type PathsResult struct {
 	Paths []graph.Path
+
+	// Truncated is set when the search stopped at a deadline before
+	// finding every path asked for; Paths holds those found by then.
+	Truncated bool
 }
 
 func (r PathsResult) Kind() Kind {
@@ -33,6 +37,9 @@ func (r PathsResult) Kind() Kind {
 
 func (r PathsResult) String() string {
 	if len(r.Paths) == 0 {
+		if r.Truncated {
+			return "No paths found before the timeout."
+		}
 		return "No paths found."
 	}
 	var b strings.Builder
@@ -40,6 +47,9 @@ func (r PathsResult) String() string {
 	for i, p := range r.Paths {
 		fmt.Fprintf(&b, "\n  %d. %s (%.6f)", i+1, formatPath(p.NodeIDs), p.Probability)
 	}
+	if r.Truncated {
+		b.WriteString("\n(truncated: timeout reached)")
+	}
 	return b.String()
 }
 
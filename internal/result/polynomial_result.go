@@ -0,0 +1,28 @@
+package result
+
+import (
+	"fmt"
+	"strings"
+)
+
+type PolynomialPoint struct {
+	P           float64
+	Reliability float64
+}
+
+// PolynomialResult holds a reliability polynomial R(p) sampled at
+// increasing values of p.
+type PolynomialResult struct {
+	Points []PolynomialPoint
+}
+
+func (r PolynomialResult) Kind() Kind { return PolynomialResultKind }
+
+func (r PolynomialResult) String() string {
+	var b strings.Builder
+	b.WriteString("Reliability polynomial:")
+	for _, pt := range r.Points {
+		fmt.Fprintf(&b, "\n  R(%.2f) = %.6f", pt.P, pt.Reliability)
+	}
+	return b.String()
+}
@@ -0,0 +1,29 @@
+package result
+
+import "fmt"
+
+// ProbabilityIntervalResult bounds a probability computed from edges whose
+// probabilities are known only to within intervals. Low and High are the
+// values with every edge at the bottom and the top of its interval. It is
+// an IntervalResult whose interval is [Low, High], and its point value is
+// the midpoint.
+type ProbabilityIntervalResult struct {
+	Low  float64
+	High float64
+}
+
+func (r ProbabilityIntervalResult) Kind() Kind {
+	return ProbabilityIntervalResultKind
+}
+
+func (r ProbabilityIntervalResult) ProbabilityValue() float64 {
+	return (r.Low + r.High) / 2
+}
+
+func (r ProbabilityIntervalResult) CI95() (low, high float64) {
+	return r.Low, r.High
+}
+
+func (r ProbabilityIntervalResult) String() string {
+	return fmt.Sprintf("Probability: [%.6f, %.6f]", r.Low, r.High)
+}
@@ -2,8 +2,19 @@ package result
 
 import "fmt"
 
+// ProbabilityResult is a probability computed without sampling error. Its
+// 95% confidence interval is the degenerate [Probability, Probability], so
+// it can be compared with a SampleResult through IntervalResult.
 type ProbabilityResult struct {
 	Probability float64
+	CI95Low     float64
+	CI95High    float64
+}
+
+// NewProbabilityResult returns a ProbabilityResult for p with the
+// degenerate interval [p, p].
+func NewProbabilityResult(p float64) ProbabilityResult {
+	return ProbabilityResult{Probability: p, CI95Low: p, CI95High: p}
 }
 
 func (r ProbabilityResult) Kind() Kind {
@@ -14,6 +25,10 @@ func (r ProbabilityResult) ProbabilityValue() float64 {
 	return r.Probability
 }
 
+func (r ProbabilityResult) CI95() (low, high float64) {
+	return r.CI95Low, r.CI95High
+}
+
 func (r ProbabilityResult) String() string {
 	return fmt.Sprintf("Probability: %.6f", r.Probability)
 }
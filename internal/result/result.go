@@ -15,9 +15,41 @@ const (
 	MultiResultKind
 	BooleanResultKind
 	SensitivityResultKind
+	NodeListResultKind
+	EdgeListResultKind
+	GraphResultKind
+	HistogramResultKind
+	NodeScoresResultKind
+	PolynomialResultKind
+	ComparisonResultKind
+	SampledWorldResultKind
+	NumberResultKind
+	ErrorResultKind
+	ValuesResultKind
+	EdgeScoresResultKind
+	StringResultKind
+	ProbabilityIntervalResultKind
+	MatrixResultKind
 )
 
 type ProbabilisticResult interface {
 	Result
 	ProbabilityValue() float64
 }
+
+// IntervalResult is a probabilistic result with a 95% confidence interval.
+// ProbabilityResult's interval is degenerate; SampleResult's reflects its
+// sampling error.
+type IntervalResult interface {
+	ProbabilisticResult
+	CI95() (low, high float64)
+}
+
+// Indistinguishable reports whether the 95% confidence intervals of a and b
+// overlap, i.e. whether the two results are not statistically different at
+// that level.
+func Indistinguishable(a, b IntervalResult) bool {
+	aLow, aHigh := a.CI95()
+	bLow, bHigh := b.CI95()
+	return aLow <= bHigh && bLow <= aHigh
+}
@@ -19,6 +19,10 @@ func (r SampleResult) ProbabilityValue() float64 {
 	return r.Estimate
 }
 
+func (r SampleResult) CI95() (low, high float64) {
+	return r.CI95Low, r.CI95High
+}
+
 func (r SampleResult) String() string {
 	return fmt.Sprintf("Estimate: %.6f (95%% CI: [%.6f, %.6f])\nSamples: %d, Std Error: %.6f",
 		r.Estimate, r.CI95Low, r.CI95High, r.NumSamples, r.StdErr)
@@ -0,0 +1,41 @@
+package result
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ritamzico/pgraph/internal/graph"
+)
+
+// SampledWorldResult is one sampled possible world: the edges that came up
+// active and those that did not, each sorted by ID.
+type SampledWorldResult struct {
+	Seed          uint64
+	ActiveEdges   []graph.EdgeID
+	InactiveEdges []graph.EdgeID
+}
+
+func (r SampledWorldResult) Kind() Kind { return SampledWorldResultKind }
+
+func (r SampledWorldResult) String() string {
+	total := len(r.ActiveEdges) + len(r.InactiveEdges)
+	if total == 0 {
+		return "No edges to sample."
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Sampled world (seed %d): %d of %d edges active", r.Seed, len(r.ActiveEdges), total)
+	fmt.Fprintf(&b, "\n  active:   %s", joinEdgeIDs(r.ActiveEdges))
+	fmt.Fprintf(&b, "\n  inactive: %s", joinEdgeIDs(r.InactiveEdges))
+	return b.String()
+}
+
+func joinEdgeIDs(ids []graph.EdgeID) string {
+	if len(ids) == 0 {
+		return "(none)"
+	}
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = string(id)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,12 @@
+package result
+
+// StringResult holds text produced by a query, such as an exported graph.
+type StringResult struct {
+	Value string
+}
+
+func (r StringResult) Kind() Kind { return StringResultKind }
+
+func (r StringResult) String() string {
+	return r.Value
+}
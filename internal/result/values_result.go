@@ -0,0 +1,26 @@
+package result
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ValuesResult holds a list of numbers, such as the distinct path
+// probabilities between two nodes, in the order the query defines.
+type ValuesResult struct {
+	Values []float64
+}
+
+func (r ValuesResult) Kind() Kind { return ValuesResultKind }
+
+func (r ValuesResult) String() string {
+	if len(r.Values) == 0 {
+		return "No values."
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Values (%d):", len(r.Values))
+	for i, v := range r.Values {
+		fmt.Fprintf(&b, "\n  %d. %.6f", i+1, v)
+	}
+	return b.String()
+}
@@ -0,0 +1,77 @@
+package schema
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/ritamzico/pgraph/internal/graph"
+)
+
+// Schema declares the expected property names and value kinds for nodes and
+// edges. A nil map leaves that element type unconstrained; a non-nil map
+// rejects any property key it does not list. Null values satisfy any
+// declared kind, since they mark a property as present but unset.
+type Schema struct {
+	NodeProps map[string]graph.ValueKind
+	EdgeProps map[string]graph.ValueKind
+}
+
+type SchemaError struct {
+	Kind    string
+	Message string
+}
+
+func (e SchemaError) Error() string {
+	return fmt.Sprintf("schema error (%v): %v", e.Kind, e.Message)
+}
+
+// Validate checks every node and edge property in g against s and returns
+// all violations, ordered by element ID and then property key.
+func Validate(g graph.ProbabilisticGraphModel, s Schema) []SchemaError {
+	var errs []SchemaError
+
+	if s.NodeProps != nil {
+		nodes := g.GetNodes()
+		slices.SortFunc(nodes, func(a, b *graph.Node) int { return cmp.Compare(a.ID, b.ID) })
+		for _, n := range nodes {
+			errs = append(errs, validateProps("node", string(n.ID), n.Props, s.NodeProps)...)
+		}
+	}
+
+	if s.EdgeProps != nil {
+		edges := g.GetEdges()
+		slices.SortFunc(edges, func(a, b *graph.Edge) int { return cmp.Compare(a.ID, b.ID) })
+		for _, e := range edges {
+			errs = append(errs, validateProps("edge", string(e.ID), e.Props, s.EdgeProps)...)
+		}
+	}
+
+	return errs
+}
+
+func validateProps(element, id string, props map[string]graph.Value, expected map[string]graph.ValueKind) []SchemaError {
+	var errs []SchemaError
+
+	for _, key := range slices.Sorted(maps.Keys(props)) {
+		want, ok := expected[key]
+		if !ok {
+			errs = append(errs, SchemaError{
+				Kind:    "UnknownProperty",
+				Message: fmt.Sprintf("%s %v has undeclared property %q", element, id, key),
+			})
+			continue
+		}
+
+		got := props[key].Kind
+		if got != want && got != graph.NullVal {
+			errs = append(errs, SchemaError{
+				Kind:    "TypeMismatch",
+				Message: fmt.Sprintf("%s %v property %q is %s, expected %s", element, id, key, got, want),
+			})
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,112 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/ritamzico/pgraph/internal/graph"
+)
+
+func buildSchemaTestGraph(t *testing.T) graph.ProbabilisticGraphModel {
+	t.Helper()
+	g := graph.CreateProbAdjListGraph()
+
+	if err := g.AddNode("A", map[string]graph.Value{
+		"region": {Kind: graph.StringVal, S: "US"},
+		"risk":   {Kind: graph.FloatVal, F: 0.4},
+	}); err != nil {
+		t.Fatalf("failed to add node A: %v", err)
+	}
+	if err := g.AddNode("B", map[string]graph.Value{
+		"region": {Kind: graph.NullVal},
+	}); err != nil {
+		t.Fatalf("failed to add node B: %v", err)
+	}
+	if err := g.AddEdge("eAB", "A", "B", 0.9, map[string]graph.Value{
+		"distance": {Kind: graph.IntVal, I: 500},
+	}); err != nil {
+		t.Fatalf("failed to add edge eAB: %v", err)
+	}
+
+	return g
+}
+
+func TestValidate_Conforming(t *testing.T) {
+	g := buildSchemaTestGraph(t)
+	s := Schema{
+		NodeProps: map[string]graph.ValueKind{"region": graph.StringVal, "risk": graph.FloatVal},
+		EdgeProps: map[string]graph.ValueKind{"distance": graph.IntVal},
+	}
+
+	if errs := Validate(g, s); len(errs) != 0 {
+		t.Errorf("expected no violations, got %v", errs)
+	}
+}
+
+func TestValidate_UnknownProperty(t *testing.T) {
+	g := buildSchemaTestGraph(t)
+	s := Schema{
+		NodeProps: map[string]graph.ValueKind{"region": graph.StringVal},
+	}
+
+	errs := Validate(g, s)
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 violation, got %d: %v", len(errs), errs)
+	}
+	if errs[0].Kind != "UnknownProperty" {
+		t.Errorf("expected UnknownProperty, got %s", errs[0].Kind)
+	}
+}
+
+func TestValidate_TypeMismatch(t *testing.T) {
+	g := buildSchemaTestGraph(t)
+	s := Schema{
+		EdgeProps: map[string]graph.ValueKind{"distance": graph.FloatVal},
+	}
+
+	errs := Validate(g, s)
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 violation, got %d: %v", len(errs), errs)
+	}
+	if errs[0].Kind != "TypeMismatch" {
+		t.Errorf("expected TypeMismatch, got %s", errs[0].Kind)
+	}
+}
+
+func TestValidate_NilMapUnconstrained(t *testing.T) {
+	g := buildSchemaTestGraph(t)
+
+	if errs := Validate(g, Schema{}); len(errs) != 0 {
+		t.Errorf("expected empty schema to accept everything, got %v", errs)
+	}
+}
+
+func TestValidate_EmptyMapRejectsAllProperties(t *testing.T) {
+	g := buildSchemaTestGraph(t)
+	s := Schema{EdgeProps: map[string]graph.ValueKind{}}
+
+	errs := Validate(g, s)
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 violation, got %d: %v", len(errs), errs)
+	}
+}
+
+func TestValidate_OrderedByElementAndKey(t *testing.T) {
+	g := buildSchemaTestGraph(t)
+	s := Schema{NodeProps: map[string]graph.ValueKind{}}
+
+	errs := Validate(g, s)
+	if len(errs) != 3 {
+		t.Fatalf("expected 3 violations, got %d: %v", len(errs), errs)
+	}
+
+	want := []string{
+		`node A has undeclared property "region"`,
+		`node A has undeclared property "risk"`,
+		`node B has undeclared property "region"`,
+	}
+	for i, e := range errs {
+		if e.Message != want[i] {
+			t.Errorf("violation %d: expected %q, got %q", i, want[i], e.Message)
+		}
+	}
+}
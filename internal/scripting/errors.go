@@ -0,0 +1,12 @@
+package scripting
+
+import "fmt"
+
+type ScriptError struct {
+	Kind    string
+	Message string
+}
+
+func (e ScriptError) Error() string {
+	return fmt.Sprintf("script error (%v): %v", e.Kind, e.Message)
+}
@@ -0,0 +1,136 @@
+// Package scripting runs user-supplied scripts inside queries, so that
+// custom logic does not need pgraph to be recompiled.
+package scripting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/ritamzico/pgraph/internal/result"
+	lua "github.com/yuin/gopher-lua"
+)
+
+// DefaultTimeout bounds a LuaReducer run whose Timeout is zero.
+const DefaultTimeout = 5 * time.Second
+
+// LuaReducer reduces probabilistic results with a Lua script. The script
+// must define a global function reduce(probs), which receives the results'
+// probabilities as a list (a table indexed from 1, in query order) and
+// returns a number between 0 and 1, the ProbabilityResult of the
+// reduction. Each Reduce runs the script in a fresh interpreter with only
+// the base, table, string and math libraries, and without file access, so
+// scripts cannot keep state between runs or touch the system. A script
+// still running after Timeout (DefaultTimeout if zero) is stopped.
+type LuaReducer struct {
+	Script  string
+	Timeout time.Duration
+}
+
+// Check compiles the script without running it, reporting syntax errors.
+func (r LuaReducer) Check() error {
+	L := lua.NewState(lua.Options{SkipOpenLibs: true})
+	defer L.Close()
+	if _, err := L.LoadString(r.Script); err != nil {
+		return ScriptError{Kind: "CompileError", Message: err.Error()}
+	}
+	return nil
+}
+
+func (r LuaReducer) Reduce(results []result.Result) (result.Result, error) {
+	return r.ReduceContext(context.Background(), results)
+}
+
+// ReduceContext is Reduce, stopped early if ctx is cancelled or its
+// deadline passes, in which case ctx's error is returned.
+func (r LuaReducer) ReduceContext(parent context.Context, results []result.Result) (result.Result, error) {
+	probs := make([]float64, len(results))
+	for i, res := range results {
+		pr, ok := res.(result.ProbabilisticResult)
+		if !ok {
+			return nil, fmt.Errorf("expected ProbabilisticResult, got %T", res)
+		}
+		probs[i] = pr.ProbabilityValue()
+	}
+
+	timeout := r.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	defer cancel()
+
+	L := newSandbox()
+	defer L.Close()
+	L.SetContext(ctx)
+
+	if err := L.DoString(r.Script); err != nil {
+		return nil, scriptFailure(parent, ctx, timeout, err)
+	}
+	reduce, ok := L.GetGlobal("reduce").(*lua.LFunction)
+	if !ok {
+		return nil, ScriptError{Kind: "MissingReduce", Message: "script must define a function reduce(probs)"}
+	}
+
+	list := L.CreateTable(len(probs), 0)
+	for _, p := range probs {
+		list.Append(lua.LNumber(p))
+	}
+	if err := L.CallByParam(lua.P{Fn: reduce, NRet: 1, Protect: true}, list); err != nil {
+		return nil, scriptFailure(parent, ctx, timeout, err)
+	}
+
+	ret := L.Get(-1)
+	n, ok := ret.(lua.LNumber)
+	if !ok {
+		return nil, ScriptError{
+			Kind:    "InvalidResult",
+			Message: fmt.Sprintf("reduce must return a number, got %s %v", ret.Type(), ret),
+		}
+	}
+	if p := float64(n); math.IsNaN(p) || p < 0 || p > 1 {
+		return nil, ScriptError{
+			Kind:    "InvalidParameter",
+			Message: fmt.Sprintf("reduce must return a probability between 0 and 1, got %v", p),
+		}
+	}
+	return result.NewProbabilityResult(float64(n)), nil
+}
+
+// newSandbox returns an interpreter with the base, table, string and math
+// libraries, minus the base functions that read files.
+func newSandbox() *lua.LState {
+	L := lua.NewState(lua.Options{SkipOpenLibs: true})
+	for _, lib := range []struct {
+		name string
+		open lua.LGFunction
+	}{
+		{lua.BaseLibName, lua.OpenBase},
+		{lua.TabLibName, lua.OpenTable},
+		{lua.StringLibName, lua.OpenString},
+		{lua.MathLibName, lua.OpenMath},
+	} {
+		L.Push(L.NewFunction(lib.open))
+		L.Push(lua.LString(lib.name))
+		L.Call(1, 0)
+	}
+	for _, name := range []string{"dofile", "loadfile"} {
+		L.SetGlobal(name, lua.LNil)
+	}
+	return L
+}
+
+// scriptFailure wraps an error raised while running a script. A run
+// stopped because parent ended returns parent's error, and one stopped by
+// the script's own deadline, in ctx, is a Timeout.
+func scriptFailure(parent, ctx context.Context, timeout time.Duration, err error) error {
+	if parent.Err() != nil {
+		return parent.Err()
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return ScriptError{Kind: "Timeout", Message: fmt.Sprintf("script did not finish within %v", timeout)}
+	}
+	return ScriptError{Kind: "RuntimeError", Message: err.Error()}
+}
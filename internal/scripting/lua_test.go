@@ -0,0 +1,107 @@
+package scripting
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/ritamzico/pgraph/internal/result"
+)
+
+func probs(ps ...float64) []result.Result {
+	results := make([]result.Result, len(ps))
+	for i, p := range ps {
+		results[i] = result.NewProbabilityResult(p)
+	}
+	return results
+}
+
+func TestLuaReducer(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   float64
+	}{
+		{"mean", `function reduce(probs)
+			local sum = 0
+			for _, p in ipairs(probs) do sum = sum + p end
+			return sum / #probs
+		end`, 0.5},
+		{"any of", `function reduce(probs)
+			local none = 1
+			for i = 1, #probs do none = none * (1 - probs[i]) end
+			return 1 - none
+		end`, 1 - 0.8*0.5*0.2},
+		{"math library", `function reduce(probs) return math.max(unpack(probs)) end`, 0.8},
+		{"order", `function reduce(probs) return probs[1] end`, 0.2},
+	}
+	for _, tt := range tests {
+		r := LuaReducer{Script: tt.script}
+		if err := r.Check(); err != nil {
+			t.Fatalf("%s: Check: %v", tt.name, err)
+		}
+		res, err := r.Reduce(probs(0.2, 0.5, 0.8))
+		if err != nil {
+			t.Fatalf("%s: Reduce: %v", tt.name, err)
+		}
+		pr, ok := res.(result.ProbabilityResult)
+		if !ok {
+			t.Fatalf("%s: expected ProbabilityResult, got %T", tt.name, res)
+		}
+		if math.Abs(pr.Probability-tt.want) > 1e-12 {
+			t.Errorf("%s: expected %f, got %f", tt.name, tt.want, pr.Probability)
+		}
+	}
+}
+
+func TestLuaReducer_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		kind   string
+	}{
+		{"runtime error", `function reduce(probs) error("boom") end`, "RuntimeError"},
+		{"missing reduce", `x = 1`, "MissingReduce"},
+		{"non-number", `function reduce(probs) return "high" end`, "InvalidResult"},
+		{"nan", `function reduce(probs) return 0/0 end`, "InvalidParameter"},
+		{"above one", `function reduce(probs) return 5 end`, "InvalidParameter"},
+		{"negative", `function reduce(probs) return -0.1 end`, "InvalidParameter"},
+		{"no file access", `function reduce(probs) return dofile("/etc/passwd") end`, "RuntimeError"},
+		{"no os library", `function reduce(probs) return os.time() end`, "RuntimeError"},
+	}
+	for _, tt := range tests {
+		_, err := LuaReducer{Script: tt.script}.Reduce(probs(0.5))
+		var se ScriptError
+		if !errors.As(err, &se) || se.Kind != tt.kind {
+			t.Errorf("%s: expected %s error, got %v", tt.name, tt.kind, err)
+		}
+	}
+
+	var se ScriptError
+	if err := (LuaReducer{Script: "function reduce("}).Check(); !errors.As(err, &se) || se.Kind != "CompileError" {
+		t.Errorf("expected CompileError, got %v", err)
+	}
+	if _, err := (LuaReducer{Script: "function reduce(p) return 1 end"}).Reduce([]result.Result{result.NodeListResult{}}); err == nil {
+		t.Error("expected error for a non-probabilistic result")
+	}
+}
+
+func TestLuaReducer_Timeout(t *testing.T) {
+	r := LuaReducer{Script: `function reduce(probs) while true do end end`, Timeout: 50 * time.Millisecond}
+	_, err := r.Reduce(probs(0.5))
+	var se ScriptError
+	if !errors.As(err, &se) || se.Kind != "Timeout" {
+		t.Errorf("expected Timeout error, got %v", err)
+	}
+}
+
+func TestLuaReducer_ContextCancelled(t *testing.T) {
+	r := LuaReducer{Script: `function reduce(probs) while true do end end`}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := r.ReduceContext(ctx, probs(0.5)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the query's context.DeadlineExceeded, got %v", err)
+	}
+}
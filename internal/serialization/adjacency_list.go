@@ -0,0 +1,52 @@
+package serialization
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ritamzico/pgraph/internal/graph"
+)
+
+// WriteAdjacencyList writes g as text, one line per node in sorted ID
+// order: "A -> [B(0.9), C(0.8)]", with neighbours sorted by ID. If maxNodes
+// is positive and g has more nodes, only the first maxNodes are written,
+// followed by "... (N more)". The output is deterministic, so it can be
+// compared against golden files.
+func WriteAdjacencyList(g graph.ProbabilisticGraphModel, w io.Writer, maxNodes int) error {
+	nodes := g.GetNodes()
+	ids := make([]graph.NodeID, len(nodes))
+	for i, n := range nodes {
+		ids[i] = n.ID
+	}
+	slices.Sort(ids)
+
+	shown := ids
+	if maxNodes > 0 && len(ids) > maxNodes {
+		shown = ids[:maxNodes]
+	}
+
+	var sb strings.Builder
+	for _, id := range shown {
+		edges, err := g.OutgoingEdges(id)
+		if err != nil {
+			return err
+		}
+		slices.SortFunc(edges, func(a, b *graph.Edge) int { return cmp.Compare(a.To, b.To) })
+
+		neighbours := make([]string, len(edges))
+		for i, e := range edges {
+			neighbours[i] = fmt.Sprintf("%s(%s)", e.To, strconv.FormatFloat(e.Probability, 'g', -1, 64))
+		}
+		fmt.Fprintf(&sb, "%s -> [%s]\n", id, strings.Join(neighbours, ", "))
+	}
+	if extra := len(ids) - len(shown); extra > 0 {
+		fmt.Fprintf(&sb, "... (%d more)\n", extra)
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
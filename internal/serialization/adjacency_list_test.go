@@ -0,0 +1,64 @@
+package serialization
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestWriteAdjacencyList(t *testing.T) {
+	g := buildGraph(t,
+		[]nodeDesc{{id: "C"}, {id: "A"}, {id: "B"}, {id: "D"}},
+		[]edgeDesc{
+			{id: "eAC", from: "A", to: "C", prob: 0.8},
+			{id: "eAB", from: "A", to: "B", prob: 0.9},
+			{id: "eBC", from: "B", to: "C", prob: 1},
+			{id: "eCA", from: "C", to: "A", prob: 0.25},
+		},
+	)
+
+	var sb strings.Builder
+	if err := WriteAdjacencyList(g, &sb, 0); err != nil {
+		t.Fatalf("WriteAdjacencyList: %v", err)
+	}
+	want := `A -> [B(0.9), C(0.8)]
+B -> [C(1)]
+C -> [A(0.25)]
+D -> []
+`
+	if sb.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", sb.String(), want)
+	}
+}
+
+func TestWriteAdjacencyList_Truncates(t *testing.T) {
+	nodes := make([]nodeDesc, 25)
+	for i := range nodes {
+		nodes[i] = nodeDesc{id: fmt.Sprintf("n%02d", i)}
+	}
+	g := buildGraph(t, nodes, []edgeDesc{{id: "e", from: "n00", to: "n24", prob: 0.5}})
+
+	var sb strings.Builder
+	if err := WriteAdjacencyList(g, &sb, 20); err != nil {
+		t.Fatalf("WriteAdjacencyList: %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(sb.String(), "\n"), "\n")
+	if len(lines) != 21 {
+		t.Fatalf("expected 20 node lines and a summary, got %d lines", len(lines))
+	}
+	if lines[0] != "n00 -> [n24(0.5)]" || lines[19] != "n19 -> []" {
+		t.Errorf("unexpected rows %q ... %q", lines[0], lines[19])
+	}
+	if lines[20] != "... (5 more)" {
+		t.Errorf("expected truncation summary, got %q", lines[20])
+	}
+
+	// Exactly maxNodes nodes are not truncated.
+	sb.Reset()
+	if err := WriteAdjacencyList(g, &sb, 25); err != nil {
+		t.Fatalf("WriteAdjacencyList: %v", err)
+	}
+	if strings.Contains(sb.String(), "more") {
+		t.Errorf("expected no truncation with maxNodes equal to the node count:\n%s", sb.String())
+	}
+}
@@ -0,0 +1,117 @@
+package serialization
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ritamzico/pgraph/internal/graph"
+)
+
+// adjacencyMatrix returns the node IDs in sorted order and the row-major
+// N×N matrix of edge probabilities, where cell [i*N+j] is the probability of
+// the edge from ids[i] to ids[j], or 0 if there is none.
+func adjacencyMatrix(g graph.ProbabilisticGraphModel) ([]graph.NodeID, []float64) {
+	nodes := g.GetNodes()
+	ids := make([]graph.NodeID, len(nodes))
+	for i, n := range nodes {
+		ids[i] = n.ID
+	}
+	slices.Sort(ids)
+
+	index := make(map[graph.NodeID]int, len(ids))
+	for i, id := range ids {
+		index[id] = i
+	}
+
+	n := len(ids)
+	matrix := make([]float64, n*n)
+	for _, e := range g.GetEdges() {
+		matrix[index[e.From]*n+index[e.To]] = e.Probability
+	}
+	return ids, matrix
+}
+
+// WriteAdjacencyMatrixCSV writes g's adjacency matrix as CSV: a header row
+// of node IDs in sorted order, then one row per source node starting with
+// its ID, with the probability of the edge to each destination or 0.0 if
+// there is none.
+func WriteAdjacencyMatrixCSV(g graph.ProbabilisticGraphModel, w io.Writer) error {
+	ids, matrix := adjacencyMatrix(g)
+	n := len(ids)
+
+	cw := csv.NewWriter(w)
+	record := make([]string, n+1)
+	for i, id := range ids {
+		record[i+1] = string(id)
+	}
+	if err := cw.Write(record); err != nil {
+		return err
+	}
+
+	for i, id := range ids {
+		record[0] = string(id)
+		for j := range n {
+			record[j+1] = formatMatrixCell(matrix[i*n+j])
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// formatMatrixCell prints p exactly, always with a decimal point so every
+// cell reads as a float.
+func formatMatrixCell(p float64) string {
+	s := strconv.FormatFloat(p, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".e") {
+		s += ".0"
+	}
+	return s
+}
+
+// WriteAdjacencyMatrixDense writes g's adjacency matrix as a NumPy .npy
+// file (format version 1.0) holding an N×N little-endian float64 array in
+// row-major order, so numpy.load reads it directly. Rows and columns follow
+// sorted node ID order, as in WriteAdjacencyMatrixCSV; the IDs themselves
+// are not stored.
+func WriteAdjacencyMatrixDense(g graph.ProbabilisticGraphModel, w io.Writer) error {
+	ids, matrix := adjacencyMatrix(g)
+	n := len(ids)
+
+	// The header is a Python dict literal, space-padded and newline
+	// terminated so that the 10-byte preamble plus header is a multiple of
+	// 64 bytes.
+	header := fmt.Sprintf("{'descr': '<f8', 'fortran_order': False, 'shape': (%d, %d), }", n, n)
+	const preamble = 10
+	pad := 64 - (preamble+len(header)+1)%64
+	if pad == 64 {
+		pad = 0
+	}
+	header += strings.Repeat(" ", pad) + "\n"
+
+	bw := bufio.NewWriter(w)
+	bw.WriteString("\x93NUMPY\x01\x00")
+	if err := binary.Write(bw, binary.LittleEndian, uint16(len(header))); err != nil {
+		return err
+	}
+	bw.WriteString(header)
+
+	var buf [8]byte
+	for _, p := range matrix {
+		binary.LittleEndian.PutUint64(buf[:], math.Float64bits(p))
+		if _, err := bw.Write(buf[:]); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,89 @@
+package serialization
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/csv"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWriteAdjacencyMatrixCSV(t *testing.T) {
+	g := buildGraph(t,
+		[]nodeDesc{{id: "C"}, {id: "A"}, {id: "B"}},
+		[]edgeDesc{
+			{id: "eAB", from: "A", to: "B", prob: 0.9},
+			{id: "eBC", from: "B", to: "C", prob: 1},
+			{id: "eCA", from: "C", to: "A", prob: 0.25},
+		},
+	)
+
+	var buf bytes.Buffer
+	if err := WriteAdjacencyMatrixCSV(g, &buf); err != nil {
+		t.Fatalf("WriteAdjacencyMatrixCSV: %v", err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("reading CSV: %v", err)
+	}
+
+	want := [][]string{
+		{"", "A", "B", "C"},
+		{"A", "0.0", "0.9", "0.0"},
+		{"B", "0.0", "0.0", "1.0"},
+		{"C", "0.25", "0.0", "0.0"},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("got %v, want %v", records, want)
+	}
+}
+
+func TestWriteAdjacencyMatrixCSVEmptyGraph(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteAdjacencyMatrixCSV(buildGraph(t, nil, nil), &buf); err != nil {
+		t.Fatalf("WriteAdjacencyMatrixCSV: %v", err)
+	}
+	// Only the header's empty corner cell is written.
+	if got := buf.String(); got != "\n" {
+		t.Errorf("got %q, want a single empty line", got)
+	}
+}
+
+func TestWriteAdjacencyMatrixDense(t *testing.T) {
+	g := buildGraph(t,
+		[]nodeDesc{{id: "B"}, {id: "A"}},
+		[]edgeDesc{{id: "eAB", from: "A", to: "B", prob: 0.75}},
+	)
+
+	var buf bytes.Buffer
+	if err := WriteAdjacencyMatrixDense(g, &buf); err != nil {
+		t.Fatalf("WriteAdjacencyMatrixDense: %v", err)
+	}
+	data := buf.Bytes()
+
+	if !bytes.HasPrefix(data, []byte("\x93NUMPY\x01\x00")) {
+		t.Fatalf("missing .npy magic: %q", data[:8])
+	}
+	headerLen := int(binary.LittleEndian.Uint16(data[8:10]))
+	if (10+headerLen)%64 != 0 {
+		t.Errorf("preamble plus header is %d bytes, want a multiple of 64", 10+headerLen)
+	}
+	header := string(data[10 : 10+headerLen])
+	if !strings.Contains(header, "'shape': (2, 2)") || !strings.HasSuffix(header, "\n") {
+		t.Errorf("unexpected header %q", header)
+	}
+
+	body := data[10+headerLen:]
+	if len(body) != 4*8 {
+		t.Fatalf("body is %d bytes, want 32", len(body))
+	}
+	got := make([]float64, 4)
+	for i := range got {
+		got[i] = math.Float64frombits(binary.LittleEndian.Uint64(body[i*8:]))
+	}
+	if want := []float64{0, 0.75, 0, 0}; !reflect.DeepEqual(got, want) {
+		t.Errorf("matrix = %v, want %v", got, want)
+	}
+}
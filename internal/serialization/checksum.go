@@ -0,0 +1,77 @@
+package serialization
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+)
+
+const checksumPrefix = "sha256:"
+
+// ChecksumMismatchError is returned when a document's "checksum" does not
+// match its content.
+type ChecksumMismatchError struct {
+	Want string // the checksum stored in the document
+	Got  string // the checksum of the content as read
+}
+
+func (e ChecksumMismatchError) Error() string {
+	return fmt.Sprintf("checksum mismatch: file says %s, content is %s", e.Want, e.Got)
+}
+
+// contentChecksum returns the "sha256:<hex>" checksum of doc without its
+// "checksum" key. The hash is taken over doc re-encoded as compact JSON with
+// sorted top-level keys, so it does not depend on indentation.
+func contentChecksum(doc map[string]json.RawMessage) (string, error) {
+	if _, ok := doc["checksum"]; ok {
+		doc = maps.Clone(doc)
+		delete(doc, "checksum")
+	}
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return checksumPrefix + hex.EncodeToString(sum[:]), nil
+}
+
+// verifyChecksum checks raw's "checksum", if it has one.
+func verifyChecksum(raw json.RawMessage) error {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return fmt.Errorf("decoding graph JSON: %w", err)
+	}
+	stored, ok := doc["checksum"]
+	if !ok {
+		return nil
+	}
+	var want string
+	if err := json.Unmarshal(stored, &want); err != nil {
+		return fmt.Errorf("checksum: expected string, got %s", stored)
+	}
+	got, err := contentChecksum(doc)
+	if err != nil {
+		return err
+	}
+	if got != want {
+		return ChecksumMismatchError{Want: want, Got: got}
+	}
+	return nil
+}
+
+// withChecksum sets sg.Checksum to the checksum of sg's content.
+func withChecksum(sg serializedGraph) (serializedGraph, error) {
+	sg.Checksum = ""
+	b, err := json.Marshal(sg)
+	if err != nil {
+		return sg, err
+	}
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return sg, err
+	}
+	sg.Checksum, err = contentChecksum(doc)
+	return sg, err
+}
@@ -0,0 +1,81 @@
+package serialization
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ritamzico/pgraph/internal/graph"
+)
+
+func writeChecksummed(t *testing.T) []byte {
+	t.Helper()
+	g := buildGraph(t,
+		[]nodeDesc{{id: "a", props: map[string]graph.Value{"name": {Kind: graph.StringVal, S: "<a & b>"}}}, {id: "b"}},
+		[]edgeDesc{{id: "e1", from: "a", to: "b", prob: 0.5}},
+	)
+	var buf bytes.Buffer
+	if err := WriteJSON(g, &buf); err != nil {
+		t.Fatalf("WriteJSON: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestChecksum_WrittenAndVerified(t *testing.T) {
+	data := writeChecksummed(t)
+
+	var doc struct {
+		Checksum string `json:"checksum"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if !strings.HasPrefix(doc.Checksum, "sha256:") || len(doc.Checksum) != len("sha256:")+64 {
+		t.Fatalf("expected a sha256 checksum, got %q", doc.Checksum)
+	}
+
+	if _, err := ReadJSON(bytes.NewReader(data)); err != nil {
+		t.Fatalf("ReadJSON: %v", err)
+	}
+
+	// Reformatting does not change the content.
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, data); err != nil {
+		t.Fatalf("Compact: %v", err)
+	}
+	if _, err := ReadJSON(&compact); err != nil {
+		t.Errorf("ReadJSON of compacted file: %v", err)
+	}
+}
+
+func TestChecksum_Mismatch(t *testing.T) {
+	data := bytes.Replace(writeChecksummed(t), []byte("0.5"), []byte("0.6"), 1)
+
+	_, err := ReadJSON(bytes.NewReader(data))
+	var ce ChecksumMismatchError
+	if !errors.As(err, &ce) {
+		t.Fatalf("expected ChecksumMismatchError, got %v", err)
+	}
+	if ce.Want == ce.Got {
+		t.Errorf("expected differing checksums, got %+v", ce)
+	}
+
+	g, _, err := ReadJSONWithOptions(bytes.NewReader(data), ReadOptions{SkipChecksum: true})
+	if err != nil {
+		t.Fatalf("ReadJSONWithOptions: %v", err)
+	}
+	if e, _ := g.GetEdgeByID("e1"); e == nil || e.Probability != 0.6 {
+		t.Errorf("expected the edited probability to load, got %+v", e)
+	}
+}
+
+func TestChecksum_Optional(t *testing.T) {
+	if _, err := ReadJSON(strings.NewReader(`{"nodes": [{"id": "a"}], "edges": []}`)); err != nil {
+		t.Errorf("ReadJSON without checksum: %v", err)
+	}
+	if _, err := ReadJSON(strings.NewReader(`{"checksum": 1, "nodes": []}`)); err == nil {
+		t.Error("expected error for a non-string checksum")
+	}
+}
@@ -0,0 +1,124 @@
+package serialization
+
+import (
+	"cmp"
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ritamzico/pgraph/internal/graph"
+)
+
+// Layout constants for WriteHTML, in SVG user units.
+const (
+	htmlNodeRadius  = 18.0
+	htmlMargin      = 60.0
+	htmlMinRadius   = 150.0
+	htmlNodeSpacing = 60.0
+	htmlCurve       = 20.0
+)
+
+// WriteHTML writes g as a self-contained HTML page with an SVG drawing: no
+// scripts or external resources. Nodes are labelled circles evenly spaced
+// on a circle in sorted ID order, so the output is deterministic. Edges
+// are arrows labelled with their probability, with the stroke width
+// growing with it; each edge bends slightly to its right so that the two
+// directions between a pair of nodes stay apart. A positive refresh adds a
+// <meta http-equiv="refresh"> tag that reloads the page at that interval.
+// It is meant for a quick look at small graphs, not as a general
+// visualization tool.
+func WriteHTML(g graph.ProbabilisticGraphModel, w io.Writer, refresh time.Duration) error {
+	nodes := g.GetNodes()
+	ids := make([]graph.NodeID, len(nodes))
+	for i, n := range nodes {
+		ids[i] = n.ID
+	}
+	slices.Sort(ids)
+
+	// The layout circle grows so that neighbours stay htmlNodeSpacing apart.
+	radius := max(htmlMinRadius, float64(len(ids))*htmlNodeSpacing/(2*math.Pi))
+	if len(ids) == 1 {
+		radius = 0
+	}
+	size := 2 * (radius + htmlMargin)
+	type point struct{ x, y float64 }
+	pos := make(map[graph.NodeID]point, len(ids))
+	for i, id := range ids {
+		angle := 2*math.Pi*float64(i)/float64(len(ids)) - math.Pi/2
+		pos[id] = point{size/2 + radius*math.Cos(angle), size/2 + radius*math.Sin(angle)}
+	}
+
+	edges := g.GetEdges()
+	slices.SortFunc(edges, func(a, b *graph.Edge) int { return cmp.Compare(a.ID, b.ID) })
+
+	var sb strings.Builder
+	sb.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+	if refresh > 0 {
+		fmt.Fprintf(&sb, "<meta http-equiv=\"refresh\" content=\"%d\">\n", max(1, int(refresh.Round(time.Second)/time.Second)))
+	}
+	fmt.Fprintf(&sb, "<title>pgraph (%d nodes, %d edges)</title>\n", len(ids), len(edges))
+	sb.WriteString(`<style>
+body { margin: 0; font-family: sans-serif; }
+.node circle { fill: #dbe9f6; stroke: #2b5d8a; stroke-width: 1.5; }
+.node text { font-size: 11px; text-anchor: middle; dominant-baseline: central; }
+.edge path { fill: none; stroke: #555; }
+.edge text { font-size: 10px; fill: #a33; text-anchor: middle; }
+</style>
+</head>
+<body>
+`)
+	fmt.Fprintf(&sb, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%s\" height=\"%s\" viewBox=\"0 0 %s %s\">\n",
+		svgNum(size), svgNum(size), svgNum(size), svgNum(size))
+	sb.WriteString(`<defs><marker id="arrow" viewBox="0 0 10 10" refX="10" refY="5" markerUnits="userSpaceOnUse" markerWidth="10" markerHeight="10" orient="auto-start-reverse"><path d="M 0 0 L 10 5 L 0 10 z" fill="#555"/></marker></defs>` + "\n")
+
+	for _, e := range edges {
+		from, to := pos[e.From], pos[e.To]
+		var path string
+		var label point
+		if e.From == e.To {
+			// A loop above the node.
+			path = fmt.Sprintf("M %s %s C %s %s %s %s %s %s",
+				svgNum(from.x-htmlNodeRadius/2), svgNum(from.y-htmlNodeRadius+2),
+				svgNum(from.x-htmlNodeRadius), svgNum(from.y-3*htmlNodeRadius),
+				svgNum(from.x+htmlNodeRadius), svgNum(from.y-3*htmlNodeRadius),
+				svgNum(from.x+htmlNodeRadius/2), svgNum(from.y-htmlNodeRadius+2))
+			label = point{from.x, from.y - 2.5*htmlNodeRadius}
+		} else {
+			dx, dy := to.x-from.x, to.y-from.y
+			dist := math.Hypot(dx, dy)
+			ux, uy := dx/dist, dy/dist
+			// Start and end on the node circles, bending to the right of
+			// the direction of travel.
+			start := point{from.x + ux*htmlNodeRadius, from.y + uy*htmlNodeRadius}
+			end := point{to.x - ux*htmlNodeRadius, to.y - uy*htmlNodeRadius}
+			ctrl := point{(from.x+to.x)/2 - uy*htmlCurve, (from.y+to.y)/2 + ux*htmlCurve}
+			path = fmt.Sprintf("M %s %s Q %s %s %s %s",
+				svgNum(start.x), svgNum(start.y), svgNum(ctrl.x), svgNum(ctrl.y), svgNum(end.x), svgNum(end.y))
+			// The curve's midpoint.
+			label = point{(start.x+2*ctrl.x+end.x)/4 - uy*4, (start.y+2*ctrl.y+end.y)/4 + ux*4}
+		}
+		fmt.Fprintf(&sb, "<g class=\"edge\"><title>%s</title><path d=\"%s\" stroke-width=\"%s\" marker-end=\"url(#arrow)\"/><text x=\"%s\" y=\"%s\">%s</text></g>\n",
+			html.EscapeString(string(e.ID)), path, svgNum(1+3*e.Probability),
+			svgNum(label.x), svgNum(label.y), strconv.FormatFloat(e.Probability, 'g', 3, 64))
+	}
+
+	for _, id := range ids {
+		p := pos[id]
+		fmt.Fprintf(&sb, "<g class=\"node\"><circle cx=\"%s\" cy=\"%s\" r=\"%s\"/><text x=\"%s\" y=\"%s\">%s</text></g>\n",
+			svgNum(p.x), svgNum(p.y), svgNum(htmlNodeRadius), svgNum(p.x), svgNum(p.y), html.EscapeString(string(id)))
+	}
+
+	sb.WriteString("</svg>\n</body>\n</html>\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// svgNum formats a coordinate with at most two decimals.
+func svgNum(f float64) string {
+	return strconv.FormatFloat(math.Round(f*100)/100, 'f', -1, 64)
+}
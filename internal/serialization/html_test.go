@@ -0,0 +1,60 @@
+package serialization
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteHTML(t *testing.T) {
+	g := buildGraph(t,
+		[]nodeDesc{{id: "B"}, {id: "A"}, {id: "<C>"}},
+		[]edgeDesc{
+			{id: "eAB", from: "A", to: "B", prob: 0.9},
+			{id: "eBA", from: "B", to: "A", prob: 0.25},
+			{id: "eAA", from: "A", to: "A", prob: 0.5},
+		},
+	)
+
+	var sb strings.Builder
+	if err := WriteHTML(g, &sb, 0); err != nil {
+		t.Fatalf("WriteHTML: %v", err)
+	}
+	out := sb.String()
+
+	if n := strings.Count(out, "<circle "); n != 3 {
+		t.Errorf("expected 3 node circles, got %d", n)
+	}
+	if n := strings.Count(out, `marker-end="url(#arrow)"`); n != 3 {
+		t.Errorf("expected 3 edge arrows, got %d", n)
+	}
+	for _, want := range []string{">0.9</text>", ">0.25</text>", ">A</text>", "&lt;C&gt;"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected output to contain %q", want)
+		}
+	}
+	if strings.Contains(out, "<C>") || strings.Contains(out, "http-equiv") {
+		t.Error("expected an escaped node ID and no refresh tag")
+	}
+
+	// The layout is deterministic.
+	var again strings.Builder
+	if err := WriteHTML(g, &again, 0); err != nil {
+		t.Fatalf("WriteHTML: %v", err)
+	}
+	if again.String() != out {
+		t.Error("expected identical output for the same graph")
+	}
+}
+
+func TestWriteHTML_Refresh(t *testing.T) {
+	g := buildGraph(t, []nodeDesc{{id: "A"}}, nil)
+
+	var sb strings.Builder
+	if err := WriteHTML(g, &sb, 5*time.Second); err != nil {
+		t.Fatalf("WriteHTML: %v", err)
+	}
+	if !strings.Contains(sb.String(), `<meta http-equiv="refresh" content="5">`) {
+		t.Errorf("expected a 5 second refresh tag, got:\n%s", sb.String())
+	}
+}
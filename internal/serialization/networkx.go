@@ -0,0 +1,168 @@
+package serialization
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/ritamzico/pgraph/internal/graph"
+)
+
+// networkXGraph is the node_link_data layout. NetworkX 3.4 renamed "links"
+// to "edges" by default, so both are accepted.
+type networkXGraph struct {
+	Directed bool             `json:"directed"`
+	Nodes    []map[string]any `json:"nodes"`
+	Links    []map[string]any `json:"links"`
+	Edges    []map[string]any `json:"edges"`
+}
+
+// ReadNetworkXJSON decodes a graph written by NetworkX's node_link_data
+// (json.dump(nx.node_link_data(G), f)). The link attribute named by
+// probabilityKey becomes the edge probability and must be present on every
+// link; the remaining node and link attributes become properties.
+//
+// Links carry no IDs in that format, so edge i is named "e<i>" in link
+// order. Node IDs may be strings or numbers. An undirected graph is loaded
+// with bidirectional edges. Attributes holding objects, or arrays of mixed
+// or non-scalar values, are rejected.
+func ReadNetworkXJSON(r io.Reader, probabilityKey string) (*graph.ProbabilisticAdjacencyListGraph, error) {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	var nx networkXGraph
+	if err := dec.Decode(&nx); err != nil {
+		return nil, fmt.Errorf("decoding NetworkX JSON: %w", err)
+	}
+
+	g := graph.CreateProbAdjListGraph()
+
+	for i, n := range nx.Nodes {
+		id, err := networkXID(n["id"])
+		if err != nil {
+			return nil, fmt.Errorf("node %d: id: %w", i, err)
+		}
+		props, err := networkXProps(n, "id")
+		if err != nil {
+			return nil, fmt.Errorf("node %s: %w", id, err)
+		}
+		if err := g.AddNode(graph.NodeID(id), props); err != nil {
+			return nil, fmt.Errorf("adding node %s: %w", id, err)
+		}
+	}
+
+	links := nx.Links
+	if links == nil {
+		links = nx.Edges
+	}
+
+	addEdge := g.AddEdge
+	if !nx.Directed {
+		addEdge = g.AddBidirectionalEdge
+	}
+
+	for i, l := range links {
+		edgeID := fmt.Sprintf("e%d", i)
+		source, err := networkXID(l["source"])
+		if err != nil {
+			return nil, fmt.Errorf("link %s: source: %w", edgeID, err)
+		}
+		target, err := networkXID(l["target"])
+		if err != nil {
+			return nil, fmt.Errorf("link %s: target: %w", edgeID, err)
+		}
+
+		raw, ok := l[probabilityKey]
+		if !ok {
+			return nil, fmt.Errorf("link %s: missing probability attribute %q", edgeID, probabilityKey)
+		}
+		num, ok := raw.(json.Number)
+		if !ok {
+			return nil, fmt.Errorf("link %s: probability attribute %q: expected number, got %T", edgeID, probabilityKey, raw)
+		}
+		prob, err := num.Float64()
+		if err != nil {
+			return nil, fmt.Errorf("link %s: probability attribute %q: %w", edgeID, probabilityKey, err)
+		}
+
+		// "key" distinguishes parallel links in a multigraph; it is not data.
+		props, err := networkXProps(l, "source", "target", "key", probabilityKey)
+		if err != nil {
+			return nil, fmt.Errorf("link %s: %w", edgeID, err)
+		}
+		if err := addEdge(graph.EdgeID(edgeID), graph.NodeID(source), graph.NodeID(target), prob, props); err != nil {
+			return nil, fmt.Errorf("adding edge %s: %w", edgeID, err)
+		}
+	}
+
+	return g, nil
+}
+
+func networkXID(v any) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case nil:
+		return "", fmt.Errorf("missing")
+	default:
+		return "", fmt.Errorf("expected string or number, got %T", v)
+	}
+}
+
+// networkXProps converts every attribute of obj except the skipped keys.
+func networkXProps(obj map[string]any, skip ...string) (map[string]graph.Value, error) {
+	props := make(map[string]graph.Value, len(obj))
+outer:
+	for k, raw := range obj {
+		for _, s := range skip {
+			if k == s {
+				continue outer
+			}
+		}
+		v, err := networkXValue(raw)
+		if err != nil {
+			return nil, fmt.Errorf("attribute %s: %w", k, err)
+		}
+		props[k] = v
+	}
+	return props, nil
+}
+
+func networkXValue(raw any) (graph.Value, error) {
+	switch raw := raw.(type) {
+	case nil:
+		return graph.Value{Kind: graph.NullVal}, nil
+	case bool:
+		return graph.Value{Kind: graph.BoolVal, B: raw}, nil
+	case string:
+		return graph.Value{Kind: graph.StringVal, S: raw}, nil
+	case json.Number:
+		if i, err := raw.Int64(); err == nil {
+			return graph.Value{Kind: graph.IntVal, I: i}, nil
+		}
+		f, err := raw.Float64()
+		if err != nil {
+			return graph.Value{}, err
+		}
+		return graph.Value{Kind: graph.FloatVal, F: f}, nil
+	case []any:
+		elems := make([]graph.Value, len(raw))
+		for i, r := range raw {
+			e, err := networkXValue(r)
+			if err != nil {
+				return graph.Value{}, fmt.Errorf("array element %d: %w", i, err)
+			}
+			if e.Kind == graph.ArrayVal {
+				return graph.Value{}, fmt.Errorf("array element %d: nested arrays are not supported", i)
+			}
+			if i > 0 && e.Kind != elems[0].Kind {
+				return graph.Value{}, fmt.Errorf("array element %d: expected %s, got %s", i, elems[0].Kind, e.Kind)
+			}
+			elems[i] = e
+		}
+		return graph.Value{Kind: graph.ArrayVal, A: elems}, nil
+	default:
+		return graph.Value{}, fmt.Errorf("unsupported value of type %T", raw)
+	}
+}
@@ -0,0 +1,110 @@
+package serialization
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ritamzico/pgraph/internal/graph"
+)
+
+const networkXDirected = `{
+  "directed": true,
+  "multigraph": false,
+  "graph": {},
+  "nodes": [
+    {"id": "A", "label": "start", "weight": 1.5},
+    {"id": "B"},
+    {"id": 3, "tags": ["x", "y"]}
+  ],
+  "links": [
+    {"source": "A", "target": "B", "p": 0.9, "capacity": 10},
+    {"source": "B", "target": 3, "p": 1, "note": null}
+  ]
+}`
+
+func TestReadNetworkXJSON_Directed(t *testing.T) {
+	g, err := ReadNetworkXJSON(strings.NewReader(networkXDirected), "p")
+	if err != nil {
+		t.Fatalf("ReadNetworkXJSON: %v", err)
+	}
+
+	assertNodeProp(t, g, "A", "label", graph.Value{Kind: graph.StringVal, S: "start"})
+	assertNodeProp(t, g, "A", "weight", graph.Value{Kind: graph.FloatVal, F: 1.5})
+	assertNodeProp(t, g, "3", "tags", graph.Value{Kind: graph.ArrayVal, A: []graph.Value{
+		{Kind: graph.StringVal, S: "x"},
+		{Kind: graph.StringVal, S: "y"},
+	}})
+
+	e, err := g.GetEdge("A", "B")
+	if err != nil {
+		t.Fatalf("GetEdge(A, B): %v", err)
+	}
+	if e.ID != "e0" || e.Probability != 0.9 {
+		t.Errorf("edge = %+v, want e0 with probability 0.9", e)
+	}
+	if _, ok := e.Props["p"]; ok {
+		t.Error("probability attribute should not be copied to props")
+	}
+	assertEdgeProp(t, g, "A", "B", "capacity", graph.Value{Kind: graph.IntVal, I: 10})
+
+	e, err = g.GetEdge("B", "3")
+	if err != nil {
+		t.Fatalf("GetEdge(B, 3): %v", err)
+	}
+	if e.Probability != 1 || e.Props["note"].Kind != graph.NullVal {
+		t.Errorf("edge = %+v", e)
+	}
+
+	if g.ContainsEdge("B", "A") {
+		t.Error("directed graph should not get reverse edges")
+	}
+}
+
+func TestReadNetworkXJSON_UndirectedEdgesKey(t *testing.T) {
+	input := `{
+	  "directed": false,
+	  "nodes": [{"id": "A"}, {"id": "B"}],
+	  "edges": [{"source": "A", "target": "B", "reliability": 0.5}]
+	}`
+	g, err := ReadNetworkXJSON(strings.NewReader(input), "reliability")
+	if err != nil {
+		t.Fatalf("ReadNetworkXJSON: %v", err)
+	}
+	for _, pair := range [][2]graph.NodeID{{"A", "B"}, {"B", "A"}} {
+		e, err := g.GetEdge(pair[0], pair[1])
+		if err != nil {
+			t.Fatalf("GetEdge(%s, %s): %v", pair[0], pair[1], err)
+		}
+		if e.Probability != 0.5 || !e.Bidirectional {
+			t.Errorf("edge %s->%s = %+v", pair[0], pair[1], e)
+		}
+	}
+}
+
+func TestReadNetworkXJSON_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"invalid json", `{`, "decoding NetworkX JSON"},
+		{"missing node id", `{"directed": true, "nodes": [{"x": 1}], "links": []}`, "node 0: id"},
+		{"object attribute", `{"directed": true, "nodes": [{"id": "A", "pos": {"x": 1}}], "links": []}`, "attribute pos"},
+		{"mixed array", `{"directed": true, "nodes": [{"id": "A", "v": [1, "a"]}], "links": []}`, "array element 1"},
+		{"missing probability", `{"directed": true, "nodes": [{"id": "A"}, {"id": "B"}], "links": [{"source": "A", "target": "B"}]}`, `missing probability attribute "p"`},
+		{"string probability", `{"directed": true, "nodes": [{"id": "A"}, {"id": "B"}], "links": [{"source": "A", "target": "B", "p": "high"}]}`, "expected number"},
+		{"probability out of range", `{"directed": true, "nodes": [{"id": "A"}, {"id": "B"}], "links": [{"source": "A", "target": "B", "p": 1.5}]}`, "adding edge e0"},
+		{"unknown node", `{"directed": true, "nodes": [{"id": "A"}], "links": [{"source": "A", "target": "Z", "p": 0.5}]}`, "adding edge e0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadNetworkXJSON(strings.NewReader(tt.input), "p")
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error %q does not mention %q", err, tt.want)
+			}
+		})
+	}
+}
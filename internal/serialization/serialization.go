@@ -7,6 +7,7 @@ import (
 	"os"
 
 	"github.com/ritamzico/pgraph/internal/graph"
+	"github.com/ritamzico/pgraph/internal/schema"
 )
 
 type serializedValue struct {
@@ -17,19 +18,37 @@ type serializedValue struct {
 type serializedNode struct {
 	ID    string                     `json:"id"`
 	Props map[string]serializedValue `json:"props,omitempty"`
+	Group string                     `json:"group,omitempty"`
+}
+
+type serializedGroup struct {
+	Name  string                     `json:"name"`
+	Props map[string]serializedValue `json:"props,omitempty"`
 }
 
 type serializedEdge struct {
-	ID          string                     `json:"id"`
-	From        string                     `json:"from"`
-	To          string                     `json:"to"`
-	Probability float64                    `json:"probability"`
-	Props       map[string]serializedValue `json:"props,omitempty"`
+	ID            string                     `json:"id"`
+	From          string                     `json:"from"`
+	To            string                     `json:"to"`
+	Probability   float64                    `json:"probability"`
+	Props         map[string]serializedValue `json:"props,omitempty"`
+	Bidirectional bool                       `json:"bidirectional,omitempty"`
+	ProbLow       float64                    `json:"prob_low,omitempty"`
+	ProbHigh      float64                    `json:"prob_high,omitempty"`
+}
+
+type serializedSchema struct {
+	Nodes map[string]string `json:"nodes"`
+	Edges map[string]string `json:"edges"`
 }
 
 type serializedGraph struct {
-	Nodes []serializedNode `json:"nodes"`
-	Edges []serializedEdge `json:"edges"`
+	Version  string            `json:"pgraph_version"`
+	Checksum string            `json:"checksum,omitempty"`
+	Schema   *serializedSchema `json:"schema,omitempty"`
+	Groups   []serializedGroup `json:"groups,omitempty"`
+	Nodes    []serializedNode  `json:"nodes"`
+	Edges    []serializedEdge  `json:"edges"`
 }
 
 func marshalValue(v graph.Value) serializedValue {
@@ -42,6 +61,14 @@ func marshalValue(v graph.Value) serializedValue {
 		return serializedValue{Kind: "string", Value: v.S}
 	case graph.BoolVal:
 		return serializedValue{Kind: "bool", Value: v.B}
+	case graph.ArrayVal:
+		elems := make([]serializedValue, len(v.A))
+		for i, e := range v.A {
+			elems[i] = marshalValue(e)
+		}
+		return serializedValue{Kind: "array", Value: elems}
+	case graph.NullVal:
+		return serializedValue{Kind: "null"}
 	default:
 		return serializedValue{Kind: "unknown"}
 	}
@@ -89,45 +116,160 @@ func unmarshalValue(sv serializedValue) (graph.Value, error) {
 			B:    b,
 		}, nil
 
+	case "array":
+		raw, ok := sv.Value.([]any)
+		if !ok {
+			return graph.Value{}, fmt.Errorf("expected array, got %T", sv.Value)
+		}
+		elems := make([]graph.Value, len(raw))
+		for i, r := range raw {
+			obj, ok := r.(map[string]any)
+			if !ok {
+				return graph.Value{}, fmt.Errorf("array element %d: expected object, got %T", i, r)
+			}
+			kind, _ := obj["kind"].(string)
+			e, err := unmarshalValue(serializedValue{Kind: kind, Value: obj["value"]})
+			if err != nil {
+				return graph.Value{}, fmt.Errorf("array element %d: %w", i, err)
+			}
+			if i > 0 && e.Kind != elems[0].Kind {
+				return graph.Value{}, fmt.Errorf("array element %d: expected %s, got %s", i, elems[0].Kind, e.Kind)
+			}
+			elems[i] = e
+		}
+		return graph.Value{
+			Kind: graph.ArrayVal,
+			A:    elems,
+		}, nil
+
+	case "null":
+		return graph.Value{Kind: graph.NullVal}, nil
+
 	default:
 		return graph.Value{}, fmt.Errorf("unknown serialized value kind %q", sv.Kind)
 	}
 }
 
+func marshalKinds(kinds map[string]graph.ValueKind) map[string]string {
+	if kinds == nil {
+		return nil
+	}
+	out := make(map[string]string, len(kinds))
+	for k, v := range kinds {
+		out[k] = v.String()
+	}
+	return out
+}
+
+func unmarshalKinds(kinds map[string]string) (map[string]graph.ValueKind, error) {
+	if kinds == nil {
+		return nil, nil
+	}
+	out := make(map[string]graph.ValueKind, len(kinds))
+	for k, v := range kinds {
+		kind, err := graph.ParseValueKind(v)
+		if err != nil {
+			return nil, fmt.Errorf("property %s: %w", k, err)
+		}
+		out[k] = kind
+	}
+	return out, nil
+}
+
+func toSerializedSchema(s *schema.Schema) *serializedSchema {
+	if s == nil {
+		return nil
+	}
+	return &serializedSchema{
+		Nodes: marshalKinds(s.NodeProps),
+		Edges: marshalKinds(s.EdgeProps),
+	}
+}
+
+func fromSerializedSchema(ss *serializedSchema) (*schema.Schema, error) {
+	if ss == nil {
+		return nil, nil
+	}
+	nodeProps, err := unmarshalKinds(ss.Nodes)
+	if err != nil {
+		return nil, fmt.Errorf("schema nodes: %w", err)
+	}
+	edgeProps, err := unmarshalKinds(ss.Edges)
+	if err != nil {
+		return nil, fmt.Errorf("schema edges: %w", err)
+	}
+	return &schema.Schema{NodeProps: nodeProps, EdgeProps: edgeProps}, nil
+}
+
+func marshalProps(props map[string]graph.Value) map[string]serializedValue {
+	sProps := make(map[string]serializedValue, len(props))
+	for k, v := range props {
+		sProps[k] = marshalValue(v)
+	}
+	return sProps
+}
+
 func toSerializedGraph(g graph.ProbabilisticGraphModel) serializedGraph {
 	nodes := g.GetNodes()
 	edges := g.GetEdges()
 
+	var sGroups []serializedGroup
+	for _, group := range g.GetGroups() {
+		sGroups = append(sGroups, serializedGroup{Name: group.Name, Props: marshalProps(group.DefaultProps)})
+	}
+
 	sNodes := make([]serializedNode, 0, len(nodes))
 	for _, n := range nodes {
-		sProps := make(map[string]serializedValue, len(n.Props))
-		for k, v := range n.Props {
-			sProps[k] = marshalValue(v)
+		sn := serializedNode{ID: string(n.ID), Props: marshalProps(n.Props)}
+		if n.Group != nil {
+			sn.Group = n.Group.Name
 		}
-		sNodes = append(sNodes, serializedNode{ID: string(n.ID), Props: sProps})
+		sNodes = append(sNodes, sn)
 	}
 
 	sEdges := make([]serializedEdge, 0, len(edges))
 	for _, e := range edges {
+		// The reverse half of a bidirectional edge is recreated on load.
+		if e.IsReverseHalf() {
+			continue
+		}
+
 		sProps := make(map[string]serializedValue, len(e.Props))
 		for k, v := range e.Props {
 			sProps[k] = marshalValue(v)
 		}
 		sEdges = append(sEdges, serializedEdge{
-			ID:          string(e.ID),
-			From:        string(e.From),
-			To:          string(e.To),
-			Probability: e.Probability,
-			Props:       sProps,
+			ID:            string(e.ID),
+			From:          string(e.From),
+			To:            string(e.To),
+			Probability:   e.Probability,
+			Props:         sProps,
+			Bidirectional: e.Bidirectional,
+			ProbLow:       e.ProbLow,
+			ProbHigh:      e.ProbHigh,
 		})
 	}
 
-	return serializedGraph{Nodes: sNodes, Edges: sEdges}
+	return serializedGraph{Version: FormatVersion, Groups: sGroups, Nodes: sNodes, Edges: sEdges}
 }
 
 func fromSerializedGraph(sg serializedGraph) (*graph.ProbabilisticAdjacencyListGraph, error) {
 	g := graph.CreateProbAdjListGraph()
 
+	for _, sgr := range sg.Groups {
+		props := make(map[string]graph.Value, len(sgr.Props))
+		for k, sv := range sgr.Props {
+			v, err := unmarshalValue(sv)
+			if err != nil {
+				return nil, fmt.Errorf("group %s prop %s: %w", sgr.Name, k, err)
+			}
+			props[k] = v
+		}
+		if err := g.AddGroup(sgr.Name, props); err != nil {
+			return nil, fmt.Errorf("adding group %s: %w", sgr.Name, err)
+		}
+	}
+
 	for _, sn := range sg.Nodes {
 		props := make(map[string]graph.Value, len(sn.Props))
 		for k, sv := range sn.Props {
@@ -140,6 +282,11 @@ func fromSerializedGraph(sg serializedGraph) (*graph.ProbabilisticAdjacencyListG
 		if err := g.AddNode(graph.NodeID(sn.ID), props); err != nil {
 			return nil, fmt.Errorf("adding node %s: %w", sn.ID, err)
 		}
+		if sn.Group != "" {
+			if err := g.SetNodeGroup(graph.NodeID(sn.ID), sn.Group); err != nil {
+				return nil, fmt.Errorf("node %s: %w", sn.ID, err)
+			}
+		}
 	}
 
 	for _, se := range sg.Edges {
@@ -151,7 +298,11 @@ func fromSerializedGraph(sg serializedGraph) (*graph.ProbabilisticAdjacencyListG
 			}
 			props[k] = v
 		}
-		if err := g.AddEdge(
+		addEdge := g.AddEdge
+		if se.Bidirectional {
+			addEdge = g.AddBidirectionalEdge
+		}
+		if err := addEdge(
 			graph.EdgeID(se.ID),
 			graph.NodeID(se.From),
 			graph.NodeID(se.To),
@@ -160,6 +311,14 @@ func fromSerializedGraph(sg serializedGraph) (*graph.ProbabilisticAdjacencyListG
 		); err != nil {
 			return nil, fmt.Errorf("adding edge %s: %w", se.ID, err)
 		}
+		if err := graph.CopyProbabilityInterval(g, &graph.Edge{
+			ID:            graph.EdgeID(se.ID),
+			Bidirectional: se.Bidirectional,
+			ProbLow:       se.ProbLow,
+			ProbHigh:      se.ProbHigh,
+		}); err != nil {
+			return nil, fmt.Errorf("edge %s probability interval: %w", se.ID, err)
+		}
 	}
 
 	return g, nil
@@ -167,36 +326,104 @@ func fromSerializedGraph(sg serializedGraph) (*graph.ProbabilisticAdjacencyListG
 
 // WriteJSON encodes a graph to JSON and writes it to w.
 func WriteJSON(g graph.ProbabilisticGraphModel, w io.Writer) error {
+	return WriteJSONWithSchema(g, nil, w)
+}
+
+// WriteJSONWithSchema encodes a graph and an optional schema to JSON and
+// writes it to w. A nil schema is omitted from the output. The output
+// carries a checksum of its content, which reading verifies.
+func WriteJSONWithSchema(g graph.ProbabilisticGraphModel, s *schema.Schema, w io.Writer) error {
+	sg := toSerializedGraph(g)
+	sg.Schema = toSerializedSchema(s)
+	sg, err := withChecksum(sg)
+	if err != nil {
+		return err
+	}
+
 	enc := json.NewEncoder(w)
 	enc.SetIndent("", "  ")
-	return enc.Encode(toSerializedGraph(g))
+	return enc.Encode(sg)
 }
 
 // ReadJSON decodes a graph from JSON read from r.
 func ReadJSON(r io.Reader) (*graph.ProbabilisticAdjacencyListGraph, error) {
+	g, _, err := ReadJSONWithSchema(r)
+	return g, err
+}
+
+// ReadOptions adjusts how ReadJSONWithOptions reads a document.
+type ReadOptions struct {
+	// SkipChecksum loads documents without verifying their "checksum",
+	// which saves hashing them.
+	SkipChecksum bool
+}
+
+// ReadJSONWithSchema decodes a graph and its optional schema from JSON read
+// from r. The returned schema is nil if the document has no "schema" key.
+// A document with a "checksum" is verified before it is decoded, and a
+// mismatch is returned as a ChecksumMismatchError. Documents in an older
+// format are upgraded with Migrate.
+func ReadJSONWithSchema(r io.Reader) (*graph.ProbabilisticAdjacencyListGraph, *schema.Schema, error) {
+	return ReadJSONWithOptions(r, ReadOptions{})
+}
+
+// ReadJSONWithOptions is ReadJSONWithSchema with options.
+func ReadJSONWithOptions(r io.Reader, opts ReadOptions) (*graph.ProbabilisticAdjacencyListGraph, *schema.Schema, error) {
+	var raw json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, nil, fmt.Errorf("decoding graph JSON: %w", err)
+	}
+	if !opts.SkipChecksum {
+		if err := verifyChecksum(raw); err != nil {
+			return nil, nil, err
+		}
+	}
+	raw, err := Migrate(raw)
+	if err != nil {
+		return nil, nil, err
+	}
 	var sg serializedGraph
-	if err := json.NewDecoder(r).Decode(&sg); err != nil {
-		return nil, fmt.Errorf("decoding graph JSON: %w", err)
+	if err := json.Unmarshal(raw, &sg); err != nil {
+		return nil, nil, fmt.Errorf("decoding graph JSON: %w", err)
+	}
+	s, err := fromSerializedSchema(sg.Schema)
+	if err != nil {
+		return nil, nil, err
+	}
+	g, err := fromSerializedGraph(sg)
+	if err != nil {
+		return nil, nil, err
 	}
-	return fromSerializedGraph(sg)
+	return g, s, nil
 }
 
 // SaveJSON writes a graph to a JSON file at path.
 func SaveJSON(g graph.ProbabilisticGraphModel, path string) error {
+	return SaveJSONWithSchema(g, nil, path)
+}
+
+// SaveJSONWithSchema writes a graph and an optional schema to a JSON file at path.
+func SaveJSONWithSchema(g graph.ProbabilisticGraphModel, s *schema.Schema, path string) error {
 	f, err := os.Create(path)
 	if err != nil {
 		return fmt.Errorf("creating file %s: %w", path, err)
 	}
 	defer f.Close()
-	return WriteJSON(g, f)
+	return WriteJSONWithSchema(g, s, f)
 }
 
 // LoadJSON reads a graph from a JSON file at path.
 func LoadJSON(path string) (*graph.ProbabilisticAdjacencyListGraph, error) {
+	g, _, err := LoadJSONWithSchema(path)
+	return g, err
+}
+
+// LoadJSONWithSchema reads a graph and its optional schema from a JSON file at path.
+func LoadJSONWithSchema(path string) (*graph.ProbabilisticAdjacencyListGraph, *schema.Schema, error) {
 	f, err := os.Open(path)
 	if err != nil {
-		return nil, fmt.Errorf("opening file %s: %w", path, err)
+		return nil, nil, fmt.Errorf("opening file %s: %w", path, err)
 	}
 	defer f.Close()
-	return ReadJSON(f)
+	return ReadJSONWithSchema(f)
 }
@@ -2,6 +2,7 @@ package serialization
 
 import (
 	"bytes"
+	"fmt"
 	"math"
 	"os"
 	"path/filepath"
@@ -9,9 +10,10 @@ import (
 	"testing"
 
 	"github.com/ritamzico/pgraph/internal/graph"
+	"github.com/ritamzico/pgraph/internal/schema"
 )
 
-func buildGraph(t *testing.T, nodes []nodeDesc, edges []edgeDesc) *graph.ProbabilisticAdjacencyListGraph {
+func buildGraph(t testing.TB, nodes []nodeDesc, edges []edgeDesc) *graph.ProbabilisticAdjacencyListGraph {
 	t.Helper()
 	g := graph.CreateProbAdjListGraph()
 	for _, n := range nodes {
@@ -78,7 +80,7 @@ func assertNodeProp(t *testing.T, g *graph.ProbabilisticAdjacencyListGraph, node
 	nodes := g.GetNodes()
 	for _, n := range nodes {
 		if string(n.ID) == nodeID {
-			got, ok := n.Props[key]
+			got, ok := n.Prop(key)
 			if !ok {
 				t.Errorf("node %s: missing prop %q", nodeID, key)
 				return
@@ -132,6 +134,15 @@ func assertValuesEqual(t *testing.T, label string, got, want graph.Value) {
 			t.Errorf("prop %s: bool value = %v, want %v", label, got.B, want.B)
 		}
 		return
+	case graph.ArrayVal:
+		if len(got.A) != len(want.A) {
+			t.Errorf("prop %s: array length = %d, want %d", label, len(got.A), len(want.A))
+			return
+		}
+		for i := range got.A {
+			assertValuesEqual(t, fmt.Sprintf("%s[%d]", label, i), got.A[i], want.A[i])
+		}
+		return
 	}
 }
 
@@ -185,6 +196,31 @@ func TestRoundTripSimpleGraph(t *testing.T) {
 	assertEdgeExists(t, got, "b", "c", 0.5)
 }
 
+func TestRoundTripProbabilityIntervals(t *testing.T) {
+	g := buildGraph(t,
+		[]nodeDesc{{id: "a"}, {id: "b"}, {id: "c"}},
+		[]edgeDesc{
+			{id: "e1", from: "a", to: "b", prob: 0.5},
+			{id: "e2", from: "b", to: "c", prob: 0.5},
+		},
+	)
+	if err := g.UpdateEdgeInterval("e1", 0, 0.4); err != nil {
+		t.Fatalf("UpdateEdgeInterval failed: %v", err)
+	}
+	got := roundTrip(t, g)
+
+	if !graph.Equal(g, got) {
+		t.Error("round trip changed the graph")
+	}
+	e1, _ := got.GetEdgeByID("e1")
+	if low, high := e1.ProbabilityBounds(); low != 0 || high != 0.4 {
+		t.Errorf("expected interval [0, 0.4], got [%g, %g]", low, high)
+	}
+	if e2, _ := got.GetEdgeByID("e2"); e2.HasProbabilityInterval() {
+		t.Error("expected e2 to keep a point probability")
+	}
+}
+
 func TestRoundTripAllPropertyTypes(t *testing.T) {
 	g := buildGraph(t,
 		[]nodeDesc{{
@@ -206,6 +242,53 @@ func TestRoundTripAllPropertyTypes(t *testing.T) {
 	assertNodeProp(t, got, "n1", "enabled", graph.Value{Kind: graph.BoolVal, B: true})
 }
 
+func TestRoundTripArrayProperties(t *testing.T) {
+	floats := graph.Value{Kind: graph.ArrayVal, A: []graph.Value{
+		{Kind: graph.FloatVal, F: 0.1},
+		{Kind: graph.FloatVal, F: 0.2},
+	}}
+	tags := graph.Value{Kind: graph.ArrayVal, A: []graph.Value{
+		{Kind: graph.StringVal, S: "rail"},
+		{Kind: graph.StringVal, S: "sea"},
+	}}
+	empty := graph.Value{Kind: graph.ArrayVal, A: []graph.Value{}}
+
+	g := buildGraph(t,
+		[]nodeDesc{
+			{id: "a", props: map[string]graph.Value{"features": floats, "none": empty}},
+			{id: "b"},
+		},
+		[]edgeDesc{{id: "e1", from: "a", to: "b", prob: 0.5, props: map[string]graph.Value{"modes": tags}}},
+	)
+	got := roundTrip(t, g)
+
+	assertNodeProp(t, got, "a", "features", floats)
+	assertNodeProp(t, got, "a", "none", empty)
+	assertEdgeProp(t, got, "a", "b", "modes", tags)
+}
+
+func TestRoundTripNullProperty(t *testing.T) {
+	null := graph.Value{Kind: graph.NullVal}
+	g := buildGraph(t,
+		[]nodeDesc{{id: "a", props: map[string]graph.Value{"owner": null}}},
+		nil,
+	)
+
+	var buf bytes.Buffer
+	if err := WriteJSON(g, &buf); err != nil {
+		t.Fatalf("WriteJSON: %v", err)
+	}
+	if !strings.Contains(buf.String(), `"kind": "null"`) {
+		t.Errorf("expected null kind in output, got:\n%s", buf.String())
+	}
+
+	got, err := ReadJSON(&buf)
+	if err != nil {
+		t.Fatalf("ReadJSON: %v", err)
+	}
+	assertNodeProp(t, got, "a", "owner", null)
+}
+
 func TestRoundTripEdgeProperties(t *testing.T) {
 	g := buildGraph(t,
 		[]nodeDesc{{id: "a"}, {id: "b"}},
@@ -613,6 +696,22 @@ func TestReadJSONStringPropertyWrongType(t *testing.T) {
 	}
 }
 
+func TestReadJSONArrayPropertyWrongType(t *testing.T) {
+	input := `{"nodes": [{"id": "a", "props": {"x": {"kind": "array", "value": 1}}}], "edges": []}`
+	_, err := ReadJSON(strings.NewReader(input))
+	if err == nil {
+		t.Error("expected error for array property with non-array value")
+	}
+}
+
+func TestReadJSONHeterogeneousArray(t *testing.T) {
+	input := `{"nodes": [{"id": "a", "props": {"x": {"kind": "array", "value": [{"kind": "int", "value": 1}, {"kind": "string", "value": "b"}]}}}], "edges": []}`
+	_, err := ReadJSON(strings.NewReader(input))
+	if err == nil {
+		t.Error("expected error for array with mixed element kinds")
+	}
+}
+
 func TestReadJSONEdgeInvalidPropertyType(t *testing.T) {
 	input := `{
 		"nodes": [{"id": "a"}, {"id": "b"}],
@@ -634,6 +733,7 @@ func TestMarshalValueAllKinds(t *testing.T) {
 		{"float", graph.Value{Kind: graph.FloatVal, F: 2.5}, serializedValue{Kind: "float", Value: 2.5}},
 		{"string", graph.Value{Kind: graph.StringVal, S: "hi"}, serializedValue{Kind: "string", Value: "hi"}},
 		{"bool", graph.Value{Kind: graph.BoolVal, B: true}, serializedValue{Kind: "bool", Value: true}},
+		{"null", graph.Value{Kind: graph.NullVal}, serializedValue{Kind: "null"}},
 	}
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -663,6 +763,7 @@ func TestUnmarshalValueRoundTrips(t *testing.T) {
 		{"string", serializedValue{Kind: "string", Value: "test"}, graph.Value{Kind: graph.StringVal, S: "test"}},
 		{"bool_true", serializedValue{Kind: "bool", Value: true}, graph.Value{Kind: graph.BoolVal, B: true}},
 		{"bool_false", serializedValue{Kind: "bool", Value: false}, graph.Value{Kind: graph.BoolVal, B: false}},
+		{"null", serializedValue{Kind: "null"}, graph.Value{Kind: graph.NullVal}},
 	}
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -677,6 +778,61 @@ func TestUnmarshalValueRoundTrips(t *testing.T) {
 	}
 }
 
+// --- Schema tests ---
+
+func TestRoundTripSchema(t *testing.T) {
+	g := buildGraph(t, []nodeDesc{{id: "a"}}, nil)
+	s := &schema.Schema{
+		NodeProps: map[string]graph.ValueKind{"region": graph.StringVal, "tags": graph.ArrayVal},
+		EdgeProps: map[string]graph.ValueKind{},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteJSONWithSchema(g, s, &buf); err != nil {
+		t.Fatalf("WriteJSONWithSchema: %v", err)
+	}
+	_, got, err := ReadJSONWithSchema(&buf)
+	if err != nil {
+		t.Fatalf("ReadJSONWithSchema: %v", err)
+	}
+
+	if got == nil {
+		t.Fatal("expected schema to be read back")
+	}
+	if got.NodeProps["region"] != graph.StringVal || got.NodeProps["tags"] != graph.ArrayVal {
+		t.Errorf("node props not preserved: %v", got.NodeProps)
+	}
+	if got.EdgeProps == nil || len(got.EdgeProps) != 0 {
+		t.Errorf("expected empty non-nil edge props, got %v", got.EdgeProps)
+	}
+}
+
+func TestWriteJSONOmitsNilSchema(t *testing.T) {
+	g := buildGraph(t, []nodeDesc{{id: "a"}}, nil)
+	var buf bytes.Buffer
+	if err := WriteJSON(g, &buf); err != nil {
+		t.Fatalf("WriteJSON: %v", err)
+	}
+	if strings.Contains(buf.String(), `"schema"`) {
+		t.Errorf("expected no schema key, got:\n%s", buf.String())
+	}
+
+	_, s, err := ReadJSONWithSchema(&buf)
+	if err != nil {
+		t.Fatalf("ReadJSONWithSchema: %v", err)
+	}
+	if s != nil {
+		t.Errorf("expected nil schema, got %+v", s)
+	}
+}
+
+func TestReadJSONUnknownSchemaKind(t *testing.T) {
+	input := `{"schema": {"nodes": {"x": "complex"}}, "nodes": [], "edges": []}`
+	if _, err := ReadJSON(strings.NewReader(input)); err == nil {
+		t.Error("expected error for unknown schema value kind")
+	}
+}
+
 // --- File I/O tests ---
 
 func TestSaveAndLoadJSON(t *testing.T) {
@@ -840,3 +996,56 @@ func TestRoundTripSmallProbability(t *testing.T) {
 	got := roundTrip(t, g)
 	assertEdgeExists(t, got, "a", "b", 1e-15)
 }
+
+func TestRoundTripBidirectionalEdge(t *testing.T) {
+	g := buildGraph(t, []nodeDesc{{id: "a"}, {id: "b"}}, nil)
+	if err := g.AddBidirectionalEdge("e1", "a", "b", 0.6, nil); err != nil {
+		t.Fatalf("AddBidirectionalEdge: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteJSON(g, &buf); err != nil {
+		t.Fatalf("WriteJSON: %v", err)
+	}
+	if strings.Contains(buf.String(), graph.ReverseEdgeSuffix) {
+		t.Errorf("reverse half should not be serialized: %s", buf.String())
+	}
+
+	got, err := ReadJSON(&buf)
+	if err != nil {
+		t.Fatalf("ReadJSON: %v", err)
+	}
+	assertEdgeExists(t, got, "a", "b", 0.6)
+	assertEdgeExists(t, got, "b", "a", 0.6)
+	if !got.ContainsBidirectionalEdge("e1") {
+		t.Error("expected e1 to be bidirectional after round trip")
+	}
+}
+
+func TestRoundTripGroups(t *testing.T) {
+	g := buildGraph(t, []nodeDesc{{id: "a"}, {id: "b"}, {id: "c"}}, nil)
+	if err := g.AddGroup("us", map[string]graph.Value{"currency": {Kind: graph.StringVal, S: "USD"}}); err != nil {
+		t.Fatalf("AddGroup: %v", err)
+	}
+	if err := g.AddGroup("empty", nil); err != nil {
+		t.Fatalf("AddGroup: %v", err)
+	}
+	for _, id := range []graph.NodeID{"a", "b"} {
+		if err := g.SetNodeGroup(id, "us"); err != nil {
+			t.Fatalf("SetNodeGroup(%s): %v", id, err)
+		}
+	}
+	got := roundTrip(t, g)
+
+	if !graph.Equal(g, got) {
+		t.Error("expected groups to survive a round trip")
+	}
+	assertNodeProp(t, got, "a", "currency", graph.Value{Kind: graph.StringVal, S: "USD"})
+}
+
+func TestReadJSONUnknownGroup(t *testing.T) {
+	input := `{"nodes": [{"id": "a", "group": "missing"}], "edges": []}`
+	if _, err := ReadJSON(strings.NewReader(input)); err == nil {
+		t.Error("expected error for a node in an undeclared group")
+	}
+}
@@ -0,0 +1,50 @@
+package serialization
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// FormatVersion is the "pgraph_version" written by WriteJSON.
+const FormatVersion = "1.0"
+
+// Migrate upgrades a serialized graph to FormatVersion. A document without a
+// "pgraph_version" key is version 0, written before the key existed; it is
+// given the key and empty "nodes" and "edges" arrays where they are missing.
+// A current document is returned unchanged, and a newer or unknown version
+// is an error.
+func Migrate(old json.RawMessage) (json.RawMessage, error) {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(old, &doc); err != nil {
+		return nil, fmt.Errorf("decoding graph JSON: %w", err)
+	}
+
+	version := ""
+	if raw, ok := doc["pgraph_version"]; ok {
+		if err := json.Unmarshal(raw, &version); err != nil {
+			return nil, fmt.Errorf("pgraph_version: expected string, got %s", raw)
+		}
+	}
+
+	switch version {
+	case FormatVersion:
+		return old, nil
+	case "":
+		return migrateV0(doc)
+	default:
+		return nil, fmt.Errorf("unsupported pgraph_version %q; this build reads up to %s", version, FormatVersion)
+	}
+}
+
+func migrateV0(doc map[string]json.RawMessage) (json.RawMessage, error) {
+	if doc == nil {
+		doc = make(map[string]json.RawMessage)
+	}
+	doc["pgraph_version"] = json.RawMessage(`"` + FormatVersion + `"`)
+	for _, key := range []string{"nodes", "edges"} {
+		if raw, ok := doc[key]; !ok || string(raw) == "null" {
+			doc[key] = json.RawMessage(`[]`)
+		}
+	}
+	return json.Marshal(doc)
+}
@@ -0,0 +1,83 @@
+package serialization
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestLoadV0Fixture(t *testing.T) {
+	g, s, err := LoadJSONWithSchema("testdata/v0_graph.json")
+	if err != nil {
+		t.Fatalf("LoadJSONWithSchema: %v", err)
+	}
+	for _, id := range []string{"supplier", "factory", "port"} {
+		assertNodeExists(t, g, id)
+	}
+	if n := len(g.GetEdges()); n != 3 {
+		t.Errorf("expected 3 edges including the bidirectional twin, got %d", n)
+	}
+	if s == nil || s.NodeProps["region"].String() != "string" {
+		t.Errorf("expected the schema to load, got %+v", s)
+	}
+}
+
+func TestMigrateV0(t *testing.T) {
+	out, err := Migrate(json.RawMessage(`{"nodes": [{"id": "a"}]}`))
+	if err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatalf("Migrate produced invalid JSON %s: %v", out, err)
+	}
+	if v := string(doc["pgraph_version"]); v != `"`+FormatVersion+`"` {
+		t.Errorf("expected pgraph_version %q, got %s", FormatVersion, v)
+	}
+	if e := string(doc["edges"]); e != "[]" {
+		t.Errorf("expected missing edges to default to [], got %s", e)
+	}
+	if n := string(doc["nodes"]); n != `[{"id":"a"}]` {
+		t.Errorf("expected nodes to be kept, got %s", n)
+	}
+
+	// A migrated document is current, so migrating again changes nothing.
+	again, err := Migrate(out)
+	if err != nil || !bytes.Equal(again, out) {
+		t.Errorf("expected current document unchanged, got %s, %v", again, err)
+	}
+}
+
+func TestMigrateRejectsUnknownVersion(t *testing.T) {
+	for _, input := range []string{
+		`{"pgraph_version": "2.0", "nodes": []}`,
+		`{"pgraph_version": 1, "nodes": []}`,
+		`[]`,
+	} {
+		if _, err := Migrate(json.RawMessage(input)); err == nil {
+			t.Errorf("expected error migrating %s", input)
+		}
+		if _, err := ReadJSON(strings.NewReader(input)); err == nil {
+			t.Errorf("expected ReadJSON to reject %s", input)
+		}
+	}
+}
+
+func TestWriteJSONWritesVersion(t *testing.T) {
+	g := buildGraph(t, []nodeDesc{{id: "a"}}, nil)
+
+	var buf bytes.Buffer
+	if err := WriteJSON(g, &buf); err != nil {
+		t.Fatalf("WriteJSON: %v", err)
+	}
+	var doc struct {
+		Version string `json:"pgraph_version"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if doc.Version != FormatVersion {
+		t.Errorf("expected pgraph_version %q, got %q", FormatVersion, doc.Version)
+	}
+}
@@ -0,0 +1,11 @@
+// Package metrics exports pgraph query and graph metrics to a Prometheus
+// registry.
+//
+// The package depends on github.com/prometheus/client_golang and is only
+// built with the pgraph_metrics build tag:
+//
+//	go build -tags pgraph_metrics ./...
+//
+// Without the tag the package is empty, so programs that don't use it do not
+// pull in the Prometheus client.
+package metrics
@@ -0,0 +1,107 @@
+//go:build pgraph_metrics
+
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/ritamzico/pgraph"
+)
+
+// InstrumentedPGraph wraps a PGraph and records metrics for every query run
+// through Query or QueryContext. Other PGraph methods are promoted unchanged.
+type InstrumentedPGraph struct {
+	*pgraph.PGraph
+
+	queries  *prometheus.CounterVec
+	duration prometheus.Histogram
+}
+
+// NewInstrumentedPGraph registers pgraph's collectors with reg and returns pg
+// wrapped to feed them. It panics if reg already holds collectors with the
+// same names, as prometheus.Registerer.MustRegister does.
+//
+// The following metrics are registered:
+//
+//	pgraph_queries_total{status="ok"|"error"}  counter
+//	pgraph_query_duration_seconds               histogram
+//	pgraph_graph_nodes                          gauge
+//	pgraph_graph_edges                          gauge
+//	pgraph_query_cache_hits_total               counter
+//	pgraph_query_cache_misses_total             counter
+//
+// The graph gauges are computed from pg's current graph at scrape time.
+// The cache counters read pg.QueryCacheStats, and stay at zero unless
+// pg.EnableQueryCache has been called.
+func NewInstrumentedPGraph(pg *pgraph.PGraph, reg prometheus.Registerer) *InstrumentedPGraph {
+	ip := &InstrumentedPGraph{
+		PGraph: pg,
+		queries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "pgraph",
+			Name:      "queries_total",
+			Help:      "Number of DSL statements and queries run, by outcome.",
+		}, []string{"status"}),
+		duration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: "pgraph",
+			Name:      "query_duration_seconds",
+			Help:      "Time taken to parse and run DSL statements and queries.",
+			Buckets:   prometheus.DefBuckets,
+		}),
+	}
+
+	nodes := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: "pgraph",
+		Name:      "graph_nodes",
+		Help:      "Number of nodes in the graph.",
+	}, func() float64 { return float64(pg.Stats().NodeCount) })
+	edges := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: "pgraph",
+		Name:      "graph_edges",
+		Help:      "Number of edges in the graph.",
+	}, func() float64 { return float64(pg.Stats().EdgeCount) })
+
+	hits := prometheus.NewCounterFunc(prometheus.CounterOpts{
+		Namespace: "pgraph",
+		Name:      "query_cache_hits_total",
+		Help:      "Number of queries answered from the query cache.",
+	}, func() float64 {
+		hits, _ := pg.QueryCacheStats()
+		return float64(hits)
+	})
+	misses := prometheus.NewCounterFunc(prometheus.CounterOpts{
+		Namespace: "pgraph",
+		Name:      "query_cache_misses_total",
+		Help:      "Number of cacheable queries the query cache had to run.",
+	}, func() float64 {
+		_, misses := pg.QueryCacheStats()
+		return float64(misses)
+	})
+
+	reg.MustRegister(ip.queries, ip.duration, nodes, edges, hits, misses)
+	return ip
+}
+
+// Query runs a DSL statement or query and records its outcome and duration.
+//
+// Deprecated: Query cannot be cancelled; use QueryContext.
+func (ip *InstrumentedPGraph) Query(dslQuery string) (pgraph.Result, error) {
+	return ip.QueryContext(context.Background(), dslQuery)
+}
+
+// QueryContext runs a DSL statement or query and records its outcome and
+// duration.
+func (ip *InstrumentedPGraph) QueryContext(ctx context.Context, dslQuery string) (pgraph.Result, error) {
+	start := time.Now()
+	res, err := ip.PGraph.QueryContext(ctx, dslQuery)
+	ip.duration.Observe(time.Since(start).Seconds())
+
+	status := "ok"
+	if err != nil {
+		status = "error"
+	}
+	ip.queries.WithLabelValues(status).Inc()
+
+	return res, err
+}
@@ -0,0 +1,124 @@
+//go:build pgraph_metrics
+
+package metrics
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/ritamzico/pgraph"
+)
+
+func newTestInstrumented(t *testing.T) (*InstrumentedPGraph, *prometheus.Registry) {
+	t.Helper()
+	reg := prometheus.NewRegistry()
+	ip := NewInstrumentedPGraph(pgraph.New(), reg)
+	for _, line := range []string{
+		"CREATE NODE A, B, C",
+		"CREATE EDGE eAB FROM A TO B PROB 0.9",
+	} {
+		if _, err := ip.Query(line); err != nil {
+			t.Fatalf("Query(%q): %v", line, err)
+		}
+	}
+	return ip, reg
+}
+
+func TestInstrumentedPGraph_CountsQueriesByStatus(t *testing.T) {
+	ip, _ := newTestInstrumented(t)
+
+	if _, err := ip.Query("MAXPATH FROM A TO B"); err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if _, err := ip.Query("MAXPATH FROM A TO"); err == nil {
+		t.Fatal("expected syntax error")
+	}
+
+	if got := testutil.ToFloat64(ip.queries.WithLabelValues("ok")); got != 3 {
+		t.Errorf("ok queries = %v, want 3", got)
+	}
+	if got := testutil.ToFloat64(ip.queries.WithLabelValues("error")); got != 1 {
+		t.Errorf("error queries = %v, want 1", got)
+	}
+}
+
+func TestInstrumentedPGraph_RecordsDuration(t *testing.T) {
+	_, reg := newTestInstrumented(t)
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("Gather: %v", err)
+	}
+	for _, mf := range families {
+		if mf.GetName() != "pgraph_query_duration_seconds" {
+			continue
+		}
+		if got := mf.GetMetric()[0].GetHistogram().GetSampleCount(); got != 2 {
+			t.Errorf("duration sample count = %d, want 2", got)
+		}
+		return
+	}
+	t.Fatal("pgraph_query_duration_seconds not registered")
+}
+
+func TestInstrumentedPGraph_GraphGaugesFollowMutations(t *testing.T) {
+	ip, reg := newTestInstrumented(t)
+
+	if err := testutil.GatherAndCompare(reg, gaugeExposition(3, 1), "pgraph_graph_nodes", "pgraph_graph_edges"); err != nil {
+		t.Error(err)
+	}
+
+	if _, err := ip.Query("DELETE NODE C"); err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if err := testutil.GatherAndCompare(reg, gaugeExposition(2, 1), "pgraph_graph_nodes", "pgraph_graph_edges"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestInstrumentedPGraph_QueryCacheCounters(t *testing.T) {
+	ip, reg := newTestInstrumented(t)
+	ip.EnableQueryCache(0, 0)
+
+	for range 3 {
+		if _, err := ip.Query("MAXPATH FROM A TO B"); err != nil {
+			t.Fatalf("Query: %v", err)
+		}
+	}
+
+	want := strings.NewReader(`# HELP pgraph_query_cache_hits_total Number of queries answered from the query cache.
+# TYPE pgraph_query_cache_hits_total counter
+pgraph_query_cache_hits_total 2
+# HELP pgraph_query_cache_misses_total Number of cacheable queries the query cache had to run.
+# TYPE pgraph_query_cache_misses_total counter
+pgraph_query_cache_misses_total 1
+`)
+	if err := testutil.GatherAndCompare(reg, want, "pgraph_query_cache_hits_total", "pgraph_query_cache_misses_total"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestNewInstrumentedPGraph_DuplicateRegistrationPanics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	NewInstrumentedPGraph(pgraph.New(), reg)
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic on duplicate registration")
+		}
+	}()
+	NewInstrumentedPGraph(pgraph.New(), reg)
+}
+
+func gaugeExposition(nodes, edges int) *strings.Reader {
+	return strings.NewReader(fmt.Sprintf(`# HELP pgraph_graph_edges Number of edges in the graph.
+# TYPE pgraph_graph_edges gauge
+pgraph_graph_edges %d
+# HELP pgraph_graph_nodes Number of nodes in the graph.
+# TYPE pgraph_graph_nodes gauge
+pgraph_graph_nodes %d
+`, edges, nodes))
+}
@@ -1,31 +1,84 @@
 package pgraph
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"time"
 
 	"github.com/ritamzico/pgraph/internal/dsl"
 	"github.com/ritamzico/pgraph/internal/graph"
+	"github.com/ritamzico/pgraph/internal/inference"
+	"github.com/ritamzico/pgraph/internal/query"
 	"github.com/ritamzico/pgraph/internal/result"
+	"github.com/ritamzico/pgraph/internal/schema"
 	"github.com/ritamzico/pgraph/internal/serialization"
 )
 
 type (
-	Result              = result.Result
-	PathResult          = result.PathResult
-	PathsResult         = result.PathsResult
-	ProbabilityResult   = result.ProbabilityResult
-	SampleResult        = result.SampleResult
-	MultiResult         = result.MultiResult
-	BooleanResult       = result.BooleanResult
-	SensitivityResult   = result.SensitivityResult
-	EdgeImpact          = result.EdgeImpact
+	Result                    = result.Result
+	IntervalResult            = result.IntervalResult
+	PathResult                = result.PathResult
+	PathsResult               = result.PathsResult
+	ProbabilityResult         = result.ProbabilityResult
+	ProbabilityIntervalResult = result.ProbabilityIntervalResult
+	SampleResult              = result.SampleResult
+	MultiResult               = result.MultiResult
+	BooleanResult             = result.BooleanResult
+	SensitivityResult         = result.SensitivityResult
+	EdgeImpact                = result.EdgeImpact
+	NodeListResult            = result.NodeListResult
+	EdgeListResult            = result.EdgeListResult
+	GraphResult               = result.GraphResult
+	HistogramResult           = result.HistogramResult
+	BucketResult              = result.BucketResult
+	NodeScoresResult          = result.NodeScoresResult
+	EdgeScoresResult          = result.EdgeScoresResult
+	PolynomialResult          = result.PolynomialResult
+	PolynomialPoint           = result.PolynomialPoint
+	MatrixResult              = result.MatrixResult
+	ComparisonResult          = result.ComparisonResult
+	SampledWorldResult        = result.SampledWorldResult
+	NumberResult              = result.NumberResult
+	ValuesResult              = result.ValuesResult
+	StringResult              = result.StringResult
+	ErrorResult               = result.ErrorResult
 )
 
+type (
+	Schema                = schema.Schema
+	SchemaError           = schema.SchemaError
+	SyntaxError           = dsl.SyntaxError
+	ChecksumMismatchError = serialization.ChecksumMismatchError
+	AssertionError        = query.AssertionError
+	LoadOptions           = serialization.ReadOptions
+	GraphStats            = graph.GraphStats
+	ValueKind             = graph.ValueKind
+	Value                 = graph.Value
+	NodeID                = graph.NodeID
+	EdgeID                = graph.EdgeID
+)
+
+const (
+	IntVal    = graph.IntVal
+	FloatVal  = graph.FloatVal
+	StringVal = graph.StringVal
+	BoolVal   = graph.BoolVal
+	ArrayVal  = graph.ArrayVal
+	NullVal   = graph.NullVal
+)
+
+// PGraph is a probabilistic graph with a DSL session. It is not safe for
+// concurrent use: callers that share one between goroutines must serialize
+// queries, mutations and transaction commits themselves.
 type PGraph struct {
 	Graph  graph.ProbabilisticGraphModel
 	parser dsl.Parser
+	schema *schema.Schema
+	hooks  hooks
 }
 
 func New() *PGraph {
@@ -37,37 +90,216 @@ func New() *PGraph {
 }
 
 func Load(r io.Reader) (*PGraph, error) {
-	g, err := serialization.ReadJSON(r)
+	return LoadWithOptions(r, LoadOptions{})
+}
+
+// LoadWithOptions is Load with options, such as skipping checksum
+// verification for fast bulk loading.
+func LoadWithOptions(r io.Reader, opts LoadOptions) (*PGraph, error) {
+	g, s, err := serialization.ReadJSONWithOptions(r, opts)
 	if err != nil {
 		return nil, err
 	}
-	return &PGraph{
-		Graph:  g,
-		parser: dsl.CreateParser(g),
-	}, nil
+	return newLoaded(g, s)
 }
 
 func LoadFile(path string) (*PGraph, error) {
-	g, err := serialization.LoadJSON(path)
+	g, s, err := serialization.LoadJSONWithSchema(path)
 	if err != nil {
 		return nil, err
 	}
-	return &PGraph{
+	return newLoaded(g, s)
+}
+
+// LoadNetworkX loads a graph from NetworkX node_link_data JSON, taking edge
+// probabilities from the link attribute named probabilityKey.
+func LoadNetworkX(r io.Reader, probabilityKey string) (*PGraph, error) {
+	g, err := serialization.ReadNetworkXJSON(r, probabilityKey)
+	if err != nil {
+		return nil, err
+	}
+	return newLoaded(g, nil)
+}
+
+func newLoaded(g graph.ProbabilisticGraphModel, s *schema.Schema) (*PGraph, error) {
+	p := &PGraph{
 		Graph:  g,
 		parser: dsl.CreateParser(g),
-	}, nil
+	}
+	if s != nil {
+		if err := p.SetSchema(*s); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
 }
 
+// Query runs a DSL statement or query.
+//
+// Deprecated: Query cannot be cancelled; use QueryContext.
 func (p *PGraph) Query(dslQuery string) (Result, error) {
-	return p.parser.ParseLine(dslQuery)
+	return p.QueryContext(context.Background(), dslQuery)
+}
+
+// QueryContext runs a DSL statement or query. Cancelling ctx stops a running
+// query, which then returns ctx's error; nothing runs if ctx is already done.
+func (p *PGraph) QueryContext(ctx context.Context, dslQuery string) (Result, error) {
+	return p.parser.ParseLineContext(ctx, dslQuery)
+}
+
+// UnreachableNodes returns, sorted by ID, the nodes that root cannot reach
+// through any path, ignoring edge probabilities.
+func (p *PGraph) UnreachableNodes(root NodeID) ([]NodeID, error) {
+	return inference.UnreachableNodes(p.parser.SessionGraph, root)
+}
+
+// Stats summarises the current graph's size and edge probabilities.
+func (p *PGraph) Stats() GraphStats {
+	return graph.ComputeStats(p.parser.SessionGraph)
+}
+
+// EnablePropertyIndex indexes nodes by the given property keys, so that
+// FIND NODES WHERE <key> = <value> looks matching nodes up instead of
+// scanning the graph. The index follows later mutations, but not a
+// replacement of the graph, e.g. by Load. It fails if the graph type does
+// not support indexes.
+func (p *PGraph) EnablePropertyIndex(keys ...string) error {
+	g := p.parser.SessionGraph
+	if hg, ok := g.(hookedGraph); ok {
+		g = hg.ProbabilisticGraphModel
+	}
+	ig, ok := g.(interface{ EnablePropertyIndex(...string) })
+	if !ok {
+		return fmt.Errorf("graph type %T does not support property indexes", g)
+	}
+	ig.EnablePropertyIndex(keys...)
+	return nil
+}
+
+// SetMinEdgeDensity makes queries using exact inference fail with a
+// LowDensityGraph error when the graph's edge density (edges divided by
+// possible directed edges) is below d. Zero, the default, disables the check.
+func (p *PGraph) SetMinEdgeDensity(d float64) {
+	p.parser.SetMinEdgeDensity(d)
+}
+
+// EnableIncrementalCache makes exact reachability queries, including
+// MATRIX REACHABILITY, reuse earlier results. An edge probability update
+// through UpdateEdge or the DSL's UPDATE EDGE only recomputes the results
+// whose search explored that edge; any other mutation recomputes them all.
+func (p *PGraph) EnableIncrementalCache() {
+	p.parser.EnableIncrementalCache()
+}
+
+// EnableQueryCache memoizes query results, so repeating a query on an
+// unchanged graph returns the earlier result without running inference.
+// Any mutation of the graph makes earlier entries stale. When the cache
+// holds maxEntries entries, expired and then least recently used ones are
+// dropped; a positive ttl also expires entries that old. Zero means no
+// limit for either. Queries without a fixed SEED draw a fresh one each
+// time, so Monte Carlo results are only reused when SEED is given.
+func (p *PGraph) EnableQueryCache(maxEntries int, ttl time.Duration) {
+	p.parser.EnableQueryCache(maxEntries, ttl)
+}
+
+// QueryCacheStats returns how many queries the query cache has answered
+// and how many it has had to run. Both are zero if EnableQueryCache has not
+// been called.
+func (p *PGraph) QueryCacheStats() (hits, misses uint64) {
+	return p.parser.QueryCacheStats()
+}
+
+// Transpose returns a new PGraph holding the current graph with every edge
+// reversed. The receiver is not modified; any attached schema is carried over.
+func (p *PGraph) Transpose() *PGraph {
+	g := graph.Transpose(p.parser.SessionGraph)
+	return &PGraph{
+		Graph:  g,
+		parser: dsl.CreateParser(g),
+		schema: p.schema,
+	}
+}
+
+// Clone returns a new PGraph holding a deep copy of the current graph, so
+// that either can be mutated without affecting the other. Any attached
+// schema is carried over.
+func (p *PGraph) Clone() *PGraph {
+	g := p.parser.SessionGraph.Clone()
+	return &PGraph{
+		Graph:  g,
+		parser: dsl.CreateParser(g),
+		schema: p.schema,
+	}
+}
+
+// SetSchema attaches s to the graph. It fails, leaving any previous schema in
+// place, if the current graph does not conform to s. The schema is written
+// out by Save and SaveFile.
+func (p *PGraph) SetSchema(s Schema) error {
+	if errs := schema.Validate(p.parser.SessionGraph, s); len(errs) > 0 {
+		return fmt.Errorf("graph does not conform to schema (%d violations, first: %w)", len(errs), errs[0])
+	}
+	p.schema = &s
+	return nil
+}
+
+// ValidateSchema checks the current graph against the attached schema and
+// returns all violations. It returns nil if no schema is set.
+func (p *PGraph) ValidateSchema() []SchemaError {
+	if p.schema == nil {
+		return nil
+	}
+	return schema.Validate(p.parser.SessionGraph, *p.schema)
 }
 
 func (p *PGraph) Save(w io.Writer) error {
-	return serialization.WriteJSON(p.parser.SessionGraph, w)
+	return serialization.WriteJSONWithSchema(p.parser.SessionGraph, p.schema, w)
 }
 
 func (p *PGraph) SaveFile(path string) error {
-	return serialization.SaveJSON(p.parser.SessionGraph, path)
+	return serialization.SaveJSONWithSchema(p.parser.SessionGraph, p.schema, path)
+}
+
+// WriteAdjacencyMatrixCSV writes the adjacency matrix of edge probabilities
+// as CSV, with rows and columns in sorted node ID order.
+func (p *PGraph) WriteAdjacencyMatrixCSV(w io.Writer) error {
+	return serialization.WriteAdjacencyMatrixCSV(p.parser.SessionGraph, w)
+}
+
+// WriteAdjacencyMatrixDense writes the adjacency matrix of edge
+// probabilities as a NumPy .npy float64 array, with rows and columns in
+// sorted node ID order.
+func (p *PGraph) WriteAdjacencyMatrixDense(w io.Writer) error {
+	return serialization.WriteAdjacencyMatrixDense(p.parser.SessionGraph, w)
+}
+
+// WriteAdjacencyList writes the graph as text, one "A -> [B(0.9), ...]"
+// line per node, with nodes and neighbours in sorted ID order. A positive
+// maxNodes truncates the listing after that many nodes.
+func (p *PGraph) WriteAdjacencyList(w io.Writer, maxNodes int) error {
+	return serialization.WriteAdjacencyList(p.parser.SessionGraph, w, maxNodes)
+}
+
+// WriteHTML writes the graph as a self-contained HTML page with an SVG
+// drawing: nodes on a circle, edges as arrows labelled with their
+// probability. A positive refresh makes the page reload itself at that
+// interval, so a browser keeps showing the latest file written.
+func (p *PGraph) WriteHTML(w io.Writer, refresh time.Duration) error {
+	return serialization.WriteHTML(p.parser.SessionGraph, w, refresh)
+}
+
+// JaccardEdgeSimilarity compares the edge sets of two graphs, returning the
+// number of shared edges over the number of distinct edges. Edges are matched
+// by their endpoints and must have (almost) equal probabilities to count as
+// shared. Two graphs without edges have similarity 1.
+func JaccardEdgeSimilarity(a, b *PGraph) float64 {
+	return inference.JaccardEdgeSimilarity(a.parser.SessionGraph, b.parser.SessionGraph)
+}
+
+// Indistinguishable reports whether the 95% confidence intervals of a and b
+// overlap. Exact results have a degenerate interval at their probability.
+func Indistinguishable(a, b IntervalResult) bool {
+	return result.Indistinguishable(a, b)
 }
 
 type jsonResult struct {
@@ -75,6 +307,30 @@ type jsonResult struct {
 	Data any    `json:"data"`
 }
 
+type jsonNode struct {
+	ID    string         `json:"id"`
+	Props map[string]any `json:"props,omitempty"`
+}
+
+type jsonEdge struct {
+	ID          string         `json:"id"`
+	From        string         `json:"from"`
+	To          string         `json:"to"`
+	Probability float64        `json:"probability"`
+	Props       map[string]any `json:"props,omitempty"`
+}
+
+func jsonProps(props map[string]graph.Value) map[string]any {
+	if len(props) == 0 {
+		return nil
+	}
+	out := make(map[string]any, len(props))
+	for k, v := range props {
+		out[k] = v.Interface()
+	}
+	return out
+}
+
 func MarshalResultJSON(r Result) ([]byte, error) {
 	var jr jsonResult
 	switch v := r.(type) {
@@ -84,12 +340,64 @@ func MarshalResultJSON(r Result) ([]byte, error) {
 		jr = jsonResult{Kind: "paths", Data: v}
 	case result.ProbabilityResult:
 		jr = jsonResult{Kind: "probability", Data: v}
+	case result.ProbabilityIntervalResult:
+		jr = jsonResult{Kind: "probability_interval", Data: v}
 	case result.SampleResult:
 		jr = jsonResult{Kind: "sample", Data: v}
 	case result.BooleanResult:
 		jr = jsonResult{Kind: "boolean", Data: v}
 	case result.SensitivityResult:
 		jr = jsonResult{Kind: "sensitivity", Data: v}
+	case result.NodeListResult:
+		nodes := make([]jsonNode, len(v.Nodes))
+		for i, n := range v.Nodes {
+			nodes[i] = jsonNode{ID: string(n.ID), Props: jsonProps(n.Props)}
+		}
+		jr = jsonResult{Kind: "nodes", Data: nodes}
+	case result.EdgeListResult:
+		edges := make([]jsonEdge, len(v.Edges))
+		for i, e := range v.Edges {
+			edges[i] = jsonEdge{
+				ID:          string(e.ID),
+				From:        string(e.From),
+				To:          string(e.To),
+				Probability: e.Probability,
+				Props:       jsonProps(e.Props),
+			}
+		}
+		jr = jsonResult{Kind: "edges", Data: edges}
+	case result.HistogramResult:
+		jr = jsonResult{Kind: "histogram", Data: v.Buckets}
+	case result.ComparisonResult:
+		jr = jsonResult{Kind: "comparison", Data: v}
+	case result.NumberResult:
+		jr = jsonResult{Kind: "number", Data: v}
+	case result.ValuesResult:
+		jr = jsonResult{Kind: "values", Data: v}
+	case result.StringResult:
+		jr = jsonResult{Kind: "string", Data: v}
+	case result.ErrorResult:
+		jr = jsonResult{Kind: "error", Data: v.Err.Error()}
+	case result.SampledWorldResult:
+		jr = jsonResult{Kind: "world", Data: v}
+	case result.PolynomialResult:
+		jr = jsonResult{Kind: "polynomial", Data: v.Points}
+	case result.MatrixResult:
+		jr = jsonResult{Kind: "matrix", Data: v}
+	case result.NodeScoresResult:
+		scores := make(map[string]float64, len(v.Scores))
+		for id, s := range v.Scores {
+			scores[string(id)] = s
+		}
+		jr = jsonResult{Kind: "scores", Data: scores}
+	case result.EdgeScoresResult:
+		jr = jsonResult{Kind: "edge_scores", Data: v.Scores}
+	case result.GraphResult:
+		var buf bytes.Buffer
+		if err := serialization.WriteJSON(v.Graph, &buf); err != nil {
+			return nil, err
+		}
+		jr = jsonResult{Kind: "graph", Data: json.RawMessage(buf.Bytes())}
 	case result.MultiResult:
 		items := make([]json.RawMessage, len(v.Results))
 		for i, sub := range v.Results {
@@ -105,3 +413,192 @@ func MarshalResultJSON(r Result) ([]byte, error) {
 	}
 	return json.Marshal(jr)
 }
+
+// UnmarshalResultJSON is the inverse of MarshalResultJSON. Node and edge
+// properties are carried as plain JSON values, so a whole-number float
+// property comes back as an int, and edges lose their Bidirectional flag.
+func UnmarshalResultJSON(data []byte) (Result, error) {
+	var jr struct {
+		Kind string          `json:"kind"`
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(data, &jr); err != nil {
+		return nil, err
+	}
+
+	switch jr.Kind {
+	case "path":
+		return unmarshalData[result.PathResult](jr.Data)
+	case "paths":
+		return unmarshalData[result.PathsResult](jr.Data)
+	case "probability":
+		return unmarshalData[result.ProbabilityResult](jr.Data)
+	case "probability_interval":
+		return unmarshalData[result.ProbabilityIntervalResult](jr.Data)
+	case "sample":
+		return unmarshalData[result.SampleResult](jr.Data)
+	case "boolean":
+		return unmarshalData[result.BooleanResult](jr.Data)
+	case "sensitivity":
+		return unmarshalData[result.SensitivityResult](jr.Data)
+	case "comparison":
+		return unmarshalData[result.ComparisonResult](jr.Data)
+	case "number":
+		return unmarshalData[result.NumberResult](jr.Data)
+	case "values":
+		return unmarshalData[result.ValuesResult](jr.Data)
+	case "string":
+		return unmarshalData[result.StringResult](jr.Data)
+	case "world":
+		return unmarshalData[result.SampledWorldResult](jr.Data)
+	case "matrix":
+		return unmarshalData[result.MatrixResult](jr.Data)
+	case "error":
+		var msg string
+		if err := json.Unmarshal(jr.Data, &msg); err != nil {
+			return nil, err
+		}
+		return result.ErrorResult{Err: errors.New(msg)}, nil
+	case "histogram":
+		var buckets []result.BucketResult
+		if err := json.Unmarshal(jr.Data, &buckets); err != nil {
+			return nil, err
+		}
+		return result.HistogramResult{Buckets: buckets}, nil
+	case "polynomial":
+		var points []result.PolynomialPoint
+		if err := json.Unmarshal(jr.Data, &points); err != nil {
+			return nil, err
+		}
+		return result.PolynomialResult{Points: points}, nil
+	case "scores":
+		var scores map[graph.NodeID]float64
+		if err := json.Unmarshal(jr.Data, &scores); err != nil {
+			return nil, err
+		}
+		return result.NodeScoresResult{Scores: scores}, nil
+	case "edge_scores":
+		var scores map[graph.EdgeID]float64
+		if err := json.Unmarshal(jr.Data, &scores); err != nil {
+			return nil, err
+		}
+		return result.EdgeScoresResult{Scores: scores}, nil
+	case "nodes":
+		var nodes []jsonNode
+		if err := unmarshalUseNumber(jr.Data, &nodes); err != nil {
+			return nil, err
+		}
+		out := make([]*graph.Node, len(nodes))
+		for i, n := range nodes {
+			props, err := valueProps(n.Props)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = &graph.Node{ID: graph.NodeID(n.ID), Props: props}
+		}
+		return result.NodeListResult{Nodes: out}, nil
+	case "edges":
+		var edges []jsonEdge
+		if err := unmarshalUseNumber(jr.Data, &edges); err != nil {
+			return nil, err
+		}
+		out := make([]*graph.Edge, len(edges))
+		for i, e := range edges {
+			props, err := valueProps(e.Props)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = &graph.Edge{
+				ID:          graph.EdgeID(e.ID),
+				From:        graph.NodeID(e.From),
+				To:          graph.NodeID(e.To),
+				Probability: e.Probability,
+				Props:       props,
+			}
+		}
+		return result.EdgeListResult{Edges: out}, nil
+	case "graph":
+		g, err := serialization.ReadJSON(bytes.NewReader(jr.Data))
+		if err != nil {
+			return nil, err
+		}
+		return result.GraphResult{Graph: g}, nil
+	case "multi":
+		var items []json.RawMessage
+		if err := json.Unmarshal(jr.Data, &items); err != nil {
+			return nil, err
+		}
+		results := make([]Result, len(items))
+		for i, item := range items {
+			r, err := UnmarshalResultJSON(item)
+			if err != nil {
+				return nil, err
+			}
+			results[i] = r
+		}
+		return result.MultiResult{Results: results}, nil
+	default:
+		return nil, fmt.Errorf("unknown result kind %q", jr.Kind)
+	}
+}
+
+func unmarshalData[T Result](data json.RawMessage) (Result, error) {
+	var v T
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func unmarshalUseNumber(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+func valueProps(props map[string]any) (map[string]graph.Value, error) {
+	if len(props) == 0 {
+		return nil, nil
+	}
+	out := make(map[string]graph.Value, len(props))
+	for k, raw := range props {
+		v, err := valueFromJSON(raw)
+		if err != nil {
+			return nil, fmt.Errorf("property %q: %w", k, err)
+		}
+		out[k] = v
+	}
+	return out, nil
+}
+
+func valueFromJSON(raw any) (graph.Value, error) {
+	switch x := raw.(type) {
+	case nil:
+		return graph.Value{Kind: graph.NullVal}, nil
+	case bool:
+		return graph.Value{Kind: graph.BoolVal, B: x}, nil
+	case string:
+		return graph.Value{Kind: graph.StringVal, S: x}, nil
+	case json.Number:
+		if i, err := x.Int64(); err == nil {
+			return graph.Value{Kind: graph.IntVal, I: i}, nil
+		}
+		f, err := x.Float64()
+		if err != nil {
+			return graph.Value{}, err
+		}
+		return graph.Value{Kind: graph.FloatVal, F: f}, nil
+	case []any:
+		elems := make([]graph.Value, len(x))
+		for i, e := range x {
+			v, err := valueFromJSON(e)
+			if err != nil {
+				return graph.Value{}, err
+			}
+			elems[i] = v
+		}
+		return graph.Value{Kind: graph.ArrayVal, A: elems}, nil
+	default:
+		return graph.Value{}, fmt.Errorf("unsupported JSON value %T", raw)
+	}
+}
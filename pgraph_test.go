@@ -0,0 +1,293 @@
+package pgraph
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ritamzico/pgraph/internal/graph"
+)
+
+func TestUnmarshalResultJSON_RoundTrip(t *testing.T) {
+	path := graph.Path{NodeIDs: []graph.NodeID{"A", "B", "D"}, Probability: 0.63}
+
+	tests := []struct {
+		name string
+		r    Result
+	}{
+		{"path", PathResult{Path: path}},
+		{"paths", PathsResult{Paths: []graph.Path{path, {NodeIDs: []graph.NodeID{"A", "C", "D"}, Probability: 0.48}}}},
+		{"probability", ProbabilityResult{Probability: 0.8076}},
+		{"sample", SampleResult{Estimate: 0.81, NumSamples: 10000, Variance: 0.15, StdErr: 0.0039}},
+		{"boolean", BooleanResult{Value: true}},
+		{"sensitivity", SensitivityResult{
+			Baseline: 0.8076,
+			Impacts: []EdgeImpact{
+				{EdgeID: "eAB", From: "A", To: "B", Probability: 0.9, Without: 0.48, Delta: 0.3276},
+			},
+		}},
+		{"nodes", NodeListResult{Nodes: []*graph.Node{
+			{ID: "A", Props: map[string]Value{
+				"count":  {Kind: IntVal, I: 3},
+				"weight": {Kind: FloatVal, F: 1.5},
+				"name":   {Kind: StringVal, S: "alpha"},
+				"tags":   {Kind: ArrayVal, A: []Value{{Kind: StringVal, S: "x"}}},
+				"gone":   {Kind: NullVal},
+			}},
+			{ID: "B"},
+		}}},
+		{"edges", EdgeListResult{Edges: []*graph.Edge{
+			{ID: "eAB", From: "A", To: "B", Probability: 0.9, Props: map[string]Value{"ok": {Kind: BoolVal, B: true}}},
+		}}},
+		{"histogram", HistogramResult{Buckets: []BucketResult{{Low: 0, High: 0.1, Count: 2}, {Low: 0.1, High: 0.2}}}},
+		{"scores", NodeScoresResult{Scores: map[graph.NodeID]float64{"A": 0.25, "B": 0.75}}},
+		{"probability_interval", ProbabilityIntervalResult{Low: 0.6, High: 0.8}},
+		{"string", StringResult{Value: `{"nodes":[],"edges":[]}`}},
+		{"edge_scores", EdgeScoresResult{Scores: map[graph.EdgeID]float64{"e1": 0.5, "e2": 0}}},
+		{"polynomial", PolynomialResult{Points: []PolynomialPoint{{P: 0, Reliability: 0}, {P: 1, Reliability: 1}}}},
+		{"comparison", ComparisonResult{
+			Exact:     ProbabilityResult{Probability: 0.8},
+			Sample:    SampleResult{Estimate: 0.79, NumSamples: 100},
+			Deviation: 0.01,
+		}},
+		{"number", NumberResult{Value: 3}},
+		{"matrix", MatrixResult{
+			Rows:   []graph.NodeID{"A", "B"},
+			Cols:   []graph.NodeID{"D"},
+			Values: [][]float64{{0.6384}, {0.7}},
+		}},
+		{"values", ValuesResult{Values: []float64{0.63, 0.48}}},
+		{"error", ErrorResult{Err: errors.New("node not found: X")}},
+		{"world", SampledWorldResult{Seed: 7, ActiveEdges: []graph.EdgeID{"e1"}, InactiveEdges: []graph.EdgeID{}}},
+		{"multi", MultiResult{Results: []Result{
+			ProbabilityResult{Probability: 0.5},
+			MultiResult{Results: []Result{BooleanResult{Value: false}}},
+		}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := MarshalResultJSON(tt.r)
+			if err != nil {
+				t.Fatalf("MarshalResultJSON: %v", err)
+			}
+			got, err := UnmarshalResultJSON(data)
+			if err != nil {
+				t.Fatalf("UnmarshalResultJSON: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.r) {
+				t.Errorf("round trip mismatch:\n got  %#v\n want %#v", got, tt.r)
+			}
+		})
+	}
+}
+
+func TestUnmarshalResultJSON_Graph(t *testing.T) {
+	pg := newTestPGraph(t)
+	r, err := pg.Query("SUBGRAPH INDUCED BY A, B")
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	data, err := MarshalResultJSON(r)
+	if err != nil {
+		t.Fatalf("MarshalResultJSON: %v", err)
+	}
+	got, err := UnmarshalResultJSON(data)
+	if err != nil {
+		t.Fatalf("UnmarshalResultJSON: %v", err)
+	}
+	gr, ok := got.(GraphResult)
+	if !ok {
+		t.Fatalf("expected GraphResult, got %T", got)
+	}
+	e, err := gr.Graph.GetEdge("A", "B")
+	if err != nil {
+		t.Fatalf("GetEdge: %v", err)
+	}
+	if e.ID != "eAB" || e.Probability != 0.9 {
+		t.Errorf("edge = %+v, want eAB with probability 0.9", e)
+	}
+}
+
+func TestUnmarshalResultJSON_Errors(t *testing.T) {
+	for _, input := range []string{
+		`not json`,
+		`{"kind": "unknown", "data": "x"}`,
+		`{"kind": "probability", "data": "x"}`,
+		`{"kind": "multi", "data": [{"kind": "bogus"}]}`,
+	} {
+		if _, err := UnmarshalResultJSON([]byte(input)); err == nil {
+			t.Errorf("UnmarshalResultJSON(%s): expected error", input)
+		}
+	}
+}
+
+func TestQueryContext_CancelledBeforeRun(t *testing.T) {
+	pg := newTestPGraph(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := pg.QueryContext(ctx, "REACHABILITY FROM A TO B"); !errors.Is(err, context.Canceled) {
+		t.Errorf("query: expected context.Canceled, got %v", err)
+	}
+	if _, err := pg.QueryContext(ctx, "CREATE NODE C"); !errors.Is(err, context.Canceled) {
+		t.Errorf("statement: expected context.Canceled, got %v", err)
+	}
+	if pg.parser.SessionGraph.ContainsNode("C") {
+		t.Error("statement ran despite cancelled context")
+	}
+}
+
+func TestQueryContext_DeadlineStopsInference(t *testing.T) {
+	pg := newTestPGraph(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	// This width needs far more samples than the deadline allows.
+	_, err := pg.QueryContext(ctx, "CONFIDENCE FROM A TO B WIDTH 0.0001")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestEnablePropertyIndex(t *testing.T) {
+	pg := New()
+	for _, line := range []string{
+		`CREATE NODE A { region: "US" }`,
+		`CREATE NODE B { region: "EU" }`,
+	} {
+		if _, err := pg.Query(line); err != nil {
+			t.Fatalf("Query(%q): %v", line, err)
+		}
+	}
+	if err := pg.EnablePropertyIndex("region"); err != nil {
+		t.Fatalf("EnablePropertyIndex: %v", err)
+	}
+	if _, err := pg.Query(`CREATE NODE C { region: "US" }`); err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+
+	r, err := pg.Query(`FIND NODES WHERE region = "US"`)
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	nodes := r.(NodeListResult).Nodes
+	if len(nodes) != 2 || nodes[0].ID != "A" || nodes[1].ID != "C" {
+		t.Errorf("expected nodes A and C, got %v", nodes)
+	}
+}
+
+func TestEnablePropertyIndex_AfterHooks(t *testing.T) {
+	pg := New()
+	pg.OnNodeAdded(func(NodeID) {})
+	if err := pg.EnablePropertyIndex("region"); err != nil {
+		t.Fatalf("EnablePropertyIndex: %v", err)
+	}
+	if _, err := pg.Query(`CREATE NODE A { region: "US" }`); err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+
+	pi, ok := pg.parser.SessionGraph.(graph.PropertyIndexer)
+	if !ok {
+		t.Fatalf("expected %T to expose the property index", pg.parser.SessionGraph)
+	}
+	nodes, ok := pi.NodesWithProperty("region", Value{Kind: graph.StringVal, S: "US"})
+	if !ok || len(nodes) != 1 || nodes[0].ID != "A" {
+		t.Errorf("expected an index lookup to find A, got %v (indexed %v)", nodes, ok)
+	}
+}
+
+func TestEnablePropertyIndex_ConditionalInactiveNode(t *testing.T) {
+	pg := New()
+	for _, line := range []string{
+		`CREATE NODE A { region: "eu" }`,
+		`CREATE NODE X { region: "eu" }`,
+	} {
+		if _, err := pg.Query(line); err != nil {
+			t.Fatalf("Query(%q): %v", line, err)
+		}
+	}
+	if err := pg.EnablePropertyIndex("region"); err != nil {
+		t.Fatalf("EnablePropertyIndex: %v", err)
+	}
+
+	r, err := pg.Query(`CONDITIONAL GIVEN NODE X INACTIVE ( FIND NODES WHERE region = "eu" )`)
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	nodes := r.(NodeListResult).Nodes
+	if len(nodes) != 1 || nodes[0].ID != "A" {
+		t.Errorf("expected only node A, got %v", nodes)
+	}
+}
+
+func TestEnableQueryCache(t *testing.T) {
+	pg := newTestPGraph(t)
+	pg.EnableQueryCache(0, 0)
+
+	for range 2 {
+		r, err := pg.Query("REACHABILITY FROM A TO B")
+		if err != nil {
+			t.Fatalf("Query: %v", err)
+		}
+		if p := r.(ProbabilityResult).Probability; p != 0.9 {
+			t.Fatalf("expected 0.9, got %v", p)
+		}
+	}
+	if hits, misses := pg.QueryCacheStats(); hits != 1 || misses != 1 {
+		t.Fatalf("expected 1 hit and 1 miss, got %d and %d", hits, misses)
+	}
+
+	if _, err := pg.Query("CREATE NODE C"); err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if _, err := pg.Query("REACHABILITY FROM A TO B"); err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if hits, misses := pg.QueryCacheStats(); hits != 1 || misses != 2 {
+		t.Errorf("expected the mutation to cause a miss, got %d hits and %d misses", hits, misses)
+	}
+}
+
+func TestEnableIncrementalCache(t *testing.T) {
+	pg := newTestPGraph(t)
+	pg.EnableIncrementalCache()
+	pg.EnableAuditLog()
+
+	reach := func() float64 {
+		t.Helper()
+		r, err := pg.Query("REACHABILITY FROM A TO B EXACT")
+		if err != nil {
+			t.Fatalf("Query: %v", err)
+		}
+		return r.(ProbabilityResult).Probability
+	}
+
+	if p := reach(); p != 0.9 {
+		t.Fatalf("expected 0.9, got %v", p)
+	}
+	if err := pg.UpdateEdge("eAB", 0.5); err != nil {
+		t.Fatalf("UpdateEdge: %v", err)
+	}
+	if p := reach(); p != 0.5 {
+		t.Errorf("expected 0.5 after UpdateEdge, got %v", p)
+	}
+	if _, err := pg.Query("UPDATE EDGE eAB PROB 0.25"); err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if p := reach(); p != 0.25 {
+		t.Errorf("expected 0.25 after UPDATE EDGE, got %v", p)
+	}
+
+	var updated []EdgeID
+	for _, m := range pg.AuditLog() {
+		if m.Kind == MutationUpdateEdge {
+			updated = append(updated, m.Args.Edge)
+		}
+	}
+	if !reflect.DeepEqual(updated, []EdgeID{"eAB", "eAB"}) {
+		t.Errorf("expected both updates in the audit log, got %v", updated)
+	}
+}
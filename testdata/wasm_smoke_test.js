@@ -0,0 +1,73 @@
+// Smoke test for the pgraph WebAssembly build. Build the binary and run:
+//
+//   GOOS=js GOARCH=wasm go build -o bin/pgraph.wasm ./cmd/wasm
+//   node testdata/wasm_smoke_test.js bin/pgraph.wasm
+//
+// wasm_exec.js is taken from the Go installation, found with `go env GOROOT`.
+
+"use strict";
+
+const assert = require("assert");
+const { execSync } = require("child_process");
+const fs = require("fs");
+const path = require("path");
+
+if (process.argv.length < 3) {
+	console.error("usage: node wasm_smoke_test.js <pgraph.wasm>");
+	process.exit(1);
+}
+
+const goroot = execSync("go env GOROOT").toString().trim();
+require(path.join(goroot, "lib", "wasm", "wasm_exec.js"));
+
+const graphJSON = JSON.stringify({
+	nodes: [{ id: "A" }, { id: "B" }, { id: "C" }],
+	edges: [
+		{ id: "eAB", from: "A", to: "B", probability: 0.9 },
+		{ id: "eBC", from: "B", to: "C", probability: 0.8 },
+	],
+});
+
+function query(handle, dsl) {
+	const res = pgraphQuery(handle, dsl);
+	assert.ok(!(res instanceof Error), `${dsl}: ${res}`);
+	return JSON.parse(res);
+}
+
+async function main() {
+	const go = new Go();
+	const { instance } = await WebAssembly.instantiate(fs.readFileSync(process.argv[2]), go.importObject);
+	// run resolves only when the Go program exits; the exports are
+	// registered once it first blocks.
+	go.run(instance);
+
+	// pgraphNew plus DSL mutations.
+	const g = pgraphNew();
+	query(g, "CREATE NODE X");
+	query(g, "CREATE NODE Y");
+	query(g, "CREATE EDGE eXY FROM X TO Y PROB 0.5");
+	const reach = query(g, "REACHABILITY FROM X TO Y EXACT");
+	assert.strictEqual(reach.kind, "probability");
+	assert.ok(Math.abs(reach.data.Probability - 0.5) < 1e-9, JSON.stringify(reach));
+
+	// pgraphLoad from a JSON string.
+	const h = pgraphLoad(graphJSON);
+	assert.ok(!(h instanceof Error), `pgraphLoad: ${h}`);
+	assert.notStrictEqual(h, g);
+	const best = query(h, "MAXPATH FROM A TO C");
+	assert.strictEqual(best.kind, "path");
+	assert.deepStrictEqual(best.data.Path.NodeIDs, ["A", "B", "C"]);
+
+	// Failures come back as Error values.
+	assert.ok(pgraphLoad("{not json") instanceof Error);
+	assert.ok(pgraphQuery(h, "MAXPATH FROM A TO nowhere") instanceof Error);
+	assert.ok(pgraphQuery(12345, "MAXPATH FROM A TO C") instanceof Error);
+	assert.ok(pgraphQuery(h) instanceof Error);
+
+	console.log("wasm smoke test passed");
+}
+
+main().catch((err) => {
+	console.error(err);
+	process.exit(1);
+});
@@ -0,0 +1,138 @@
+package pgraph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ritamzico/pgraph/internal/dsl"
+	"github.com/ritamzico/pgraph/internal/schema"
+)
+
+// ErrTxDone is returned by PGraphTx methods called after Commit or Rollback.
+var ErrTxDone = errors.New("transaction has already been committed or rolled back")
+
+// ErrTxConflict is returned by Commit when the parent's graph has changed
+// since BeginTx.
+var ErrTxConflict = errors.New("graph was changed after the transaction began")
+
+// PGraphTx stages mutations against a private copy of a PGraph's graph.
+// Nothing is visible to the parent until Commit.
+type PGraphTx struct {
+	parent  *PGraph
+	parser  dsl.Parser
+	done    bool
+	version uint64 // the parent graph's version at BeginTx
+
+	// staged records the transaction's mutations, and fired the parent
+	// hooks each of them fires on Commit.
+	staged hooks
+	fired  []func(*hooks)
+}
+
+// BeginTx starts a transaction on a snapshot of the current graph, with the
+// same engine settings and caches enabled. Changing the parent's graph
+// before Commit, directly or by committing another transaction, makes
+// Commit fail with ErrTxConflict.
+func (p *PGraph) BeginTx() *PGraphTx {
+	tx := &PGraphTx{parent: p, parser: p.parser.Fork(), version: p.parser.SessionGraph.Version()}
+	tx.staged = hooks{
+		nodeAdded:   []func(NodeID){func(id NodeID) { tx.queue(func(h *hooks) { fireNode(h.nodeAdded, id) }) }},
+		edgeAdded:   []func(EdgeID){func(id EdgeID) { tx.queue(func(h *hooks) { fireEdge(h.edgeAdded, id) }) }},
+		nodeRemoved: []func(NodeID){func(id NodeID) { tx.queue(func(h *hooks) { fireNode(h.nodeRemoved, id) }) }},
+		edgeRemoved: []func(EdgeID){func(id EdgeID) { tx.queue(func(h *hooks) { fireEdge(h.edgeRemoved, id) }) }},
+		audit:       &auditLog{},
+	}
+	tx.parser.ReplaceSessionGraph(hookedGraph{ProbabilisticGraphModel: tx.parser.SessionGraph, h: &tx.staged})
+	return tx
+}
+
+// queue adds fire to run against the parent's hooks on Commit.
+func (tx *PGraphTx) queue(fire func(*hooks)) {
+	tx.fired = append(tx.fired, fire)
+}
+
+func (tx *PGraphTx) AddNode(id NodeID, props map[string]Value) error {
+	if tx.done {
+		return ErrTxDone
+	}
+	return tx.parser.SessionGraph.AddNode(id, props)
+}
+
+func (tx *PGraphTx) RemoveNode(id NodeID) error {
+	if tx.done {
+		return ErrTxDone
+	}
+	return tx.parser.SessionGraph.RemoveNode(id)
+}
+
+func (tx *PGraphTx) AddEdge(id EdgeID, from, to NodeID, prob float64, props map[string]Value) error {
+	if tx.done {
+		return ErrTxDone
+	}
+	return tx.parser.SessionGraph.AddEdge(id, from, to, prob, props)
+}
+
+func (tx *PGraphTx) RemoveEdge(from, to NodeID) error {
+	if tx.done {
+		return ErrTxDone
+	}
+	return tx.parser.SessionGraph.RemoveEdge(from, to)
+}
+
+// Query runs a DSL statement or query against the transaction's graph.
+//
+// Deprecated: Query cannot be cancelled; use QueryContext.
+func (tx *PGraphTx) Query(dslQuery string) (Result, error) {
+	return tx.QueryContext(context.Background(), dslQuery)
+}
+
+// QueryContext is like Query but runs under ctx, as PGraph.QueryContext does.
+func (tx *PGraphTx) QueryContext(ctx context.Context, dslQuery string) (Result, error) {
+	if tx.done {
+		return nil, ErrTxDone
+	}
+	return tx.parser.ParseLineContext(ctx, dslQuery)
+}
+
+// Commit makes the transaction's graph the parent's graph, then fires the
+// parent's hooks for each staged mutation in order. If the parent's graph
+// has changed since BeginTx, Commit returns ErrTxConflict and rolls the
+// transaction back, leaving the parent as it is. If the parent has a schema
+// and the staged graph violates it, Commit fails and the transaction stays
+// open so it can be fixed or rolled back. If the parent's audit log is
+// enabled, the transaction is recorded as a single commit_tx event.
+func (tx *PGraphTx) Commit() error {
+	if tx.done {
+		return ErrTxDone
+	}
+	p := tx.parent
+	if p.parser.SessionGraph.Version() != tx.version {
+		tx.Rollback()
+		return ErrTxConflict
+	}
+
+	g := tx.parser.SessionGraph.(hookedGraph).ProbabilisticGraphModel
+	if s := p.schema; s != nil {
+		if errs := schema.Validate(g, *s); len(errs) > 0 {
+			return fmt.Errorf("graph does not conform to schema (%d violations, first: %w)", len(errs), errs[0])
+		}
+	}
+	p.parser.ReplaceSessionGraph(hookedGraph{ProbabilisticGraphModel: g, h: &p.hooks})
+	p.recordCommit(tx.staged.audit.events)
+	for _, fire := range tx.fired {
+		fire(&p.hooks)
+	}
+	tx.done = true
+	return nil
+}
+
+// Rollback discards the transaction's changes.
+func (tx *PGraphTx) Rollback() error {
+	if tx.done {
+		return ErrTxDone
+	}
+	tx.parser = dsl.Parser{}
+	tx.done = true
+	return nil
+}
@@ -0,0 +1,181 @@
+package pgraph
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ritamzico/pgraph/internal/query"
+)
+
+func newTestPGraph(t *testing.T) *PGraph {
+	t.Helper()
+	pg := New()
+	for _, line := range []string{
+		"CREATE NODE A, B",
+		"CREATE EDGE eAB FROM A TO B PROB 0.9",
+	} {
+		if _, err := pg.Query(line); err != nil {
+			t.Fatalf("Query(%q): %v", line, err)
+		}
+	}
+	return pg
+}
+
+func TestTx_CommitAppliesChanges(t *testing.T) {
+	pg := newTestPGraph(t)
+	tx := pg.BeginTx()
+
+	if err := tx.AddNode("C", nil); err != nil {
+		t.Fatalf("AddNode: %v", err)
+	}
+	if err := tx.AddEdge("eBC", "B", "C", 0.5, nil); err != nil {
+		t.Fatalf("AddEdge: %v", err)
+	}
+	if _, err := tx.Query("DELETE EDGE eAB"); err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+
+	// Staged changes are invisible to the parent until Commit.
+	if _, err := pg.Query("MAXPATH FROM B TO C"); err == nil {
+		t.Error("expected parent not to see uncommitted node C")
+	}
+
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+
+	res, err := pg.Query("REACHABILITY FROM B TO C EXACT")
+	if err != nil {
+		t.Fatalf("Query after commit: %v", err)
+	}
+	if p := res.(ProbabilityResult).Probability; p != 0.5 {
+		t.Errorf("expected committed edge with probability 0.5, got %f", p)
+	}
+	res, _ = pg.Query("REACHABILITY FROM A TO B EXACT")
+	if p := res.(ProbabilityResult).Probability; p != 0 {
+		t.Errorf("expected committed deletion of eAB, got %f", p)
+	}
+}
+
+func TestTx_RollbackDiscardsChanges(t *testing.T) {
+	pg := newTestPGraph(t)
+	tx := pg.BeginTx()
+
+	if err := tx.RemoveNode("B"); err != nil {
+		t.Fatalf("RemoveNode: %v", err)
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatalf("Rollback: %v", err)
+	}
+
+	res, err := pg.Query("REACHABILITY FROM A TO B EXACT")
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if p := res.(ProbabilityResult).Probability; p != 0.9 {
+		t.Errorf("expected original graph after rollback, got %f", p)
+	}
+}
+
+func TestTx_DoneTransactionRejectsCalls(t *testing.T) {
+	pg := newTestPGraph(t)
+	tx := pg.BeginTx()
+	tx.Commit()
+
+	if err := tx.AddNode("C", nil); !errors.Is(err, ErrTxDone) {
+		t.Errorf("expected ErrTxDone from AddNode, got %v", err)
+	}
+	if err := tx.Commit(); !errors.Is(err, ErrTxDone) {
+		t.Errorf("expected ErrTxDone from Commit, got %v", err)
+	}
+	if err := tx.Rollback(); !errors.Is(err, ErrTxDone) {
+		t.Errorf("expected ErrTxDone from Rollback, got %v", err)
+	}
+}
+
+func TestTx_CommitEnforcesSchema(t *testing.T) {
+	pg := newTestPGraph(t)
+	if err := pg.SetSchema(Schema{NodeProps: map[string]ValueKind{"region": StringVal}}); err != nil {
+		t.Fatalf("SetSchema: %v", err)
+	}
+
+	tx := pg.BeginTx()
+	tx.AddNode("C", map[string]Value{"region": {Kind: IntVal, I: 1}})
+	if err := tx.Commit(); err == nil {
+		t.Fatal("expected schema violation on commit")
+	}
+
+	// The transaction stays open after a failed commit.
+	if err := tx.RemoveNode("C"); err != nil {
+		t.Fatalf("RemoveNode after failed commit: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Errorf("expected commit to succeed once fixed: %v", err)
+	}
+}
+
+func TestTx_CommitConflictsWithParentChange(t *testing.T) {
+	pg := newTestPGraph(t)
+	tx := pg.BeginTx()
+	if err := tx.AddNode("C", nil); err != nil {
+		t.Fatalf("AddNode: %v", err)
+	}
+	if err := pg.AddNode("X", nil); err != nil {
+		t.Fatalf("AddNode on parent: %v", err)
+	}
+
+	if err := tx.Commit(); !errors.Is(err, ErrTxConflict) {
+		t.Fatalf("expected ErrTxConflict, got %v", err)
+	}
+	// The parent's change survives and the transaction's is dropped.
+	if _, err := pg.Query("CREATE EDGE eAX FROM A TO X PROB 0.5"); err != nil {
+		t.Errorf("expected parent node X to survive the failed commit: %v", err)
+	}
+	if _, err := pg.Query("CREATE EDGE eAC FROM A TO C PROB 0.5"); err == nil {
+		t.Error("expected the conflicting transaction's node C to be discarded")
+	}
+	if err := tx.Rollback(); !errors.Is(err, ErrTxDone) {
+		t.Errorf("expected the conflicting transaction to be done, got %v", err)
+	}
+}
+
+func TestTx_CommitConflictsWithEarlierCommit(t *testing.T) {
+	pg := newTestPGraph(t)
+	first, second := pg.BeginTx(), pg.BeginTx()
+	first.AddNode("C", nil)
+	second.AddNode("D", nil)
+
+	if err := first.Commit(); err != nil {
+		t.Fatalf("first Commit: %v", err)
+	}
+	if err := second.Commit(); !errors.Is(err, ErrTxConflict) {
+		t.Errorf("expected ErrTxConflict from the second commit, got %v", err)
+	}
+	if s := pg.Stats(); s.NodeCount != 3 {
+		t.Errorf("expected A, B and C, got %d nodes", s.NodeCount)
+	}
+}
+
+func TestTx_ReadsDoNotConflict(t *testing.T) {
+	pg := newTestPGraph(t)
+	tx := pg.BeginTx()
+	tx.AddNode("C", nil)
+	if _, err := pg.Query("REACHABILITY FROM A TO B EXACT"); err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Errorf("expected a read on the parent not to conflict: %v", err)
+	}
+}
+
+func TestTx_KeepsParentSettings(t *testing.T) {
+	pg := newTestPGraph(t)
+	pg.SetMinEdgeDensity(0.9)
+
+	tx := pg.BeginTx()
+	_, err := tx.Query("REACHABILITY FROM A TO B EXACT")
+	var qe query.QueryError
+	if !errors.As(err, &qe) || qe.Kind != "LowDensityGraph" {
+		t.Errorf("expected the transaction to keep MinEdgeDensity, got %v", err)
+	}
+}